@@ -54,6 +54,11 @@ Cache:
     NonBlock: false
     PingTimeout: 0s
 
+CORS:
+  Enabled: false
+  AllowOrigins: []
+  AllowHeaders: []
+
 Auth:
   AccessSecret: test
   AccessExpire: 1h
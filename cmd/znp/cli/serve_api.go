@@ -79,6 +79,60 @@ func RunServices(ctx context.Context, cfg config.Config) error {
 		kernellogic.RunStatusPoller(runCtx, svcCtx)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunBindingReconciler(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunSyncJobWorker(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunBindingRolloutWorker(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunSubscriptionEnforcer(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunDeviceEnforcer(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunTLSCertificateChecker(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunTrafficCollector(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunAuditCollector(runCtx, svcCtx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernellogic.RunEventStreamConsumer(runCtx, svcCtx)
+	}()
+
 	var runErr error
 	select {
 	case <-runCtx.Done():
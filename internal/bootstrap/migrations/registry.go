@@ -269,8 +269,479 @@ var migrationRegistry = []Migration{
 			return nil
 		},
 	},
+	{
+		Version: 2026101701,
+		Name:    "protocol-binding-sync-queue",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.ProtocolBinding{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasIndex(&repository.ProtocolBinding{}, "SyncRequestedAt") {
+				if err := migrator.DropIndex(&repository.ProtocolBinding{}, "SyncRequestedAt"); err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"sync_requested_at", "sync_request_reason"} {
+				if migrator.HasColumn(&repository.ProtocolBinding{}, column) {
+					if err := migrator.DropColumn(&repository.ProtocolBinding{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101702,
+		Name:    "kernel-traffic-cursors",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.KernelTrafficCursor{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.KernelTrafficCursor{})
+		},
+	},
+	{
+		Version: 2026101703,
+		Name:    "node-kernel-event-mode",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.Node{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.Node{}, "kernel_event_mode") {
+				return migrator.DropColumn(&repository.Node{}, "kernel_event_mode")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101801,
+		Name:    "plan-rate-limits",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.Plan{}, &repository.UserRateLimit{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if err := migrator.DropTable(&repository.UserRateLimit{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"upload_rate_bytes", "download_rate_bytes"} {
+				if migrator.HasColumn(&repository.Plan{}, column) {
+					if err := migrator.DropColumn(&repository.Plan{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101802,
+		Name:    "subscription-device-limits",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.SubscriptionDevice{},
+				&repository.DeviceLimitViolation{},
+				&repository.DevicePolicySetting{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(
+				&repository.DevicePolicySetting{},
+				&repository.DeviceLimitViolation{},
+				&repository.SubscriptionDevice{},
+			)
+		},
+	},
+	{
+		Version: 2026101803,
+		Name:    "node-tls-certificates",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.NodeTLSCertificate{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.NodeTLSCertificate{})
+		},
+	},
+	{
+		Version: 2026101804,
+		Name:    "kernel-audit-ingestion",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.AuditLog{}, &repository.KernelAuditCursor{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if err := migrator.DropTable(&repository.KernelAuditCursor{}); err != nil {
+				return err
+			}
+			if migrator.HasIndex(&repository.AuditLog{}, "idx_audit_logs_node_external") {
+				if err := migrator.DropIndex(&repository.AuditLog{}, "idx_audit_logs_node_external"); err != nil {
+					return err
+				}
+			}
+			if migrator.HasIndex(&repository.AuditLog{}, "Source") {
+				if err := migrator.DropIndex(&repository.AuditLog{}, "Source"); err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"source", "node_id", "external_id"} {
+				if migrator.HasColumn(&repository.AuditLog{}, column) {
+					if err := migrator.DropColumn(&repository.AuditLog{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101805,
+		Name:    "node-config-snapshots",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.NodeConfigSnapshot{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.NodeConfigSnapshot{})
+		},
+	},
+	{
+		Version: 2026101806,
+		Name:    "node-bootstraps",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.NodeBootstrap{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.NodeBootstrap{})
+		},
+	},
+	{
+		Version: 2026101807,
+		Name:    "sync-jobs",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.SyncJob{}, &repository.SyncJobItem{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.SyncJobItem{}, &repository.SyncJob{})
+		},
+	},
+	{
+		Version: 2026101808,
+		Name:    "binding-rollouts",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.BindingRollout{}, &repository.BindingRolloutItem{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.BindingRolloutItem{}, &repository.BindingRollout{})
+		},
+	},
+	{
+		Version: 2026101809,
+		Name:    "node-port-range",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.Node{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			for _, column := range []string{"port_range_start", "port_range_end"} {
+				if migrator.HasColumn(&repository.Node{}, column) {
+					if err := migrator.DropColumn(&repository.Node{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101810,
+		Name:    "binding-upstream",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.ProtocolBinding{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.ProtocolBinding{}, "upstream_binding_id") {
+				return migrator.DropColumn(&repository.ProtocolBinding{}, "upstream_binding_id")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101811,
+		Name:    "entry-health-policy",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.SiteSetting{}, &repository.ProtocolEntry{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			for _, column := range []string{"unhealthy_entry_policy", "unhealthy_entry_suffix"} {
+				if migrator.HasColumn(&repository.SiteSetting{}, column) {
+					if err := migrator.DropColumn(&repository.SiteSetting{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			if migrator.HasColumn(&repository.ProtocolEntry{}, "priority") {
+				return migrator.DropColumn(&repository.ProtocolEntry{}, "priority")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101812,
+		Name:    "subscription-template-usage",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.SubscriptionTemplateUsage{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			return db.WithContext(ctx).Migrator().DropTable(&repository.SubscriptionTemplateUsage{})
+		},
+	},
+	{
+		Version: 2026101813,
+		Name:    "subscription-status-reason",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			// Existing rows get no reason: whether an admin or the enforcer parked
+			// them is unknown, so they are not restored automatically.
+			return db.WithContext(ctx).AutoMigrate(&repository.Subscription{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.Subscription{}, "status_reason") {
+				return migrator.DropColumn(&repository.Subscription{}, "status_reason")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101814,
+		Name:    "protocol-binding-applied-profile",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.ProtocolBinding{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.ProtocolBinding{}, "applied_profile") {
+				return migrator.DropColumn(&repository.ProtocolBinding{}, "applied_profile")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101815,
+		Name:    "kernel-audit-cursor-last-id",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.KernelAuditCursor{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.KernelAuditCursor{}, "last_id") {
+				return migrator.DropColumn(&repository.KernelAuditCursor{}, "last_id")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101816,
+		Name:    "node-tls-certificate-drop-pem",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			// The kernel loads certificates from files on the node; the panel no
+			// longer keeps uploaded PEM as a fallback.
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&legacyNodeTLSCertificate{}, "certificate_pem") {
+				return migrator.DropColumn(&legacyNodeTLSCertificate{}, "certificate_pem")
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if !migrator.HasColumn(&legacyNodeTLSCertificate{}, "certificate_pem") {
+				return migrator.AddColumn(&legacyNodeTLSCertificate{}, "CertificatePEM")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101817,
+		Name:    "kernel-traffic-cursor-per-user",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return mergeUserTrafficCursors(ctx, db)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			// Merged cursors stay valid for a per-binding collector: it only
+			// reads cursors keyed by binding and starts those from scratch.
+			return nil
+		},
+	},
+	{
+		Version: 2026101818,
+		Name:    "protocol-binding-applied-spec",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.ProtocolBinding{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.ProtocolBinding{}, "applied_spec") {
+				return migrator.DropColumn(&repository.ProtocolBinding{}, "applied_spec")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026101819,
+		Name:    "protocol-binding-sync-request-full",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.ProtocolBinding{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			if db == nil {
+				return fmt.Errorf("migrations: database connection is required")
+			}
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.ProtocolBinding{}, "sync_request_full") {
+				return migrator.DropColumn(&repository.ProtocolBinding{}, "sync_request_full")
+			}
+			return nil
+		},
+	},
+}
+
+// mergeUserTrafficCursors folds per-binding user cursors into one cursor per
+// node and user. The kernel keeps one counter per user, so every binding
+// cursor of a user saw the same counter; push callbacks only advanced the
+// binding they reported, so their bytes add up.
+func mergeUserTrafficCursors(ctx context.Context, db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("migrations: database connection is required")
+	}
+	var cursors []repository.KernelTrafficCursor
+	if err := db.WithContext(ctx).
+		Where("protocol_binding_id <> 0 AND user_id <> 0").
+		Order("id ASC").
+		Find(&cursors).Error; err != nil {
+		return err
+	}
+	if len(cursors) == 0 {
+		return nil
+	}
+
+	type scope struct{ node, user uint64 }
+	merged := make(map[scope]repository.KernelTrafficCursor)
+	order := make([]scope, 0, len(cursors))
+	ids := make([]uint64, 0, len(cursors))
+	for _, cursor := range cursors {
+		ids = append(ids, cursor.ID)
+		key := scope{node: cursor.NodeID, user: cursor.UserID}
+		current, ok := merged[key]
+		if !ok {
+			order = append(order, key)
+			merged[key] = repository.KernelTrafficCursor{
+				NodeID:      cursor.NodeID,
+				UserID:      cursor.UserID,
+				Counter:     cursor.Counter,
+				PushedBytes: cursor.PushedBytes,
+				PulledAt:    cursor.PulledAt,
+			}
+			continue
+		}
+		current.Counter = max(current.Counter, cursor.Counter)
+		current.PushedBytes += cursor.PushedBytes
+		if cursor.PulledAt.After(current.PulledAt) {
+			current.PulledAt = cursor.PulledAt
+		}
+		merged[key] = current
+	}
+
+	if err := db.WithContext(ctx).Where("id IN ?", ids).Delete(&repository.KernelTrafficCursor{}).Error; err != nil {
+		return err
+	}
+	for _, key := range order {
+		cursor := merged[key]
+		var existing int64
+		if err := db.WithContext(ctx).Model(&repository.KernelTrafficCursor{}).
+			Where("node_id = ? AND protocol_binding_id = 0 AND user_id = ?", key.node, key.user).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			continue
+		}
+		if err := db.WithContext(ctx).Create(&cursor).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyNodeTLSCertificate describes the dropped certificate_pem column.
+type legacyNodeTLSCertificate struct {
+	CertificatePEM string `gorm:"column:certificate_pem;type:text"`
 }
 
+func (legacyNodeTLSCertificate) TableName() string { return "node_tls_certificates" }
+
 type statusColumn struct {
 	table   string
 	column  string
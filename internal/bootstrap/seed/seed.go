@@ -1011,6 +1011,8 @@ func seedOrders(tx *gorm.DB) error {
 			"duration_days":       plan.DurationDays,
 			"traffic_limit_bytes": plan.TrafficLimitBytes,
 			"devices_limit":       plan.DevicesLimit,
+			"upload_rate_bytes":   plan.UploadRateBytes,
+			"download_rate_bytes": plan.DownloadRateBytes,
 			"features":            plan.Features,
 			"tags":                plan.Tags,
 		},
@@ -13,17 +13,22 @@ import (
 type Config struct {
 	rest.RestConf
 
-	Project     ProjectConfig    `json:"project" yaml:"Project"`
-	Site        SiteConfig       `json:"site" yaml:"Site"`
-	Database    database.Config  `json:"database" yaml:"Database"`
-	Cache       cache.Config     `json:"cache" yaml:"Cache"`
-	CORS        CORSConfig       `json:"cors" yaml:"CORS"`
-	Auth        AuthConfig       `json:"auth" yaml:"Auth"`
-	Credentials CredentialConfig `json:"credentials" yaml:"Credentials"`
-	Metrics     MetricsConfig    `json:"metrics" yaml:"Metrics"`
-	Admin       AdminConfig      `json:"admin" yaml:"Admin"`
-	Webhook     WebhookConfig    `json:"webhook" yaml:"Webhook"`
-	GRPC        GRPCServerConfig `json:"grpcServer" yaml:"GRPCServer"`
+	Project        ProjectConfig        `json:"project" yaml:"Project"`
+	Site           SiteConfig           `json:"site" yaml:"Site"`
+	Database       database.Config      `json:"database" yaml:"Database"`
+	Cache          cache.Config         `json:"cache" yaml:"Cache"`
+	CORS           CORSConfig           `json:"cors" yaml:"CORS"`
+	Auth           AuthConfig           `json:"auth" yaml:"Auth"`
+	Credentials    CredentialConfig     `json:"credentials" yaml:"Credentials"`
+	Metrics        MetricsConfig        `json:"metrics" yaml:"Metrics"`
+	Admin          AdminConfig          `json:"admin" yaml:"Admin"`
+	Webhook        WebhookConfig        `json:"webhook" yaml:"Webhook"`
+	GRPC           GRPCServerConfig     `json:"grpcServer" yaml:"GRPCServer"`
+	KernelTLS      KernelTLSConfig      `json:"kernelTls,optional" yaml:"KernelTLS"`
+	KernelSnapshot KernelSnapshotConfig `json:"kernelSnapshot,optional" yaml:"KernelSnapshot"`
+	KernelClient   KernelClientConfig   `json:"kernelClient,optional" yaml:"KernelClient"`
+	SyncJobs       SyncJobsConfig       `json:"syncJobs,optional" yaml:"SyncJobs"`
+	ClientIP       ClientIPConfig       `json:"clientIp,optional" yaml:"ClientIP"`
 }
 
 type ProjectConfig struct {
@@ -416,6 +421,115 @@ func (g GRPCServerConfig) ReflectionEnabled() bool {
 	return *g.Reflection
 }
 
+// KernelTLSConfig controls certificate expiry checks for kernel TLS nodes.
+type KernelTLSConfig struct {
+	CheckInterval time.Duration `json:"checkInterval,optional" yaml:"CheckInterval"`
+	ExpiryWindow  time.Duration `json:"expiryWindow,optional" yaml:"ExpiryWindow"`
+}
+
+// Normalize applies defaults for kernel TLS checks.
+func (k *KernelTLSConfig) Normalize() {
+	if k.CheckInterval <= 0 {
+		k.CheckInterval = time.Hour
+	}
+	if k.ExpiryWindow <= 0 {
+		k.ExpiryWindow = 14 * 24 * time.Hour
+	}
+}
+
+// KernelSnapshotConfig controls node configuration snapshots taken through kernel /v1/export.
+type KernelSnapshotConfig struct {
+	// ExportDir is the directory on the kernel host the export files are written to.
+	ExportDir      string `json:"exportDir,optional" yaml:"ExportDir"`
+	AutoBeforeSync *bool  `json:"autoBeforeSync,optional" yaml:"AutoBeforeSync"`
+	MaxPerNode     int    `json:"maxPerNode,optional" yaml:"MaxPerNode"`
+}
+
+// Normalize applies defaults for kernel snapshots.
+func (k *KernelSnapshotConfig) Normalize() {
+	k.ExportDir = strings.TrimRight(strings.TrimSpace(k.ExportDir), "/")
+	if k.ExportDir == "" {
+		k.ExportDir = "snapshots"
+	}
+	if k.AutoBeforeSync == nil {
+		k.AutoBeforeSync = boolPtr(true)
+	}
+	if k.MaxPerNode <= 0 {
+		k.MaxPerNode = 50
+	}
+}
+
+// AutoSnapshotEnabled reports whether a snapshot is taken before binding syncs.
+// An unnormalized config leaves it disabled.
+func (k KernelSnapshotConfig) AutoSnapshotEnabled() bool {
+	return k.AutoBeforeSync != nil && *k.AutoBeforeSync
+}
+
+// KernelClientConfig controls retries and circuit breaking of kernel control calls.
+type KernelClientConfig struct {
+	// RetryMaxAttempts bounds attempts of idempotent calls; 1 disables retries.
+	RetryMaxAttempts int           `json:"retryMaxAttempts,optional" yaml:"RetryMaxAttempts"`
+	RetryBaseDelay   time.Duration `json:"retryBaseDelay,optional" yaml:"RetryBaseDelay"`
+	RetryMaxDelay    time.Duration `json:"retryMaxDelay,optional" yaml:"RetryMaxDelay"`
+	RetryJitter      float64       `json:"retryJitter,optional" yaml:"RetryJitter"`
+	// BreakerFailureThreshold is the number of consecutive failed calls that opens a node breaker.
+	BreakerFailureThreshold int           `json:"breakerFailureThreshold,optional" yaml:"BreakerFailureThreshold"`
+	BreakerOpenDuration     time.Duration `json:"breakerOpenDuration,optional" yaml:"BreakerOpenDuration"`
+}
+
+// Normalize applies defaults for the kernel control client.
+func (k *KernelClientConfig) Normalize() {
+	if k.RetryMaxAttempts <= 0 {
+		k.RetryMaxAttempts = 3
+	}
+	if k.RetryBaseDelay <= 0 {
+		k.RetryBaseDelay = 200 * time.Millisecond
+	}
+	if k.RetryMaxDelay <= 0 {
+		k.RetryMaxDelay = 2 * time.Second
+	}
+	if k.RetryMaxDelay < k.RetryBaseDelay {
+		k.RetryMaxDelay = k.RetryBaseDelay
+	}
+	if k.RetryJitter <= 0 {
+		k.RetryJitter = 0.2
+	}
+	if k.RetryJitter > 1 {
+		k.RetryJitter = 1
+	}
+	if k.BreakerFailureThreshold <= 0 {
+		k.BreakerFailureThreshold = 5
+	}
+	if k.BreakerOpenDuration <= 0 {
+		k.BreakerOpenDuration = 30 * time.Second
+	}
+}
+
+// SyncJobsConfig bounds the concurrency of background binding sync jobs.
+type SyncJobsConfig struct {
+	// Concurrency caps the bindings synced at once across all nodes.
+	Concurrency int `json:"concurrency,optional" yaml:"Concurrency"`
+}
+
+// Normalize applies defaults for sync jobs.
+func (s *SyncJobsConfig) Normalize() {
+	if s.Concurrency <= 0 {
+		s.Concurrency = 8
+	}
+}
+
+// ClientIPConfig controls how the caller address is resolved behind proxies.
+type ClientIPConfig struct {
+	// TrustedProxies lists the proxy addresses or CIDRs whose X-Forwarded-For
+	// header is honoured; requests from anywhere else use the socket address.
+	TrustedProxies []string `json:"trustedProxies,optional" yaml:"TrustedProxies"`
+}
+
+// Normalize trims the proxy list.
+func (c *ClientIPConfig) Normalize() {
+	c.TrustedProxies = normalizeStringList(c.TrustedProxies, false)
+}
+
 // Normalize 将配置补齐默认值。
 func (c *Config) Normalize() {
 	c.Project.Name = strings.TrimSpace(c.Project.Name)
@@ -432,6 +546,11 @@ func (c *Config) Normalize() {
 	c.Admin.Normalize()
 	c.Webhook.Normalize()
 	c.GRPC.Normalize()
+	c.KernelTLS.Normalize()
+	c.KernelSnapshot.Normalize()
+	c.KernelClient.Normalize()
+	c.SyncJobs.Normalize()
+	c.ClientIP.Normalize()
 	c.Middlewares.Prometheus = c.Metrics.Enabled()
 	c.Middlewares.Metrics = c.Metrics.Enabled()
 }
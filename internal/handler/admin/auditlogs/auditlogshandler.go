@@ -84,6 +84,8 @@ func writeAuditLogCSV(w http.ResponseWriter, logs []types.AuditLogSummary) {
 		"resource_type",
 		"resource_id",
 		"source_ip",
+		"source",
+		"node_id",
 		"metadata",
 		"created_at",
 	})
@@ -93,6 +95,10 @@ func writeAuditLogCSV(w http.ResponseWriter, logs []types.AuditLogSummary) {
 		if entry.ActorID != nil {
 			actorID = strconv.FormatUint(*entry.ActorID, 10)
 		}
+		nodeID := ""
+		if entry.NodeID != nil {
+			nodeID = strconv.FormatUint(*entry.NodeID, 10)
+		}
 		metadata := ""
 		if entry.Metadata != nil {
 			if payload, err := json.Marshal(entry.Metadata); err == nil {
@@ -108,6 +114,8 @@ func writeAuditLogCSV(w http.ResponseWriter, logs []types.AuditLogSummary) {
 			entry.ResourceType,
 			entry.ResourceID,
 			entry.SourceIP,
+			entry.Source,
+			nodeID,
 			metadata,
 			strconv.FormatInt(entry.CreatedAt, 10),
 		})
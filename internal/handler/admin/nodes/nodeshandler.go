@@ -9,6 +9,7 @@ import (
 
 	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
 	adminnodes "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/nodes"
+	adminbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -193,3 +194,247 @@ func AdminSyncNodeStatusHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		httpx.OkJsonCtx(r.Context(), w, resp)
 	}
 }
+
+// AdminNodeTLSHandler lists kernel TLS nodes and certificate expiry.
+func AdminNodeTLSHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminNodeTLSRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewTLSLogic(r.Context(), svcCtx)
+		resp, err := logic.List(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminUpdateNodeTLSModeHandler switches the mode of a kernel TLS node.
+func AdminUpdateNodeTLSModeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpdateNodeTLSModeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewTLSLogic(r.Context(), svcCtx)
+		resp, err := logic.UpdateMode(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminSetNodeTLSCertificatePathsHandler points a kernel TLS node at certificate files on the node.
+func AdminSetNodeTLSCertificatePathsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSetNodeTLSCertificatePathsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewTLSLogic(r.Context(), svcCtx)
+		resp, err := logic.SetCertificatePaths(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminCreateNodeSnapshotHandler captures the running configuration of a node kernel.
+func AdminCreateNodeSnapshotHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminCreateNodeSnapshotRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSnapshotLogic(r.Context(), svcCtx)
+		resp, err := logic.Create(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminListNodeSnapshotsHandler lists the configuration snapshots of a node.
+func AdminListNodeSnapshotsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminListNodeSnapshotsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSnapshotLogic(r.Context(), svcCtx)
+		resp, err := logic.List(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminGetNodeSnapshotHandler returns a node configuration snapshot.
+func AdminGetNodeSnapshotHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminGetNodeSnapshotRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSnapshotLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminNodeSnapshotDiffHandler compares snapshots or a snapshot with the intended config.
+func AdminNodeSnapshotDiffHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminNodeSnapshotDiffRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSnapshotLogic(r.Context(), svcCtx)
+		resp, err := logic.Diff(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminGetNodeHandler returns a node with its interfaces and rule providers.
+func AdminGetNodeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminGetNodeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewInventoryLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRefreshNodeRuleProviderHandler refreshes a rule provider on one node.
+func AdminRefreshNodeRuleProviderHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRefreshNodeRuleProviderRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			if !errors.Is(err, io.EOF) {
+				handlercommon.RespondInvalidRequest(w, r, err)
+				return
+			}
+		}
+
+		logic := adminnodes.NewInventoryLogic(r.Context(), svcCtx)
+		resp, err := logic.RefreshProvider(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRefreshRuleProvidersHandler refreshes a rule provider on all nodes with a tag.
+func AdminRefreshRuleProvidersHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRefreshRuleProvidersRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewInventoryLogic(r.Context(), svcCtx)
+		resp, err := logic.RefreshProviders(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminBootstrapNodeHandler starts a bulk load of users and protocols onto a node kernel.
+func AdminBootstrapNodeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminBootstrapNodeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			if !errors.Is(err, io.EOF) {
+				handlercommon.RespondInvalidRequest(w, r, err)
+				return
+			}
+		}
+
+		logic := adminbindings.NewBootstrapLogic(r.Context(), svcCtx)
+		resp, err := logic.Start(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminGetNodeBootstrapHandler returns the progress of a node bootstrap.
+func AdminGetNodeBootstrapHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminGetNodeBootstrapRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewBootstrapLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -110,7 +110,7 @@ func AdminSyncProtocolBindingHandler(svcCtx *svc.ServiceContext) http.HandlerFun
 	}
 }
 
-// AdminSyncProtocolBindingsHandler triggers sync for multiple bindings.
+// AdminSyncProtocolBindingsHandler queues a sync job for multiple bindings.
 func AdminSyncProtocolBindingsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminSyncProtocolBindingsRequest
@@ -119,8 +119,8 @@ func AdminSyncProtocolBindingsHandler(svcCtx *svc.ServiceContext) http.HandlerFu
 			return
 		}
 
-		logic := adminbindings.NewSyncLogic(r.Context(), svcCtx)
-		resp, err := logic.SyncBatch(&req)
+		logic := adminbindings.NewSyncJobLogic(r.Context(), svcCtx)
+		resp, err := logic.Submit(&req)
 		if err != nil {
 			handlercommon.RespondError(w, r, err)
 			return
@@ -149,3 +149,183 @@ func AdminSyncProtocolBindingStatusHandler(svcCtx *svc.ServiceContext) http.Hand
 		httpx.OkJsonCtx(r.Context(), w, resp)
 	}
 }
+
+// AdminProtocolDriftHandler reports drift between bindings and kernels.
+func AdminProtocolDriftHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminProtocolDriftRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewDriftLogic(r.Context(), svcCtx)
+		resp, err := logic.Scan(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminFixProtocolDriftHandler converges kernels onto the stored bindings.
+func AdminFixProtocolDriftHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminFixProtocolDriftRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewDriftLogic(r.Context(), svcCtx)
+		resp, err := logic.Fix(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminGetSyncJobHandler returns a binding sync job with its results so far.
+func AdminGetSyncJobHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSyncJobRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSyncJobLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminCancelSyncJobHandler cancels a queued or running sync job.
+func AdminCancelSyncJobHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSyncJobRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSyncJobLogic(r.Context(), svcCtx)
+		resp, err := logic.Cancel(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRetrySyncJobHandler re-queues the failed bindings of a sync job.
+func AdminRetrySyncJobHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSyncJobRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewSyncJobLogic(r.Context(), svcCtx)
+		resp, err := logic.RetryFailed(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminCreateBindingRolloutHandler starts a staged rollout of a binding profile.
+func AdminCreateBindingRolloutHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminCreateBindingRolloutRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewRolloutLogic(r.Context(), svcCtx)
+		resp, err := logic.Create(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminGetBindingRolloutHandler returns a binding rollout with its per-binding state.
+func AdminGetBindingRolloutHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminBindingRolloutRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewRolloutLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRollbackBindingRolloutHandler restores the previous profiles of a rollout.
+func AdminRollbackBindingRolloutHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRollbackBindingRolloutRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewRolloutLogic(r.Context(), svcCtx)
+		resp, err := logic.Rollback(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminProtocolBindingChainHandler returns the relay chain of a binding.
+func AdminProtocolBindingChainHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminProtocolBindingChainRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminbindings.NewChainLogic(r.Context(), svcCtx)
+		resp, err := logic.Chain(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
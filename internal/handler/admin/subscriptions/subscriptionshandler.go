@@ -130,3 +130,83 @@ func AdminExtendSubscriptionHandler(svcCtx *svc.ServiceContext) http.HandlerFunc
 		httpx.OkJsonCtx(r.Context(), w, resp)
 	}
 }
+
+// AdminSubscriptionDevicesHandler lists devices seen for a subscription.
+func AdminSubscriptionDevicesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSubscriptionDevicesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminsubs.NewDevicesLogic(r.Context(), svcCtx)
+		resp, err := logic.List(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminResetSubscriptionDevicesHandler clears devices and violations of a subscription.
+func AdminResetSubscriptionDevicesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSubscriptionDevicesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminsubs.NewDevicesLogic(r.Context(), svcCtx)
+		resp, err := logic.Reset(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminGetDevicePolicyHandler returns the device limit policy.
+func AdminGetDevicePolicyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminGetDevicePolicyRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminsubs.NewDevicePolicyLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminUpdateDevicePolicyHandler updates the device limit policy.
+func AdminUpdateDevicePolicyHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpdateDevicePolicyRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminsubs.NewDevicePolicyLogic(r.Context(), svcCtx)
+		resp, err := logic.Update(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
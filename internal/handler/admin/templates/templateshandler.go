@@ -124,3 +124,63 @@ func AdminSubscriptionTemplateHistoryHandler(svcCtx *svc.ServiceContext) http.Ha
 		httpx.OkJsonCtx(r.Context(), w, resp)
 	}
 }
+
+// AdminValidateSubscriptionTemplateHandler validates a template and dry-runs it.
+func AdminValidateSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminValidateSubscriptionTemplateRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewValidateLogic(r.Context(), svcCtx)
+		resp, err := logic.Validate(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRollbackSubscriptionTemplateHandler republishes a historical template version.
+func AdminRollbackSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRollbackSubscriptionTemplateRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewRollbackLogic(r.Context(), svcCtx)
+		resp, err := logic.Rollback(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminSubscriptionTemplateDiffHandler returns a line diff between two template versions.
+func AdminSubscriptionTemplateDiffHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSubscriptionTemplateDiffRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewDiffLogic(r.Context(), svcCtx)
+		resp, err := logic.Diff(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
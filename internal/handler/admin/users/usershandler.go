@@ -150,3 +150,63 @@ func AdminRotateUserCredentialHandler(svcCtx *svc.ServiceContext) http.HandlerFu
 		httpx.OkJsonCtx(r.Context(), w, resp)
 	}
 }
+
+// AdminGetUserRateLimitHandler returns a user's speed limit override.
+func AdminGetUserRateLimitHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminGetUserRateLimitRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminusers.NewRateLimitLogic(r.Context(), svcCtx)
+		resp, err := logic.Get(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminSetUserRateLimitHandler sets a user's speed limit override.
+func AdminSetUserRateLimitHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSetUserRateLimitRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminusers.NewRateLimitLogic(r.Context(), svcCtx)
+		resp, err := logic.Set(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminDeleteUserRateLimitHandler removes a user's speed limit override.
+func AdminDeleteUserRateLimitHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminDeleteUserRateLimitRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondInvalidRequest(w, r, err)
+			return
+		}
+
+		logic := adminusers.NewRateLimitLogic(r.Context(), svcCtx)
+		resp, err := logic.Delete(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
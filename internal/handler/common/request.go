@@ -0,0 +1,79 @@
+package common
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies holds the networks whose X-Forwarded-For header is honoured.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies accepts plain addresses and CIDRs; invalid entries are ignored.
+func ParseTrustedProxies(values []string) TrustedProxies {
+	var proxies TrustedProxies
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+	return proxies
+}
+
+func (p TrustedProxies) contains(ip net.IP) bool {
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP resolves the caller address. X-Forwarded-For is only honoured when
+// the request comes from a trusted proxy; its hops are then walked from the
+// right and the first address that is not a trusted proxy is returned.
+func ClientIP(r *http.Request, proxies TrustedProxies) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(strings.TrimSpace(host))
+	if remote == nil {
+		return ""
+	}
+	if !proxies.contains(remote) {
+		return remote.String()
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		parsed := net.ParseIP(hop)
+		if parsed == nil {
+			break
+		}
+		client = parsed
+		if !proxies.contains(parsed) {
+			break
+		}
+	}
+	return client.String()
+}
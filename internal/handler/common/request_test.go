@@ -0,0 +1,34 @@
+package common
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "bogus"})
+
+	cases := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{name: "untrusted remote ignores header", remote: "198.51.100.7:5000", xff: "203.0.113.9", want: "198.51.100.7"},
+		{name: "trusted proxy", remote: "10.1.2.3:5000", xff: "203.0.113.9", want: "203.0.113.9"},
+		{name: "spoofed left hop", remote: "10.1.2.3:5000", xff: "1.1.1.1, 203.0.113.9, 192.0.2.1", want: "203.0.113.9"},
+		{name: "all hops trusted", remote: "192.0.2.1:5000", xff: "10.0.0.5", want: "10.0.0.5"},
+		{name: "invalid hop", remote: "10.1.2.3:5000", xff: "junk", want: "10.1.2.3"},
+		{name: "no header", remote: "10.1.2.3:5000", want: "10.1.2.3"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tc.remote
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		require.Equal(t, tc.want, ClientIP(req, proxies), tc.name)
+	}
+}
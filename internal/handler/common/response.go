@@ -28,7 +28,7 @@ func RespondError(w http.ResponseWriter, r *http.Request, err error) {
 		status = http.StatusBadRequest
 	case errors.Is(err, repository.ErrInviteCodeRequired):
 		status = http.StatusBadRequest
-	case errors.Is(err, repository.ErrConflict):
+	case errors.Is(err, repository.ErrConflict), errors.Is(err, repository.ErrInvalidState):
 		status = http.StatusConflict
 	case errors.Is(err, repository.ErrForbidden):
 		status = http.StatusForbidden
@@ -42,6 +42,8 @@ func RespondError(w http.ResponseWriter, r *http.Request, err error) {
 		status = http.StatusBadRequest
 	case errors.Is(err, kernel.ErrNotImplemented):
 		status = http.StatusNotImplemented
+	case errors.Is(err, kernel.ErrCircuitOpen):
+		status = http.StatusServiceUnavailable
 	case errors.Is(err, context.Canceled):
 		status = http.StatusRequestTimeout
 	}
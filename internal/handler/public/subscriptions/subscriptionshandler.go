@@ -17,6 +17,7 @@ type publicSubscriptionRequest struct {
 
 // PublicSubscriptionDownloadHandler renders subscription content by token.
 func PublicSubscriptionDownloadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	proxies := handlercommon.ParseTrustedProxies(svcCtx.Config.ClientIP.TrustedProxies)
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req publicSubscriptionRequest
 		if err := httpx.Parse(r, &req); err != nil {
@@ -25,7 +26,7 @@ func PublicSubscriptionDownloadHandler(svcCtx *svc.ServiceContext) http.HandlerF
 		}
 
 		logic := publicsub.NewDownloadLogic(r.Context(), svcCtx)
-		result, err := logic.Download(req.Token, r.Header.Get("User-Agent"))
+		result, err := logic.Download(req.Token, r.Header.Get("User-Agent"), handlercommon.ClientIP(r, proxies))
 		if err != nil {
 			handlercommon.RespondError(w, r, err)
 			return
@@ -136,6 +136,12 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/nodes",
 				Handler: adminnodes.AdminCreateNodeHandler(serverCtx),
 			},
+			{
+				// Get node detail with interfaces and rule providers
+				Method:  http.MethodGet,
+				Path:    "/admin/nodes/:id",
+				Handler: adminnodes.AdminGetNodeHandler(serverCtx),
+			},
 			{
 				// Update node
 				Method:  http.MethodPatch,
@@ -172,12 +178,78 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/nodes/:id/kernels/sync",
 				Handler: adminnodes.AdminSyncNodeKernelHandler(serverCtx),
 			},
+			{
+				// Bootstrap node users and protocols
+				Method:  http.MethodPost,
+				Path:    "/admin/nodes/:id/bootstrap",
+				Handler: adminnodes.AdminBootstrapNodeHandler(serverCtx),
+			},
+			{
+				// Get node bootstrap progress
+				Method:  http.MethodGet,
+				Path:    "/admin/nodes/:id/bootstrap",
+				Handler: adminnodes.AdminGetNodeBootstrapHandler(serverCtx),
+			},
 			{
 				// Sync node status
 				Method:  http.MethodPost,
 				Path:    "/admin/nodes/status/sync",
 				Handler: adminnodes.AdminSyncNodeStatusHandler(serverCtx),
 			},
+			{
+				// Refresh node rule provider
+				Method:  http.MethodPost,
+				Path:    "/admin/nodes/:id/rule-providers/:name/refresh",
+				Handler: adminnodes.AdminRefreshNodeRuleProviderHandler(serverCtx),
+			},
+			{
+				// Refresh rule provider on nodes by tag
+				Method:  http.MethodPost,
+				Path:    "/admin/nodes/rule-providers/refresh",
+				Handler: adminnodes.AdminRefreshRuleProvidersHandler(serverCtx),
+			},
+			{
+				// List kernel TLS nodes and certificate expiry
+				Method:  http.MethodGet,
+				Path:    "/admin/nodes/:id/tls",
+				Handler: adminnodes.AdminNodeTLSHandler(serverCtx),
+			},
+			{
+				// Switch kernel TLS node mode
+				Method:  http.MethodPatch,
+				Path:    "/admin/nodes/:id/tls/:tls_id",
+				Handler: adminnodes.AdminUpdateNodeTLSModeHandler(serverCtx),
+			},
+			{
+				// Set kernel TLS node certificate paths
+				Method:  http.MethodPost,
+				Path:    "/admin/nodes/:id/tls/:tls_id/certificate-paths",
+				Handler: adminnodes.AdminSetNodeTLSCertificatePathsHandler(serverCtx),
+			},
+			{
+				// Take node configuration snapshot
+				Method:  http.MethodPost,
+				Path:    "/admin/nodes/:id/snapshots",
+				Handler: adminnodes.AdminCreateNodeSnapshotHandler(serverCtx),
+			},
+			{
+				// List node configuration snapshots
+				Method:  http.MethodGet,
+				Path:    "/admin/nodes/:id/snapshots",
+				Handler: adminnodes.AdminListNodeSnapshotsHandler(serverCtx),
+			},
+			{
+				// Get node configuration snapshot
+				Method:  http.MethodGet,
+				Path:    "/admin/nodes/:id/snapshots/:snapshot_id",
+				Handler: adminnodes.AdminGetNodeSnapshotHandler(serverCtx),
+			},
+			{
+				// Diff node configuration snapshots
+				Method:  http.MethodGet,
+				Path:    "/admin/nodes/:id/snapshot-diff",
+				Handler: adminnodes.AdminNodeSnapshotDiffHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1"),
 	)
@@ -358,6 +430,12 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/protocol-bindings/:id/sync",
 				Handler: adminprotocolbindings.AdminSyncProtocolBindingHandler(serverCtx),
 			},
+			{
+				// Get protocol binding relay chain
+				Method:  http.MethodGet,
+				Path:    "/admin/protocol-bindings/:id/chain",
+				Handler: adminprotocolbindings.AdminProtocolBindingChainHandler(serverCtx),
+			},
 			{
 				// Sync protocol bindings
 				Method:  http.MethodPost,
@@ -370,6 +448,54 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/protocol-bindings/status/sync",
 				Handler: adminprotocolbindings.AdminSyncProtocolBindingStatusHandler(serverCtx),
 			},
+			{
+				// Scan kernel drift
+				Method:  http.MethodGet,
+				Path:    "/admin/protocol-bindings/drift",
+				Handler: adminprotocolbindings.AdminProtocolDriftHandler(serverCtx),
+			},
+			{
+				// Fix kernel drift
+				Method:  http.MethodPost,
+				Path:    "/admin/protocol-bindings/drift/fix",
+				Handler: adminprotocolbindings.AdminFixProtocolDriftHandler(serverCtx),
+			},
+			{
+				// Get sync job
+				Method:  http.MethodGet,
+				Path:    "/admin/sync-jobs/:id",
+				Handler: adminprotocolbindings.AdminGetSyncJobHandler(serverCtx),
+			},
+			{
+				// Cancel sync job
+				Method:  http.MethodPost,
+				Path:    "/admin/sync-jobs/:id/cancel",
+				Handler: adminprotocolbindings.AdminCancelSyncJobHandler(serverCtx),
+			},
+			{
+				// Retry failed bindings of a sync job
+				Method:  http.MethodPost,
+				Path:    "/admin/sync-jobs/:id/retry-failed",
+				Handler: adminprotocolbindings.AdminRetrySyncJobHandler(serverCtx),
+			},
+			{
+				// Start staged binding rollout
+				Method:  http.MethodPost,
+				Path:    "/admin/binding-rollouts",
+				Handler: adminprotocolbindings.AdminCreateBindingRolloutHandler(serverCtx),
+			},
+			{
+				// Get binding rollout
+				Method:  http.MethodGet,
+				Path:    "/admin/binding-rollouts/:id",
+				Handler: adminprotocolbindings.AdminGetBindingRolloutHandler(serverCtx),
+			},
+			{
+				// Roll back binding rollout
+				Method:  http.MethodPost,
+				Path:    "/admin/binding-rollouts/:id/rollback",
+				Handler: adminprotocolbindings.AdminRollbackBindingRolloutHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1"),
 	)
@@ -478,6 +604,30 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/subscriptions/:id/extend",
 				Handler: adminsubscriptions.AdminExtendSubscriptionHandler(serverCtx),
 			},
+			{
+				// List devices seen for a subscription
+				Method:  http.MethodGet,
+				Path:    "/admin/subscriptions/:id/devices",
+				Handler: adminsubscriptions.AdminSubscriptionDevicesHandler(serverCtx),
+			},
+			{
+				// Reset subscription devices and device limit violation
+				Method:  http.MethodDelete,
+				Path:    "/admin/subscriptions/:id/devices",
+				Handler: adminsubscriptions.AdminResetSubscriptionDevicesHandler(serverCtx),
+			},
+			{
+				// Get device limit policy
+				Method:  http.MethodGet,
+				Path:    "/admin/device-policy",
+				Handler: adminsubscriptions.AdminGetDevicePolicyHandler(serverCtx),
+			},
+			{
+				// Update device limit policy
+				Method:  http.MethodPatch,
+				Path:    "/admin/device-policy",
+				Handler: adminsubscriptions.AdminUpdateDevicePolicyHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1"),
 	)
@@ -520,6 +670,24 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/subscription-templates/:id/publish",
 				Handler: admintemplates.AdminPublishSubscriptionTemplateHandler(serverCtx),
 			},
+			{
+				// Roll back subscription template to a published version
+				Method:  http.MethodPost,
+				Path:    "/admin/subscription-templates/:id/rollback",
+				Handler: admintemplates.AdminRollbackSubscriptionTemplateHandler(serverCtx),
+			},
+			{
+				// Diff two subscription template versions
+				Method:  http.MethodGet,
+				Path:    "/admin/subscription-templates/:id/diff",
+				Handler: admintemplates.AdminSubscriptionTemplateDiffHandler(serverCtx),
+			},
+			{
+				// Validate subscription template and dry-run render
+				Method:  http.MethodPost,
+				Path:    "/admin/subscription-templates/:id/validate",
+				Handler: admintemplates.AdminValidateSubscriptionTemplateHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1"),
 	)
@@ -550,6 +718,24 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/admin/users/:id/force-logout",
 				Handler: adminusers.AdminForceLogoutHandler(serverCtx),
 			},
+			{
+				// Get user speed limit override
+				Method:  http.MethodGet,
+				Path:    "/admin/users/:id/rate-limit",
+				Handler: adminusers.AdminGetUserRateLimitHandler(serverCtx),
+			},
+			{
+				// Set user speed limit override
+				Method:  http.MethodPut,
+				Path:    "/admin/users/:id/rate-limit",
+				Handler: adminusers.AdminSetUserRateLimitHandler(serverCtx),
+			},
+			{
+				// Remove user speed limit override
+				Method:  http.MethodDelete,
+				Path:    "/admin/users/:id/rate-limit",
+				Handler: adminusers.AdminDeleteUserRateLimitHandler(serverCtx),
+			},
 			{
 				// Reset user password
 				Method:  http.MethodPost,
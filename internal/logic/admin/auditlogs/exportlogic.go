@@ -45,6 +45,8 @@ func (l *ExportLogic) Export(req *types.AdminAuditLogExportRequest) (*types.Admi
 		Action:       req.Action,
 		ResourceType: req.ResourceType,
 		ResourceID:   req.ResourceID,
+		Source:       req.Source,
+		NodeID:       req.NodeID,
 		Since:        req.Since,
 		Until:        req.Until,
 	}
@@ -18,6 +18,8 @@ func mapAuditLogSummary(entry repository.AuditLog) types.AuditLogSummary {
 		ResourceType: entry.ResourceType,
 		ResourceID:   entry.ResourceID,
 		SourceIP:     entry.SourceIP,
+		Source:       entry.Source,
+		NodeID:       entry.NodeID,
 		Metadata:     entry.Metadata,
 		CreatedAt:    toUnixOrZero(entry.CreatedAt),
 	}
@@ -38,6 +40,13 @@ func buildAuditLogOptions(req *types.AdminAuditLogListRequest) (repository.Audit
 		Action:       strings.TrimSpace(req.Action),
 		ResourceType: strings.TrimSpace(req.ResourceType),
 		ResourceID:   strings.TrimSpace(req.ResourceID),
+		Source:       strings.TrimSpace(req.Source),
+		NodeID:       req.NodeID,
+	}
+	switch strings.ToLower(opts.Source) {
+	case "", repository.AuditLogSourcePanel, repository.AuditLogSourceKernel:
+	default:
+		return repository.AuditLogListOptions{}, repository.ErrInvalidArgument
 	}
 
 	if req.Since > 0 {
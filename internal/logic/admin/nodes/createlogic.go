@@ -63,6 +63,11 @@ func (l *CreateLogic) Create(req *types.AdminCreateNodeRequest) (*types.AdminNod
 		statusSyncEnabled = *req.StatusSyncEnabled
 	}
 
+	kernelEventMode, ok := nodecfg.NormalizeKernelEventMode(req.KernelEventMode)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported kernel event mode", repository.ErrInvalidArgument)
+	}
+
 	statusCode := status.NodeStatusOffline
 	if req.Status != 0 {
 		normalized, err := normalizeNodeStatus(req.Status)
@@ -76,6 +81,9 @@ func (l *CreateLogic) Create(req *types.AdminCreateNodeRequest) (*types.AdminNod
 	if req.CapacityMbps < 0 {
 		return nil, repository.ErrInvalidArgument
 	}
+	if err := validatePortRange(req.PortRangeStart, req.PortRangeEnd); err != nil {
+		return nil, err
+	}
 
 	kernelDefaultProtocol := strings.TrimSpace(req.KernelDefaultProtocol)
 	if kernelDefaultProtocol == "" {
@@ -147,6 +155,8 @@ func (l *CreateLogic) Create(req *types.AdminCreateNodeRequest) (*types.AdminNod
 		Status:                          statusCode,
 		Tags:                            tags,
 		CapacityMbps:                    req.CapacityMbps,
+		PortRangeStart:                  req.PortRangeStart,
+		PortRangeEnd:                    req.PortRangeEnd,
 		Description:                     strings.TrimSpace(req.Description),
 		AccessAddress:                   strings.TrimSpace(req.AccessAddress),
 		ControlEndpoint:                 endpoint,
@@ -162,6 +172,7 @@ func (l *CreateLogic) Create(req *types.AdminCreateNodeRequest) (*types.AdminNod
 		KernelStatusPollBackoffJitter:             kernelStatusPollBackoffJitter,
 		KernelOfflineProbeMaxIntervalSeconds:      kernelOfflineProbeMaxIntervalSeconds,
 		StatusSyncEnabled:                         statusSyncEnabled,
+		KernelEventMode:                           kernelEventMode,
 		CreatedAt:                                 now,
 		UpdatedAt:                                 now,
 	}
@@ -21,6 +21,8 @@ func mapNodeSummary(node repository.Node) types.NodeSummary {
 		Status:                          node.Status,
 		Tags:                            append([]string(nil), node.Tags...),
 		CapacityMbps:                    node.CapacityMbps,
+		PortRangeStart:                  node.PortRangeStart,
+		PortRangeEnd:                    node.PortRangeEnd,
 		Description:                     node.Description,
 		AccessAddress:                   node.AccessAddress,
 		ControlEndpoint:                 node.ControlEndpoint,
@@ -33,6 +35,7 @@ func mapNodeSummary(node repository.Node) types.NodeSummary {
 		KernelStatusPollBackoffJitter:             node.KernelStatusPollBackoffJitter,
 		KernelOfflineProbeMaxIntervalSeconds:      node.KernelOfflineProbeMaxIntervalSeconds,
 		StatusSyncEnabled:                         node.StatusSyncEnabled,
+		KernelEventMode:                           node.KernelEventMode,
 		LastSyncedAt:                              toUnixOrZero(node.LastSyncedAt),
 		UpdatedAt:                                 toUnixOrZero(node.UpdatedAt),
 	}
@@ -49,6 +52,17 @@ func mapKernelSummary(kernel repository.NodeKernel) types.NodeKernelSummary {
 	}
 }
 
+// validatePortRange 校验节点端口自动分配区间，起止均为 0 表示未配置。
+func validatePortRange(start, end int) error {
+	if start == 0 && end == 0 {
+		return nil
+	}
+	if start < 1 || end > 65535 || start > end {
+		return repository.NewInvalidArgument("port range must satisfy 1 <= port_range_start <= port_range_end <= 65535")
+	}
+	return nil
+}
+
 func normalizeNodeStatus(statusCode int) (int, error) {
 	if statusCode == 0 {
 		return 0, repository.ErrInvalidArgument
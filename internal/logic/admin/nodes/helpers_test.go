@@ -0,0 +1,149 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func setupNodeTestContext(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+	testutil.RequireSQLite(t)
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+// fakeKernel serves the inventory, TLS and event registration endpoints of
+// the control API.
+type fakeKernel struct {
+	URL string
+
+	mu         sync.Mutex
+	interfaces []kernel.InterfaceSummary
+	providers  []kernel.ProviderStatus
+	refreshed  map[string]kernel.ProviderStatus
+	refreshes  int
+	tlsNodes   []kernel.TLSNodeSummary
+	tlsDetails map[string]kernel.TLSNodeDetail
+	tlsPatches []kernel.TLSNodePatchRequest
+	events     []kernel.EventRegistrationRecord
+	services   []kernel.EventSubscriptionRecord
+}
+
+func newFakeKernel(t *testing.T) *fakeKernel {
+	t.Helper()
+	fake := &fakeKernel{
+		refreshed:  map[string]kernel.ProviderStatus{},
+		tlsDetails: map[string]kernel.TLSNodeDetail{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	fake.URL = server.URL
+	return fake
+}
+
+func (f *fakeKernel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/interfaces":
+		_ = json.NewEncoder(w).Encode(kernel.InterfaceListResponse{Interfaces: f.interfaces})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/rules/providers":
+		_ = json.NewEncoder(w).Encode(f.providers)
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/rules/providers/") && strings.HasSuffix(r.URL.Path, "/refresh"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/rules/providers/"), "/refresh")
+		provider, ok := f.refreshed[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		f.refreshes++
+		_ = json.NewEncoder(w).Encode(provider)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/tls/nodes":
+		_ = json.NewEncoder(w).Encode(f.tlsNodes)
+	case strings.HasPrefix(r.URL.Path, "/v1/tls/nodes/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/tls/nodes/")
+		detail, ok := f.tlsDetails[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			var req kernel.TLSNodePatchRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			f.tlsPatches = append(f.tlsPatches, req)
+		}
+		_ = json.NewEncoder(w).Encode(detail)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/events/registrations":
+		_ = json.NewEncoder(w).Encode(f.events)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/events/registrations/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/events/registrations/")
+		for i, record := range f.events {
+			if record.ID == id {
+				f.events = append(f.events[:i], f.events[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/service-events/registrations":
+		_ = json.NewEncoder(w).Encode(f.services)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/service-events/registrations/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/service-events/registrations/")
+		for i, record := range f.services {
+			if record.ID == id {
+				f.services = append(f.services[:i], f.services[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeKernel) refreshCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.refreshes
+}
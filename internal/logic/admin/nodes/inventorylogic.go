@@ -0,0 +1,269 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+// InventoryLogic 提供节点详情（网卡、规则 Provider）与 Provider 刷新。
+type InventoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewInventoryLogic 构造函数。
+func NewInventoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *InventoryLogic {
+	return &InventoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Get 返回节点摘要，并实时读取内核主机网卡与规则 Provider 状态。
+// 内核不可达时节点摘要照常返回，对应部分通过 *_error 字段说明原因。
+func (l *InventoryLogic) Get(req *types.AdminGetNodeRequest) (*types.AdminNodeDetailResponse, error) {
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []types.NodeSummary{mapNodeSummary(node)}
+	nodeIDs := []uint64{node.ID}
+	certs, err := l.svcCtx.Repositories.NodeTLSCertificate.ListByNodeIDs(l.ctx, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	applyTLSExpiry(summaries, certs, time.Now().UTC(), l.svcCtx.Config.KernelTLS.ExpiryWindow)
+	cursors, err := l.svcCtx.Repositories.KernelAuditCursor.ListByNodeIDs(l.ctx, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	applyAuditSink(summaries, cursors)
+
+	resp := &types.AdminNodeDetailResponse{
+		Node:          summaries[0],
+		Interfaces:    []types.NodeInterfaceSummary{},
+		RuleProviders: []types.NodeRuleProviderSummary{},
+	}
+
+	control, err := newNodeControlClient(l.svcCtx, node)
+	if err != nil {
+		resp.InterfacesError = err.Error()
+		resp.RuleProvidersError = err.Error()
+		return resp, nil
+	}
+
+	interfaces, err := control.ListInterfaces(l.ctx)
+	if err != nil {
+		l.Errorf("kernel interfaces fetch failed node_id=%d: %v", node.ID, err)
+		resp.InterfacesError = err.Error()
+	} else {
+		for _, iface := range interfaces.Interfaces {
+			resp.Interfaces = append(resp.Interfaces, mapInterfaceSummary(iface))
+		}
+	}
+
+	providers, err := control.ListRuleProviders(l.ctx)
+	if err != nil {
+		l.Errorf("kernel rule providers fetch failed node_id=%d: %v", node.ID, err)
+		resp.RuleProvidersError = err.Error()
+	} else {
+		for _, provider := range providers {
+			resp.RuleProviders = append(resp.RuleProviders, mapRuleProviderSummary(provider))
+		}
+	}
+
+	return resp, nil
+}
+
+// RefreshProvider 刷新单个节点上的规则 Provider。
+func (l *InventoryLogic) RefreshProvider(req *types.AdminRefreshNodeRuleProviderRequest) (*types.AdminNodeRuleProviderResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, repository.ErrInvalidArgument
+	}
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	control, err := newNodeControlClient(l.svcCtx, node)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidState, err)
+	}
+
+	provider, err := control.RefreshRuleProvider(l.ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	summary := mapRuleProviderSummary(provider)
+	// The kernel already refreshed; a lost audit entry must not hide that.
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.node.rule_provider.refresh", "node", fmt.Sprintf("%d", node.ID), map[string]any{
+		"provider": name,
+		"state":    summary.State,
+	}); err != nil {
+		l.Errorf("audit rule provider refresh failed node_id=%d: %v", node.ID, err)
+	}
+
+	return &types.AdminNodeRuleProviderResponse{
+		NodeID:       node.ID,
+		RuleProvider: summary,
+	}, nil
+}
+
+// RefreshProviders 按标签（或指定节点）批量刷新规则 Provider。
+// 已禁用或未配置控制面地址的节点记为跳过，单节点失败不影响其它节点。
+func (l *InventoryLogic) RefreshProviders(req *types.AdminRefreshRuleProvidersRequest) (*types.AdminRefreshRuleProvidersResponse, error) {
+	if req == nil {
+		return nil, repository.ErrInvalidArgument
+	}
+	name := strings.TrimSpace(req.Name)
+	tag := strings.TrimSpace(req.Tag)
+	nodeIDs := uniqueNodeIDs(req.NodeIDs)
+	if name == "" || (tag == "" && len(nodeIDs) == 0) {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	nodes, err := l.selectNodes(tag, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]types.NodeRuleProviderRefreshResult, 0, len(nodes))
+	for _, node := range nodes {
+		result := types.NodeRuleProviderRefreshResult{
+			NodeID:   node.ID,
+			NodeName: node.Name,
+			Status:   status.SyncResultStatusError,
+		}
+		if node.Status == status.NodeStatusDisabled {
+			result.Status = status.SyncResultStatusSkipped
+			result.Message = "node disabled"
+			results = append(results, result)
+			continue
+		}
+		control, err := newNodeControlClient(l.svcCtx, node)
+		if err != nil {
+			result.Status = status.SyncResultStatusSkipped
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		provider, err := control.RefreshRuleProvider(l.ctx, name)
+		if err != nil {
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+		summary := mapRuleProviderSummary(provider)
+		result.Status = status.SyncResultStatusSynced
+		result.Message = "refreshed"
+		result.RuleProvider = &summary
+		results = append(results, result)
+
+		// The kernel already refreshed; a lost audit entry must not hide that.
+		if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.node.rule_provider.refresh", "node", fmt.Sprintf("%d", node.ID), map[string]any{
+			"provider": name,
+			"state":    summary.State,
+			"tag":      tag,
+		}); err != nil {
+			l.Errorf("audit rule provider refresh failed node_id=%d: %v", node.ID, err)
+		}
+	}
+
+	return &types.AdminRefreshRuleProvidersResponse{
+		Name:    name,
+		Tag:     tag,
+		Results: results,
+	}, nil
+}
+
+// selectNodes 取指定节点；未指定时取带有该标签的全部节点（标签不区分大小写）。
+func (l *InventoryLogic) selectNodes(tag string, nodeIDs []uint64) ([]repository.Node, error) {
+	if len(nodeIDs) > 0 {
+		nodes := make([]repository.Node, 0, len(nodeIDs))
+		for _, nodeID := range nodeIDs {
+			node, err := l.svcCtx.Repositories.Node.Get(l.ctx, nodeID)
+			if err != nil {
+				return nil, err
+			}
+			if tag != "" && !nodeHasTag(node, tag) {
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes, nil
+	}
+
+	all, err := l.svcCtx.Repositories.Node.ListAll(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]repository.Node, 0, len(all))
+	for _, node := range all {
+		if nodeHasTag(node, tag) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func nodeHasTag(node repository.Node, tag string) bool {
+	for _, candidate := range node.Tags {
+		if strings.EqualFold(strings.TrimSpace(candidate), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func mapInterfaceSummary(iface kernel.InterfaceSummary) types.NodeInterfaceSummary {
+	addresses := make([]types.NodeInterfaceAddress, 0, len(iface.Addresses))
+	for _, addr := range iface.Addresses {
+		addresses = append(addresses, types.NodeInterfaceAddress{
+			Family:  addr.Family,
+			Address: addr.Address,
+			Prefix:  addr.Prefix,
+		})
+	}
+	return types.NodeInterfaceSummary{
+		Name:       iface.Name,
+		MAC:        iface.MAC,
+		MTU:        iface.MTU,
+		IsUp:       iface.IsUp,
+		IsLoopback: iface.IsLoopback,
+		Kind:       iface.Kind,
+		Addresses:  addresses,
+	}
+}
+
+func mapRuleProviderSummary(provider kernel.ProviderStatus) types.NodeRuleProviderSummary {
+	summary := types.NodeRuleProviderSummary{
+		Name:      provider.Name,
+		State:     provider.State,
+		Checksum:  provider.Checksum,
+		ETag:      provider.ETag,
+		LastError: provider.LastError,
+	}
+	// 内核上报毫秒时间戳，面板统一使用秒。
+	if provider.LastSuccessUnixMS != nil {
+		summary.LastSuccessAt = *provider.LastSuccessUnixMS / 1000
+	}
+	if provider.LastAttemptUnixMS != nil {
+		summary.LastAttemptAt = *provider.LastAttemptUnixMS / 1000
+	}
+	return summary
+}
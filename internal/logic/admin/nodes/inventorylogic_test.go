@@ -0,0 +1,90 @@
+package nodes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestNodeInventory(t *testing.T) {
+	svcCtx, cleanup := setupNodeTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	lastSuccess := int64(1_700_000_000_000)
+	mtu := 1500
+	fake := newFakeKernel(t)
+	fake.interfaces = []kernel.InterfaceSummary{{
+		Name:      "eth0",
+		MTU:       &mtu,
+		IsUp:      true,
+		Kind:      kernel.InterfaceKindPhysical,
+		Addresses: []kernel.InterfaceAddressSummary{{Family: "ipv4", Address: "10.0.0.2", Prefix: 24}},
+	}}
+	fake.providers = []kernel.ProviderStatus{
+		{Name: "geosite", State: kernel.ProviderStateError, LastError: "fetch timeout"},
+	}
+	fake.refreshed["geosite"] = kernel.ProviderStatus{
+		Name:              "geosite",
+		State:             kernel.ProviderStateHealthy,
+		LastSuccessUnixMS: &lastSuccess,
+	}
+
+	now := time.Now().UTC()
+	edge := repository.Node{Name: "edge-1", Tags: []string{"HK"}, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&edge).Error)
+	offline := repository.Node{Name: "edge-2", Tags: []string{"hk"}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&offline).Error)
+	disabled := repository.Node{Name: "edge-3", Tags: []string{"hk"}, Status: status.NodeStatusDisabled, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&disabled).Error)
+	other := repository.Node{Name: "edge-4", Tags: []string{"us"}, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&other).Error)
+
+	logic := NewInventoryLogic(ctx, svcCtx)
+	detail, err := logic.Get(&types.AdminGetNodeRequest{NodeID: edge.ID})
+	require.NoError(t, err)
+	require.Equal(t, edge.ID, detail.Node.ID)
+	require.Len(t, detail.Interfaces, 1)
+	require.Equal(t, "10.0.0.2", detail.Interfaces[0].Addresses[0].Address)
+	require.Len(t, detail.RuleProviders, 1)
+	require.Equal(t, kernel.ProviderStateError, detail.RuleProviders[0].State)
+	require.Empty(t, detail.InterfacesError)
+
+	detail, err = logic.Get(&types.AdminGetNodeRequest{NodeID: offline.ID})
+	require.NoError(t, err)
+	require.Empty(t, detail.Interfaces)
+	require.NotEmpty(t, detail.InterfacesError)
+	require.NotEmpty(t, detail.RuleProvidersError)
+
+	single, err := logic.RefreshProvider(&types.AdminRefreshNodeRuleProviderRequest{NodeID: edge.ID, Name: "geosite"})
+	require.NoError(t, err)
+	require.Equal(t, kernel.ProviderStateHealthy, single.RuleProvider.State)
+	require.Equal(t, lastSuccess/1000, single.RuleProvider.LastSuccessAt)
+
+	batch, err := logic.RefreshProviders(&types.AdminRefreshRuleProvidersRequest{Name: "geosite", Tag: "hk"})
+	require.NoError(t, err)
+	require.Len(t, batch.Results, 3)
+	byNode := make(map[uint64]types.NodeRuleProviderRefreshResult, len(batch.Results))
+	for _, result := range batch.Results {
+		byNode[result.NodeID] = result
+	}
+	require.Equal(t, status.SyncResultStatusSynced, byNode[edge.ID].Status)
+	require.Equal(t, status.SyncResultStatusSkipped, byNode[offline.ID].Status)
+	require.Equal(t, status.SyncResultStatusSkipped, byNode[disabled.ID].Status)
+	require.Equal(t, 2, fake.refreshCount())
+
+	logs, _, err := repos.AuditLog.List(ctx, repository.AuditLogListOptions{Action: "admin.node.rule_provider.refresh"})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+
+	_, err = logic.RefreshProviders(&types.AdminRefreshRuleProvidersRequest{Name: "geosite"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
@@ -2,6 +2,7 @@ package nodes
 
 import (
 	"context"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -44,10 +45,24 @@ func (l *ListLogic) List(req *types.AdminListNodesRequest) (*types.AdminNodeList
 	}
 
 	summaries := make([]types.NodeSummary, 0, len(nodes))
+	nodeIDs := make([]uint64, 0, len(nodes))
 	for _, node := range nodes {
 		summaries = append(summaries, mapNodeSummary(node))
+		nodeIDs = append(nodeIDs, node.ID)
 	}
 
+	certs, err := l.svcCtx.Repositories.NodeTLSCertificate.ListByNodeIDs(l.ctx, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	applyTLSExpiry(summaries, certs, time.Now().UTC(), l.svcCtx.Config.KernelTLS.ExpiryWindow)
+
+	cursors, err := l.svcCtx.Repositories.KernelAuditCursor.ListByNodeIDs(l.ctx, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	applyAuditSink(summaries, cursors)
+
 	page, perPage := normalizePage(req.Page, req.PerPage)
 	pagination := types.PaginationMeta{
 		Page:       page,
@@ -75,3 +90,48 @@ func normalizePage(page, perPage int) (int, int) {
 	}
 	return page, perPage
 }
+
+// applyTLSExpiry 以节点上最早到期的 TLS 证书标记节点摘要。
+func applyTLSExpiry(summaries []types.NodeSummary, certs []repository.NodeTLSCertificate, now time.Time, window time.Duration) {
+	earliest := make(map[uint64]repository.NodeTLSCertificate, len(certs))
+	for _, cert := range certs {
+		if cert.NotAfter == nil {
+			continue
+		}
+		current, ok := earliest[cert.NodeID]
+		if !ok || cert.NotAfter.Before(*current.NotAfter) {
+			earliest[cert.NodeID] = cert
+		}
+	}
+	for i := range summaries {
+		cert, ok := earliest[summaries[i].ID]
+		if !ok {
+			continue
+		}
+		summaries[i].TLSExpiresAt = cert.NotAfter.Unix()
+		summaries[i].TLSExpiring = certificateExpiring(cert, now, window)
+	}
+}
+
+// applyAuditSink 附加审计采集器最近一次记录的内核审计管道状态。
+func applyAuditSink(summaries []types.NodeSummary, cursors []repository.KernelAuditCursor) {
+	byNode := make(map[uint64]repository.KernelAuditCursor, len(cursors))
+	for _, cursor := range cursors {
+		byNode[cursor.NodeID] = cursor
+	}
+	for i := range summaries {
+		cursor, ok := byNode[summaries[i].ID]
+		if !ok || cursor.SinkCheckedAt == nil {
+			continue
+		}
+		summaries[i].AuditSink = &types.NodeAuditSinkStatus{
+			Healthy:      cursor.SinkHealthy,
+			Queued:       cursor.SinkQueued,
+			Dropped:      cursor.SinkDropped,
+			SinkFailures: cursor.SinkFailures,
+			LastError:    cursor.SinkLastError,
+			PullError:    cursor.PullError,
+			CheckedAt:    cursor.SinkCheckedAt.Unix(),
+		}
+	}
+}
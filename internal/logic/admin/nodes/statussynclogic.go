@@ -88,11 +88,11 @@ func (l *SyncStatusLogic) Sync(req *types.AdminSyncNodeStatusRequest) (*types.Ad
 	}
 
 	for key, nodeGroup := range controlGroups {
-		client, err := kernel.NewControlClient(kernel.HTTPOptions{
+		client, err := kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 			BaseURL: key.endpoint,
 			Token:   key.token,
 			Timeout: key.timeout,
-		})
+		}))
 		if err != nil {
 			l.markNodeGroup(nodeGroup, status.NodeStatusOffline, status.NodeSyncResultStatusOffline, err.Error(), results, indexByID)
 			continue
@@ -172,11 +172,11 @@ func (l *SyncLogic) fetchKernelConfigFromProtocols(node repository.Node, protoco
 		return kernel.NodeConfig{}, kernel.ErrNotFound
 	}
 
-	control, err := kernel.NewControlClient(kernel.HTTPOptions{
+	control, err := kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 		BaseURL: endpoint,
 		Token:   token,
 		Timeout: resolveKernelHTTPTimeout(node),
-	})
+	}))
 	if err != nil {
 		return kernel.NodeConfig{}, err
 	}
@@ -0,0 +1,273 @@
+package nodes
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const pemCertificateMarker = "-----BEGIN CERTIFICATE-----"
+
+// CheckNodeTLS 读取节点内核上的 TLS 节点并刷新证书信息。
+// 证书优先取自 profile 中的 PEM，其次通过 TLS 握手探测监听地址。
+func CheckNodeTLS(ctx context.Context, svcCtx *svc.ServiceContext, node repository.Node) ([]repository.NodeTLSCertificate, error) {
+	control, err := newNodeControlClient(svcCtx, node)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := control.ListTLSNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := svcCtx.Repositories.NodeTLSCertificate
+	certs := make([]repository.NodeTLSCertificate, 0, len(summaries))
+	keep := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		tlsNodeID := strings.TrimSpace(summary.ID)
+		if tlsNodeID == "" {
+			continue
+		}
+		keep = append(keep, tlsNodeID)
+
+		record := repository.NodeTLSCertificate{
+			NodeID:        node.ID,
+			TLSNodeID:     tlsNodeID,
+			Role:          strings.TrimSpace(summary.Role),
+			Mode:          strings.ToLower(strings.TrimSpace(summary.TLSMode)),
+			InnerProtocol: strings.TrimSpace(summary.InnerProtocol),
+			HasReality:    summary.HasReality,
+			CheckedAt:     time.Now().UTC(),
+		}
+
+		detail, err := control.GetTLSNode(ctx, tlsNodeID)
+		if err != nil {
+			record.CheckError = err.Error()
+		} else {
+			record.Listen = strings.TrimSpace(detail.Listen)
+			inspectTLSCertificate(ctx, node, detail, &record)
+		}
+
+		saved, err := repo.Upsert(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, saved)
+	}
+
+	if err := repo.DeleteMissing(ctx, node.ID, keep); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+func inspectTLSCertificate(ctx context.Context, node repository.Node, detail kernel.TLSNodeDetail, record *repository.NodeTLSCertificate) {
+	if record.Mode != kernel.TLSModeServer && record.Mode != kernel.TLSModeDual {
+		return
+	}
+
+	if data := findCertificatePEM(detail.Profile); data != "" {
+		if cert, err := parseCertificatePEM(data); err == nil {
+			applyCertificate(record, cert, repository.TLSCertificateSourceProfile)
+			return
+		}
+	}
+
+	var probeErr error
+	if address, serverName := resolveProbeTarget(node, record.Listen); address != "" {
+		cert, err := probeTLSCertificate(ctx, address, serverName, resolveKernelHTTPTimeout(node))
+		if err == nil {
+			applyCertificate(record, cert, repository.TLSCertificateSourceProbe)
+			return
+		}
+		probeErr = err
+	} else {
+		probeErr = errors.New("tls listen address unknown")
+	}
+	record.CheckError = probeErr.Error()
+}
+
+func applyCertificate(record *repository.NodeTLSCertificate, cert *x509.Certificate, source string) {
+	notBefore := cert.NotBefore.UTC()
+	notAfter := cert.NotAfter.UTC()
+	sum := sha256.Sum256(cert.Raw)
+
+	record.Subject = cert.Subject.String()
+	record.Issuer = cert.Issuer.String()
+	record.DNSNames = append([]string(nil), cert.DNSNames...)
+	record.Fingerprint = hex.EncodeToString(sum[:])
+	record.NotBefore = &notBefore
+	record.NotAfter = &notAfter
+	record.Source = source
+}
+
+// findCertificatePEM 在 profile 中查找第一个内联 PEM 证书（按键名排序遍历）。
+func findCertificatePEM(value any) string {
+	switch typed := value.(type) {
+	case string:
+		if strings.Contains(typed, pemCertificateMarker) {
+			return typed
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if found := findCertificatePEM(typed[key]); found != "" {
+				return found
+			}
+		}
+	case []any:
+		for _, item := range typed {
+			if found := findCertificatePEM(item); found != "" {
+				return found
+			}
+		}
+	}
+	return ""
+}
+
+// parseCertificatePEM 解析 PEM 中的第一个证书（叶子证书）。
+func parseCertificatePEM(data string) (*x509.Certificate, error) {
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("no certificate found in pem")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// probeTLSCertificate 与 TLS 监听地址握手并返回对端叶子证书。
+func probeTLSCertificate(ctx context.Context, address, serverName string, timeout time.Duration) (*x509.Certificate, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout},
+		Config: &tls.Config{
+			ServerName: serverName,
+			// Only the expiry is read; the chain is not trusted for anything.
+			InsecureSkipVerify: true,
+		},
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(probeCtx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil, errors.New("unexpected tls connection type")
+	}
+	peers := tlsConn.ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return nil, errors.New("no peer certificate")
+	}
+	return peers[0], nil
+}
+
+// resolveProbeTarget 将内核监听地址转换为可探测地址；监听在通配地址时使用节点访问地址。
+func resolveProbeTarget(node repository.Node, listen string) (string, string) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(listen))
+	if err != nil || port == "" {
+		return "", ""
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = nodeHost(node)
+	}
+	if host == "" {
+		return "", ""
+	}
+	serverName := ""
+	if net.ParseIP(host) == nil {
+		serverName = host
+	}
+	return net.JoinHostPort(host, port), serverName
+}
+
+func nodeHost(node repository.Node) string {
+	for _, candidate := range []string{node.AccessAddress, node.ControlEndpoint} {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		if strings.Contains(candidate, "://") {
+			if parsed, err := url.Parse(candidate); err == nil && parsed.Hostname() != "" {
+				return parsed.Hostname()
+			}
+			continue
+		}
+		if host, _, err := net.SplitHostPort(candidate); err == nil {
+			return host
+		}
+		return candidate
+	}
+	return ""
+}
+
+func newNodeControlClient(svcCtx *svc.ServiceContext, node repository.Node) (*kernel.ControlClient, error) {
+	endpoint := strings.TrimSpace(node.ControlEndpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("node control endpoint not configured")
+	}
+	return kernel.NewControlClient(svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
+		BaseURL: endpoint,
+		Token:   resolveNodeControlToken(node),
+		Timeout: resolveKernelHTTPTimeout(node),
+	}))
+}
+
+// certificateExpiring 判断证书是否在告警窗口内到期（已过期同样视为到期）。
+func certificateExpiring(cert repository.NodeTLSCertificate, now time.Time, window time.Duration) bool {
+	return cert.NotAfter != nil && cert.NotAfter.Before(now.Add(window))
+}
+
+func mapTLSCertificateSummary(cert repository.NodeTLSCertificate, now time.Time, window time.Duration) types.NodeTLSCertificateSummary {
+	summary := types.NodeTLSCertificateSummary{
+		TLSNodeID:     cert.TLSNodeID,
+		Role:          cert.Role,
+		Mode:          cert.Mode,
+		InnerProtocol: cert.InnerProtocol,
+		HasReality:    cert.HasReality,
+		Listen:        cert.Listen,
+		Subject:       cert.Subject,
+		Issuer:        cert.Issuer,
+		DNSNames:      append([]string{}, cert.DNSNames...),
+		Fingerprint:   cert.Fingerprint,
+		Expiring:      certificateExpiring(cert, now, window),
+		Source:        cert.Source,
+		CheckError:    cert.CheckError,
+		CheckedAt:     toUnixOrZero(cert.CheckedAt),
+	}
+	if cert.NotBefore != nil {
+		summary.NotBefore = cert.NotBefore.Unix()
+	}
+	if cert.NotAfter != nil {
+		summary.NotAfter = cert.NotAfter.Unix()
+		summary.DaysRemaining = int(cert.NotAfter.Sub(now) / (24 * time.Hour))
+	}
+	return summary
+}
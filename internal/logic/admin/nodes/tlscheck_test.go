@@ -0,0 +1,103 @@
+package nodes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestCheckNodeTLS(t *testing.T) {
+	svcCtx, cleanup := setupNodeTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	listener := httptest.NewTLSServer(http.NotFoundHandler())
+	defer listener.Close()
+	served := listener.Certificate()
+
+	fake := newFakeKernel(t)
+	fake.tlsNodes = []kernel.TLSNodeSummary{
+		{ID: "edge-tls", Role: "listener", InnerProtocol: "http", TLSMode: kernel.TLSModeServer},
+		{ID: "upstream", Role: "connector", InnerProtocol: "http", TLSMode: kernel.TLSModeClient},
+	}
+	fake.tlsDetails["edge-tls"] = kernel.TLSNodeDetail{ID: "edge-tls", Listen: listener.Listener.Addr().String()}
+	fake.tlsDetails["upstream"] = kernel.TLSNodeDetail{ID: "upstream", Connect: "example.com:443"}
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	stale := repository.NodeTLSCertificate{NodeID: node.ID, TLSNodeID: "removed"}
+	_, err := repos.NodeTLSCertificate.Upsert(ctx, stale)
+	require.NoError(t, err)
+
+	certs, err := CheckNodeTLS(ctx, svcCtx, node)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+
+	stored, err := repos.NodeTLSCertificate.ListByNode(ctx, node.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+
+	edge, err := repos.NodeTLSCertificate.Get(ctx, node.ID, "edge-tls")
+	require.NoError(t, err)
+	require.Equal(t, repository.TLSCertificateSourceProbe, edge.Source)
+	require.Empty(t, edge.CheckError)
+	require.NotNil(t, edge.NotAfter)
+	require.True(t, served.NotAfter.Equal(*edge.NotAfter))
+
+	upstream, err := repos.NodeTLSCertificate.Get(ctx, node.ID, "upstream")
+	require.NoError(t, err)
+	require.Nil(t, upstream.NotAfter)
+
+	summaries := []types.NodeSummary{{ID: node.ID}}
+	applyTLSExpiry(summaries, stored, now, time.Until(served.NotAfter)+time.Hour)
+	require.Equal(t, served.NotAfter.Unix(), summaries[0].TLSExpiresAt)
+	require.True(t, summaries[0].TLSExpiring)
+
+	summaries = []types.NodeSummary{{ID: node.ID}}
+	applyTLSExpiry(summaries, stored, now, time.Hour)
+	require.False(t, summaries[0].TLSExpiring)
+
+	// Installing a certificate sends file paths only and reports what the
+	// listener serves afterwards.
+	logic := NewTLSLogic(ctx, svcCtx)
+	_, err = logic.SetCertificatePaths(&types.AdminSetNodeTLSCertificatePathsRequest{NodeID: node.ID, TLSNodeID: "edge-tls", CertificatePath: "/etc/zero/tls/fullchain.pem"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	resp, err := logic.SetCertificatePaths(&types.AdminSetNodeTLSCertificatePathsRequest{
+		NodeID:          node.ID,
+		TLSNodeID:       "edge-tls",
+		CertificatePath: "/etc/zero/tls/fullchain.pem",
+		PrivateKeyPath:  "/etc/zero/tls/privkey.pem",
+	})
+	require.NoError(t, err)
+	require.Len(t, fake.tlsPatches, 1)
+	require.Equal(t, map[string]any{
+		"mode": kernel.TLSModeServer,
+		"server": map[string]any{
+			"certificate": "/etc/zero/tls/fullchain.pem",
+			"private_key": "/etc/zero/tls/privkey.pem",
+		},
+	}, fake.tlsPatches[0].TLS)
+	require.Equal(t, repository.TLSCertificateSourceProbe, resp.TLSNode.Source)
+	require.Equal(t, served.NotAfter.Unix(), resp.TLSNode.NotAfter)
+}
+
+func TestFindCertificatePEM(t *testing.T) {
+	profile := map[string]any{
+		"server": map[string]any{
+			"certificate": "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+			"private_key": "/etc/zero/tls/privkey.pem",
+		},
+	}
+	require.Contains(t, findCertificatePEM(profile), pemCertificateMarker)
+	require.Empty(t, findCertificatePEM(map[string]any{"server": map[string]any{"certificate": "/etc/zero/tls/fullchain.pem"}}))
+}
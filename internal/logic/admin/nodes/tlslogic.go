@@ -0,0 +1,205 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+// TLSLogic 管理节点内核的 TLS 证书与模式。
+type TLSLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTLSLogic 构造函数。
+func NewTLSLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TLSLogic {
+	return &TLSLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 实时读取内核 TLS 节点与证书到期时间；内核不可达时返回最近一次巡检结果。
+func (l *TLSLogic) List(req *types.AdminNodeTLSRequest) (*types.AdminNodeTLSResponse, error) {
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	window := l.svcCtx.Config.KernelTLS.ExpiryWindow
+	resp := &types.AdminNodeTLSResponse{
+		NodeID:              node.ID,
+		ExpiryWindowSeconds: int64(window / time.Second),
+	}
+
+	certs, err := CheckNodeTLS(l.ctx, l.svcCtx, node)
+	if err != nil {
+		l.Errorf("kernel tls check failed node_id=%d: %v", node.ID, err)
+		resp.Message = err.Error()
+		certs, err = l.svcCtx.Repositories.NodeTLSCertificate.ListByNode(l.ctx, node.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	resp.TLSNodes = make([]types.NodeTLSCertificateSummary, 0, len(certs))
+	for _, cert := range certs {
+		resp.TLSNodes = append(resp.TLSNodes, mapTLSCertificateSummary(cert, now, window))
+	}
+	return resp, nil
+}
+
+// UpdateMode 切换内核 TLS 节点的模式。
+func (l *TLSLogic) UpdateMode(req *types.AdminUpdateNodeTLSModeRequest) (*types.AdminNodeTLSNodeResponse, error) {
+	mode, err := normalizeTLSMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	node, control, err := l.resolveNode(req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	tlsNodeID := strings.TrimSpace(req.TLSNodeID)
+	if tlsNodeID == "" {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	if _, err := control.PatchTLSNode(l.ctx, tlsNodeID, kernel.TLSNodePatchRequest{
+		TLS: map[string]any{"mode": mode},
+	}); err != nil {
+		return nil, err
+	}
+	// The kernel already switched modes; a lost audit entry must not hide that.
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.node.tls.mode", "node", fmt.Sprintf("%d", node.ID), map[string]any{
+		"tls_node_id": tlsNodeID,
+		"mode":        mode,
+	}); err != nil {
+		l.Errorf("audit tls mode failed node_id=%d: %v", node.ID, err)
+	}
+	return l.refresh(node, tlsNodeID)
+}
+
+// SetCertificatePaths 让内核 TLS 节点改用已部署在节点上的证书与私钥文件。
+// core.yaml 中 tls.server 只接受文件路径，面板不下发 PEM；下发后重新巡检并返回探测到的证书。
+func (l *TLSLogic) SetCertificatePaths(req *types.AdminSetNodeTLSCertificatePathsRequest) (*types.AdminNodeTLSNodeResponse, error) {
+	certPath := strings.TrimSpace(req.CertificatePath)
+	keyPath := strings.TrimSpace(req.PrivateKeyPath)
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("%w: certificate_path and private_key_path are required", repository.ErrInvalidArgument)
+	}
+
+	node, control, err := l.resolveNode(req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	tlsNodeID := strings.TrimSpace(req.TLSNodeID)
+	if tlsNodeID == "" {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	mode := kernel.TLSModeServer
+	summaries, err := control.ListTLSNodes(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, summary := range summaries {
+		if strings.TrimSpace(summary.ID) != tlsNodeID {
+			continue
+		}
+		found = true
+		if current := strings.ToLower(strings.TrimSpace(summary.TLSMode)); current == kernel.TLSModeDual {
+			mode = current
+		}
+	}
+	if !found {
+		return nil, repository.ErrNotFound
+	}
+
+	if _, err := control.PatchTLSNode(l.ctx, tlsNodeID, kernel.TLSNodePatchRequest{
+		TLS: map[string]any{
+			"mode": mode,
+			"server": map[string]any{
+				"certificate": certPath,
+				"private_key": keyPath,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	resp, err := l.refresh(node, tlsNodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]any{
+		"tls_node_id":      tlsNodeID,
+		"certificate_path": certPath,
+		"private_key_path": keyPath,
+		"fingerprint":      resp.TLSNode.Fingerprint,
+		"not_after":        resp.TLSNode.NotAfter,
+	}
+	if resp.TLSNode.CheckError != "" {
+		metadata["check_error"] = resp.TLSNode.CheckError
+	}
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.node.tls.certificate", "node", fmt.Sprintf("%d", node.ID), metadata); err != nil {
+		l.Errorf("audit tls certificate failed node_id=%d: %v", node.ID, err)
+	}
+	return resp, nil
+}
+
+func (l *TLSLogic) resolveNode(nodeID uint64) (repository.Node, *kernel.ControlClient, error) {
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, nodeID)
+	if err != nil {
+		return repository.Node{}, nil, err
+	}
+	control, err := newNodeControlClient(l.svcCtx, node)
+	if err != nil {
+		return repository.Node{}, nil, fmt.Errorf("%w: %v", repository.ErrInvalidState, err)
+	}
+	return node, control, nil
+}
+
+func (l *TLSLogic) refresh(node repository.Node, tlsNodeID string) (*types.AdminNodeTLSNodeResponse, error) {
+	certs, err := CheckNodeTLS(l.ctx, l.svcCtx, node)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, cert := range certs {
+		if cert.TLSNodeID == tlsNodeID {
+			return &types.AdminNodeTLSNodeResponse{
+				NodeID:  node.ID,
+				TLSNode: mapTLSCertificateSummary(cert, now, l.svcCtx.Config.KernelTLS.ExpiryWindow),
+			}, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func normalizeTLSMode(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case kernel.TLSModeDisabled,
+		kernel.TLSModeServer,
+		kernel.TLSModeClient,
+		kernel.TLSModeDual:
+		return mode, nil
+	default:
+		return "", repository.ErrInvalidArgument
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	adminbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
@@ -14,6 +17,10 @@ import (
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
 
+// bindingSyncReasonEventModeChange marks binding syncs queued by a switch of
+// the node event mode.
+const bindingSyncReasonEventModeChange = "node.event_mode_change"
+
 // UpdateLogic handles node updates.
 type UpdateLogic struct {
 	logx.Logger
@@ -86,6 +93,26 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateNodeRequest) (*types.AdminNod
 		input.CapacityMbps = req.CapacityMbps
 		metadata["capacity_mbps"] = *req.CapacityMbps
 	}
+	if req.PortRangeStart != nil || req.PortRangeEnd != nil {
+		current, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		start, end := current.PortRangeStart, current.PortRangeEnd
+		if req.PortRangeStart != nil {
+			start = *req.PortRangeStart
+		}
+		if req.PortRangeEnd != nil {
+			end = *req.PortRangeEnd
+		}
+		if err := validatePortRange(start, end); err != nil {
+			return nil, err
+		}
+		input.PortRangeStart = &start
+		input.PortRangeEnd = &end
+		metadata["port_range_start"] = start
+		metadata["port_range_end"] = end
+	}
 	if req.Description != nil {
 		desc := strings.TrimSpace(*req.Description)
 		input.Description = &desc
@@ -243,9 +270,22 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateNodeRequest) (*types.AdminNod
 		input.StatusSyncEnabled = req.StatusSyncEnabled
 		metadata["status_sync_enabled"] = *req.StatusSyncEnabled
 	}
+	if req.KernelEventMode != nil {
+		mode, ok := nodecfg.NormalizeKernelEventMode(*req.KernelEventMode)
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported kernel event mode", repository.ErrInvalidArgument)
+		}
+		input.KernelEventMode = &mode
+		metadata["kernel_event_mode"] = mode
+	}
 
-	var updated repository.Node
+	var previous, updated repository.Node
 	if err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		var err error
+		previous, err = txRepos.Node.Get(l.ctx, req.NodeID)
+		if err != nil {
+			return err
+		}
 		node, err := txRepos.Node.Update(l.ctx, req.NodeID, input)
 		if err != nil {
 			return err
@@ -269,7 +309,56 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateNodeRequest) (*types.AdminNod
 		return nil, err
 	}
 
+	// 节点地址变更后，经由本节点绑定转发的中继需重新下发上游地址。
+	if input.AccessAddress != nil || input.ControlEndpoint != nil {
+		l.requestRelaySync(updated.ID)
+	}
+	// 事件模式切换为 pull 后，不再需要内核向面板推送回调。
+	previousMode, _ := nodecfg.NormalizeKernelEventMode(previous.KernelEventMode)
+	if mode, _ := nodecfg.NormalizeKernelEventMode(updated.KernelEventMode); mode != previousMode {
+		l.applyEventModeChange(previous, updated)
+	}
+
 	return &types.AdminNodeResponse{
 		Node: mapNodeSummary(updated),
 	}, nil
 }
+
+func (l *UpdateLogic) requestRelaySync(nodeID uint64) {
+	bindings, err := l.svcCtx.Repositories.ProtocolBinding.ListByNodeIDs(l.ctx, []uint64{nodeID})
+	if err != nil {
+		l.Errorf("list node bindings failed node_id=%d: %v", nodeID, err)
+		return
+	}
+	ids := make([]uint64, 0, len(bindings))
+	for _, binding := range bindings {
+		ids = append(ids, binding.ID)
+	}
+	if err := relayutil.RequestDownstreamSync(l.ctx, l.svcCtx.Repositories, ids); err != nil {
+		l.Errorf("queue relay sync failed node_id=%d: %v", nodeID, err)
+	}
+}
+
+// applyEventModeChange removes the push callbacks of a node that switched to
+// pull and queues its bindings, so the reconciler registers the callbacks
+// again after a switch back to push.
+func (l *UpdateLogic) applyEventModeChange(previous, updated repository.Node) {
+	if mode, _ := nodecfg.NormalizeKernelEventMode(updated.KernelEventMode); mode == nodecfg.KernelEventModePull {
+		if err := adminbindings.NewSyncLogic(l.ctx, l.svcCtx).RemoveEventRegistrations(previous); err != nil {
+			l.Errorf("remove kernel event registrations failed node_id=%d: %v", updated.ID, err)
+		}
+	}
+
+	bindings, err := l.svcCtx.Repositories.ProtocolBinding.ListByNodeIDs(l.ctx, []uint64{updated.ID})
+	if err != nil {
+		l.Errorf("list node bindings failed node_id=%d: %v", updated.ID, err)
+		return
+	}
+	ids := make([]uint64, 0, len(bindings))
+	for _, binding := range bindings {
+		ids = append(ids, binding.ID)
+	}
+	if err := l.svcCtx.Repositories.ProtocolBinding.RequestSync(l.ctx, ids, bindingSyncReasonEventModeChange, time.Now().UTC()); err != nil {
+		l.Errorf("queue binding sync failed node_id=%d: %v", updated.ID, err)
+	}
+}
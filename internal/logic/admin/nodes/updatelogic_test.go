@@ -0,0 +1,62 @@
+package nodes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestUpdateEventModeRemovesRegistrations(t *testing.T) {
+	svcCtx, cleanup := setupNodeTestContext(t)
+	defer cleanup()
+	svcCtx.Config.Host = "panel.example.com"
+	ctx := security.WithUser(context.Background(), security.UserClaims{ID: 1, Email: "ops@example.com", Roles: []string{"admin"}})
+
+	fake := newFakeKernel(t)
+	fake.events = []kernel.EventRegistrationRecord{
+		{ID: "e1", Event: "node_healthy", Callback: "http://panel.example.com/api/v1/kernel/events"},
+		{ID: "e2", Event: "node_healthy", Callback: "https://ops.example.com/node-events"},
+	}
+	fake.services = []kernel.EventSubscriptionRecord{
+		{ID: "s1", Event: "user_traffic_reported", Callback: "http://panel.example.com/api/v1/kernel/service-events"},
+	}
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, KernelEventMode: nodecfg.KernelEventModePush, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&node).Error)
+	sibling := repository.Node{Name: "edge-2", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, KernelEventMode: nodecfg.KernelEventModePush, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&sibling).Error)
+	binding := repository.ProtocolBinding{Name: "edge", NodeID: node.ID, Protocol: "vless", KernelID: "edge", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&binding).Error)
+
+	pull := nodecfg.KernelEventModePull
+	update := func(nodeID uint64) {
+		_, err := NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdateNodeRequest{NodeID: nodeID, KernelEventMode: &pull})
+		require.NoError(t, err)
+	}
+
+	// The sibling still relies on the callbacks of the shared kernel.
+	update(node.ID)
+	require.Len(t, fake.events, 2)
+	require.Len(t, fake.services, 1)
+
+	queued, err := svcCtx.Repositories.ProtocolBinding.Get(ctx, binding.ID)
+	require.NoError(t, err)
+	require.NotNil(t, queued.SyncRequestedAt)
+	require.Equal(t, bindingSyncReasonEventModeChange, queued.SyncRequestReason)
+
+	// Only the panel callbacks go once no node pushes any more.
+	update(sibling.ID)
+	require.Len(t, fake.events, 1)
+	require.Equal(t, "e2", fake.events[0].ID)
+	require.Empty(t, fake.services)
+}
@@ -38,6 +38,10 @@ func (l *CreateLogic) Create(req *types.AdminCreatePlanRequest) (*types.PlanSumm
 		return nil, err
 	}
 
+	if err := validateRateLimits(req.UploadRateBytes, req.DownloadRateBytes); err != nil {
+		return nil, err
+	}
+
 	currency := strings.TrimSpace(req.Currency)
 	if currency == "" {
 		currency = "CNY"
@@ -63,6 +67,8 @@ func (l *CreateLogic) Create(req *types.AdminCreatePlanRequest) (*types.PlanSumm
 		TrafficLimitBytes:  req.TrafficLimitBytes,
 		TrafficMultipliers: normalizeTrafficMultipliers(req.TrafficMultipliers),
 		DevicesLimit:       req.DevicesLimit,
+		UploadRateBytes:    req.UploadRateBytes,
+		DownloadRateBytes:  req.DownloadRateBytes,
 		SortOrder:          req.SortOrder,
 		Status:             statusCode,
 		Visible:            req.Visible,
@@ -56,6 +56,14 @@ func ensureBindingsExist(ctx context.Context, repo repository.ProtocolBindingRep
 	return nil
 }
 
+// validateRateLimits checks speed limits in bytes per second; zero means unlimited.
+func validateRateLimits(upload, download int64) error {
+	if upload < 0 || download < 0 {
+		return repository.ErrInvalidArgument
+	}
+	return nil
+}
+
 func normalizePlanStatus(statusCode int) (int, error) {
 	if statusCode == 0 {
 		return 0, repository.ErrInvalidArgument
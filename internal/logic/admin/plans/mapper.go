@@ -21,6 +21,8 @@ func toPlanSummary(plan repository.Plan, options []repository.PlanBillingOption,
 		TrafficLimitBytes:  plan.TrafficLimitBytes,
 		TrafficMultipliers: cloneTrafficMultipliers(plan.TrafficMultipliers),
 		DevicesLimit:       plan.DevicesLimit,
+		UploadRateBytes:    plan.UploadRateBytes,
+		DownloadRateBytes:  plan.DownloadRateBytes,
 		SortOrder:          plan.SortOrder,
 		Status:             plan.Status,
 		Visible:            plan.Visible,
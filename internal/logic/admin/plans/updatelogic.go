@@ -79,6 +79,15 @@ func (l *UpdateLogic) Update(req *types.AdminUpdatePlanRequest) (*types.PlanSumm
 	if req.DevicesLimit != nil {
 		plan.DevicesLimit = *req.DevicesLimit
 	}
+	if req.UploadRateBytes != nil {
+		plan.UploadRateBytes = *req.UploadRateBytes
+	}
+	if req.DownloadRateBytes != nil {
+		plan.DownloadRateBytes = *req.DownloadRateBytes
+	}
+	if err := validateRateLimits(plan.UploadRateBytes, plan.DownloadRateBytes); err != nil {
+		return nil, err
+	}
 	if req.SortOrder != nil {
 		plan.SortOrder = *req.SortOrder
 	}
@@ -0,0 +1,449 @@
+package protocolbindings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const (
+	defaultBootstrapChunkSize = 1000
+	maxBootstrapChunkSize     = 10000
+	// maxBootstrapFailures bounds the failures kept on a run; FailureCount keeps the total.
+	maxBootstrapFailures = 200
+	// bootstrapStaleAfter treats a running bootstrap without progress as interrupted,
+	// e.g. after a panel restart.
+	bootstrapStaleAfter = 10 * time.Minute
+)
+
+// BootstrapLogic loads the user snapshot of a node's kernel in chunks, the
+// first through /v1/users/import, which replaces the kernel's user registry,
+// and the rest through single /v1/users calls. It then creates the node's
+// protocols and verifies each protocol's user list.
+type BootstrapLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	sync   *SyncLogic
+}
+
+// NewBootstrapLogic constructs BootstrapLogic.
+func NewBootstrapLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BootstrapLogic {
+	return &BootstrapLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		sync:   NewSyncLogic(ctx, svcCtx),
+	}
+}
+
+// Start records a bootstrap run and executes it in the background; progress is
+// read back through Get.
+func (l *BootstrapLogic) Start(req *types.AdminBootstrapNodeRequest) (*types.AdminNodeBootstrapResponse, error) {
+	chunkSize, err := normalizeBootstrapChunkSize(req.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node.Status == status.NodeStatusDisabled {
+		return nil, fmt.Errorf("%w: node disabled", repository.ErrInvalidState)
+	}
+	if _, err := l.sync.resolveControlClient(repository.ProtocolBinding{Node: node}); err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidState, err)
+	}
+
+	latest, err := l.svcCtx.Repositories.NodeBootstrap.Latest(l.ctx, node.ID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+	case err != nil:
+		return nil, err
+	case latest.Status == repository.NodeBootstrapStatusRunning:
+		if time.Since(latest.UpdatedAt) < bootstrapStaleAfter {
+			return nil, repository.ErrConflict
+		}
+		now := time.Now().UTC()
+		latest.Status = repository.NodeBootstrapStatusFailed
+		latest.Message = "interrupted"
+		latest.FinishedAt = &now
+		if _, err := l.svcCtx.Repositories.NodeBootstrap.Save(l.ctx, latest); err != nil {
+			return nil, err
+		}
+	}
+
+	actor, ok := security.UserFromContext(l.ctx)
+	var actorID *uint64
+	if ok && actor.ID != 0 {
+		actorID = &actor.ID
+	}
+	run, err := l.svcCtx.Repositories.NodeBootstrap.Create(l.ctx, repository.NodeBootstrap{
+		NodeID:    node.ID,
+		Status:    repository.NodeBootstrapStatusRunning,
+		Stage:     repository.NodeBootstrapStageUsers,
+		ChunkSize: chunkSize,
+		ActorID:   actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := l.svcCtx.Repositories.AuditLog.Create(l.ctx, repository.AuditLog{
+		ActorID:      actorID,
+		ActorEmail:   actor.Email,
+		ActorRoles:   actor.Roles,
+		Action:       "admin.node.bootstrap",
+		ResourceType: "node",
+		ResourceID:   fmt.Sprintf("%d", node.ID),
+		Metadata: map[string]any{
+			"bootstrap_id": run.ID,
+			"chunk_size":   chunkSize,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	// The run outlives the request, so it gets its own context.
+	runner := NewBootstrapLogic(context.Background(), l.svcCtx)
+	go runner.run(node, run)
+
+	return &types.AdminNodeBootstrapResponse{Bootstrap: mapNodeBootstrapSummary(run)}, nil
+}
+
+// Get returns a bootstrap run of a node, the latest one by default.
+func (l *BootstrapLogic) Get(req *types.AdminGetNodeBootstrapRequest) (*types.AdminNodeBootstrapResponse, error) {
+	if req.NodeID == 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+	var (
+		run repository.NodeBootstrap
+		err error
+	)
+	if req.BootstrapID != 0 {
+		run, err = l.svcCtx.Repositories.NodeBootstrap.Get(l.ctx, req.BootstrapID)
+		if err == nil && run.NodeID != req.NodeID {
+			err = repository.ErrNotFound
+		}
+	} else {
+		run, err = l.svcCtx.Repositories.NodeBootstrap.Latest(l.ctx, req.NodeID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &types.AdminNodeBootstrapResponse{Bootstrap: mapNodeBootstrapSummary(run)}, nil
+}
+
+func (l *BootstrapLogic) run(node repository.Node, run repository.NodeBootstrap) {
+	// Regular syncs of every node on this kernel wait until the bootstrap has finished.
+	defer l.sync.lockKernel(node)()
+
+	bindings, usersByBinding, users, err := l.collectBootstrapUsers(node)
+	if err != nil {
+		run.Message = err.Error()
+		l.finish(&run)
+		return
+	}
+	run.TotalUsers = len(users)
+	run.ChunksTotal = len(bootstrapChunks(users, run.ChunkSize))
+	run.ProtocolsTotal = len(bindings)
+	l.saveProgress(&run)
+
+	control, err := l.sync.resolveControlClient(repository.ProtocolBinding{Node: node})
+	if err != nil {
+		run.Message = err.Error()
+		l.finish(&run)
+		return
+	}
+
+	imported := l.importUsers(control, &run, users)
+
+	run.Stage = repository.NodeBootstrapStageProtocols
+	l.saveProgress(&run)
+	created := l.createProtocols(control, &run, bindings, usersByBinding, !imported)
+
+	run.Stage = repository.NodeBootstrapStageReconcile
+	l.saveProgress(&run)
+	l.verifyProtocolUsers(control, &run, created, usersByBinding)
+
+	l.finish(&run)
+}
+
+// collectBootstrapUsers returns the active bindings of a node, the users of
+// each binding and the user snapshot of the node's kernel. The import
+// replaces every kernel user, so the snapshot also covers the active bindings
+// of other enabled nodes sharing the kernel.
+func (l *BootstrapLogic) collectBootstrapUsers(node repository.Node) ([]repository.ProtocolBinding, map[uint64][]kernel.User, []kernel.User, error) {
+	all, err := l.sync.kernelBindings(node)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	bindings := make([]repository.ProtocolBinding, 0, len(all))
+	usersByBinding := make(map[uint64][]kernel.User, len(all))
+	byID := make(map[string]kernel.User)
+	for _, binding := range all {
+		if strings.TrimSpace(binding.KernelID) == "" || binding.Status != status.ProtocolBindingStatusActive {
+			continue
+		}
+		if binding.NodeID == node.ID {
+			binding.Node = node
+		} else if binding.Node.Status == status.NodeStatusDisabled {
+			continue
+		}
+
+		users, err := l.sync.buildKernelUsers(binding)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if binding.NodeID == node.ID {
+			bindings = append(bindings, binding)
+			usersByBinding[binding.ID] = users
+		}
+		for _, user := range users {
+			byID[user.ID] = user
+		}
+	}
+
+	users := make([]kernel.User, 0, len(byID))
+	for _, user := range byID {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return bindings, usersByBinding, users, nil
+}
+
+// importUsers loads the user snapshot chunk_size users per request, each
+// within one node timeout. core.yaml defines import as replacing every kernel
+// user, so only the first chunk goes through /v1/users/import; later chunks
+// create their users one by one. It reports whether the import went through;
+// users that fail to load afterwards are recorded as failures.
+func (l *BootstrapLogic) importUsers(control *kernel.ControlClient, run *repository.NodeBootstrap, users []kernel.User) bool {
+	if len(users) == 0 {
+		return true
+	}
+	chunks := bootstrapChunks(users, run.ChunkSize)
+	resp, err := control.ImportUsers(l.ctx, chunks[0])
+	if err != nil {
+		l.Errorf("kernel users import failed node_id=%d users=%d: %v", run.NodeID, len(chunks[0]), err)
+		addBootstrapFailure(run, repository.NodeBootstrapFailure{
+			Stage:   repository.NodeBootstrapStageUsers,
+			Message: err.Error(),
+		})
+		l.saveProgress(run)
+		return false
+	}
+	if resp.Imported < len(chunks[0]) {
+		addBootstrapFailure(run, repository.NodeBootstrapFailure{
+			Stage:   repository.NodeBootstrapStageUsers,
+			Message: fmt.Sprintf("kernel imported %d of %d users", resp.Imported, len(chunks[0])),
+		})
+	}
+	run.ImportedUsers = resp.Imported
+	run.ChunksDone = 1
+	l.saveProgress(run)
+
+	for _, chunk := range chunks[1:] {
+		for _, user := range chunk {
+			if _, err := control.CreateUser(l.ctx, kernel.UserCreateRequest(user)); err != nil {
+				addBootstrapFailure(run, repository.NodeBootstrapFailure{
+					Stage:   repository.NodeBootstrapStageUsers,
+					UserID:  user.ID,
+					Message: err.Error(),
+				})
+				continue
+			}
+			run.ImportedUsers++
+		}
+		run.ChunksDone++
+		l.saveProgress(run)
+	}
+	return true
+}
+
+// createProtocols upserts each binding and returns the bindings the kernel
+// accepted. The imported registry already serves the users, so they are only
+// sent inline, as a full sync does, when the import failed.
+func (l *BootstrapLogic) createProtocols(control *kernel.ControlClient, run *repository.NodeBootstrap, bindings []repository.ProtocolBinding, usersByBinding map[uint64][]kernel.User, inline bool) []repository.ProtocolBinding {
+	created := make([]repository.ProtocolBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		l.sync.ensureEventRegistrations(binding)
+
+		profile := buildKernelProfile(binding)
+		upstream, connect, err := l.sync.resolveUpstream(binding)
+		if err == nil {
+			profile.Upstream = upstream
+			req := kernel.ProtocolUpsertRequest{
+				Listen:  normalizeListen(binding.Listen, binding.AccessPort),
+				Connect: connect,
+				Profile: profile,
+			}
+			if inline {
+				req.Users = usersByBinding[binding.ID]
+			}
+			if _, err = control.UpsertProtocol(l.ctx, req); err == nil {
+				l.sync.recordApplied(binding, req)
+			}
+		}
+		if err != nil {
+			addBootstrapFailure(run, repository.NodeBootstrapFailure{
+				Stage:    repository.NodeBootstrapStageProtocols,
+				KernelID: binding.KernelID,
+				Message:  err.Error(),
+			})
+			_, _ = l.sync.updateSyncState(binding, status.ProtocolBindingSyncStatusError, err.Error())
+		} else {
+			run.ProtocolsDone++
+			created = append(created, binding)
+			_, _ = l.sync.updateSyncState(binding, status.ProtocolBindingSyncStatusSynced, "")
+		}
+		l.saveProgress(run)
+	}
+	return created
+}
+
+// verifyProtocolUsers reads back the users of each created protocol, one
+// protocol per request, and records the users the kernel did not attach.
+// ImportedUsers ends up as the number of distinct users found on the kernel.
+func (l *BootstrapLogic) verifyProtocolUsers(control *kernel.ControlClient, run *repository.NodeBootstrap, bindings []repository.ProtocolBinding, usersByBinding map[uint64][]kernel.User) {
+	if len(bindings) == 0 {
+		return
+	}
+	loaded := make(map[string]struct{}, run.TotalUsers)
+	for _, binding := range bindings {
+		current, err := control.ListProtocolUsers(l.ctx, binding.KernelID)
+		if err != nil {
+			addBootstrapFailure(run, repository.NodeBootstrapFailure{
+				Stage:    repository.NodeBootstrapStageReconcile,
+				KernelID: binding.KernelID,
+				Message:  err.Error(),
+			})
+			continue
+		}
+		present := make(map[string]struct{}, len(current))
+		for _, user := range current {
+			present[user.ID] = struct{}{}
+		}
+		for _, user := range usersByBinding[binding.ID] {
+			if _, ok := present[user.ID]; ok {
+				loaded[user.ID] = struct{}{}
+				continue
+			}
+			addBootstrapFailure(run, repository.NodeBootstrapFailure{
+				Stage:    repository.NodeBootstrapStageReconcile,
+				UserID:   user.ID,
+				KernelID: binding.KernelID,
+				Message:  "user not attached to protocol",
+			})
+		}
+	}
+	run.ImportedUsers = len(loaded)
+	l.saveProgress(run)
+}
+
+func (l *BootstrapLogic) finish(run *repository.NodeBootstrap) {
+	now := time.Now().UTC()
+	run.FinishedAt = &now
+	switch {
+	case run.Message != "", run.ProtocolsTotal > 0 && run.ProtocolsDone == 0:
+		run.Status = repository.NodeBootstrapStatusFailed
+	case run.FailureCount > 0:
+		run.Status = repository.NodeBootstrapStatusPartial
+	default:
+		run.Status = repository.NodeBootstrapStatusSucceeded
+	}
+	// A failed run keeps the stage it stopped at.
+	if run.Status != repository.NodeBootstrapStatusFailed {
+		run.Stage = repository.NodeBootstrapStageDone
+	}
+	l.saveProgress(run)
+	l.Infof("node bootstrap finished node_id=%d bootstrap_id=%d status=%s users=%d/%d protocols=%d/%d failures=%d",
+		run.NodeID, run.ID, run.Status, run.ImportedUsers, run.TotalUsers, run.ProtocolsDone, run.ProtocolsTotal, run.FailureCount)
+}
+
+func (l *BootstrapLogic) saveProgress(run *repository.NodeBootstrap) {
+	saved, err := l.svcCtx.Repositories.NodeBootstrap.Save(l.ctx, *run)
+	if err != nil {
+		l.Errorf("node bootstrap progress save failed bootstrap_id=%d: %v", run.ID, err)
+		return
+	}
+	*run = saved
+}
+
+func addBootstrapFailure(run *repository.NodeBootstrap, failure repository.NodeBootstrapFailure) {
+	run.FailureCount++
+	if len(run.Failures) < maxBootstrapFailures {
+		run.Failures = append(run.Failures, failure)
+	}
+}
+
+// bootstrapChunks splits users into chunks of at most chunkSize users.
+func bootstrapChunks(users []kernel.User, chunkSize int) [][]kernel.User {
+	if chunkSize <= 0 {
+		chunkSize = defaultBootstrapChunkSize
+	}
+	chunks := make([][]kernel.User, 0, (len(users)+chunkSize-1)/chunkSize)
+	for start := 0; start < len(users); start += chunkSize {
+		end := min(start+chunkSize, len(users))
+		chunks = append(chunks, users[start:end])
+	}
+	return chunks
+}
+
+func normalizeBootstrapChunkSize(size int) (int, error) {
+	switch {
+	case size == 0:
+		return defaultBootstrapChunkSize, nil
+	case size < 0 || size > maxBootstrapChunkSize:
+		return 0, repository.ErrInvalidArgument
+	default:
+		return size, nil
+	}
+}
+
+func mapNodeBootstrapSummary(run repository.NodeBootstrap) types.NodeBootstrapSummary {
+	failures := make([]types.NodeBootstrapFailure, 0, len(run.Failures))
+	for _, failure := range run.Failures {
+		failures = append(failures, types.NodeBootstrapFailure{
+			Stage:    failure.Stage,
+			UserID:   failure.UserID,
+			KernelID: failure.KernelID,
+			Message:  failure.Message,
+		})
+	}
+	summary := types.NodeBootstrapSummary{
+		ID:             run.ID,
+		NodeID:         run.NodeID,
+		Status:         run.Status,
+		Stage:          run.Stage,
+		ChunkSize:      run.ChunkSize,
+		TotalUsers:     run.TotalUsers,
+		ImportedUsers:  run.ImportedUsers,
+		ChunksTotal:    run.ChunksTotal,
+		ChunksDone:     run.ChunksDone,
+		ProtocolsTotal: run.ProtocolsTotal,
+		ProtocolsDone:  run.ProtocolsDone,
+		FailureCount:   run.FailureCount,
+		Failures:       failures,
+		Message:        run.Message,
+		ActorID:        run.ActorID,
+		StartedAt:      run.StartedAt.Unix(),
+		UpdatedAt:      run.UpdatedAt.Unix(),
+	}
+	if run.FinishedAt != nil {
+		summary.FinishedAt = run.FinishedAt.Unix()
+	}
+	return summary
+}
@@ -0,0 +1,161 @@
+package protocolbindings
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestNodeBootstrap(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+	fake := newFakeKernel(t)
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", ControlEndpoint: fake.URL, KernelEventMode: "pull", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+
+	// The first chunk replaces the registry, later chunks add single users.
+	logic := NewBootstrapLogic(ctx, svcCtx)
+	control, err := logic.sync.resolveControlClient(repository.ProtocolBinding{Node: node})
+	require.NoError(t, err)
+	fake.registry["stale"] = kernel.User{ID: "stale"}
+	run, err := repos.NodeBootstrap.Create(ctx, repository.NodeBootstrap{NodeID: node.ID, ChunkSize: 3})
+	require.NoError(t, err)
+	users := make([]kernel.User, 0, 10)
+	for i := 1; i <= 10; i++ {
+		id := fmt.Sprintf("u%02d", i)
+		users = append(users, kernel.User{ID: id, Username: id, Password: "secret"})
+	}
+	run.TotalUsers = len(users)
+	run.ChunksTotal = len(bootstrapChunks(users, run.ChunkSize))
+	require.Equal(t, 4, run.ChunksTotal)
+
+	require.True(t, logic.importUsers(control, &run, users))
+	require.Equal(t, []int{3}, fake.imports)
+	require.Equal(t, 7, fake.calls(http.MethodPost, "/v1/users"))
+	require.Len(t, fake.registry, 10)
+	require.NotContains(t, fake.registry, "stale")
+	require.Equal(t, 10, run.ImportedUsers)
+	require.Equal(t, 4, run.ChunksDone)
+	require.Zero(t, run.FailureCount)
+
+	// The registry serves the users, so protocols go out without them; a
+	// user the kernel does not serve is reported by the verification pass.
+	fake.mu.Lock()
+	delete(fake.registry, "u07")
+	fake.mu.Unlock()
+	binding := repository.ProtocolBinding{ID: 99, KernelID: "probe", Protocol: "vless", Role: "listener", Listen: "443", Node: node}
+	byBinding := map[uint64][]kernel.User{binding.ID: users}
+	created := logic.createProtocols(control, &run, []repository.ProtocolBinding{binding}, byBinding, false)
+	require.Len(t, created, 1)
+	require.Empty(t, fake.upserts[0].Users)
+
+	logic.verifyProtocolUsers(control, &run, created, byBinding)
+	require.Equal(t, 9, run.ImportedUsers)
+	require.Equal(t, 1, run.FailureCount)
+	require.Equal(t, "u07", run.Failures[0].UserID)
+	require.Equal(t, repository.NodeBootstrapStageReconcile, run.Failures[0].Stage)
+
+	logic.finish(&run)
+	stored, err := repos.NodeBootstrap.Get(ctx, run.ID)
+	require.NoError(t, err)
+	require.Equal(t, 9, stored.ImportedUsers)
+	require.Equal(t, repository.NodeBootstrapStatusPartial, stored.Status)
+	require.Len(t, stored.Failures, 1)
+
+	// A failed import falls back to inline users.
+	fake.reset()
+	fake.setDown(true)
+	require.False(t, logic.importUsers(control, &run, users))
+	fake.setDown(false)
+	logic.createProtocols(control, &run, []repository.ProtocolBinding{binding}, byBinding, true)
+	require.Len(t, fake.upserts[0].Users, 10)
+
+	fake.reset()
+
+	// Full run in the background. The snapshot also carries the users of a
+	// sibling node on the same kernel, whose protocol is left alone.
+	sibling := repository.Node{Name: "edge-2", ControlEndpoint: fake.URL, KernelEventMode: "pull", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&sibling).Error)
+	for i, target := range []struct {
+		kernelID string
+		nodeID   uint64
+	}{{"edge", node.ID}, {"relay", node.ID}, {"exit", sibling.ID}} {
+		binding := repository.ProtocolBinding{
+			Name:      target.kernelID,
+			NodeID:    target.nodeID,
+			Protocol:  "vless",
+			Role:      "listener",
+			Listen:    "443",
+			KernelID:  target.kernelID,
+			Status:    status.ProtocolBindingStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&binding).Error)
+		if target.kernelID == "relay" {
+			continue
+		}
+		planID := uint64(20 + i)
+		require.NoError(t, repos.PlanProtocolBinding.Replace(ctx, planID, []uint64{binding.ID}))
+		user := repository.User{Email: fmt.Sprintf("%s@example.com", target.kernelID), Status: 1, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(&user).Error)
+		sub := repository.Subscription{
+			UserID:    user.ID,
+			PlanID:    planID,
+			Status:    status.SubscriptionStatusActive,
+			ExpiresAt: now.Add(24 * time.Hour),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&sub).Error)
+	}
+
+	started, err := logic.Start(&types.AdminBootstrapNodeRequest{NodeID: node.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.NodeBootstrapStatusRunning, started.Bootstrap.Status)
+	require.Equal(t, defaultBootstrapChunkSize, started.Bootstrap.ChunkSize)
+
+	var latest *types.AdminNodeBootstrapResponse
+	require.Eventually(t, func() bool {
+		latest, err = logic.Get(&types.AdminGetNodeBootstrapRequest{NodeID: node.ID})
+		return err == nil && latest.Bootstrap.Status != repository.NodeBootstrapStatusRunning
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Equal(t, started.Bootstrap.ID, latest.Bootstrap.ID)
+	require.Equal(t, repository.NodeBootstrapStatusSucceeded, latest.Bootstrap.Status)
+	require.Equal(t, repository.NodeBootstrapStageDone, latest.Bootstrap.Stage)
+	require.Equal(t, 2, latest.Bootstrap.ProtocolsDone)
+	require.Equal(t, 2, latest.Bootstrap.TotalUsers)
+
+	fake.mu.Lock()
+	require.Equal(t, []int{2}, fake.imports)
+	require.Len(t, fake.upserts, 2)
+	for _, protocol := range fake.upserts {
+		require.Equal(t, "0.0.0.0:443", protocol.Listen)
+		require.NotEqual(t, "exit", protocol.Profile.ID)
+		require.Empty(t, protocol.Users)
+	}
+	fake.mu.Unlock()
+	require.Equal(t, 2, fake.calls(http.MethodGet, "/users"))
+
+	bindings, err := repos.ProtocolBinding.ListByNodeIDs(ctx, []uint64{node.ID})
+	require.NoError(t, err)
+	for _, binding := range bindings {
+		require.Equal(t, status.ProtocolBindingSyncStatusSynced, binding.SyncStatus)
+	}
+
+	_, err = logic.Start(&types.AdminBootstrapNodeRequest{NodeID: node.ID, ChunkSize: maxBootstrapChunkSize + 1})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
@@ -0,0 +1,83 @@
+package protocolbindings
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ChainLogic reports the relay chain of a binding.
+type ChainLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewChainLogic constructs ChainLogic.
+func NewChainLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChainLogic {
+	return &ChainLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Chain returns the upstream hops and downstream relays of a binding.
+func (l *ChainLogic) Chain(req *types.AdminProtocolBindingChainRequest) (*types.AdminProtocolBindingChainResponse, error) {
+	binding, err := l.svcCtx.Repositories.ProtocolBinding.Get(l.ctx, req.BindingID)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := relayutil.Chain(l.ctx, l.svcCtx.Repositories, binding)
+	if err != nil {
+		return nil, err
+	}
+	downstream, err := relayutil.Downstream(l.ctx, l.svcCtx.Repositories, []uint64{binding.ID})
+	if err != nil {
+		return nil, err
+	}
+	health, err := relayutil.ChainHealth(l.ctx, l.svcCtx.Repositories, []repository.ProtocolBinding{binding})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.AdminProtocolBindingChainResponse{
+		Binding:           mapChainHop(binding),
+		Upstream:          make([]types.ProtocolBindingChainHop, 0, len(upstream)),
+		Downstream:        make([]types.ProtocolBindingChainHop, 0, len(downstream)),
+		ChainHealthStatus: health[binding.ID],
+	}
+	for _, hop := range upstream {
+		resp.Upstream = append(resp.Upstream, mapChainHop(hop))
+	}
+	for _, hop := range downstream {
+		resp.Downstream = append(resp.Downstream, mapChainHop(hop))
+	}
+	return resp, nil
+}
+
+func mapChainHop(binding repository.ProtocolBinding) types.ProtocolBindingChainHop {
+	address, port := relayutil.Endpoint(binding)
+	hop := types.ProtocolBindingChainHop{
+		BindingID:    binding.ID,
+		Name:         binding.Name,
+		NodeID:       binding.NodeID,
+		NodeName:     binding.Node.Name,
+		Protocol:     normalizeBindingProtocol(binding),
+		Role:         binding.Role,
+		KernelID:     binding.KernelID,
+		Address:      address,
+		Port:         port,
+		Status:       binding.Status,
+		HealthStatus: binding.HealthStatus,
+	}
+	if binding.UpstreamBindingID != nil {
+		hop.UpstreamBindingID = *binding.UpstreamBindingID
+	}
+	return hop
+}
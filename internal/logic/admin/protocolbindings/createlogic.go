@@ -6,6 +6,9 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/portutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/protocolschema"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -34,7 +37,8 @@ func (l *CreateLogic) Create(req *types.AdminCreateProtocolBindingRequest) (*typ
 	if !ok {
 		return nil, repository.ErrInvalidArgument
 	}
-	if _, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID); err != nil {
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -63,6 +67,16 @@ func (l *CreateLogic) Create(req *types.AdminCreateProtocolBindingRequest) (*typ
 		return nil, repository.ErrInvalidArgument
 	}
 	profile := req.Profile
+	if preset := strings.TrimSpace(req.Preset); preset != "" {
+		merged, err := protocolschema.ApplyPreset(protocol, preset, role == "connector", profile)
+		if err != nil {
+			return nil, repository.NewInvalidArgument(err.Error())
+		}
+		profile = merged
+	}
+	if err := validateBindingProfile(protocol, role, profile); err != nil {
+		return nil, err
+	}
 
 	binding := repository.ProtocolBinding{
 		Name:        strings.TrimSpace(req.Name),
@@ -78,12 +92,44 @@ func (l *CreateLogic) Create(req *types.AdminCreateProtocolBindingRequest) (*typ
 		Description: strings.TrimSpace(req.Description),
 		Profile:     cloneBindingProfile(profile),
 		Metadata:    req.Metadata,
+		Node:        node,
+	}
+	if req.UpstreamBindingID != 0 {
+		if err := relayutil.ValidateUpstream(l.ctx, l.svcCtx.Repositories, binding, req.UpstreamBindingID); err != nil {
+			return nil, err
+		}
+		upstreamID := req.UpstreamBindingID
+		binding.UpstreamBindingID = &upstreamID
 	}
 
-	created, err := l.svcCtx.Repositories.ProtocolBinding.Create(l.ctx, binding)
+	// The node row lock serialises port checks and allocation on the node, so
+	// concurrent creates cannot claim the same port.
+	var created repository.ProtocolBinding
+	err = l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		locked, err := txRepos.Node.GetForUpdate(l.ctx, binding.NodeID)
+		if err != nil {
+			return err
+		}
+		binding.Node = locked
+		if req.AutoPort {
+			if err := assignAutoPort(l.ctx, txRepos, &binding); err != nil {
+				return err
+			}
+		}
+		if err := portutil.CheckBinding(l.ctx, txRepos, binding); err != nil {
+			return err
+		}
+		binding.Node = repository.Node{}
+
+		created, err = txRepos.ProtocolBinding.Create(l.ctx, binding)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := relayutil.RequestUpstreamSync(l.ctx, l.svcCtx.Repositories, req.UpstreamBindingID); err != nil {
+		l.Errorf("queue upstream sync failed binding_id=%d: %v", created.ID, err)
+	}
 
 	summary := mapProtocolBindingSummary(created)
 	return &summary, nil
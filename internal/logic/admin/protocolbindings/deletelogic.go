@@ -2,9 +2,13 @@ package protocolbindings
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -25,7 +29,56 @@ func NewDeleteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteLogi
 	}
 }
 
-// Delete removes a protocol binding.
+// Delete removes a protocol binding and the protocol it runs on the kernel.
+// Kernel cleanup is best effort: failures are logged and left for the drift
+// scanner to report as extra protocols.
 func (l *DeleteLogic) Delete(req *types.AdminDeleteProtocolBindingRequest) error {
-	return l.svcCtx.Repositories.ProtocolBinding.Delete(l.ctx, req.BindingID)
+	binding, err := l.svcCtx.Repositories.ProtocolBinding.Get(l.ctx, req.BindingID)
+	if err != nil {
+		return err
+	}
+	relays, err := l.svcCtx.Repositories.ProtocolBinding.ListByUpstreamIDs(l.ctx, []uint64{binding.ID})
+	if err != nil {
+		return err
+	}
+	if len(relays) > 0 {
+		return fmt.Errorf("%w: binding %d is the upstream of %d relay binding(s)", repository.ErrConflict, binding.ID, len(relays))
+	}
+	if err := l.svcCtx.Repositories.ProtocolBinding.Delete(l.ctx, binding.ID); err != nil {
+		return err
+	}
+	if binding.UpstreamBindingID != nil {
+		if err := relayutil.RequestUpstreamSync(l.ctx, l.svcCtx.Repositories, *binding.UpstreamBindingID); err != nil {
+			l.Errorf("queue upstream sync failed binding_id=%d: %v", binding.ID, err)
+		}
+	}
+
+	if err := l.removeFromKernel(binding); err != nil {
+		l.Errorf("kernel protocol cleanup failed binding_id=%d kernel_id=%s: %v", binding.ID, binding.KernelID, err)
+	}
+	return nil
+}
+
+func (l *DeleteLogic) removeFromKernel(binding repository.ProtocolBinding) error {
+	if strings.TrimSpace(binding.KernelID) == "" || strings.TrimSpace(binding.Node.ControlEndpoint) == "" {
+		return nil
+	}
+	// Another binding on the same kernel, possibly of another panel node
+	// sharing the endpoint, may still own the kernel protocol.
+	syncLogic := NewSyncLogic(l.ctx, l.svcCtx)
+	siblings, err := syncLogic.kernelBindings(binding.Node)
+	if err != nil {
+		return err
+	}
+	for _, sibling := range siblings {
+		if sibling.KernelID == binding.KernelID {
+			return nil
+		}
+	}
+
+	control, err := syncLogic.resolveControlClient(binding)
+	if err != nil {
+		return err
+	}
+	return syncLogic.removeKernelProtocol(binding.Node, control, binding.KernelID)
 }
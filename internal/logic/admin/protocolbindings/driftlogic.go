@@ -0,0 +1,329 @@
+package protocolbindings
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+// DriftLogic compares what runs on kernels with the protocol bindings stored
+// in the panel and converges the kernels on request.
+type DriftLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	sync   *SyncLogic
+}
+
+// NewDriftLogic constructs DriftLogic.
+func NewDriftLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DriftLogic {
+	return &DriftLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		sync:   NewSyncLogic(ctx, svcCtx),
+	}
+}
+
+// nodeDrift keeps the scan report together with what is needed to fix it.
+type nodeDrift struct {
+	report   types.NodeDriftReport
+	control  *kernel.ControlClient
+	resync   []repository.ProtocolBinding
+	userSync []repository.ProtocolBinding
+	extra    []string
+}
+
+// Scan reports missing, extra and mismatched protocols and users per node.
+func (l *DriftLogic) Scan(req *types.AdminProtocolDriftRequest) (*types.AdminProtocolDriftResponse, error) {
+	var nodeIDs []uint64
+	if req != nil && req.NodeID != 0 {
+		nodeIDs = []uint64{req.NodeID}
+	}
+	nodes, err := l.resolveNodes(nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]types.NodeDriftReport, 0, len(nodes))
+	for _, node := range nodes {
+		reports = append(reports, l.scanNode(node).report)
+	}
+	return &types.AdminProtocolDriftResponse{Nodes: reports}, nil
+}
+
+// Fix re-syncs missing or mismatched bindings and removes protocols that no
+// binding owns any more.
+func (l *DriftLogic) Fix(req *types.AdminFixProtocolDriftRequest) (*types.AdminFixProtocolDriftResponse, error) {
+	var nodeIDs []uint64
+	if req != nil {
+		nodeIDs = uniqueNodeIDs(req.NodeIDs)
+	}
+	nodes, err := l.resolveNodes(nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]types.NodeDriftFixResult, 0, len(nodes))
+	for _, node := range nodes {
+		result := l.fixNode(node)
+		results = append(results, result)
+		if result.Status == status.SyncResultStatusSkipped {
+			continue
+		}
+
+		// The kernel has already been changed; a lost audit entry must not hide that.
+		if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.node.drift.fix", "node", fmt.Sprintf("%d", node.ID), map[string]any{
+			"status":            result.Status,
+			"message":           result.Message,
+			"protocols_synced":  result.ProtocolsSynced,
+			"protocols_removed": result.ProtocolsRemoved,
+		}); err != nil {
+			l.Errorf("audit drift fix failed node_id=%d: %v", node.ID, err)
+		}
+	}
+
+	return &types.AdminFixProtocolDriftResponse{Results: results}, nil
+}
+
+func (l *DriftLogic) fixNode(node repository.Node) types.NodeDriftFixResult {
+	result := types.NodeDriftFixResult{
+		NodeID:  node.ID,
+		Status:  status.SyncResultStatusError,
+		FixedAt: time.Now().UTC().Unix(),
+	}
+
+	drift := l.scanNode(node)
+	if drift.report.Message != "" {
+		result.Message = drift.report.Message
+		return result
+	}
+	if drift.report.InSync {
+		result.Status = status.SyncResultStatusSkipped
+		result.Message = "in sync"
+		return result
+	}
+
+	var failures []string
+	apply := func(bindings []repository.ProtocolBinding, mode string) {
+		for _, binding := range bindings {
+			synced := l.sync.syncBinding(binding, mode)
+			if synced.Status != status.SyncResultStatusSynced {
+				failures = append(failures, fmt.Sprintf("binding %d: %s", binding.ID, synced.Message))
+				continue
+			}
+			result.ProtocolsSynced++
+		}
+	}
+	apply(drift.resync, SyncModeFull)
+	apply(drift.userSync, SyncModeIncremental)
+
+	for _, kernelID := range drift.extra {
+		if err := l.sync.removeKernelProtocol(node, drift.control, kernelID); err != nil {
+			failures = append(failures, fmt.Sprintf("protocol %s: %v", kernelID, err))
+			continue
+		}
+		result.ProtocolsRemoved++
+	}
+
+	if len(failures) > 0 {
+		result.Message = strings.Join(failures, "; ")
+		return result
+	}
+	result.Status = status.SyncResultStatusSynced
+	result.Message = "ok"
+	return result
+}
+
+func (l *DriftLogic) scanNode(node repository.Node) nodeDrift {
+	drift := nodeDrift{
+		report: types.NodeDriftReport{
+			NodeID:              node.ID,
+			NodeName:            node.Name,
+			MissingProtocols:    []types.ProtocolDriftItem{},
+			ExtraProtocols:      []types.ProtocolDriftItem{},
+			MismatchedProtocols: []types.ProtocolDriftItem{},
+			Users:               []types.ProtocolUserDrift{},
+			CheckedAt:           time.Now().UTC().Unix(),
+		},
+	}
+
+	control, err := l.sync.resolveControlClient(repository.ProtocolBinding{Node: node})
+	if err != nil {
+		drift.report.Message = err.Error()
+		return drift
+	}
+	drift.control = control
+
+	protocols, err := control.ListProtocols(l.ctx)
+	if err != nil {
+		drift.report.Message = err.Error()
+		return drift
+	}
+	running := make(map[string]kernel.ProtocolSummary, len(protocols))
+	for _, protocol := range protocols {
+		running[strings.TrimSpace(protocol.ID)] = protocol
+	}
+
+	// Every node on the same kernel owns its protocols; only this node's
+	// bindings are checked. Disabled bindings are neither expected nor
+	// reported as orphans.
+	bindings, err := l.sync.kernelBindings(node)
+	if err != nil {
+		drift.report.Message = err.Error()
+		return drift
+	}
+
+	owned := make(map[string]struct{}, len(bindings))
+	for _, binding := range bindings {
+		kernelID := strings.TrimSpace(binding.KernelID)
+		if kernelID == "" {
+			continue
+		}
+		owned[kernelID] = struct{}{}
+		if binding.NodeID != node.ID || binding.Status != status.ProtocolBindingStatusActive {
+			continue
+		}
+
+		item := types.ProtocolDriftItem{
+			BindingID: binding.ID,
+			KernelID:  kernelID,
+			Protocol:  normalizeBindingProtocol(binding),
+		}
+		summary, ok := running[kernelID]
+		if !ok {
+			item.Reason = "not running on kernel"
+			drift.report.MissingProtocols = append(drift.report.MissingProtocols, item)
+			drift.resync = append(drift.resync, binding)
+			continue
+		}
+		if reason := protocolMismatch(binding, summary); reason != "" {
+			item.Reason = reason
+			drift.report.MismatchedProtocols = append(drift.report.MismatchedProtocols, item)
+			drift.resync = append(drift.resync, binding)
+			continue
+		}
+
+		userDrift, err := l.scanUsers(control, binding)
+		if err != nil {
+			drift.report.Message = fmt.Sprintf("binding %d users: %v", binding.ID, err)
+			return drift
+		}
+		if userDrift != nil {
+			drift.report.Users = append(drift.report.Users, *userDrift)
+			drift.userSync = append(drift.userSync, binding)
+		}
+	}
+
+	for kernelID, summary := range running {
+		if _, ok := owned[kernelID]; ok {
+			continue
+		}
+		drift.report.ExtraProtocols = append(drift.report.ExtraProtocols, types.ProtocolDriftItem{
+			KernelID: kernelID,
+			Protocol: strings.ToLower(strings.TrimSpace(summary.Protocol)),
+			Reason:   "no protocol binding",
+		})
+		drift.extra = append(drift.extra, kernelID)
+	}
+	sort.Slice(drift.report.ExtraProtocols, func(i, j int) bool {
+		return drift.report.ExtraProtocols[i].KernelID < drift.report.ExtraProtocols[j].KernelID
+	})
+	sort.Strings(drift.extra)
+
+	drift.report.InSync = len(drift.report.MissingProtocols) == 0 &&
+		len(drift.report.ExtraProtocols) == 0 &&
+		len(drift.report.MismatchedProtocols) == 0 &&
+		len(drift.report.Users) == 0
+	return drift
+}
+
+func (l *DriftLogic) scanUsers(control *kernel.ControlClient, binding repository.ProtocolBinding) (*types.ProtocolUserDrift, error) {
+	expected, err := l.sync.buildKernelUsers(binding)
+	if err != nil {
+		return nil, err
+	}
+	current, err := control.ListProtocolUsers(l.ctx, binding.KernelID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffKernelUsers(current, expected)
+	if err := l.sync.dropSharedUsers(binding, &diff); err != nil {
+		return nil, err
+	}
+	if diff.empty() {
+		return nil, nil
+	}
+	drift := &types.ProtocolUserDrift{
+		BindingID:       binding.ID,
+		KernelID:        binding.KernelID,
+		MissingUsers:    make([]string, 0, len(diff.missing)),
+		ExtraUsers:      make([]string, 0, len(diff.extra)),
+		MismatchedUsers: make([]string, 0, len(diff.mismatched)),
+	}
+	for _, user := range diff.missing {
+		drift.MissingUsers = append(drift.MissingUsers, user.ID)
+	}
+	for _, user := range diff.extra {
+		drift.ExtraUsers = append(drift.ExtraUsers, user.ID)
+	}
+	for _, change := range diff.mismatched {
+		drift.MismatchedUsers = append(drift.MismatchedUsers, change.expected.ID)
+	}
+	return drift, nil
+}
+
+// protocolMismatch compares the fields the kernel reports back; empty kernel
+// values are treated as unknown rather than as drift.
+func protocolMismatch(binding repository.ProtocolBinding, summary kernel.ProtocolSummary) string {
+	var reasons []string
+	if got := strings.ToLower(strings.TrimSpace(summary.Protocol)); got != "" && got != normalizeBindingProtocol(binding) {
+		reasons = append(reasons, fmt.Sprintf("protocol %s != %s", got, normalizeBindingProtocol(binding)))
+	}
+	if got, want := strings.TrimSpace(summary.Role), strings.TrimSpace(binding.Role); got != "" && want != "" && !strings.EqualFold(got, want) {
+		reasons = append(reasons, fmt.Sprintf("role %s != %s", got, want))
+	}
+	if got, want := strings.TrimSpace(summary.Listen), normalizeListen(binding.Listen, binding.AccessPort); got != "" && want != "" && got != want {
+		reasons = append(reasons, fmt.Sprintf("listen %s != %s", got, want))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (l *DriftLogic) resolveNodes(nodeIDs []uint64) ([]repository.Node, error) {
+	if len(nodeIDs) > 0 {
+		nodes := make([]repository.Node, 0, len(nodeIDs))
+		for _, id := range nodeIDs {
+			node, err := l.svcCtx.Repositories.Node.Get(l.ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes, nil
+	}
+
+	all, err := l.svcCtx.Repositories.Node.ListAll(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]repository.Node, 0, len(all))
+	for _, node := range all {
+		if node.Status == status.NodeStatusDisabled || strings.TrimSpace(node.ControlEndpoint) == "" {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,170 @@
+package protocolbindings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestDriftScanAndFix(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := security.WithUser(context.Background(), security.UserClaims{ID: 1, Email: "ops@example.com", Roles: []string{"admin"}})
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	fake := newFakeKernel(t)
+	fake.protocols["edge"] = kernel.ProtocolSummary{ID: "edge", Role: "listener", Protocol: "vless", Listen: "0.0.0.0:443"}
+	fake.protocols["orphan"] = kernel.ProtocolSummary{ID: "orphan", Role: "listener", Protocol: "trojan"}
+	fake.setUsers("edge", "9")
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	for _, kernelID := range []string{"edge", "missing"} {
+		binding := repository.ProtocolBinding{
+			Name:      kernelID,
+			NodeID:    node.ID,
+			Protocol:  "vless",
+			Role:      "listener",
+			Listen:    "0.0.0.0:443",
+			KernelID:  kernelID,
+			Status:    status.ProtocolBindingStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&binding).Error)
+	}
+
+	logic := NewDriftLogic(ctx, svcCtx)
+	scan, err := logic.Scan(&types.AdminProtocolDriftRequest{NodeID: node.ID})
+	require.NoError(t, err)
+	require.Len(t, scan.Nodes, 1)
+	report := scan.Nodes[0]
+	require.False(t, report.InSync)
+	require.Empty(t, report.Message)
+	require.Len(t, report.MissingProtocols, 1)
+	require.Equal(t, "missing", report.MissingProtocols[0].KernelID)
+	require.Len(t, report.ExtraProtocols, 1)
+	require.Equal(t, "orphan", report.ExtraProtocols[0].KernelID)
+	require.Empty(t, report.MismatchedProtocols)
+	require.Len(t, report.Users, 1)
+	require.Equal(t, []string{"9"}, report.Users[0].ExtraUsers)
+
+	fixed, err := logic.Fix(&types.AdminFixProtocolDriftRequest{NodeIDs: []uint64{node.ID}})
+	require.NoError(t, err)
+	require.Len(t, fixed.Results, 1)
+	require.Equal(t, status.SyncResultStatusSynced, fixed.Results[0].Status)
+	require.Equal(t, 2, fixed.Results[0].ProtocolsSynced)
+	require.Equal(t, 1, fixed.Results[0].ProtocolsRemoved)
+	require.NotContains(t, fake.protocols, "orphan")
+	require.Empty(t, fake.users["edge"])
+
+	logs, _, err := repos.AuditLog.List(ctx, repository.AuditLogListOptions{Action: "admin.node.drift.fix"})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, "ops@example.com", logs[0].ActorEmail)
+	require.EqualValues(t, 2, logs[0].Metadata["protocols_synced"])
+
+	// A converged node is skipped and leaves no audit entry.
+	scan, err = logic.Scan(&types.AdminProtocolDriftRequest{NodeID: node.ID})
+	require.NoError(t, err)
+	require.True(t, scan.Nodes[0].InSync)
+	fixed, err = logic.Fix(&types.AdminFixProtocolDriftRequest{NodeIDs: []uint64{node.ID}})
+	require.NoError(t, err)
+	require.Equal(t, status.SyncResultStatusSkipped, fixed.Results[0].Status)
+	_, total, err := repos.AuditLog.List(ctx, repository.AuditLogListOptions{Action: "admin.node.drift.fix"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+}
+
+func TestDriftSharedKernel(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := security.WithUser(context.Background(), security.UserClaims{ID: 1, Email: "ops@example.com", Roles: []string{"admin"}})
+	db := svcCtx.DB
+
+	// Two panel nodes drive the same kernel; each owns one protocol.
+	fake := newFakeKernel(t)
+	fake.protocols["edge"] = kernel.ProtocolSummary{ID: "edge", Role: "listener", Protocol: "vless", Listen: "0.0.0.0:443"}
+	fake.protocols["exit"] = kernel.ProtocolSummary{ID: "exit", Role: "listener", Protocol: "vless", Listen: "0.0.0.0:443"}
+	fake.protocols["orphan"] = kernel.ProtocolSummary{ID: "orphan", Role: "listener", Protocol: "trojan"}
+
+	now := time.Now().UTC()
+	bindings := map[string]repository.ProtocolBinding{}
+	for _, kernelID := range []string{"edge", "exit"} {
+		node := repository.Node{Name: kernelID + "-node", ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(&node).Error)
+		binding := repository.ProtocolBinding{
+			Name:      kernelID,
+			NodeID:    node.ID,
+			Protocol:  "vless",
+			Role:      "listener",
+			Listen:    "0.0.0.0:443",
+			KernelID:  kernelID,
+			Status:    status.ProtocolBindingStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&binding).Error)
+		bindings[kernelID] = binding
+	}
+	nodeID := bindings["edge"].NodeID
+
+	logic := NewDriftLogic(ctx, svcCtx)
+	scan, err := logic.Scan(&types.AdminProtocolDriftRequest{NodeID: nodeID})
+	require.NoError(t, err)
+	require.Len(t, scan.Nodes, 1)
+	require.Len(t, scan.Nodes[0].ExtraProtocols, 1)
+	require.Equal(t, "orphan", scan.Nodes[0].ExtraProtocols[0].KernelID)
+	require.Empty(t, scan.Nodes[0].MissingProtocols)
+
+	fixed, err := logic.Fix(&types.AdminFixProtocolDriftRequest{NodeIDs: []uint64{nodeID}})
+	require.NoError(t, err)
+	require.Equal(t, 1, fixed.Results[0].ProtocolsRemoved)
+	require.NotContains(t, fake.protocols, "orphan")
+	require.Contains(t, fake.protocols, "exit")
+
+	// A sibling node binding that reuses the kernel id keeps the protocol
+	// alive when the original binding is deleted.
+	reuse := bindings["exit"]
+	reuse.ID = 0
+	reuse.Name = "exit-reuse"
+	reuse.NodeID = nodeID
+	require.NoError(t, db.Create(&reuse).Error)
+	require.NoError(t, NewDeleteLogic(ctx, svcCtx).Delete(&types.AdminDeleteProtocolBindingRequest{BindingID: bindings["exit"].ID}))
+	require.Contains(t, fake.protocols, "exit")
+}
+
+func TestRemoveKernelProtocol(t *testing.T) {
+	fake, logic, control, _ := newIncrementalTestLogic(t)
+	fake.users["orphan"] = []kernel.UserView{{ID: "1", Username: "u1"}}
+	fake.users["edge"] = []kernel.UserView{{ID: "1", Username: "u1"}}
+	fake.protocols["orphan"] = kernel.ProtocolSummary{ID: "orphan", Protocol: "vless"}
+	fake.protocols["edge"] = kernel.ProtocolSummary{ID: "edge", Protocol: "trojan"}
+	node := repository.Node{ControlEndpoint: fake.URL}
+	require.NoError(t, logic.removeKernelProtocol(node, control, "orphan"))
+
+	require.NotContains(t, fake.protocols, "orphan")
+	require.Contains(t, fake.protocols, "edge")
+	require.Len(t, fake.users["edge"], 1)
+
+	// Already gone on the kernel.
+	require.NoError(t, logic.removeKernelProtocol(node, control, "missing"))
+}
+
+func TestProtocolMismatch(t *testing.T) {
+	binding := repository.ProtocolBinding{Protocol: "VLESS", Role: "listener", AccessPort: 443}
+
+	require.Empty(t, protocolMismatch(binding, kernel.ProtocolSummary{Protocol: "vless", Role: "Listener", Listen: "0.0.0.0:443"}))
+	require.Empty(t, protocolMismatch(binding, kernel.ProtocolSummary{}), "unreported fields are not drift")
+	require.Contains(t, protocolMismatch(binding, kernel.ProtocolSummary{Protocol: "trojan"}), "protocol trojan != vless")
+	require.Contains(t, protocolMismatch(binding, kernel.ProtocolSummary{Listen: "0.0.0.0:8443"}), "listen")
+}
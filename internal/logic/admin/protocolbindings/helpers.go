@@ -1,12 +1,15 @@
 package protocolbindings
 
 import (
+	"context"
 	"net"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/portutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
+	"github.com/zero-net-panel/zero-net-panel/internal/protocolschema"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
@@ -17,6 +20,19 @@ var allowedRoles = map[string]struct{}{
 	"connector": {},
 }
 
+// validateBindingProfile checks a profile against the schema of its protocol:
+// listeners use the server fields, connectors the client fields.
+func validateBindingProfile(protocol, role string, profile map[string]any) error {
+	validate := protocolschema.ValidateServer
+	if role == "connector" {
+		validate = protocolschema.ValidateClient
+	}
+	if err := validate(protocol, profile); err != nil {
+		return repository.NewInvalidArgument(err.Error())
+	}
+	return nil
+}
+
 func normalizeRole(role string) (string, bool) {
 	role = strings.ToLower(strings.TrimSpace(role))
 	if role == "" {
@@ -27,30 +43,38 @@ func normalizeRole(role string) (string, bool) {
 }
 
 func mapProtocolBindingSummary(binding repository.ProtocolBinding) types.ProtocolBindingSummary {
-	return types.ProtocolBindingSummary{
-		ID:              binding.ID,
-		Name:            binding.Name,
-		NodeID:          binding.NodeID,
-		NodeName:        binding.Node.Name,
-		Protocol:        normalizeBindingProtocol(binding),
-		Role:            binding.Role,
-		Listen:          binding.Listen,
-		Connect:         binding.Connect,
-		AccessPort:      binding.AccessPort,
-		Status:          binding.Status,
-		KernelID:        binding.KernelID,
-		SyncStatus:      binding.SyncStatus,
-		HealthStatus:    binding.HealthStatus,
-		LastSyncedAt:    toUnixOrZero(binding.LastSyncedAt),
-		LastHeartbeatAt: toUnixOrZero(binding.LastHeartbeatAt),
-		LastSyncError:   binding.LastSyncError,
-		Tags:            append([]string(nil), binding.Tags...),
-		Description:     binding.Description,
-		Profile:         cloneBindingProfile(binding.Profile),
-		Metadata:        binding.Metadata,
-		CreatedAt:       toUnixOrZero(binding.CreatedAt),
-		UpdatedAt:       toUnixOrZero(binding.UpdatedAt),
+	summary := types.ProtocolBindingSummary{
+		ID:                binding.ID,
+		Name:              binding.Name,
+		NodeID:            binding.NodeID,
+		NodeName:          binding.Node.Name,
+		Protocol:          normalizeBindingProtocol(binding),
+		Role:              binding.Role,
+		Listen:            binding.Listen,
+		Connect:           binding.Connect,
+		AccessPort:        binding.AccessPort,
+		Status:            binding.Status,
+		KernelID:          binding.KernelID,
+		SyncStatus:        binding.SyncStatus,
+		HealthStatus:      binding.HealthStatus,
+		ChainHealthStatus: binding.HealthStatus,
+		LastSyncedAt:      toUnixOrZero(binding.LastSyncedAt),
+		LastHeartbeatAt:   toUnixOrZero(binding.LastHeartbeatAt),
+		LastSyncError:     binding.LastSyncError,
+		SyncPending:       binding.SyncRequestedAt != nil,
+		SyncRequestedAt:   toUnixOrZeroPtr(binding.SyncRequestedAt),
+		SyncRequestReason: binding.SyncRequestReason,
+		Tags:              append([]string(nil), binding.Tags...),
+		Description:       binding.Description,
+		Profile:           cloneBindingProfile(binding.Profile),
+		Metadata:          binding.Metadata,
+		CreatedAt:         toUnixOrZero(binding.CreatedAt),
+		UpdatedAt:         toUnixOrZero(binding.UpdatedAt),
 	}
+	if binding.UpstreamBindingID != nil {
+		summary.UpstreamBindingID = *binding.UpstreamBindingID
+	}
+	return summary
 }
 
 func toUnixOrZero(ts time.Time) int64 {
@@ -60,6 +84,13 @@ func toUnixOrZero(ts time.Time) int64 {
 	return ts.Unix()
 }
 
+func toUnixOrZeroPtr(ts *time.Time) int64 {
+	if ts == nil {
+		return 0
+	}
+	return toUnixOrZero(*ts)
+}
+
 func extractHostPort(address string) (string, int) {
 	address = strings.TrimSpace(address)
 	if address == "" {
@@ -127,3 +158,26 @@ func normalizeBindingHealthStatus(statusCode int) (int, error) {
 		return 0, repository.ErrInvalidArgument
 	}
 }
+
+// assignAutoPort allocates a free port from the node's range and rewrites the
+// binding's access port and listen address to use it.
+func assignAutoPort(ctx context.Context, repos *repository.Repositories, binding *repository.ProtocolBinding) error {
+	if binding.Role != "listener" {
+		return repository.NewInvalidArgument("auto_port requires a listener binding")
+	}
+	node := binding.Node
+	if node.ID != binding.NodeID {
+		loaded, err := repos.Node.Get(ctx, binding.NodeID)
+		if err != nil {
+			return err
+		}
+		node = loaded
+	}
+	port, err := portutil.Allocate(ctx, repos, node, binding.ID)
+	if err != nil {
+		return err
+	}
+	binding.AccessPort = port
+	binding.Listen = portutil.JoinListen(binding.Listen, port)
+	return nil
+}
@@ -0,0 +1,319 @@
+package protocolbindings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func setupProtocolBindingTestContext(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+	testutil.RequireSQLite(t)
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+	credentials, err := security.NewCredentialManager("protocol-binding-test-key")
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+		Credentials:  credentials,
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+// fakeKernel serves the control API endpoints the binding logic calls. Import
+// replaces the whole user registry, /v1/users edits single registry users and
+// protocol upserts attach their users inline, as core.yaml documents. A
+// protocol serves its inline users plus every registry user.
+type fakeKernel struct {
+	URL string
+
+	mu        sync.Mutex
+	down      bool
+	registry  map[string]kernel.User
+	imports   []int
+	protocols map[string]kernel.ProtocolSummary
+	users     map[string][]kernel.UserView
+	dropUser  string
+	upserts   []kernel.ProtocolUpsertRequest
+	exports   []kernel.ExportRequest
+	requests  []string
+}
+
+func newFakeKernel(t *testing.T) *fakeKernel {
+	t.Helper()
+	fake := &fakeKernel{
+		registry:  map[string]kernel.User{},
+		protocols: map[string]kernel.ProtocolSummary{},
+		users:     map[string][]kernel.UserView{},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	fake.URL = server.URL
+	return fake
+}
+
+func (f *fakeKernel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if f.down {
+		http.Error(w, "unavailable", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/users/import":
+		var users []kernel.User
+		_ = json.NewDecoder(r.Body).Decode(&users)
+		f.imports = append(f.imports, len(users))
+		f.registry = make(map[string]kernel.User, len(users))
+		for _, user := range users {
+			f.registry[user.ID] = user
+		}
+		_ = json.NewEncoder(w).Encode(kernel.UsersImportResponse{Imported: len(users)})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/users":
+		var req kernel.UserCreateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if _, ok := f.registry[req.ID]; ok {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		f.registry[req.ID] = kernel.User{ID: req.ID, Username: req.Username, Password: req.Password, Rate: req.Rate, Metadata: req.Metadata, Tags: req.Tags}
+		_ = json.NewEncoder(w).Encode(kernel.UserCreateResponse{ID: req.ID})
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/v1/users/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/users/")
+		var req kernel.UserPatchRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		user, ok := f.registry[id]
+		if ok {
+			if req.Password != nil {
+				user.Password = *req.Password
+			}
+			if req.Rate != nil {
+				user.Rate = req.Rate
+			}
+			if req.Metadata != nil {
+				user.Metadata = req.Metadata
+			}
+			if req.Tags != nil {
+				user.Tags = *req.Tags
+			}
+			f.registry[id] = user
+		}
+		// A kernel user is one object, whether imported or attached inline.
+		for _, views := range f.users {
+			for i := range views {
+				if views[i].ID != id {
+					continue
+				}
+				ok = true
+				if req.Rate != nil {
+					views[i].Rate = req.Rate
+				}
+				if req.Metadata != nil {
+					views[i].Metadata = req.Metadata
+				}
+				if req.Tags != nil {
+					views[i].Tags = *req.Tags
+				}
+			}
+		}
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(kernel.UserPatchResponse{ID: id})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/users/"):
+		id := strings.TrimPrefix(r.URL.Path, "/v1/users/")
+		_, ok := f.registry[id]
+		delete(f.registry, id)
+		for protocolID, views := range f.users {
+			kept := views[:0]
+			for _, view := range views {
+				if view.ID == id {
+					ok = true
+					continue
+				}
+				kept = append(kept, view)
+			}
+			f.users[protocolID] = kept
+		}
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/protocols":
+		result := make([]kernel.ProtocolSummary, 0, len(f.protocols))
+		for _, protocol := range f.protocols {
+			result = append(result, protocol)
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+		_ = json.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/protocols":
+		var req kernel.ProtocolUpsertRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.upserts = append(f.upserts, req)
+		views := make([]kernel.UserView, 0, len(req.Users))
+		for _, user := range req.Users {
+			if user.ID == f.dropUser {
+				continue
+			}
+			views = append(views, kernel.UserView{ID: user.ID, Username: user.Username, Rate: user.Rate, Metadata: user.Metadata})
+		}
+		summary := kernel.ProtocolSummary{
+			ID:        req.Profile.ID,
+			Role:      req.Profile.Role,
+			Protocol:  req.Profile.Protocol,
+			Listen:    req.Listen,
+			Connect:   req.Connect,
+			UserCount: len(views),
+		}
+		f.protocols[req.Profile.ID] = summary
+		f.users[req.Profile.ID] = views
+		_ = json.NewEncoder(w).Encode(summary)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/protocols/"):
+		protocolID := strings.TrimPrefix(r.URL.Path, "/v1/protocols/")
+		if _, ok := f.protocols[protocolID]; !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		delete(f.protocols, protocolID)
+		delete(f.users, protocolID)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/users"):
+		protocolID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/protocols/"), "/users")
+		inline, ok := f.users[protocolID]
+		if _, known := f.protocols[protocolID]; !ok && !known {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.protocolUsers(inline))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/export":
+		var req kernel.ExportRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.exports = append(f.exports, req)
+		unique := map[string]struct{}{}
+		for _, views := range f.users {
+			for _, view := range views {
+				unique[view.ID] = struct{}{}
+			}
+		}
+		users := len(unique)
+		_ = json.NewEncoder(w).Encode(kernel.ExportResponse{
+			Path:      req.Path,
+			Protocols: len(f.protocols),
+			Version:   "v1",
+			Users:     &users,
+			Pipeline:  &kernel.PipelineExportMeta{Strategies: 1, Rules: 3},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// protocolUsers merges the inline users of a protocol with the registry;
+// callers hold f.mu.
+func (f *fakeKernel) protocolUsers(inline []kernel.UserView) []kernel.UserView {
+	users := make([]kernel.UserView, 0, len(inline)+len(f.registry))
+	seen := make(map[string]struct{}, len(inline)+len(f.registry))
+	for _, view := range inline {
+		seen[view.ID] = struct{}{}
+		users = append(users, view)
+	}
+	ids := make([]string, 0, len(f.registry))
+	for id := range f.registry {
+		if _, ok := seen[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		user := f.registry[id]
+		users = append(users, kernel.UserView{ID: user.ID, Username: user.Username, Rate: user.Rate, Metadata: user.Metadata, Tags: user.Tags})
+	}
+	return users
+}
+
+func (f *fakeKernel) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+// setUsers attaches users with the given ids to a protocol.
+func (f *fakeKernel) setUsers(protocolID string, userIDs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	views := make([]kernel.UserView, 0, len(userIDs))
+	for _, id := range userIDs {
+		views = append(views, kernel.UserView{ID: id})
+	}
+	f.users[protocolID] = views
+}
+
+func (f *fakeKernel) upsertCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.upserts)
+}
+
+// calls counts requests whose method and path end with the given suffix.
+func (f *fakeKernel) calls(method, pathSuffix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, request := range f.requests {
+		if strings.HasPrefix(request, method+" ") && strings.HasSuffix(request, pathSuffix) {
+			count++
+		}
+	}
+	return count
+}
+
+// reset clears recorded traffic while keeping the kernel state.
+func (f *fakeKernel) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dropUser = ""
+	f.imports = nil
+	f.upserts = nil
+	f.exports = nil
+	f.requests = nil
+}
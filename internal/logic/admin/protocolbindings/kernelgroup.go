@@ -0,0 +1,102 @@
+package protocolbindings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+// kernelKey identifies the kernel behind a panel node. Nodes that share a
+// control endpoint and credentials drive the same kernel, as the status poller
+// already assumes; the credentials only enter the key as a fingerprint.
+func kernelKey(node repository.Node) string {
+	endpoint := strings.TrimSpace(node.ControlEndpoint)
+	token := resolveControlToken(node)
+	if token == "" {
+		return endpoint
+	}
+	sum := sha256.Sum256([]byte(token))
+	return endpoint + "|" + hex.EncodeToString(sum[:4])
+}
+
+// lockKernel serializes writes to the kernel of a node and returns the
+// release func.
+func (l *SyncLogic) lockKernel(node repository.Node) func() {
+	return l.svcCtx.KernelLocks.Lock(kernelKey(node))
+}
+
+// kernelNodeIDs returns the ids of every node on the kernel of node, node
+// included.
+func (l *SyncLogic) kernelNodeIDs(node repository.Node) ([]uint64, error) {
+	nodes, err := l.svcCtx.Repositories.Node.ListAll(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := kernelKey(node)
+	ids := []uint64{node.ID}
+	for _, candidate := range nodes {
+		if candidate.ID != node.ID && kernelKey(candidate) == key {
+			ids = append(ids, candidate.ID)
+		}
+	}
+	return ids, nil
+}
+
+// kernelBindings returns the bindings of every node on the kernel of node.
+// Protocol ownership is decided on this set: a protocol that a binding of a
+// sibling node runs is not an orphan of node.
+func (l *SyncLogic) kernelBindings(node repository.Node) ([]repository.ProtocolBinding, error) {
+	ids, err := l.kernelNodeIDs(node)
+	if err != nil {
+		return nil, err
+	}
+	return l.svcCtx.Repositories.ProtocolBinding.ListByNodeIDs(l.ctx, ids)
+}
+
+// sharedKernelUsers returns the ids of the users that active bindings other
+// than binding expect on its kernel. Kernel users are not scoped to a
+// protocol, so such a user is never extra for binding.
+func (l *SyncLogic) sharedKernelUsers(binding repository.ProtocolBinding) (map[string]struct{}, error) {
+	bindings, err := l.kernelBindings(binding.Node)
+	if err != nil {
+		return nil, err
+	}
+	shared := make(map[string]struct{})
+	for _, sibling := range bindings {
+		if sibling.ID == binding.ID || strings.TrimSpace(sibling.KernelID) == "" ||
+			sibling.Status != status.ProtocolBindingStatusActive || sibling.Node.Status == status.NodeStatusDisabled {
+			continue
+		}
+		users, err := l.buildKernelUsers(sibling)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			shared[user.ID] = struct{}{}
+		}
+	}
+	return shared, nil
+}
+
+// dropSharedUsers removes the users other bindings on the kernel expect from
+// a diff's extra users.
+func (l *SyncLogic) dropSharedUsers(binding repository.ProtocolBinding, diff *kernelUserDiff) error {
+	if len(diff.extra) == 0 {
+		return nil
+	}
+	shared, err := l.sharedKernelUsers(binding)
+	if err != nil {
+		return err
+	}
+	extra := diff.extra[:0]
+	for _, user := range diff.extra {
+		if _, ok := shared[user.ID]; !ok {
+			extra = append(extra, user)
+		}
+	}
+	diff.extra = extra
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
@@ -46,9 +47,16 @@ func (l *ListLogic) List(req *types.AdminListProtocolBindingsRequest) (*types.Ad
 		return nil, err
 	}
 
+	chainHealth, err := relayutil.ChainHealth(l.ctx, l.svcCtx.Repositories, bindings)
+	if err != nil {
+		return nil, err
+	}
+
 	summaries := make([]types.ProtocolBindingSummary, 0, len(bindings))
 	for _, binding := range bindings {
-		summaries = append(summaries, mapProtocolBindingSummary(binding))
+		summary := mapProtocolBindingSummary(binding)
+		summary.ChainHealthStatus = chainHealth[binding.ID]
+		summaries = append(summaries, summary)
 	}
 
 	page, perPage := normalizePage(req.Page, req.PerPage)
@@ -0,0 +1,120 @@
+package protocolbindings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolentries"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestBindingPortConflicts(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db := svcCtx.DB
+
+	now := time.Now().UTC()
+	node := repository.Node{
+		Name:            "edge-1",
+		AccessAddress:   "203.0.113.10",
+		ControlEndpoint: "http://203.0.113.10:8080",
+		PortRangeStart:  20000,
+		PortRangeEnd:    20002,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	require.NoError(t, db.Create(&node).Error)
+
+	create := NewCreateLogic(ctx, svcCtx)
+	request := func(kernelID, protocol, listen string, port int) *types.AdminCreateProtocolBindingRequest {
+		return &types.AdminCreateProtocolBindingRequest{
+			NodeID:     node.ID,
+			Protocol:   protocol,
+			Role:       "listener",
+			Listen:     listen,
+			AccessPort: port,
+			KernelID:   kernelID,
+			Profile:    map[string]any{},
+		}
+	}
+
+	trojan, err := create.Create(request("trojan-in", "trojan", "", 443))
+	require.NoError(t, err)
+
+	_, err = create.Create(request("vmess-in", "vmess", "0.0.0.0:443", 0))
+	require.ErrorIs(t, err, repository.ErrConflict)
+	require.ErrorContains(t, err, "port 443")
+
+	// Same kernel inbound, a UDP-only protocol and a different host do not collide.
+	_, err = create.Create(request("trojan-in", "trojan", "", 443))
+	require.NoError(t, err)
+	_, err = create.Create(request("hy2-in", "hysteria2", "", 443))
+	require.NoError(t, err)
+	_, err = create.Create(request("local-in", "vmess", "127.0.0.1:8443", 0))
+	require.NoError(t, err)
+	_, err = create.Create(request("public-in", "vmess", "203.0.113.10:8443", 0))
+	require.NoError(t, err)
+	_, err = create.Create(request("any-in", "vmess", ":8443", 0))
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	autoReq := request("auto-a", "vless", "203.0.113.10", 0)
+	autoReq.AutoPort = true
+	first, err := create.Create(autoReq)
+	require.NoError(t, err)
+	require.Equal(t, 20000, first.AccessPort)
+	require.Equal(t, "203.0.113.10:20000", first.Listen)
+
+	// An entry published on the node's own address reserves its port.
+	entries := protocolentries.NewCreateLogic(ctx, svcCtx)
+	_, err = entries.Create(&types.AdminCreateProtocolEntryRequest{
+		Name:         "public",
+		BindingID:    trojan.ID,
+		EntryAddress: "203.0.113.10",
+		EntryPort:    20000,
+	})
+	require.ErrorIs(t, err, repository.ErrConflict)
+	_, err = entries.Create(&types.AdminCreateProtocolEntryRequest{
+		Name:         "public",
+		BindingID:    trojan.ID,
+		EntryAddress: "203.0.113.10",
+		EntryPort:    20001,
+	})
+	require.NoError(t, err)
+	_, err = entries.Create(&types.AdminCreateProtocolEntryRequest{
+		Name:         "public",
+		BindingID:    trojan.ID,
+		EntryAddress: "198.51.100.7",
+		EntryPort:    20000,
+	})
+	require.NoError(t, err)
+
+	autoReq = request("auto-b", "vless", "", 0)
+	autoReq.AutoPort = true
+	second, err := create.Create(autoReq)
+	require.NoError(t, err)
+	require.Equal(t, 20002, second.AccessPort)
+
+	autoReq = request("auto-c", "vless", "", 0)
+	autoReq.AutoPort = true
+	_, err = create.Create(autoReq)
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	update := NewUpdateLogic(ctx, svcCtx)
+	port := 443
+	_, err = update.Update(&types.AdminUpdateProtocolBindingRequest{BindingID: second.ID, AccessPort: &port})
+	require.ErrorIs(t, err, repository.ErrConflict)
+	updated, err := update.Update(&types.AdminUpdateProtocolBindingRequest{BindingID: second.ID, AutoPort: true})
+	require.NoError(t, err)
+	require.Equal(t, 20002, updated.AccessPort)
+
+	connector := request("relay-out", "vless", "", 0)
+	connector.Role = "connector"
+	connector.AutoPort = true
+	_, err = create.Create(connector)
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
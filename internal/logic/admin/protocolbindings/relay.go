@@ -0,0 +1,101 @@
+package protocolbindings
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const relayUserPrefix = "relay-"
+
+// errRelayCredentials reports that relay identities cannot be derived.
+var errRelayCredentials = errors.New("relay credentials require Credentials.MasterKey")
+
+// resolveUpstream renders the immediate upstream of a relay binding. Each hop
+// forwards only to its own upstream, so deeper hops are configured when their
+// bindings sync. Connectors without an explicit connect address dial the
+// upstream endpoint.
+func (l *SyncLogic) resolveUpstream(binding repository.ProtocolBinding) ([]kernel.UpstreamProfile, string, error) {
+	connect := strings.TrimSpace(binding.Connect)
+	if binding.UpstreamBindingID == nil || *binding.UpstreamBindingID == 0 {
+		return nil, connect, nil
+	}
+	chain, err := relayutil.Chain(l.ctx, l.svcCtx.Repositories, binding)
+	if err != nil {
+		return nil, "", err
+	}
+	upstream := chain[0]
+	host, port := relayutil.Endpoint(upstream)
+	if host == "" || port <= 0 {
+		return nil, "", repository.InvalidArgumentf("upstream binding %d has no reachable address", upstream.ID)
+	}
+	if l.svcCtx.Credentials == nil {
+		return nil, "", errRelayCredentials
+	}
+	identity, err := l.svcCtx.Credentials.DeriveRelayIdentity(binding.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	profile := map[string]any{}
+	entries, err := l.svcCtx.Repositories.ProtocolEntry.ListByBindingIDs(l.ctx, []uint64{upstream.ID})
+	if err != nil {
+		return nil, "", err
+	}
+	for _, entry := range entries {
+		if entry.Status == status.ProtocolEntryStatusActive && len(entry.Profile) > 0 {
+			profile = cloneBindingProfile(entry.Profile)
+			break
+		}
+	}
+	profile["server"] = host
+	profile["port"] = port
+	profile["username"] = identity.Username
+	profile["password"] = identity.Password
+
+	if binding.Role == "connector" && connect == "" {
+		connect = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	return []kernel.UpstreamProfile{{
+		ID:          upstream.KernelID,
+		Tags:        mergeTags(upstream.Tags),
+		Description: strings.TrimSpace(upstream.Description),
+		Protocol:    normalizeBindingProtocol(upstream),
+		Profile:     profile,
+	}}, connect, nil
+}
+
+// buildRelayUsers returns the kernel users that let the relays directly behind
+// binding authenticate with it.
+func (l *SyncLogic) buildRelayUsers(binding repository.ProtocolBinding) ([]kernel.User, error) {
+	downstream, err := l.svcCtx.Repositories.ProtocolBinding.ListByUpstreamIDs(l.ctx, []uint64{binding.ID})
+	if err != nil || len(downstream) == 0 {
+		return nil, err
+	}
+	if l.svcCtx.Credentials == nil {
+		return nil, errRelayCredentials
+	}
+	users := make([]kernel.User, 0, len(downstream))
+	for _, relay := range downstream {
+		if relay.Status != status.ProtocolBindingStatusActive {
+			continue
+		}
+		identity, err := l.svcCtx.Credentials.DeriveRelayIdentity(relay.ID)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, kernel.User{
+			ID:       relayUserPrefix + strconv.FormatUint(relay.ID, 10),
+			Username: identity.Username,
+			Password: identity.Password,
+			Metadata: map[string]any{"relay_binding_id": strconv.FormatUint(relay.ID, 10)},
+		})
+	}
+	return users, nil
+}
@@ -0,0 +1,107 @@
+package protocolbindings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestRelayChain(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	exit := repository.Node{Name: "exit", AccessAddress: "198.51.100.20", CreatedAt: now, UpdatedAt: now}
+	edge := repository.Node{Name: "edge", AccessAddress: "203.0.113.10", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&exit).Error)
+	require.NoError(t, db.Create(&edge).Error)
+
+	create := NewCreateLogic(ctx, svcCtx)
+	upstream, err := create.Create(&types.AdminCreateProtocolBindingRequest{
+		NodeID:     exit.ID,
+		Protocol:   "vless",
+		Role:       "listener",
+		AccessPort: 8443,
+		KernelID:   "exit-in",
+		Profile:    map[string]any{},
+	})
+	require.NoError(t, err)
+	relay, err := create.Create(&types.AdminCreateProtocolBindingRequest{
+		NodeID:            edge.ID,
+		Protocol:          "vless",
+		Role:              "connector",
+		KernelID:          "edge-out",
+		UpstreamBindingID: upstream.ID,
+		Profile:           map[string]any{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, upstream.ID, relay.UpstreamBindingID)
+
+	// Connectors cannot serve as upstreams and chains must not loop.
+	_, err = create.Create(&types.AdminCreateProtocolBindingRequest{
+		NodeID:            edge.ID,
+		Protocol:          "vless",
+		Role:              "connector",
+		KernelID:          "edge-out-2",
+		UpstreamBindingID: relay.ID,
+		Profile:           map[string]any{},
+	})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	update := NewUpdateLogic(ctx, svcCtx)
+	self := upstream.ID
+	_, err = update.Update(&types.AdminUpdateProtocolBindingRequest{BindingID: upstream.ID, UpstreamBindingID: &self})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	pending, err := repos.ProtocolBinding.Get(ctx, upstream.ID)
+	require.NoError(t, err)
+	require.NotNil(t, pending.SyncRequestedAt)
+
+	relayBinding, err := repos.ProtocolBinding.Get(ctx, relay.ID)
+	require.NoError(t, err)
+	sync := NewSyncLogic(ctx, svcCtx)
+	profiles, connect, err := sync.resolveUpstream(relayBinding)
+	require.NoError(t, err)
+	require.Equal(t, "198.51.100.20:8443", connect)
+	require.Len(t, profiles, 1)
+	require.Equal(t, "exit-in", profiles[0].ID)
+	require.Equal(t, "198.51.100.20", profiles[0].Profile["server"])
+	require.Equal(t, 8443, profiles[0].Profile["port"])
+
+	upstreamBinding, err := repos.ProtocolBinding.Get(ctx, upstream.ID)
+	require.NoError(t, err)
+	users, err := sync.buildRelayUsers(upstreamBinding)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, profiles[0].Profile["username"], users[0].Username)
+	require.Equal(t, profiles[0].Profile["password"], users[0].Password)
+
+	// The relay inherits the worst health along its chain.
+	require.NoError(t, db.Model(&repository.ProtocolBinding{}).Where("id = ?", relay.ID).
+		Update("health_status", status.ProtocolBindingHealthStatusHealthy).Error)
+	require.NoError(t, db.Model(&repository.ProtocolBinding{}).Where("id = ?", upstream.ID).
+		Update("health_status", status.ProtocolBindingHealthStatusUnhealthy).Error)
+	chain, err := NewChainLogic(ctx, svcCtx).Chain(&types.AdminProtocolBindingChainRequest{BindingID: relay.ID})
+	require.NoError(t, err)
+	require.Len(t, chain.Upstream, 1)
+	require.Equal(t, upstream.ID, chain.Upstream[0].BindingID)
+	require.Equal(t, status.ProtocolBindingHealthStatusUnhealthy, chain.ChainHealthStatus)
+
+	// Moving the upstream queues the relay for a resync.
+	port := 9443
+	_, err = update.Update(&types.AdminUpdateProtocolBindingRequest{BindingID: upstream.ID, AccessPort: &port})
+	require.NoError(t, err)
+	relayBinding, err = repos.ProtocolBinding.Get(ctx, relay.ID)
+	require.NoError(t, err)
+	require.NotNil(t, relayBinding.SyncRequestedAt)
+
+	err = NewDeleteLogic(ctx, svcCtx).Delete(&types.AdminDeleteProtocolBindingRequest{BindingID: upstream.ID})
+	require.ErrorIs(t, err, repository.ErrConflict)
+}
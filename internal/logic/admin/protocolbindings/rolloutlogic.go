@@ -0,0 +1,566 @@
+package protocolbindings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+const (
+	defaultRolloutCanaryPercent = 10
+	defaultRolloutSoakSeconds   = 300
+	// rolloutHeartbeatGrace is the least time a stage waits past its soak for
+	// a healthy heartbeat from every applied binding.
+	rolloutHeartbeatGrace = time.Minute
+)
+
+// RolloutLogic pushes a binding profile change in stages. The canary stage goes
+// first; every applied binding has to report healthy after the change and none
+// may degrade during the soak period before the next stage is applied. A failed
+// push, degraded health or a missing heartbeat restores the previous profile on
+// every binding touched so far.
+type RolloutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRolloutLogic constructs RolloutLogic.
+func NewRolloutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RolloutLogic {
+	return &RolloutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Create plans a rollout. The background rollout worker applies its stages.
+func (l *RolloutLogic) Create(req *types.AdminCreateBindingRolloutRequest) (*types.AdminBindingRolloutResponse, error) {
+	if len(req.Profile) == 0 {
+		return nil, fmt.Errorf("%w: profile is required", repository.ErrInvalidArgument)
+	}
+	canaryPercent := req.CanaryPercent
+	if canaryPercent == 0 {
+		canaryPercent = defaultRolloutCanaryPercent
+	}
+	if canaryPercent < 0 || canaryPercent > 100 {
+		return nil, fmt.Errorf("%w: canary_percent must be between 1 and 100", repository.ErrInvalidArgument)
+	}
+	stagePercents, err := normalizeStagePercents(req.StagePercents)
+	if err != nil {
+		return nil, err
+	}
+	soakSeconds := req.SoakSeconds
+	if soakSeconds == 0 {
+		soakSeconds = defaultRolloutSoakSeconds
+	}
+	if soakSeconds < 0 {
+		return nil, fmt.Errorf("%w: soak_seconds must not be negative", repository.ErrInvalidArgument)
+	}
+
+	bindings, err := l.resolveBindings(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(bindings) == 0 {
+		return nil, fmt.Errorf("%w: no bindings selected", repository.ErrInvalidArgument)
+	}
+	if err := l.ensureNotRollingOut(bindings); err != nil {
+		return nil, err
+	}
+	for _, binding := range bindings {
+		if err := validateBindingProfile(binding.Protocol, binding.Role, req.Profile); err != nil {
+			return nil, err
+		}
+	}
+
+	canaryTag := strings.TrimSpace(req.CanaryTag)
+	stages, err := planRolloutStages(bindings, canaryTag, canaryPercent, stagePercents)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]repository.BindingRolloutItem, 0, len(bindings))
+	for stage, stageBindings := range stages {
+		for _, binding := range stageBindings {
+			items = append(items, repository.BindingRolloutItem{
+				BindingID:       binding.ID,
+				NodeID:          binding.NodeID,
+				Stage:           stage,
+				PreviousProfile: cloneBindingProfile(binding.Profile),
+			})
+		}
+	}
+
+	actor, ok := security.UserFromContext(l.ctx)
+	var actorID *uint64
+	if ok && actor.ID != 0 {
+		actorID = &actor.ID
+	}
+	rollout, err := l.svcCtx.Repositories.BindingRollout.Create(l.ctx, repository.BindingRollout{
+		KernelID:      req.KernelID,
+		StageCount:    len(stages),
+		Profile:       cloneBindingProfile(req.Profile),
+		CanaryTag:     canaryTag,
+		CanaryPercent: canaryPercent,
+		StagePercents: stagePercents,
+		SoakSeconds:   soakSeconds,
+		ActorID:       actorID,
+	}, items)
+	if err != nil {
+		return nil, err
+	}
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.binding_rollout.create", "binding_rollout", fmt.Sprintf("%d", rollout.ID), map[string]any{
+		"bindings": len(items),
+		"stages":   rollout.StageCount,
+	}); err != nil {
+		return nil, err
+	}
+	return l.respond(rollout.ID)
+}
+
+// Get returns a rollout with the state of its bindings.
+func (l *RolloutLogic) Get(req *types.AdminBindingRolloutRequest) (*types.AdminBindingRolloutResponse, error) {
+	return l.respond(req.RolloutID)
+}
+
+// Rollback asks the rollout worker to restore the previous profile of every
+// binding a running or succeeded rollout has touched. The rollout moves to
+// rolling_back at once; stage progress stops there.
+func (l *RolloutLogic) Rollback(req *types.AdminRollbackBindingRolloutRequest) (*types.AdminBindingRolloutResponse, error) {
+	rollout, err := l.svcCtx.Repositories.BindingRollout.Get(l.ctx, req.RolloutID)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = "rolled back manually"
+	}
+	if err := l.svcCtx.Repositories.BindingRollout.RequestRollback(l.ctx, rollout.ID, reason); err != nil {
+		if errors.Is(err, repository.ErrInvalidState) {
+			return nil, fmt.Errorf("%w: rollout already rolling back or rolled back", repository.ErrInvalidState)
+		}
+		return nil, err
+	}
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.binding_rollout.rollback", "binding_rollout", fmt.Sprintf("%d", rollout.ID), map[string]any{"reason": reason}); err != nil {
+		return nil, err
+	}
+	return l.respond(rollout.ID)
+}
+
+// Advance moves a rollout forward: it carries out a requested rollback, applies
+// the current stage, rolls back when an applied binding reports degraded health
+// or no healthy heartbeat, and starts the next stage once the soak period has
+// passed with every applied binding confirmed healthy.
+func (l *RolloutLogic) Advance(rolloutID uint64) error {
+	rollout, err := l.svcCtx.Repositories.BindingRollout.Get(l.ctx, rolloutID)
+	if err != nil {
+		return err
+	}
+	switch rollout.Status {
+	case repository.BindingRolloutStatusRollingBack:
+		return l.rollback(rollout, rollout.Message)
+	case repository.BindingRolloutStatusRunning:
+	default:
+		return nil
+	}
+	if rollout.StageStartedAt == nil {
+		return l.applyStage(rollout)
+	}
+
+	items, err := l.svcCtx.Repositories.BindingRollout.ListItems(l.ctx, rollout.ID)
+	if err != nil {
+		return err
+	}
+	var unconfirmed []uint64
+	for _, item := range items {
+		if item.Status != repository.BindingRolloutItemStatusApplied || item.AppliedAt == nil {
+			continue
+		}
+		binding, err := l.svcCtx.Repositories.ProtocolBinding.Get(l.ctx, item.BindingID)
+		if err != nil {
+			return err
+		}
+		// Only health reported after the change counts for or against the rollout.
+		reported := binding.LastHeartbeatAt.After(*item.AppliedAt)
+		if isDegradedHealth(binding.HealthStatus) && reported {
+			return l.rollback(rollout, fmt.Sprintf("binding %d health degraded at stage %d", binding.ID, rollout.Stage))
+		}
+		if binding.HealthStatus != status.ProtocolBindingHealthStatusHealthy || !reported {
+			unconfirmed = append(unconfirmed, binding.ID)
+		}
+	}
+
+	soak := time.Duration(rollout.SoakSeconds) * time.Second
+	elapsed := time.Since(*rollout.StageStartedAt)
+	if elapsed < soak {
+		return nil
+	}
+	if len(unconfirmed) > 0 {
+		if elapsed < soak+max(soak, rolloutHeartbeatGrace) {
+			return nil
+		}
+		return l.rollback(rollout, fmt.Sprintf("binding %d reported no healthy heartbeat at stage %d", unconfirmed[0], rollout.Stage))
+	}
+	if rollout.Stage+1 >= rollout.StageCount {
+		now := time.Now().UTC()
+		rollout.Status = repository.BindingRolloutStatusSucceeded
+		rollout.FinishedAt = &now
+		return l.save(rollout, repository.BindingRolloutStatusRunning)
+	}
+	rollout.Stage++
+	rollout.StageStartedAt = nil
+	if err := l.save(rollout, repository.BindingRolloutStatusRunning); err != nil {
+		return err
+	}
+	return l.applyStage(rollout)
+}
+
+// save stores rollout progress unless an admin requested a rollback meanwhile;
+// the next worker tick carries that rollback out.
+func (l *RolloutLogic) save(rollout repository.BindingRollout, expectedStatus string) error {
+	_, err := l.svcCtx.Repositories.BindingRollout.Save(context.WithoutCancel(l.ctx), rollout, expectedStatus)
+	if errors.Is(err, repository.ErrConflict) {
+		l.Infof("binding rollout %d changed status concurrently; progress not saved", rollout.ID)
+		return nil
+	}
+	return err
+}
+
+// applyStage pushes the new profile to the pending bindings of the current
+// stage and starts its soak period. The first failed push rolls back.
+func (l *RolloutLogic) applyStage(rollout repository.BindingRollout) error {
+	items, err := l.svcCtx.Repositories.BindingRollout.ListItems(l.ctx, rollout.ID)
+	if err != nil {
+		return err
+	}
+	syncLogic := NewSyncLogic(l.ctx, l.svcCtx)
+	for _, item := range items {
+		if item.Stage != rollout.Stage || item.Status != repository.BindingRolloutItemStatusPending {
+			continue
+		}
+		if err := l.ctx.Err(); err != nil {
+			return err
+		}
+		// Stop pushing once an admin has asked for a rollback.
+		current, err := l.svcCtx.Repositories.BindingRollout.Get(l.ctx, rollout.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != repository.BindingRolloutStatusRunning {
+			return nil
+		}
+		if applied := l.applyItem(syncLogic, rollout, item); !applied {
+			return l.rollback(rollout, fmt.Sprintf("binding %d failed to apply at stage %d", item.BindingID, rollout.Stage))
+		}
+	}
+
+	now := time.Now().UTC()
+	rollout.StageStartedAt = &now
+	return l.save(rollout, repository.BindingRolloutStatusRunning)
+}
+
+func (l *RolloutLogic) applyItem(syncLogic *SyncLogic, rollout repository.BindingRollout, item repository.BindingRolloutItem) bool {
+	now := time.Now().UTC()
+	item.AppliedAt = &now
+	item.Status = repository.BindingRolloutItemStatusFailed
+
+	result, err := l.pushProfile(syncLogic, item.BindingID, rollout.Profile)
+	switch {
+	case err != nil:
+		item.Message = err.Error()
+	case result.Status != status.SyncResultStatusSynced:
+		item.Message = result.Message
+	default:
+		item.Status = repository.BindingRolloutItemStatusApplied
+		item.Message = ""
+	}
+
+	if err := l.svcCtx.Repositories.BindingRollout.SaveItem(context.WithoutCancel(l.ctx), item); err != nil {
+		l.Errorf("binding rollout save item failed rollout_id=%d binding_id=%d: %v", rollout.ID, item.BindingID, err)
+	}
+	return item.Status == repository.BindingRolloutItemStatusApplied
+}
+
+// rollback restores the previous profile of applied and failed bindings,
+// skips the pending ones and closes the rollout. A binding whose profile was
+// edited after the rollout applied it keeps the edit and is reported instead.
+func (l *RolloutLogic) rollback(rollout repository.BindingRollout, reason string) error {
+	// A rollback that has started must finish even when the worker stops.
+	ctx := context.WithoutCancel(l.ctx)
+	items, err := l.svcCtx.Repositories.BindingRollout.ListItems(ctx, rollout.ID)
+	if err != nil {
+		return err
+	}
+	syncLogic := NewSyncLogic(ctx, l.svcCtx)
+	var changed []uint64
+	for _, item := range items {
+		switch item.Status {
+		case repository.BindingRolloutItemStatusPending:
+			item.Status = repository.BindingRolloutItemStatusSkipped
+		case repository.BindingRolloutItemStatusApplied, repository.BindingRolloutItemStatusFailed:
+			binding, err := l.svcCtx.Repositories.ProtocolBinding.Get(ctx, item.BindingID)
+			if err != nil {
+				return err
+			}
+			if !sameBindingProfile(binding.Profile, rollout.Profile) {
+				item.Status = repository.BindingRolloutItemStatusRollbackSkipped
+				item.Message = "binding profile changed after the rollout applied it; previous profile not restored"
+				changed = append(changed, item.BindingID)
+				break
+			}
+			result, err := l.pushProfile(syncLogic, item.BindingID, item.PreviousProfile)
+			switch {
+			case err != nil:
+				item.Status = repository.BindingRolloutItemStatusRollbackFailed
+				item.Message = err.Error()
+			case result.Status != status.SyncResultStatusSynced:
+				item.Status = repository.BindingRolloutItemStatusRollbackFailed
+				item.Message = result.Message
+			default:
+				item.Status = repository.BindingRolloutItemStatusRolledBack
+			}
+		default:
+			continue
+		}
+		if err := l.svcCtx.Repositories.BindingRollout.SaveItem(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+	expectedStatus := rollout.Status
+	rollout.Status = repository.BindingRolloutStatusRolledBack
+	rollout.Message = reason
+	if len(changed) > 0 {
+		rollout.Message = fmt.Sprintf("%s; bindings %v changed after apply and were not restored", reason, changed)
+		l.Errorf("binding rollout %d: bindings %v changed after apply; previous profile not restored", rollout.ID, changed)
+	}
+	rollout.FinishedAt = &now
+	if err := l.save(rollout, expectedStatus); err != nil {
+		return err
+	}
+	l.Infof("binding rollout %d rolled back: %s", rollout.ID, reason)
+	return nil
+}
+
+// sameBindingProfile compares profiles by their JSON form, so values read back
+// from storage compare equal to the ones written.
+func sameBindingProfile(a, b map[string]any) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+// pushProfile stores a profile on a binding and pushes the binding to its kernel.
+func (l *RolloutLogic) pushProfile(syncLogic *SyncLogic, bindingID uint64, profile map[string]any) (types.ProtocolBindingSyncResult, error) {
+	next := cloneBindingProfile(profile)
+	if next == nil {
+		next = map[string]any{}
+	}
+	binding, err := l.svcCtx.Repositories.ProtocolBinding.Update(syncLogic.ctx, bindingID, repository.UpdateProtocolBindingInput{Profile: &next})
+	if err != nil {
+		return types.ProtocolBindingSyncResult{}, err
+	}
+	return syncLogic.syncBinding(binding, SyncModeFull), nil
+}
+
+func (l *RolloutLogic) resolveBindings(req *types.AdminCreateBindingRolloutRequest) ([]repository.ProtocolBinding, error) {
+	bindings, err := NewSyncLogic(l.ctx, l.svcCtx).resolveBindings(&types.AdminSyncProtocolBindingsRequest{
+		BindingIDs: req.BindingIDs,
+		NodeIDs:    req.NodeIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	kernelID := strings.TrimSpace(req.KernelID)
+	if kernelID != "" {
+		if len(req.BindingIDs) == 0 && len(req.NodeIDs) == 0 {
+			bindings, err = l.svcCtx.Repositories.ProtocolBinding.ListAll(l.ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		filtered := bindings[:0]
+		for _, binding := range bindings {
+			if binding.KernelID == kernelID {
+				filtered = append(filtered, binding)
+			}
+		}
+		bindings = filtered
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].ID < bindings[j].ID })
+	return bindings, nil
+}
+
+// ensureNotRollingOut rejects bindings that already take part in a running rollout.
+func (l *RolloutLogic) ensureNotRollingOut(bindings []repository.ProtocolBinding) error {
+	active, err := l.svcCtx.Repositories.BindingRollout.ListActiveBindingIDs(l.ctx)
+	if err != nil {
+		return err
+	}
+	busy := make(map[uint64]struct{}, len(active))
+	for _, id := range active {
+		busy[id] = struct{}{}
+	}
+	for _, binding := range bindings {
+		if _, ok := busy[binding.ID]; ok {
+			return fmt.Errorf("%w: binding %d is already part of a running rollout", repository.ErrConflict, binding.ID)
+		}
+	}
+	return nil
+}
+
+func (l *RolloutLogic) respond(rolloutID uint64) (*types.AdminBindingRolloutResponse, error) {
+	rollout, err := l.svcCtx.Repositories.BindingRollout.Get(l.ctx, rolloutID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := l.svcCtx.Repositories.BindingRollout.ListItems(l.ctx, rollout.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AdminBindingRolloutResponse{Rollout: mapBindingRolloutSummary(rollout, items)}, nil
+}
+
+// normalizeStagePercents validates the cumulative stage shares; they must grow
+// strictly and end at 100.
+func normalizeStagePercents(percents []int) ([]int, error) {
+	if len(percents) == 0 {
+		return []int{100}, nil
+	}
+	previous := 0
+	for _, percent := range percents {
+		if percent <= previous || percent > 100 {
+			return nil, fmt.Errorf("%w: stage_percents must increase within 1..100", repository.ErrInvalidArgument)
+		}
+		previous = percent
+	}
+	if previous != 100 {
+		return nil, fmt.Errorf("%w: stage_percents must end at 100", repository.ErrInvalidArgument)
+	}
+	return append([]int(nil), percents...), nil
+}
+
+// planRolloutStages splits bindings into the canary stage and the cumulative
+// stages that follow. Stages left empty by rounding are dropped.
+func planRolloutStages(bindings []repository.ProtocolBinding, canaryTag string, canaryPercent int, stagePercents []int) ([][]repository.ProtocolBinding, error) {
+	var canary, rest []repository.ProtocolBinding
+	if canaryTag != "" {
+		for _, binding := range bindings {
+			if bindingNodeHasTag(binding, canaryTag) {
+				canary = append(canary, binding)
+			} else {
+				rest = append(rest, binding)
+			}
+		}
+		if len(canary) == 0 {
+			return nil, fmt.Errorf("%w: no selected binding is on a node tagged %q", repository.ErrInvalidArgument, canaryTag)
+		}
+	} else {
+		count := percentOf(len(bindings), canaryPercent)
+		canary = bindings[:count]
+		rest = bindings[count:]
+	}
+
+	stages := [][]repository.ProtocolBinding{canary}
+	assigned := 0
+	for _, percent := range stagePercents {
+		target := percentOf(len(rest), percent)
+		if target > assigned {
+			stages = append(stages, rest[assigned:target])
+			assigned = target
+		}
+	}
+	return stages, nil
+}
+
+// percentOf returns the rounded-up share of total, at least one.
+func percentOf(total, percent int) int {
+	count := (total*percent + 99) / 100
+	if count < 1 && total > 0 {
+		count = 1
+	}
+	if count > total {
+		count = total
+	}
+	return count
+}
+
+func bindingNodeHasTag(binding repository.ProtocolBinding, tag string) bool {
+	for _, candidate := range binding.Node.Tags {
+		if strings.EqualFold(strings.TrimSpace(candidate), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDegradedHealth(healthStatus int) bool {
+	switch healthStatus {
+	case status.ProtocolBindingHealthStatusDegraded,
+		status.ProtocolBindingHealthStatusUnhealthy,
+		status.ProtocolBindingHealthStatusOffline:
+		return true
+	default:
+		return false
+	}
+}
+
+func mapBindingRolloutSummary(rollout repository.BindingRollout, items []repository.BindingRolloutItem) types.BindingRolloutSummary {
+	summaries := make([]types.BindingRolloutItemSummary, 0, len(items))
+	for _, item := range items {
+		summaries = append(summaries, types.BindingRolloutItemSummary{
+			BindingID: item.BindingID,
+			NodeID:    item.NodeID,
+			Stage:     item.Stage,
+			Status:    item.Status,
+			Message:   item.Message,
+			AppliedAt: toUnixOrZeroPtr(item.AppliedAt),
+		})
+	}
+	stagePercents := rollout.StagePercents
+	if stagePercents == nil {
+		stagePercents = []int{}
+	}
+	return types.BindingRolloutSummary{
+		ID:             rollout.ID,
+		KernelID:       rollout.KernelID,
+		Status:         rollout.Status,
+		Stage:          rollout.Stage,
+		StageCount:     rollout.StageCount,
+		Profile:        cloneBindingProfile(rollout.Profile),
+		CanaryTag:      rollout.CanaryTag,
+		CanaryPercent:  rollout.CanaryPercent,
+		StagePercents:  stagePercents,
+		SoakSeconds:    rollout.SoakSeconds,
+		StageStartedAt: toUnixOrZeroPtr(rollout.StageStartedAt),
+		Message:        rollout.Message,
+		ActorID:        rollout.ActorID,
+		CreatedAt:      toUnixOrZero(rollout.CreatedAt),
+		UpdatedAt:      toUnixOrZero(rollout.UpdatedAt),
+		FinishedAt:     toUnixOrZeroPtr(rollout.FinishedAt),
+		Items:          summaries,
+	}
+}
@@ -0,0 +1,199 @@
+package protocolbindings
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestBindingRollout(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+	fake := newFakeKernel(t)
+
+	now := time.Now().UTC()
+	nodeIDs := make([]uint64, 0, 4)
+	bindingIDs := make([]uint64, 0, 4)
+	for i := 0; i < 4; i++ {
+		tags := []string{}
+		if i == 0 {
+			tags = []string{"Canary"}
+		}
+		node := repository.Node{Name: fmt.Sprintf("node-%d", i), ControlEndpoint: fake.URL, Tags: tags, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(&node).Error)
+		nodeIDs = append(nodeIDs, node.ID)
+
+		binding := repository.ProtocolBinding{
+			Name:      fmt.Sprintf("binding-%d", i),
+			NodeID:    node.ID,
+			Protocol:  "vless",
+			Role:      "listener",
+			Listen:    "0.0.0.0:443",
+			KernelID:  "edge",
+			Status:    status.ProtocolBindingStatusActive,
+			Profile:   map[string]any{"security": "none"},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&binding).Error)
+		bindingIDs = append(bindingIDs, binding.ID)
+	}
+	profileOf := func(id uint64) any {
+		binding, err := repos.ProtocolBinding.Get(ctx, id)
+		require.NoError(t, err)
+		return binding.Profile["security"]
+	}
+
+	logic := NewRolloutLogic(ctx, svcCtx)
+	_, err := logic.Create(&types.AdminCreateBindingRolloutRequest{KernelID: "edge", Profile: map[string]any{"security": "tls"}, StagePercents: []int{50, 40}})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	_, err = logic.Create(&types.AdminCreateBindingRolloutRequest{KernelID: "edge", Profile: map[string]any{"security": "tls"}, CanaryTag: "missing"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	created, err := logic.Create(&types.AdminCreateBindingRolloutRequest{
+		KernelID:      "edge",
+		Profile:       map[string]any{"security": "tls"},
+		CanaryTag:     "canary",
+		StagePercents: []int{50, 100},
+		SoakSeconds:   60,
+	})
+	require.NoError(t, err)
+	rollout := created.Rollout
+	require.Equal(t, repository.BindingRolloutStatusRunning, rollout.Status)
+	require.Equal(t, 3, rollout.StageCount)
+	require.Len(t, rollout.Items, 4)
+	require.Equal(t, bindingIDs[0], rollout.Items[0].BindingID)
+	require.Equal(t, 0, rollout.Items[0].Stage)
+	require.Equal(t, 1, rollout.Items[2].Stage)
+	require.Equal(t, 2, rollout.Items[3].Stage)
+
+	_, err = logic.Create(&types.AdminCreateBindingRolloutRequest{BindingIDs: bindingIDs[3:], Profile: map[string]any{"security": "none"}})
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	// The canary stage is applied first and soaks before the next stage.
+	require.NoError(t, logic.Advance(rollout.ID))
+	require.Equal(t, "tls", profileOf(bindingIDs[0]))
+	require.Equal(t, "none", profileOf(bindingIDs[1]))
+	require.Equal(t, 1, fake.upsertCount())
+	require.NoError(t, logic.Advance(rollout.ID))
+	require.Equal(t, 1, fake.upsertCount())
+
+	// Health reported before the change does not count against the rollout,
+	// and the stage waits for a healthy heartbeat after the change.
+	_, err = repos.ProtocolBinding.UpdateHealthByKernelIDForNodes(ctx, "edge", nodeIDs[:1], status.ProtocolBindingHealthStatusDegraded, now.Add(-time.Hour), "stale")
+	require.NoError(t, err)
+	backdateRolloutStage(t, repos, rollout.ID, 61*time.Second)
+	require.NoError(t, logic.Advance(rollout.ID))
+	resp, err := logic.Get(&types.AdminBindingRolloutRequest{RolloutID: rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRunning, resp.Rollout.Status)
+	require.Equal(t, 0, resp.Rollout.Stage)
+	require.Equal(t, "none", profileOf(bindingIDs[1]))
+
+	_, err = repos.ProtocolBinding.UpdateHealthByKernelIDForNodes(ctx, "edge", nodeIDs[:1], status.ProtocolBindingHealthStatusHealthy, time.Now().UTC().Add(time.Minute), "")
+	require.NoError(t, err)
+	require.NoError(t, logic.Advance(rollout.ID))
+	resp, err = logic.Get(&types.AdminBindingRolloutRequest{RolloutID: rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRunning, resp.Rollout.Status)
+	require.Equal(t, 1, resp.Rollout.Stage)
+	require.Equal(t, "tls", profileOf(bindingIDs[1]))
+	require.Equal(t, "tls", profileOf(bindingIDs[2]))
+	require.Equal(t, "none", profileOf(bindingIDs[3]))
+
+	// Degraded health after the change rolls every applied binding back.
+	_, err = repos.ProtocolBinding.UpdateHealthByKernelIDForNodes(ctx, "edge", nodeIDs[1:2], status.ProtocolBindingHealthStatusUnhealthy, time.Now().UTC().Add(time.Minute), "probe failed")
+	require.NoError(t, err)
+	require.NoError(t, logic.Advance(rollout.ID))
+	resp, err = logic.Get(&types.AdminBindingRolloutRequest{RolloutID: rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRolledBack, resp.Rollout.Status)
+	require.Contains(t, resp.Rollout.Message, "health degraded")
+	require.NotZero(t, resp.Rollout.FinishedAt)
+	for _, item := range resp.Rollout.Items[:3] {
+		require.Equal(t, repository.BindingRolloutItemStatusRolledBack, item.Status)
+	}
+	require.Equal(t, repository.BindingRolloutItemStatusSkipped, resp.Rollout.Items[3].Status)
+	for _, id := range bindingIDs {
+		require.Equal(t, "none", profileOf(id))
+	}
+
+	_, err = logic.Rollback(&types.AdminRollbackBindingRolloutRequest{RolloutID: rollout.ID})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	// A failed push rolls back immediately.
+	fake.setDown(true)
+	created, err = logic.Create(&types.AdminCreateBindingRolloutRequest{BindingIDs: bindingIDs, Profile: map[string]any{"security": "tls"}})
+	require.NoError(t, err)
+	require.Equal(t, 2, created.Rollout.StageCount)
+	require.NoError(t, logic.Advance(created.Rollout.ID))
+	resp, err = logic.Get(&types.AdminBindingRolloutRequest{RolloutID: created.Rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRolledBack, resp.Rollout.Status)
+	require.Equal(t, repository.BindingRolloutItemStatusRollbackFailed, resp.Rollout.Items[0].Status)
+	require.Equal(t, "none", profileOf(bindingIDs[0]))
+
+	// A binding that never reports healthy after the change rolls back once
+	// the soak and the heartbeat grace have passed.
+	fake.setDown(false)
+	created, err = logic.Create(&types.AdminCreateBindingRolloutRequest{BindingIDs: bindingIDs[3:], Profile: map[string]any{"security": "tls"}})
+	require.NoError(t, err)
+	require.NoError(t, logic.Advance(created.Rollout.ID))
+	require.Equal(t, "tls", profileOf(bindingIDs[3]))
+	backdateRolloutStage(t, repos, created.Rollout.ID, 301*time.Second)
+	require.NoError(t, logic.Advance(created.Rollout.ID))
+	resp, err = logic.Get(&types.AdminBindingRolloutRequest{RolloutID: created.Rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRunning, resp.Rollout.Status)
+	backdateRolloutStage(t, repos, created.Rollout.ID, 601*time.Second)
+	require.NoError(t, logic.Advance(created.Rollout.ID))
+	resp, err = logic.Get(&types.AdminBindingRolloutRequest{RolloutID: created.Rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRolledBack, resp.Rollout.Status)
+	require.Contains(t, resp.Rollout.Message, "no healthy heartbeat")
+	require.Equal(t, "none", profileOf(bindingIDs[3]))
+
+	// A manual rollback is queued for the worker and keeps later edits.
+	created, err = logic.Create(&types.AdminCreateBindingRolloutRequest{BindingIDs: bindingIDs[2:3], Profile: map[string]any{"security": "tls"}})
+	require.NoError(t, err)
+	require.NoError(t, logic.Advance(created.Rollout.ID))
+	edited := map[string]any{"security": "reality"}
+	_, err = repos.ProtocolBinding.Update(ctx, bindingIDs[2], repository.UpdateProtocolBindingInput{Profile: &edited})
+	require.NoError(t, err)
+	upserts := fake.upsertCount()
+	resp, err = logic.Rollback(&types.AdminRollbackBindingRolloutRequest{RolloutID: created.Rollout.ID, Reason: "bad cert"})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRollingBack, resp.Rollout.Status)
+	require.Equal(t, upserts, fake.upsertCount())
+	_, err = logic.Rollback(&types.AdminRollbackBindingRolloutRequest{RolloutID: created.Rollout.ID})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	require.NoError(t, logic.Advance(created.Rollout.ID))
+	resp, err = logic.Get(&types.AdminBindingRolloutRequest{RolloutID: created.Rollout.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.BindingRolloutStatusRolledBack, resp.Rollout.Status)
+	require.Contains(t, resp.Rollout.Message, "bad cert")
+	require.Equal(t, repository.BindingRolloutItemStatusRollbackSkipped, resp.Rollout.Items[0].Status)
+	require.Equal(t, "reality", profileOf(bindingIDs[2]))
+	require.Equal(t, upserts, fake.upsertCount())
+}
+
+func backdateRolloutStage(t *testing.T, repos *repository.Repositories, rolloutID uint64, by time.Duration) {
+	t.Helper()
+	ctx := context.Background()
+	rollout, err := repos.BindingRollout.Get(ctx, rolloutID)
+	require.NoError(t, err)
+	startedAt := time.Now().UTC().Add(-by)
+	rollout.StageStartedAt = &startedAt
+	_, err = repos.BindingRollout.Save(ctx, rollout, rollout.Status)
+	require.NoError(t, err)
+}
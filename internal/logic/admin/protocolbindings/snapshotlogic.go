@@ -0,0 +1,589 @@
+package protocolbindings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+// Diff side kinds.
+const (
+	snapshotRefSnapshot = "snapshot"
+	snapshotRefIntended = "intended"
+)
+
+// SnapshotLogic captures, lists and compares node configuration snapshots.
+type SnapshotLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	sync   *SyncLogic
+}
+
+// NewSnapshotLogic constructs SnapshotLogic.
+func NewSnapshotLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SnapshotLogic {
+	return &SnapshotLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		sync:   NewSyncLogic(ctx, svcCtx),
+	}
+}
+
+// Create takes a manual snapshot of what the node kernel is running.
+func (l *SnapshotLogic) Create(req *types.AdminCreateNodeSnapshotRequest) (*types.AdminNodeSnapshotResponse, error) {
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+	control, err := l.sync.resolveControlClient(repository.ProtocolBinding{Node: node})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidState, err)
+	}
+
+	actor, ok := security.UserFromContext(l.ctx)
+	var actorID *uint64
+	if ok && actor.ID != 0 {
+		actorID = &actor.ID
+	}
+
+	snapshot, err := l.sync.captureSnapshot(control, node, repository.NodeSnapshotTriggerManual, req.Reason, actorID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := l.svcCtx.Repositories.AuditLog.Create(l.ctx, repository.AuditLog{
+		ActorID:      actorID,
+		ActorEmail:   actor.Email,
+		ActorRoles:   actor.Roles,
+		Action:       "admin.node.snapshot.create",
+		ResourceType: "node",
+		ResourceID:   fmt.Sprintf("%d", node.ID),
+		Metadata: map[string]any{
+			"snapshot_id": snapshot.ID,
+			"version":     snapshot.Version,
+			"reason":      snapshot.Reason,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return mapNodeSnapshotResponse(snapshot), nil
+}
+
+// List returns the snapshots of a node, newest first.
+func (l *SnapshotLogic) List(req *types.AdminListNodeSnapshotsRequest) (*types.AdminNodeSnapshotListResponse, error) {
+	if _, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID); err != nil {
+		return nil, err
+	}
+	snapshots, total, err := l.svcCtx.Repositories.NodeConfigSnapshot.List(l.ctx, req.NodeID, repository.NodeConfigSnapshotListOptions{
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]types.NodeSnapshotSummary, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		summaries = append(summaries, mapNodeSnapshotSummary(snapshot))
+	}
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	return &types.AdminNodeSnapshotListResponse{
+		Snapshots: summaries,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
+
+// Get returns a snapshot with its captured protocols.
+func (l *SnapshotLogic) Get(req *types.AdminGetNodeSnapshotRequest) (*types.AdminNodeSnapshotResponse, error) {
+	snapshot, err := l.getNodeSnapshot(req.NodeID, req.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	return mapNodeSnapshotResponse(snapshot), nil
+}
+
+// Diff compares snapshot From with snapshot To, or with the configuration the
+// panel intends the node to run when To is zero.
+func (l *SnapshotLogic) Diff(req *types.AdminNodeSnapshotDiffRequest) (*types.AdminNodeSnapshotDiffResponse, error) {
+	from, err := l.getNodeSnapshot(req.NodeID, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.AdminNodeSnapshotDiffResponse{
+		NodeID: req.NodeID,
+		From:   mapNodeSnapshotRef(from),
+	}
+
+	var target []repository.NodeConfigProtocol
+	intended := req.To == 0
+	if intended {
+		node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		target, err = l.sync.buildIntendedConfig(node)
+		if err != nil {
+			return nil, err
+		}
+		resp.To = types.NodeSnapshotRef{Kind: snapshotRefIntended, CreatedAt: time.Now().UTC().Unix()}
+	} else {
+		to, err := l.getNodeSnapshot(req.NodeID, req.To)
+		if err != nil {
+			return nil, err
+		}
+		target = to.Protocols
+		resp.To = mapNodeSnapshotRef(to)
+	}
+
+	// Kernels may omit fields they do not report back; against the intended
+	// config an empty captured value is treated as unknown, not as a change.
+	applyNodeConfigDiff(resp, from.Protocols, target, intended)
+	return resp, nil
+}
+
+func (l *SnapshotLogic) getNodeSnapshot(nodeID, snapshotID uint64) (repository.NodeConfigSnapshot, error) {
+	if snapshotID == 0 {
+		return repository.NodeConfigSnapshot{}, repository.ErrInvalidArgument
+	}
+	snapshot, err := l.svcCtx.Repositories.NodeConfigSnapshot.Get(l.ctx, snapshotID)
+	if err != nil {
+		return repository.NodeConfigSnapshot{}, err
+	}
+	if snapshot.NodeID != nodeID {
+		return repository.NodeConfigSnapshot{}, repository.ErrNotFound
+	}
+	return snapshot, nil
+}
+
+// snapshotBeforeSync records what the node runs before a binding is pushed.
+// Callers invoke it right before the first write that reaches the kernel, so
+// syncs that find nothing to change do not pay for a capture. Unchanged
+// configurations are not stored again; failures never block the sync.
+func (l *SyncLogic) snapshotBeforeSync(control *kernel.ControlClient, binding repository.ProtocolBinding, mode string) {
+	if l.svcCtx == nil || !l.svcCtx.Config.KernelSnapshot.AutoSnapshotEnabled() {
+		return
+	}
+	reason := fmt.Sprintf("binding %d %s sync", binding.ID, mode)
+	if _, err := l.captureSnapshot(control, binding.Node, repository.NodeSnapshotTriggerSync, reason, nil, false); err != nil {
+		l.Errorf("kernel snapshot before sync failed node_id=%d binding_id=%d: %v", binding.Node.ID, binding.ID, err)
+	}
+}
+
+// captureSnapshot reads the running protocols and their users, then asks the
+// kernel to export its configuration. Without force, a capture identical to the
+// latest snapshot is not stored and the latest snapshot is returned instead.
+func (l *SyncLogic) captureSnapshot(control *kernel.ControlClient, node repository.Node, trigger, reason string, actorID *uint64, force bool) (repository.NodeConfigSnapshot, error) {
+	protocols, err := l.captureRunningConfig(control, node)
+	if err != nil {
+		return repository.NodeConfigSnapshot{}, err
+	}
+	hash, err := hashNodeConfig(protocols)
+	if err != nil {
+		return repository.NodeConfigSnapshot{}, err
+	}
+
+	repo := l.svcCtx.Repositories.NodeConfigSnapshot
+	if !force {
+		latest, err := repo.Latest(l.ctx, node.ID)
+		switch {
+		case err == nil && latest.ContentHash == hash:
+			return latest, nil
+		case err != nil && !errors.Is(err, repository.ErrNotFound):
+			return repository.NodeConfigSnapshot{}, err
+		}
+	}
+
+	now := time.Now().UTC()
+	snapshot := repository.NodeConfigSnapshot{
+		NodeID:      node.ID,
+		Trigger:     trigger,
+		Reason:      truncateSnapshotReason(reason),
+		ContentHash: hash,
+		Protocols:   protocols,
+		ActorID:     actorID,
+		CreatedAt:   now,
+	}
+
+	cfg := l.svcCtx.Config.KernelSnapshot
+	dir := cfg.ExportDir
+	if dir == "" {
+		dir = "snapshots"
+	}
+	// One export file per node: each capture overwrites the previous one, so
+	// the kernel host does not accumulate files the panel cannot prune.
+	exported, err := control.Export(l.ctx, kernel.ExportRequest{
+		Path:            fmt.Sprintf("%s/node-%d.yaml", dir, node.ID),
+		Format:          "yaml",
+		RedactUsers:     true,
+		IncludeUsers:    true,
+		IncludeAPI:      true,
+		IncludePipeline: true,
+		VersionLabel:    fmt.Sprintf("znp-node-%d-%s-%d", node.ID, trigger, now.Unix()),
+	})
+	if err != nil {
+		snapshot.ExportError = err.Error()
+	} else {
+		snapshot.Export = mapKernelExport(exported)
+	}
+
+	saved, err := repo.Create(l.ctx, snapshot)
+	if err != nil {
+		return repository.NodeConfigSnapshot{}, err
+	}
+	if cfg.MaxPerNode > 0 {
+		if err := repo.Prune(l.ctx, node.ID, cfg.MaxPerNode); err != nil {
+			l.Errorf("kernel snapshot prune failed node_id=%d: %v", node.ID, err)
+		}
+	}
+	return saved, nil
+}
+
+// captureRunningConfig lists kernel protocols and the user ids attached to each.
+// Profiles are not reported by the kernel; each protocol carries the profile the
+// panel last pushed to it instead.
+func (l *SyncLogic) captureRunningConfig(control *kernel.ControlClient, node repository.Node) ([]repository.NodeConfigProtocol, error) {
+	summaries, err := control.ListProtocols(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings, err := l.svcCtx.Repositories.ProtocolBinding.ListByNodeIDs(l.ctx, []uint64{node.ID})
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]map[string]any, len(bindings))
+	for _, binding := range bindings {
+		if kernelID := strings.TrimSpace(binding.KernelID); kernelID != "" && binding.AppliedProfile != nil {
+			applied[kernelID] = binding.AppliedProfile
+		}
+	}
+
+	protocols := make([]repository.NodeConfigProtocol, 0, len(summaries))
+	for _, summary := range summaries {
+		kernelID := strings.TrimSpace(summary.ID)
+		if kernelID == "" {
+			continue
+		}
+		users, err := control.ListProtocolUsers(l.ctx, kernelID)
+		if err != nil && !errors.Is(err, kernel.ErrNotFound) {
+			return nil, err
+		}
+		userIDs := make([]string, 0, len(users))
+		for _, user := range users {
+			userIDs = append(userIDs, strings.TrimSpace(user.ID))
+		}
+		protocols = append(protocols, normalizeNodeConfigProtocol(repository.NodeConfigProtocol{
+			KernelID:    kernelID,
+			Role:        summary.Role,
+			Protocol:    summary.Protocol,
+			Tags:        summary.Tags,
+			Description: summary.Description,
+			Listen:      summary.Listen,
+			Connect:     summary.Connect,
+			Users:       userIDs,
+			Profile:     applied[kernelID],
+		}))
+	}
+	sortNodeConfig(protocols)
+	return protocols, nil
+}
+
+// buildIntendedConfig renders the active bindings of a node the way syncBinding pushes them.
+func (l *SyncLogic) buildIntendedConfig(node repository.Node) ([]repository.NodeConfigProtocol, error) {
+	bindings, err := l.svcCtx.Repositories.ProtocolBinding.ListByNodeIDs(l.ctx, []uint64{node.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	protocols := make([]repository.NodeConfigProtocol, 0, len(bindings))
+	for _, binding := range bindings {
+		kernelID := strings.TrimSpace(binding.KernelID)
+		if kernelID == "" || binding.Status != status.ProtocolBindingStatusActive {
+			continue
+		}
+		users, err := l.buildKernelUsers(binding)
+		if err != nil {
+			return nil, err
+		}
+		userIDs := make([]string, 0, len(users))
+		for _, user := range users {
+			userIDs = append(userIDs, user.ID)
+		}
+		_, connect, err := l.resolveUpstream(binding)
+		if err != nil {
+			return nil, err
+		}
+		protocols = append(protocols, normalizeNodeConfigProtocol(repository.NodeConfigProtocol{
+			KernelID:    kernelID,
+			Role:        binding.Role,
+			Protocol:    normalizeBindingProtocol(binding),
+			Tags:        mergeTags(binding.Tags),
+			Description: binding.Description,
+			Listen:      normalizeListen(binding.Listen, binding.AccessPort),
+			Connect:     connect,
+			Users:       userIDs,
+			Profile:     buildKernelProfile(binding).Profile,
+		}))
+	}
+	sortNodeConfig(protocols)
+	return protocols, nil
+}
+
+func normalizeNodeConfigProtocol(protocol repository.NodeConfigProtocol) repository.NodeConfigProtocol {
+	protocol.Role = strings.ToLower(strings.TrimSpace(protocol.Role))
+	protocol.Protocol = strings.ToLower(strings.TrimSpace(protocol.Protocol))
+	protocol.Description = strings.TrimSpace(protocol.Description)
+	protocol.Listen = strings.TrimSpace(protocol.Listen)
+	protocol.Connect = strings.TrimSpace(protocol.Connect)
+	tags := mergeTags(protocol.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	sort.Strings(tags)
+	protocol.Tags = tags
+	users := make([]string, 0, len(protocol.Users))
+	for _, id := range protocol.Users {
+		if id = strings.TrimSpace(id); id != "" {
+			users = append(users, id)
+		}
+	}
+	sort.Strings(users)
+	protocol.Users = users
+	if len(protocol.Profile) == 0 {
+		protocol.Profile = nil
+	} else {
+		protocol.Profile = cloneBindingProfile(protocol.Profile)
+	}
+	return protocol
+}
+
+func sortNodeConfig(protocols []repository.NodeConfigProtocol) {
+	sort.Slice(protocols, func(i, j int) bool {
+		return protocols[i].KernelID < protocols[j].KernelID
+	})
+}
+
+func hashNodeConfig(protocols []repository.NodeConfigProtocol) (string, error) {
+	payload, err := json.Marshal(protocols)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// applyNodeConfigDiff fills the diff of from -> to; lenient skips fields whose
+// from-side value is empty.
+func applyNodeConfigDiff(resp *types.AdminNodeSnapshotDiffResponse, from, to []repository.NodeConfigProtocol, lenient bool) {
+	resp.AddedProtocols = []types.NodeSnapshotProtocol{}
+	resp.RemovedProtocols = []types.NodeSnapshotProtocol{}
+	resp.ChangedProtocols = []types.NodeSnapshotProtocolChange{}
+
+	before := make(map[string]repository.NodeConfigProtocol, len(from))
+	for _, protocol := range from {
+		before[protocol.KernelID] = protocol
+	}
+	after := make(map[string]repository.NodeConfigProtocol, len(to))
+	for _, protocol := range to {
+		after[protocol.KernelID] = protocol
+	}
+
+	for _, protocol := range from {
+		if _, ok := after[protocol.KernelID]; !ok {
+			resp.RemovedProtocols = append(resp.RemovedProtocols, mapNodeSnapshotProtocol(protocol))
+		}
+	}
+	for _, protocol := range to {
+		previous, ok := before[protocol.KernelID]
+		if !ok {
+			resp.AddedProtocols = append(resp.AddedProtocols, mapNodeSnapshotProtocol(protocol))
+			continue
+		}
+		if change, changed := diffNodeConfigProtocol(previous, protocol, lenient); changed {
+			resp.ChangedProtocols = append(resp.ChangedProtocols, change)
+		}
+	}
+
+	resp.Identical = len(resp.AddedProtocols) == 0 &&
+		len(resp.RemovedProtocols) == 0 &&
+		len(resp.ChangedProtocols) == 0
+}
+
+func diffNodeConfigProtocol(from, to repository.NodeConfigProtocol, lenient bool) (types.NodeSnapshotProtocolChange, bool) {
+	change := types.NodeSnapshotProtocolChange{
+		KernelID:     to.KernelID,
+		Fields:       []types.NodeSnapshotFieldChange{},
+		UsersAdded:   []string{},
+		UsersRemoved: []string{},
+	}
+	compare := func(field, before, after string) {
+		if before == after || (lenient && before == "") {
+			return
+		}
+		change.Fields = append(change.Fields, types.NodeSnapshotFieldChange{Field: field, From: before, To: after})
+	}
+	compare("role", from.Role, to.Role)
+	compare("protocol", from.Protocol, to.Protocol)
+	compare("listen", from.Listen, to.Listen)
+	compare("connect", from.Connect, to.Connect)
+	compare("description", from.Description, to.Description)
+	compare("tags", strings.Join(from.Tags, ","), strings.Join(to.Tags, ","))
+	compare("profile", encodeSnapshotProfile(from.Profile), encodeSnapshotProfile(to.Profile))
+
+	before := make(map[string]struct{}, len(from.Users))
+	for _, id := range from.Users {
+		before[id] = struct{}{}
+	}
+	after := make(map[string]struct{}, len(to.Users))
+	for _, id := range to.Users {
+		after[id] = struct{}{}
+		if _, ok := before[id]; !ok {
+			change.UsersAdded = append(change.UsersAdded, id)
+		}
+	}
+	for _, id := range from.Users {
+		if _, ok := after[id]; !ok {
+			change.UsersRemoved = append(change.UsersRemoved, id)
+		}
+	}
+
+	changed := len(change.Fields) > 0 || len(change.UsersAdded) > 0 || len(change.UsersRemoved) > 0
+	return change, changed
+}
+
+// encodeSnapshotProfile renders a profile as JSON with sorted keys; empty
+// profiles encode to "".
+func encodeSnapshotProfile(profile map[string]any) string {
+	if len(profile) == 0 {
+		return ""
+	}
+	payload, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Sprint(profile)
+	}
+	return string(payload)
+}
+
+func mapKernelExport(resp kernel.ExportResponse) repository.NodeConfigExport {
+	export := repository.NodeConfigExport{
+		Path:      strings.TrimSpace(resp.Path),
+		Version:   strings.TrimSpace(resp.Version),
+		Protocols: resp.Protocols,
+		Users:     resp.Users,
+		API:       resp.API,
+	}
+	if resp.Pipeline != nil {
+		export.PipelineStrategies = resp.Pipeline.Strategies
+		export.PipelineRules = resp.Pipeline.Rules
+		export.PipelineResolvedRules = resp.Pipeline.ResolvedRules
+	}
+	if resp.Filters != nil {
+		export.FilterProtocols = append([]string(nil), resp.Filters.Protocols...)
+	}
+	return export
+}
+
+func mapNodeSnapshotSummary(snapshot repository.NodeConfigSnapshot) types.NodeSnapshotSummary {
+	users := 0
+	for _, protocol := range snapshot.Protocols {
+		users += len(protocol.Users)
+	}
+	filters := snapshot.Export.FilterProtocols
+	if filters == nil {
+		filters = []string{}
+	}
+	return types.NodeSnapshotSummary{
+		ID:            snapshot.ID,
+		NodeID:        snapshot.NodeID,
+		Version:       snapshot.Version,
+		Trigger:       snapshot.Trigger,
+		Reason:        snapshot.Reason,
+		ContentHash:   snapshot.ContentHash,
+		ProtocolCount: len(snapshot.Protocols),
+		UserCount:     users,
+		Export: types.NodeSnapshotExport{
+			Path:                  snapshot.Export.Path,
+			Version:               snapshot.Export.Version,
+			Protocols:             snapshot.Export.Protocols,
+			Users:                 snapshot.Export.Users,
+			API:                   snapshot.Export.API,
+			PipelineStrategies:    snapshot.Export.PipelineStrategies,
+			PipelineRules:         snapshot.Export.PipelineRules,
+			PipelineResolvedRules: snapshot.Export.PipelineResolvedRules,
+			FilterProtocols:       append([]string{}, filters...),
+		},
+		ExportError: snapshot.ExportError,
+		ActorID:     snapshot.ActorID,
+		CreatedAt:   toUnixOrZero(snapshot.CreatedAt),
+	}
+}
+
+func mapNodeSnapshotResponse(snapshot repository.NodeConfigSnapshot) *types.AdminNodeSnapshotResponse {
+	protocols := make([]types.NodeSnapshotProtocol, 0, len(snapshot.Protocols))
+	for _, protocol := range snapshot.Protocols {
+		protocols = append(protocols, mapNodeSnapshotProtocol(protocol))
+	}
+	return &types.AdminNodeSnapshotResponse{
+		Snapshot:  mapNodeSnapshotSummary(snapshot),
+		Protocols: protocols,
+	}
+}
+
+func mapNodeSnapshotProtocol(protocol repository.NodeConfigProtocol) types.NodeSnapshotProtocol {
+	profile := cloneBindingProfile(protocol.Profile)
+	if profile == nil {
+		profile = map[string]any{}
+	}
+	return types.NodeSnapshotProtocol{
+		KernelID:    protocol.KernelID,
+		Role:        protocol.Role,
+		Protocol:    protocol.Protocol,
+		Tags:        append([]string{}, protocol.Tags...),
+		Description: protocol.Description,
+		Listen:      protocol.Listen,
+		Connect:     protocol.Connect,
+		Users:       append([]string{}, protocol.Users...),
+		Profile:     profile,
+	}
+}
+
+func mapNodeSnapshotRef(snapshot repository.NodeConfigSnapshot) types.NodeSnapshotRef {
+	return types.NodeSnapshotRef{
+		Kind:       snapshotRefSnapshot,
+		SnapshotID: snapshot.ID,
+		Version:    snapshot.Version,
+		CreatedAt:  toUnixOrZero(snapshot.CreatedAt),
+	}
+}
+
+func truncateSnapshotReason(reason string) string {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 255 {
+		return reason[:255]
+	}
+	return reason
+}
@@ -0,0 +1,143 @@
+package protocolbindings
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestNodeSnapshots(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+	svcCtx.Config.KernelSnapshot.Normalize()
+
+	fake := newFakeKernel(t)
+	fake.protocols["edge"] = kernel.ProtocolSummary{ID: "edge", Role: "listener", Protocol: "vless", Listen: "0.0.0.0:443"}
+	fake.protocols["legacy"] = kernel.ProtocolSummary{ID: "legacy", Role: "listener", Protocol: "trojan", Listen: "0.0.0.0:8443"}
+	fake.setUsers("edge", "1", "2")
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	binding := repository.ProtocolBinding{
+		Name:      "edge",
+		NodeID:    node.ID,
+		Protocol:  "vless",
+		Role:      "listener",
+		Listen:    "0.0.0.0:8080",
+		KernelID:  "edge",
+		Status:    status.ProtocolBindingStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(&binding).Error)
+	binding.Node = node
+
+	logic := NewSnapshotLogic(ctx, svcCtx)
+	first, err := logic.Create(&types.AdminCreateNodeSnapshotRequest{NodeID: node.ID, Reason: "before maintenance"})
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Snapshot.Version)
+	require.Equal(t, 2, first.Snapshot.ProtocolCount)
+	require.Equal(t, 2, first.Snapshot.UserCount)
+	require.Equal(t, 3, first.Snapshot.Export.PipelineRules)
+	require.Len(t, fake.exports, 1)
+	require.True(t, fake.exports[0].RedactUsers)
+	require.Equal(t, fmt.Sprintf("snapshots/node-%d.yaml", node.ID), fake.exports[0].Path)
+
+	// The sync hook skips an unchanged configuration.
+	sync := NewSyncLogic(ctx, svcCtx)
+	control, err := sync.resolveControlClient(binding)
+	require.NoError(t, err)
+	sync.snapshotBeforeSync(control, binding, SyncModeFull)
+	_, total, err := repos.NodeConfigSnapshot.List(ctx, node.ID, repository.NodeConfigSnapshotListOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+
+	fake.setUsers("edge", "2", "3")
+	sync.snapshotBeforeSync(control, binding, SyncModeFull)
+	list, err := logic.List(&types.AdminListNodeSnapshotsRequest{NodeID: node.ID})
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 2)
+	second := list.Snapshots[0]
+	require.Equal(t, 2, second.Version)
+	require.Equal(t, repository.NodeSnapshotTriggerSync, second.Trigger)
+
+	diff, err := logic.Diff(&types.AdminNodeSnapshotDiffRequest{NodeID: node.ID, From: first.Snapshot.ID, To: second.ID})
+	require.NoError(t, err)
+	require.False(t, diff.Identical)
+	require.Len(t, diff.ChangedProtocols, 1)
+	require.Equal(t, []string{"3"}, diff.ChangedProtocols[0].UsersAdded)
+	require.Equal(t, []string{"1"}, diff.ChangedProtocols[0].UsersRemoved)
+
+	// Against the intended config: legacy has no binding and edge listens elsewhere.
+	diff, err = logic.Diff(&types.AdminNodeSnapshotDiffRequest{NodeID: node.ID, From: second.ID})
+	require.NoError(t, err)
+	require.Equal(t, "intended", diff.To.Kind)
+	require.Len(t, diff.RemovedProtocols, 1)
+	require.Equal(t, "legacy", diff.RemovedProtocols[0].KernelID)
+	require.Len(t, diff.ChangedProtocols, 1)
+	require.Equal(t, []types.NodeSnapshotFieldChange{{Field: "listen", From: "0.0.0.0:443", To: "0.0.0.0:8080"}}, diff.ChangedProtocols[0].Fields)
+	require.Equal(t, []string{"2", "3"}, diff.ChangedProtocols[0].UsersRemoved)
+
+	// Incremental syncs capture only when they are about to write.
+	fake.setUsers("edge", "3")
+	req := kernel.ProtocolUpsertRequest{
+		Listen:  "0.0.0.0:443",
+		Users:   []kernel.User{{ID: "3"}},
+		Profile: kernel.NodeProfile{ID: "edge", Role: "listener", Protocol: "vless"},
+	}
+	binding.AppliedSpec = protocolSpecFingerprint(req)
+	_, err = sync.syncUsersIncremental(binding, control, req)
+	require.NoError(t, err)
+	_, total, err = repos.NodeConfigSnapshot.List(ctx, node.ID, repository.NodeConfigSnapshotListOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+
+	req.Users = append(req.Users, kernel.User{ID: "4"})
+	_, err = sync.syncUsersIncremental(binding, control, req)
+	require.NoError(t, err)
+	list, err = logic.List(&types.AdminListNodeSnapshotsRequest{NodeID: node.ID})
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 3)
+	require.Equal(t, repository.NodeSnapshotTriggerSync, list.Snapshots[0].Trigger)
+	require.Contains(t, list.Snapshots[0].Reason, "incremental")
+	require.Equal(t, 1, list.Snapshots[0].UserCount, "captured before user 4 was created")
+	require.Contains(t, fake.registry, "4")
+
+	// Captures carry the profile last pushed; the diff shows later edits.
+	applied := map[string]any{"flow": "xtls-rprx-vision"}
+	_, err = repos.ProtocolBinding.UpdateSyncState(ctx, binding.ID, repository.UpdateProtocolBindingInput{AppliedProfile: &applied})
+	require.NoError(t, err)
+	edited := map[string]any{"flow": "none"}
+	_, err = repos.ProtocolBinding.Update(ctx, binding.ID, repository.UpdateProtocolBindingInput{Profile: &edited})
+	require.NoError(t, err)
+	third, err := logic.Create(&types.AdminCreateNodeSnapshotRequest{NodeID: node.ID})
+	require.NoError(t, err)
+	require.Equal(t, "xtls-rprx-vision", third.Protocols[0].Profile["flow"])
+	require.Len(t, fake.exports, 4)
+	for _, export := range fake.exports {
+		require.Equal(t, fake.exports[0].Path, export.Path)
+	}
+
+	diff, err = logic.Diff(&types.AdminNodeSnapshotDiffRequest{NodeID: node.ID, From: third.Snapshot.ID})
+	require.NoError(t, err)
+	require.Len(t, diff.ChangedProtocols, 1)
+	require.Contains(t, diff.ChangedProtocols[0].Fields, types.NodeSnapshotFieldChange{
+		Field: "profile",
+		From:  `{"flow":"xtls-rprx-vision"}`,
+		To:    `{"flow":"none"}`,
+	})
+
+	_, err = logic.Get(&types.AdminGetNodeSnapshotRequest{NodeID: node.ID + 1, SnapshotID: second.ID})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
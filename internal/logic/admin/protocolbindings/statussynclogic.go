@@ -101,11 +101,11 @@ func (l *StatusSyncLogic) Sync(req *types.AdminSyncProtocolBindingStatusRequest)
 	}
 
 	for key, nodeGroup := range controlGroups {
-		client, err := kernel.NewControlClient(kernel.HTTPOptions{
+		client, err := kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 			BaseURL: key.endpoint,
 			Token:   key.token,
 			Timeout: key.timeout,
-		})
+		}))
 		if err != nil {
 			l.markResults(nodeGroup, status.SyncResultStatusError, err.Error(), nil, results, indexByID)
 			continue
@@ -117,44 +117,11 @@ func (l *StatusSyncLogic) Sync(req *types.AdminSyncProtocolBindingStatusRequest)
 			continue
 		}
 
-		healthByKernel := make(map[string]int)
-		for _, node := range snapshot.Snapshot.Nodes {
-			kernelID := strings.TrimSpace(node.ID)
-			if kernelID == "" {
-				continue
-			}
-			healthByKernel[kernelID] = mapKernelHealthStatus(node.Health.Status)
-		}
-
-		observedAt := time.Now().UTC()
-		updatedCounts := make(map[uint64]int)
-		var updateErr error
+		var groupBindings []repository.ProtocolBinding
 		for _, nodeID := range nodeGroup {
-			for _, binding := range bindingsByNode[nodeID] {
-				if binding.KernelID == "" || binding.Status != status.ProtocolBindingStatusActive {
-					continue
-				}
-				health, ok := healthByKernel[binding.KernelID]
-				if !ok {
-					health = status.ProtocolBindingHealthStatusOffline
-				}
-				_, err := l.svcCtx.Repositories.ProtocolBinding.UpdateHealthByKernelIDForNodes(
-					l.ctx,
-					binding.KernelID,
-					[]uint64{nodeID},
-					health,
-					observedAt,
-					"",
-				)
-				if err != nil {
-					if !errors.Is(err, repository.ErrNotFound) {
-						updateErr = err
-					}
-					continue
-				}
-				updatedCounts[nodeID]++
-			}
+			groupBindings = append(groupBindings, bindingsByNode[nodeID]...)
 		}
+		updatedCounts, updateErr := ApplyKernelHealth(l.ctx, l.svcCtx.Repositories, groupBindings, snapshot.Snapshot, false, time.Now().UTC())
 
 		statusValue := status.SyncResultStatusSynced
 		message := "ok"
@@ -174,6 +141,52 @@ func (l *StatusSyncLogic) Sync(req *types.AdminSyncProtocolBindingStatusRequest)
 	return &types.AdminSyncProtocolBindingStatusResponse{Results: results}, nil
 }
 
+// ApplyKernelHealth writes the health reported in a kernel runtime snapshot to
+// the given bindings and returns the number of updated bindings per node.
+// Active bindings missing from a full snapshot are marked offline; a partial
+// (diff) snapshot only touches the bindings it mentions.
+func ApplyKernelHealth(ctx context.Context, repos *repository.Repositories, bindings []repository.ProtocolBinding, snapshot kernel.RuntimeStatusSnapshot, partial bool, observedAt time.Time) (map[uint64]int, error) {
+	healthByKernel := make(map[string]int)
+	for _, node := range snapshot.Nodes {
+		kernelID := strings.TrimSpace(node.ID)
+		if kernelID == "" {
+			continue
+		}
+		healthByKernel[kernelID] = mapKernelHealthStatus(node.Health.Status)
+	}
+
+	updatedCounts := make(map[uint64]int)
+	var updateErr error
+	for _, binding := range bindings {
+		if binding.KernelID == "" || binding.Status != status.ProtocolBindingStatusActive {
+			continue
+		}
+		health, ok := healthByKernel[binding.KernelID]
+		if !ok {
+			if partial {
+				continue
+			}
+			health = status.ProtocolBindingHealthStatusOffline
+		}
+		_, err := repos.ProtocolBinding.UpdateHealthByKernelIDForNodes(
+			ctx,
+			binding.KernelID,
+			[]uint64{binding.NodeID},
+			health,
+			observedAt,
+			"",
+		)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				updateErr = err
+			}
+			continue
+		}
+		updatedCounts[binding.NodeID]++
+	}
+	return updatedCounts, updateErr
+}
+
 func (l *StatusSyncLogic) markResults(nodeIDs []uint64, statusCode int, message string, updated map[uint64]int, results []types.ProtocolBindingStatusSyncResult, indexByID map[uint64]int) {
 	if len(nodeIDs) == 0 {
 		return
@@ -0,0 +1,385 @@
+package protocolbindings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+var syncJobFinishedStatuses = []string{
+	repository.SyncJobStatusSucceeded,
+	repository.SyncJobStatusPartial,
+	repository.SyncJobStatusFailed,
+	repository.SyncJobStatusCancelled,
+}
+
+// SyncJobLogic queues binding syncs as database-backed jobs. Jobs are processed
+// by the background sync job worker, so they survive panel restarts and report
+// per-binding results as they finish.
+type SyncJobLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSyncJobLogic constructs SyncJobLogic.
+func NewSyncJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SyncJobLogic {
+	return &SyncJobLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Submit queues a sync job for the requested bindings.
+func (l *SyncJobLogic) Submit(req *types.AdminSyncProtocolBindingsRequest) (*types.AdminSyncJobResponse, error) {
+	mode, err := normalizeSyncMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := NewSyncLogic(l.ctx, l.svcCtx).resolveBindings(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(bindings) == 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].ID < bindings[j].ID })
+
+	items := make([]repository.SyncJobItem, 0, len(bindings))
+	for _, binding := range bindings {
+		items = append(items, repository.SyncJobItem{BindingID: binding.ID, NodeID: binding.NodeID})
+	}
+
+	actor, ok := security.UserFromContext(l.ctx)
+	var actorID *uint64
+	if ok && actor.ID != 0 {
+		actorID = &actor.ID
+	}
+	job, err := l.svcCtx.Repositories.SyncJob.Create(l.ctx, repository.SyncJob{Mode: mode, ActorID: actorID}, items)
+	if err != nil {
+		return nil, err
+	}
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.sync_job.create", "sync_job", fmt.Sprintf("%d", job.ID), map[string]any{
+		"mode":  mode,
+		"total": job.Total,
+	}); err != nil {
+		return nil, err
+	}
+	return l.respond(job.ID)
+}
+
+// Get returns a job with the results of its finished bindings.
+func (l *SyncJobLogic) Get(req *types.AdminSyncJobRequest) (*types.AdminSyncJobResponse, error) {
+	return l.respond(req.JobID)
+}
+
+// Cancel stops a queued or running job. Bindings already being synced finish;
+// the rest are marked cancelled.
+func (l *SyncJobLogic) Cancel(req *types.AdminSyncJobRequest) (*types.AdminSyncJobResponse, error) {
+	job, err := l.svcCtx.Repositories.SyncJob.Get(l.ctx, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := l.svcCtx.Repositories.SyncJob.UpdateStatus(l.ctx, job.ID,
+		[]string{repository.SyncJobStatusQueued, repository.SyncJobStatusRunning},
+		repository.SyncJobStatusCancelled, "cancelled")
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: sync job already finished", repository.ErrInvalidState)
+	}
+	if _, err := l.svcCtx.Repositories.SyncJob.UpdateItemsStatus(l.ctx, job.ID,
+		[]string{repository.SyncJobItemStatusPending}, repository.SyncJobItemStatusCancelled); err != nil {
+		return nil, err
+	}
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.sync_job.cancel", "sync_job", fmt.Sprintf("%d", job.ID), nil); err != nil {
+		return nil, err
+	}
+	return l.respond(job.ID)
+}
+
+// RetryFailed re-queues the failed and skipped bindings of a finished job.
+func (l *SyncJobLogic) RetryFailed(req *types.AdminSyncJobRequest) (*types.AdminSyncJobResponse, error) {
+	job, err := l.svcCtx.Repositories.SyncJob.Get(l.ctx, req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if !isSyncJobFinished(job.Status) {
+		return nil, fmt.Errorf("%w: sync job still active", repository.ErrInvalidState)
+	}
+	retried, err := l.svcCtx.Repositories.SyncJob.UpdateItemsStatus(l.ctx, job.ID,
+		[]string{repository.SyncJobItemStatusError, repository.SyncJobItemStatusSkipped},
+		repository.SyncJobItemStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if retried == 0 {
+		return nil, fmt.Errorf("%w: no failed bindings to retry", repository.ErrInvalidState)
+	}
+	if _, err := l.svcCtx.Repositories.SyncJob.UpdateStatus(l.ctx, job.ID, syncJobFinishedStatuses, repository.SyncJobStatusQueued, ""); err != nil {
+		return nil, err
+	}
+	if err := auditutil.Record(l.ctx, l.svcCtx.Repositories, "admin.sync_job.retry_failed", "sync_job", fmt.Sprintf("%d", job.ID), map[string]any{"retried": retried}); err != nil {
+		return nil, err
+	}
+	return l.respond(job.ID)
+}
+
+// Process syncs the pending bindings of a queued or running job. Nodes are
+// handled in parallel, at most SyncJobs.Concurrency at once, while the bindings
+// of one node are pushed one after another. If the context ends first the job
+// stays running and its unfinished bindings are picked up again later.
+func (l *SyncJobLogic) Process(jobID uint64) error {
+	job, err := l.svcCtx.Repositories.SyncJob.Get(l.ctx, jobID)
+	if err != nil {
+		return err
+	}
+	switch job.Status {
+	case repository.SyncJobStatusQueued:
+		started, err := l.svcCtx.Repositories.SyncJob.UpdateStatus(l.ctx, job.ID, []string{repository.SyncJobStatusQueued}, repository.SyncJobStatusRunning, "")
+		if err != nil || !started {
+			return err
+		}
+	case repository.SyncJobStatusRunning:
+	default:
+		return nil
+	}
+
+	items, err := l.svcCtx.Repositories.SyncJob.ListItems(l.ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	byNode := make(map[uint64][]repository.SyncJobItem)
+	nodeOrder := make([]uint64, 0)
+	for _, item := range items {
+		if item.Status != repository.SyncJobItemStatusPending {
+			continue
+		}
+		if _, ok := byNode[item.NodeID]; !ok {
+			nodeOrder = append(nodeOrder, item.NodeID)
+		}
+		byNode[item.NodeID] = append(byNode[item.NodeID], item)
+	}
+
+	cfg := l.svcCtx.Config.SyncJobs
+	cfg.Normalize()
+	slots := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for _, nodeID := range nodeOrder {
+		queue := byNode[nodeID]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// SyncLogic caches event registrations and is not safe for concurrent use.
+			syncLogic := NewSyncLogic(l.ctx, l.svcCtx)
+			for _, item := range queue {
+				if l.ctx.Err() != nil {
+					return
+				}
+				slots <- struct{}{}
+				l.processItem(syncLogic, job.Mode, item)
+				<-slots
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := l.ctx.Err(); err != nil {
+		return err
+	}
+	return l.finish(job.ID)
+}
+
+// processItem syncs one binding unless the item was cancelled or claimed elsewhere.
+func (l *SyncJobLogic) processItem(syncLogic *SyncLogic, mode string, item repository.SyncJobItem) {
+	claimed, err := l.svcCtx.Repositories.SyncJob.ClaimItem(l.ctx, item.ID)
+	if err != nil {
+		l.Errorf("sync job claim failed job_id=%d binding_id=%d: %v", item.JobID, item.BindingID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	var result types.ProtocolBindingSyncResult
+	binding, err := l.svcCtx.Repositories.ProtocolBinding.Get(l.ctx, item.BindingID)
+	if err != nil {
+		result = types.ProtocolBindingSyncResult{
+			BindingID: item.BindingID,
+			Status:    status.SyncResultStatusError,
+			Message:   err.Error(),
+			Mode:      mode,
+			SyncedAt:  time.Now().UTC().Unix(),
+		}
+	} else {
+		result = syncLogic.syncBinding(binding, mode)
+	}
+
+	// The result must be stored even when the worker is shutting down.
+	saveCtx := context.WithoutCancel(l.ctx)
+	if l.ctx.Err() != nil {
+		// Interrupted by shutdown: queue the binding again instead of failing it.
+		item.Status = repository.SyncJobItemStatusPending
+		if err := l.svcCtx.Repositories.SyncJob.SaveItemResult(saveCtx, item); err != nil {
+			l.Errorf("sync job requeue failed job_id=%d binding_id=%d: %v", item.JobID, item.BindingID, err)
+		}
+		return
+	}
+
+	syncedAt := time.Unix(result.SyncedAt, 0).UTC()
+	item.Status = syncJobItemStatus(result.Status)
+	item.ResultStatus = result.Status
+	item.Mode = result.Mode
+	item.Message = result.Message
+	item.UsersAdded = result.UsersAdded
+	item.UsersUpdated = result.UsersUpdated
+	item.UsersRemoved = result.UsersRemoved
+	item.SyncedAt = &syncedAt
+	if err := l.svcCtx.Repositories.SyncJob.SaveItemResult(saveCtx, item); err != nil {
+		l.Errorf("sync job save result failed job_id=%d binding_id=%d: %v", item.JobID, item.BindingID, err)
+	}
+}
+
+// finish settles a running job once none of its bindings are left to sync.
+// A job cancelled in the meantime keeps its cancelled state.
+func (l *SyncJobLogic) finish(jobID uint64) error {
+	items, err := l.svcCtx.Repositories.SyncJob.ListItems(l.ctx, jobID)
+	if err != nil {
+		return err
+	}
+	counts := countSyncJobItems(items)
+	if counts.pending > 0 || counts.running > 0 {
+		return nil
+	}
+
+	final := repository.SyncJobStatusSucceeded
+	message := ""
+	if unsynced := counts.failed + counts.skipped + counts.cancelled; unsynced > 0 {
+		final = repository.SyncJobStatusPartial
+		if counts.synced == 0 {
+			final = repository.SyncJobStatusFailed
+		}
+		message = fmt.Sprintf("%d of %d bindings not synced", unsynced, len(items))
+	}
+	_, err = l.svcCtx.Repositories.SyncJob.UpdateStatus(l.ctx, jobID, []string{repository.SyncJobStatusRunning}, final, message)
+	return err
+}
+
+func (l *SyncJobLogic) respond(jobID uint64) (*types.AdminSyncJobResponse, error) {
+	job, err := l.svcCtx.Repositories.SyncJob.Get(l.ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := l.svcCtx.Repositories.SyncJob.ListItems(l.ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AdminSyncJobResponse{Job: mapSyncJobSummary(job, items)}, nil
+}
+
+type syncJobItemCounts struct {
+	pending, running, synced, failed, skipped, cancelled int
+}
+
+func countSyncJobItems(items []repository.SyncJobItem) syncJobItemCounts {
+	var counts syncJobItemCounts
+	for _, item := range items {
+		switch item.Status {
+		case repository.SyncJobItemStatusPending:
+			counts.pending++
+		case repository.SyncJobItemStatusRunning:
+			counts.running++
+		case repository.SyncJobItemStatusSynced:
+			counts.synced++
+		case repository.SyncJobItemStatusError:
+			counts.failed++
+		case repository.SyncJobItemStatusSkipped:
+			counts.skipped++
+		case repository.SyncJobItemStatusCancelled:
+			counts.cancelled++
+		}
+	}
+	return counts
+}
+
+func syncJobItemStatus(resultStatus int) string {
+	switch resultStatus {
+	case status.SyncResultStatusSynced:
+		return repository.SyncJobItemStatusSynced
+	case status.SyncResultStatusSkipped:
+		return repository.SyncJobItemStatusSkipped
+	default:
+		return repository.SyncJobItemStatusError
+	}
+}
+
+func isSyncJobFinished(jobStatus string) bool {
+	for _, finished := range syncJobFinishedStatuses {
+		if jobStatus == finished {
+			return true
+		}
+	}
+	return false
+}
+
+func mapSyncJobSummary(job repository.SyncJob, items []repository.SyncJobItem) types.SyncJobSummary {
+	counts := countSyncJobItems(items)
+	results := make([]types.ProtocolBindingSyncResult, 0, len(items))
+	for _, item := range items {
+		if item.SyncedAt == nil || item.Status == repository.SyncJobItemStatusPending || item.Status == repository.SyncJobItemStatusRunning {
+			continue
+		}
+		results = append(results, types.ProtocolBindingSyncResult{
+			BindingID:    item.BindingID,
+			Status:       item.ResultStatus,
+			Message:      item.Message,
+			Mode:         item.Mode,
+			UsersAdded:   item.UsersAdded,
+			UsersUpdated: item.UsersUpdated,
+			UsersRemoved: item.UsersRemoved,
+			SyncedAt:     item.SyncedAt.Unix(),
+		})
+	}
+	return types.SyncJobSummary{
+		ID:         job.ID,
+		Status:     job.Status,
+		Mode:       job.Mode,
+		Total:      job.Total,
+		Pending:    counts.pending,
+		Running:    counts.running,
+		Synced:     counts.synced,
+		Failed:     counts.failed,
+		Skipped:    counts.skipped,
+		Cancelled:  counts.cancelled,
+		Message:    job.Message,
+		ActorID:    job.ActorID,
+		CreatedAt:  toUnixOrZero(job.CreatedAt),
+		StartedAt:  toUnixOrZeroPtr(job.StartedAt),
+		FinishedAt: toUnixOrZeroPtr(job.FinishedAt),
+		Results:    results,
+	}
+}
+
+// ResetInterruptedSyncJobItems returns bindings left running by a stopped
+// panel to the queue; it is called once before the worker starts.
+func ResetInterruptedSyncJobItems(ctx context.Context, svcCtx *svc.ServiceContext) (int64, error) {
+	if svcCtx == nil || svcCtx.Repositories == nil {
+		return 0, errors.New("sync jobs: repositories not configured")
+	}
+	return svcCtx.Repositories.SyncJob.UpdateItemsStatus(ctx, 0,
+		[]string{repository.SyncJobItemStatusRunning}, repository.SyncJobItemStatusPending)
+}
@@ -0,0 +1,116 @@
+package protocolbindings
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestSyncJobs(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	good := newFakeKernel(t)
+	flaky := newFakeKernel(t)
+	flaky.setDown(true)
+
+	now := time.Now().UTC()
+	nodeIDs := make([]uint64, 0, 2)
+	for i, endpoint := range []string{good.URL, flaky.URL} {
+		node := repository.Node{Name: fmt.Sprintf("node-%d", i), ControlEndpoint: endpoint, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(&node).Error)
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	for i, nodeID := range []uint64{nodeIDs[0], nodeIDs[0], nodeIDs[1]} {
+		binding := repository.ProtocolBinding{
+			Name:      fmt.Sprintf("binding-%d", i),
+			NodeID:    nodeID,
+			Protocol:  "vless",
+			Role:      "listener",
+			Listen:    "0.0.0.0:443",
+			KernelID:  fmt.Sprintf("edge-%d", i),
+			Status:    status.ProtocolBindingStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&binding).Error)
+	}
+
+	logic := NewSyncJobLogic(ctx, svcCtx)
+	submitted, err := logic.Submit(&types.AdminSyncProtocolBindingsRequest{NodeIDs: nodeIDs})
+	require.NoError(t, err)
+	job := submitted.Job
+	require.Equal(t, repository.SyncJobStatusQueued, job.Status)
+	require.Equal(t, 3, job.Total)
+	require.Equal(t, 3, job.Pending)
+	require.Empty(t, job.Results)
+
+	require.NoError(t, logic.Process(job.ID))
+	resp, err := logic.Get(&types.AdminSyncJobRequest{JobID: job.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.SyncJobStatusPartial, resp.Job.Status)
+	require.Equal(t, 2, resp.Job.Synced)
+	require.Equal(t, 1, resp.Job.Failed)
+	require.Len(t, resp.Job.Results, 3)
+	require.NotZero(t, resp.Job.FinishedAt)
+	require.Equal(t, 2, good.upsertCount())
+
+	_, err = logic.Cancel(&types.AdminSyncJobRequest{JobID: job.ID})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	// Only the failed binding runs again.
+	flaky.setDown(false)
+	resp, err = logic.RetryFailed(&types.AdminSyncJobRequest{JobID: job.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.SyncJobStatusQueued, resp.Job.Status)
+	require.Equal(t, 1, resp.Job.Pending)
+	require.Zero(t, resp.Job.FinishedAt)
+	require.NoError(t, logic.Process(job.ID))
+	resp, err = logic.Get(&types.AdminSyncJobRequest{JobID: job.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.SyncJobStatusSucceeded, resp.Job.Status)
+	require.Equal(t, 3, resp.Job.Synced)
+	require.Equal(t, 2, good.upsertCount())
+	require.Equal(t, 1, flaky.upsertCount())
+	_, err = logic.RetryFailed(&types.AdminSyncJobRequest{JobID: job.ID})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	// A cancelled job leaves its bindings untouched.
+	submitted, err = logic.Submit(&types.AdminSyncProtocolBindingsRequest{NodeIDs: nodeIDs})
+	require.NoError(t, err)
+	resp, err = logic.Cancel(&types.AdminSyncJobRequest{JobID: submitted.Job.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.SyncJobStatusCancelled, resp.Job.Status)
+	require.Equal(t, 3, resp.Job.Cancelled)
+	require.NoError(t, logic.Process(submitted.Job.ID))
+	require.Equal(t, 2, good.upsertCount())
+
+	// A binding left running by a stopped panel is synced after recovery.
+	submitted, err = logic.Submit(&types.AdminSyncProtocolBindingsRequest{NodeIDs: nodeIDs[1:]})
+	require.NoError(t, err)
+	_, err = repos.SyncJob.UpdateStatus(ctx, submitted.Job.ID, []string{repository.SyncJobStatusQueued}, repository.SyncJobStatusRunning, "")
+	require.NoError(t, err)
+	items, err := repos.SyncJob.ListItems(ctx, submitted.Job.ID)
+	require.NoError(t, err)
+	claimed, err := repos.SyncJob.ClaimItem(ctx, items[0].ID)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	reset, err := ResetInterruptedSyncJobItems(ctx, svcCtx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, reset)
+	require.NoError(t, logic.Process(submitted.Job.ID))
+	resp, err = logic.Get(&types.AdminSyncJobRequest{JobID: submitted.Job.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.SyncJobStatusSucceeded, resp.Job.Status)
+	require.Equal(t, 2, flaky.upsertCount())
+}
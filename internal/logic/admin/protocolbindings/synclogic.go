@@ -3,6 +3,7 @@ package protocolbindings
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/zero-net-panel/zero-net-panel/internal/logic/credentialutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -54,12 +56,21 @@ func (l *SyncLogic) SyncSingle(req *types.AdminSyncProtocolBindingRequest) (*typ
 		return nil, err
 	}
 
-	result := l.syncBinding(binding)
+	mode, err := normalizeSyncMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	result := l.syncBinding(binding, mode)
 	return &result, nil
 }
 
 // SyncBatch triggers sync for multiple bindings.
 func (l *SyncLogic) SyncBatch(req *types.AdminSyncProtocolBindingsRequest) (*types.AdminSyncProtocolBindingsResponse, error) {
+	mode, err := normalizeSyncMode(req.Mode)
+	if err != nil {
+		return nil, err
+	}
 	bindings, err := l.resolveBindings(req)
 	if err != nil {
 		return nil, err
@@ -70,7 +81,7 @@ func (l *SyncLogic) SyncBatch(req *types.AdminSyncProtocolBindingsRequest) (*typ
 
 	results := make([]types.ProtocolBindingSyncResult, 0, len(bindings))
 	for _, binding := range bindings {
-		results = append(results, l.syncBinding(binding))
+		results = append(results, l.syncBinding(binding, mode))
 	}
 
 	return &types.AdminSyncProtocolBindingsResponse{Results: results}, nil
@@ -115,20 +126,28 @@ func (l *SyncLogic) resolveBindings(req *types.AdminSyncProtocolBindingsRequest)
 	return results, nil
 }
 
-func (l *SyncLogic) syncBinding(binding repository.ProtocolBinding) types.ProtocolBindingSyncResult {
+func (l *SyncLogic) syncBinding(binding repository.ProtocolBinding, mode string) types.ProtocolBindingSyncResult {
 	result := types.ProtocolBindingSyncResult{
 		BindingID: binding.ID,
 		Status:    status.SyncResultStatusError,
+		Mode:      SyncModeFull,
 		SyncedAt:  time.Now().UTC().Unix(),
 	}
 
-	if err := l.ensureNodeEventRegistration(binding); err != nil {
-		l.Errorf("kernel event registration failed: %v", err)
-	}
-	if err := l.ensureServiceEventRegistration(binding); err != nil {
-		l.Errorf("kernel service event registration failed: %v", err)
+	// Pushes to one kernel never overlap, whichever worker or node issues them.
+	defer l.lockKernel(binding.Node)()
+
+	// An open breaker means the node failed repeatedly; skip it without waiting
+	// on timeouts until the breaker admits a trial call.
+	if l.svcCtx.KernelBreakers.State(binding.Node.ControlEndpoint) == kernel.CircuitOpen {
+		result.Status = status.SyncResultStatusSkipped
+		result.Message = kernel.ErrCircuitOpen.Error()
+		l.markNodeDegraded(binding, kernel.ErrCircuitOpen)
+		return result
 	}
 
+	l.ensureEventRegistrations(binding)
+
 	control, err := l.resolveControlClient(binding)
 	if err != nil {
 		result.Message = err.Error()
@@ -136,24 +155,21 @@ func (l *SyncLogic) syncBinding(binding repository.ProtocolBinding) types.Protoc
 		return result
 	}
 
-	profile := kernel.NodeProfile{
-		ID:          binding.KernelID,
-		Role:        binding.Role,
-		Protocol:    normalizeBindingProtocol(binding),
-		Tags:        mergeTags(binding.Tags),
-		Description: strings.TrimSpace(binding.Description),
-		Profile:     cloneBindingProfile(binding.Profile),
-	}
-	if len(profile.Profile) == 0 {
-		profile.Profile = map[string]any{}
-	}
-
+	profile := buildKernelProfile(binding)
 	if profile.ID == "" {
 		result.Message = "kernel_id is required"
 		_, _ = l.updateSyncState(binding, status.ProtocolBindingSyncStatusError, result.Message)
 		return result
 	}
 
+	upstream, connect, err := l.resolveUpstream(binding)
+	if err != nil {
+		result.Message = err.Error()
+		_, _ = l.updateSyncState(binding, status.ProtocolBindingSyncStatusError, result.Message)
+		return result
+	}
+	profile.Upstream = upstream
+
 	users, err := l.buildKernelUsers(binding)
 	if err != nil {
 		result.Message = err.Error()
@@ -163,13 +179,43 @@ func (l *SyncLogic) syncBinding(binding repository.ProtocolBinding) types.Protoc
 
 	req := kernel.ProtocolUpsertRequest{
 		Listen:  normalizeListen(binding.Listen, binding.AccessPort),
-		Connect: binding.Connect,
+		Connect: connect,
 		Users:   users,
 		Profile: profile,
 	}
 
+	if mode == SyncModeIncremental && binding.SyncStatus == status.ProtocolBindingSyncStatusSynced {
+		stats, err := l.syncUsersIncremental(binding, control, req)
+		switch {
+		case err == nil:
+			result.Status = status.SyncResultStatusSynced
+			result.Message = "ok"
+			result.Mode = SyncModeIncremental
+			result.UsersAdded = stats.added
+			result.UsersUpdated = stats.updated
+			result.UsersRemoved = stats.removed
+			_, _ = l.updateSyncState(binding, status.ProtocolBindingSyncStatusSynced, "")
+			return result
+		case errors.Is(err, kernel.ErrNotFound), errors.Is(err, errSpecChanged):
+			// The protocol is missing on the kernel or changed beyond its
+			// users; fall through to a full upsert.
+		default:
+			if errors.Is(err, kernel.ErrCircuitOpen) {
+				l.markNodeDegraded(binding, err)
+			}
+			result.Message = err.Error()
+			result.Mode = SyncModeIncremental
+			_, _ = l.updateSyncState(binding, status.ProtocolBindingSyncStatusError, result.Message)
+			return result
+		}
+	}
+
+	l.snapshotBeforeSync(control, binding, SyncModeFull)
 	_, err = control.UpsertProtocol(l.ctx, req)
 	if err != nil {
+		if errors.Is(err, kernel.ErrCircuitOpen) {
+			l.markNodeDegraded(binding, err)
+		}
 		result.Message = err.Error()
 		_, _ = l.updateSyncState(binding, status.ProtocolBindingSyncStatusError, result.Message)
 		return result
@@ -177,10 +223,85 @@ func (l *SyncLogic) syncBinding(binding repository.ProtocolBinding) types.Protoc
 
 	result.Status = status.SyncResultStatusSynced
 	result.Message = "ok"
+	l.recordApplied(binding, req)
 	_, _ = l.updateSyncState(binding, status.ProtocolBindingSyncStatusSynced, "")
 	return result
 }
 
+// recordApplied keeps the profile the kernel now runs for the binding and a
+// fingerprint of the upsert's non-user fields. The kernel does not report
+// profiles back, so snapshots and incremental syncs read them from here.
+func (l *SyncLogic) recordApplied(binding repository.ProtocolBinding, req kernel.ProtocolUpsertRequest) {
+	applied := cloneBindingProfile(req.Profile.Profile)
+	if applied == nil {
+		applied = map[string]any{}
+	}
+	spec := protocolSpecFingerprint(req)
+	if _, err := l.svcCtx.Repositories.ProtocolBinding.UpdateSyncState(l.ctx, binding.ID, repository.UpdateProtocolBindingInput{
+		AppliedProfile: &applied,
+		AppliedSpec:    &spec,
+	}); err != nil {
+		l.Errorf("record applied profile failed binding_id=%d: %v", binding.ID, err)
+	}
+}
+
+// markNodeDegraded flags every binding of the node as degraded. The bindings'
+// sync errors are kept; the breaker clears the flag when it closes.
+func (l *SyncLogic) markNodeDegraded(binding repository.ProtocolBinding, reason error) {
+	l.Infof("node %d degraded: %v", binding.NodeID, reason)
+	if _, err := l.svcCtx.Repositories.ProtocolBinding.UpdateHealthByNodeIDs(l.ctx, []uint64{binding.NodeID}, status.ProtocolBindingHealthStatusDegraded); err != nil {
+		l.Errorf("mark node %d degraded failed: %v", binding.NodeID, err)
+	}
+}
+
+// ensureEventRegistrations registers the push callbacks of a binding's node.
+// Pull-mode nodes are consumed over /v1/events/stream and need no callbacks.
+func (l *SyncLogic) ensureEventRegistrations(binding repository.ProtocolBinding) {
+	if mode, _ := nodecfg.NormalizeKernelEventMode(binding.Node.KernelEventMode); mode == nodecfg.KernelEventModePull {
+		// Registrations are removed when the node switches to pull; a later
+		// switch back must register them again.
+		l.forgetEventRegistrations(binding.Node)
+		return
+	}
+	if err := l.ensureNodeEventRegistration(binding); err != nil {
+		l.Errorf("kernel event registration failed: %v", err)
+	}
+	if err := l.ensureServiceEventRegistration(binding); err != nil {
+		l.Errorf("kernel service event registration failed: %v", err)
+	}
+}
+
+// buildKernelProfile renders the kernel node profile of a binding.
+func buildKernelProfile(binding repository.ProtocolBinding) kernel.NodeProfile {
+	profile := kernel.NodeProfile{
+		ID:          binding.KernelID,
+		Role:        binding.Role,
+		Protocol:    normalizeBindingProtocol(binding),
+		Tags:        mergeTags(binding.Tags),
+		Description: strings.TrimSpace(binding.Description),
+		Profile:     cloneBindingProfile(binding.Profile),
+	}
+	if len(profile.Profile) == 0 {
+		profile.Profile = map[string]any{}
+	}
+	return profile
+}
+
+// removeKernelProtocol deletes a kernel protocol together with its attached
+// users under the kernel lock of node; a protocol that is already gone is not
+// an error.
+func (l *SyncLogic) removeKernelProtocol(node repository.Node, control *kernel.ControlClient, kernelID string) error {
+	kernelID = strings.TrimSpace(kernelID)
+	if kernelID == "" {
+		return repository.ErrInvalidArgument
+	}
+	defer l.lockKernel(node)()
+	if err := control.DeleteProtocol(l.ctx, kernelID); err != nil && !errors.Is(err, kernel.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
 type serviceEventKey struct {
 	endpoint string
 	token    string
@@ -208,11 +329,11 @@ func (l *SyncLogic) ensureNodeEventRegistration(binding repository.ProtocolBindi
 		return fmt.Errorf("node control endpoint not configured")
 	}
 	token := resolveControlToken(binding.Node)
-	control, err := kernel.NewControlClient(kernel.HTTPOptions{
+	control, err := kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 		BaseURL: endpoint,
 		Token:   token,
 		Timeout: resolveKernelHTTPTimeout(binding.Node),
-	})
+	}))
 	if err != nil {
 		return err
 	}
@@ -268,11 +389,11 @@ func (l *SyncLogic) ensureServiceEventRegistration(binding repository.ProtocolBi
 		return nil
 	}
 
-	control, err := kernel.NewControlClient(kernel.HTTPOptions{
+	control, err := kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 		BaseURL: endpoint,
 		Token:   token,
 		Timeout: resolveKernelHTTPTimeout(binding.Node),
-	})
+	}))
 	if err != nil {
 		return err
 	}
@@ -292,6 +413,94 @@ func (l *SyncLogic) ensureServiceEventRegistration(binding repository.ProtocolBi
 	return nil
 }
 
+// RemoveEventRegistrations deletes the push callbacks of the panel from the
+// kernel of node once the node consumes events over the stream. They are kept
+// while another push-mode node shares the kernel. Registrations of other
+// callback addresses are left alone.
+func (l *SyncLogic) RemoveEventRegistrations(node repository.Node) error {
+	if l.svcCtx == nil {
+		return repository.ErrInvalidState
+	}
+	endpoint := strings.TrimSpace(node.ControlEndpoint)
+	if endpoint == "" {
+		return nil
+	}
+	nodes, err := l.svcCtx.Repositories.Node.ListAll(l.ctx)
+	if err != nil {
+		return err
+	}
+	key := kernelKey(node)
+	for _, candidate := range nodes {
+		if candidate.ID == node.ID || candidate.Status == status.NodeStatusDisabled || kernelKey(candidate) != key {
+			continue
+		}
+		if mode, _ := nodecfg.NormalizeKernelEventMode(candidate.KernelEventMode); mode == nodecfg.KernelEventModePush {
+			return nil
+		}
+	}
+
+	nodeCallback, err := l.resolveNodeEventCallbackURL()
+	if err != nil {
+		return err
+	}
+	serviceCallback, err := l.resolveServiceEventCallbackURL()
+	if err != nil {
+		return err
+	}
+	control, err := kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
+		BaseURL: endpoint,
+		Token:   resolveControlToken(node),
+		Timeout: resolveKernelHTTPTimeout(node),
+	}))
+	if err != nil {
+		return err
+	}
+	l.forgetEventRegistrations(node)
+
+	registrations, err := control.ListEventRegistrations(l.ctx)
+	if err != nil {
+		return err
+	}
+	for _, record := range registrations {
+		if record.Callback != nodeCallback {
+			continue
+		}
+		if err := control.DeleteEventRegistration(l.ctx, record.ID); err != nil && !errors.Is(err, kernel.ErrNotFound) {
+			return err
+		}
+	}
+	subscriptions, err := control.ListServiceEventRegistrations(l.ctx)
+	if err != nil {
+		return err
+	}
+	for _, record := range subscriptions {
+		if record.Callback != serviceCallback {
+			continue
+		}
+		if err := control.DeleteServiceEventRegistration(l.ctx, record.ID); err != nil && !errors.Is(err, kernel.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// forgetEventRegistrations drops the cached registrations of the kernel of
+// node.
+func (l *SyncLogic) forgetEventRegistrations(node repository.Node) {
+	endpoint := strings.TrimSpace(node.ControlEndpoint)
+	token := resolveControlToken(node)
+	for key := range l.registeredNodeEvents {
+		if key.endpoint == endpoint && key.token == token {
+			delete(l.registeredNodeEvents, key)
+		}
+	}
+	for key := range l.registeredServiceEvents {
+		if key.endpoint == endpoint && key.token == token {
+			delete(l.registeredServiceEvents, key)
+		}
+	}
+}
+
 func (l *SyncLogic) resolveNodeEventCallbackURL() (string, error) {
 	if l.nodeEventCallback != "" {
 		return l.nodeEventCallback, nil
@@ -380,7 +589,21 @@ func buildCallbackFromBase(raw string, port int, path string) (string, error) {
 	return base.String(), nil
 }
 
+// buildKernelUsers returns the subscribers of a binding plus the relay users
+// of bindings that forward through it.
 func (l *SyncLogic) buildKernelUsers(binding repository.ProtocolBinding) ([]kernel.User, error) {
+	users, err := l.buildSubscriptionUsers(binding)
+	if err != nil {
+		return nil, err
+	}
+	relays, err := l.buildRelayUsers(binding)
+	if err != nil {
+		return nil, err
+	}
+	return append(users, relays...), nil
+}
+
+func (l *SyncLogic) buildSubscriptionUsers(binding repository.ProtocolBinding) ([]kernel.User, error) {
 	planIDs, err := l.svcCtx.Repositories.PlanProtocolBinding.ListPlanIDsByBindingID(l.ctx, binding.ID)
 	if err != nil {
 		return nil, err
@@ -395,13 +618,29 @@ func (l *SyncLogic) buildKernelUsers(binding repository.ProtocolBinding) ([]kern
 	}
 
 	now := time.Now().UTC()
-	users := make([]kernel.User, 0, len(subs))
-	seen := make(map[uint64]struct{}, len(subs))
-
+	effective := make([]repository.Subscription, 0, len(subs))
+	userIDs := make([]uint64, 0, len(subs))
 	for _, sub := range subs {
 		if !subscriptionutil.IsSubscriptionEffective(sub, now) {
 			continue
 		}
+		effective = append(effective, sub)
+		userIDs = append(userIDs, sub.UserID)
+	}
+	rates, penalties, err := l.resolveUserRates(userIDs, now)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]kernel.User, 0, len(effective))
+	seen := make(map[uint64]struct{}, len(effective))
+
+	for _, sub := range effective {
+		// A suspended subscription must not shadow another subscription of the
+		// same user on this binding, so the penalty is checked first.
+		if penalty, ok := penalties[sub.ID]; ok && penalty.Action == repository.DevicePolicySuspend {
+			continue
+		}
 		if _, ok := seen[sub.UserID]; ok {
 			continue
 		}
@@ -418,12 +657,15 @@ func (l *SyncLogic) buildKernelUsers(binding repository.ProtocolBinding) ([]kern
 			continue
 		}
 
+		rate := rates[sub.UserID]
 		users = append(users, kernel.User{
 			ID:       strconv.FormatUint(sub.UserID, 10),
 			Username: strings.TrimSpace(identity.Username),
 			Password: strings.TrimSpace(identity.Password),
+			Rate:     kernelUserRate(rate.upload, rate.download),
 			Metadata: map[string]any{
-				"subscription_id": sub.ID,
+				"subscription_id":       strconv.FormatUint(sub.ID, 10),
+				kernelUserCredentialKey: credential.Fingerprint,
 			},
 		})
 	}
@@ -431,6 +673,127 @@ func (l *SyncLogic) buildKernelUsers(binding repository.ProtocolBinding) ([]kern
 	return users, nil
 }
 
+// userRate is the bandwidth of a kernel user in bytes per second; zero is unlimited.
+type userRate struct {
+	upload   int64
+	download int64
+}
+
+// resolveUserRates computes one rate per user. The kernel keeps a single user
+// object per node, so the rate must not depend on which binding is synced:
+// it is the most generous plan across every effective subscription of the
+// user, replaced by an admin override and capped by any device throttle.
+// The device penalties of those subscriptions are returned alongside.
+func (l *SyncLogic) resolveUserRates(userIDs []uint64, now time.Time) (map[uint64]userRate, map[uint64]repository.DeviceLimitViolation, error) {
+	subs, err := l.svcCtx.Repositories.Subscription.ListActiveByUserIDs(l.ctx, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	effective := make([]repository.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		if subscriptionutil.IsSubscriptionEffective(sub, now) {
+			effective = append(effective, sub)
+		}
+	}
+
+	overrides, err := l.loadRateOverrides(effective, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	penalties, err := l.loadDevicePenalties(effective, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rates := make(map[uint64]userRate, len(userIDs))
+	resolved := make(map[uint64]bool, len(userIDs))
+	for _, sub := range effective {
+		upload, download := subscriptionutil.ExtractRateLimits(sub.PlanSnapshot)
+		if !resolved[sub.UserID] {
+			rates[sub.UserID] = userRate{upload: upload, download: download}
+			resolved[sub.UserID] = true
+			continue
+		}
+		rate := rates[sub.UserID]
+		rates[sub.UserID] = userRate{
+			upload:   maxRate(rate.upload, upload),
+			download: maxRate(rate.download, download),
+		}
+	}
+
+	for userID, override := range overrides {
+		rates[userID] = userRate{upload: override.UploadRateBytes, download: override.DownloadRateBytes}
+	}
+	for _, sub := range effective {
+		penalty, ok := penalties[sub.ID]
+		if !ok || penalty.Action != repository.DevicePolicyThrottle {
+			continue
+		}
+		rate := rates[sub.UserID]
+		rates[sub.UserID] = userRate{
+			upload:   capRate(rate.upload, penalty.ThrottleRateBytes),
+			download: capRate(rate.download, penalty.ThrottleRateBytes),
+		}
+	}
+	return rates, penalties, nil
+}
+
+// loadRateOverrides returns the active admin speed overrides keyed by user.
+func (l *SyncLogic) loadRateOverrides(subs []repository.Subscription, now time.Time) (map[uint64]repository.UserRateLimit, error) {
+	userIDs := make([]uint64, 0, len(subs))
+	for _, sub := range subs {
+		userIDs = append(userIDs, sub.UserID)
+	}
+	limits, err := l.svcCtx.Repositories.UserRateLimit.ListActiveByUserIDs(l.ctx, userIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint64]repository.UserRateLimit, len(limits))
+	for _, limit := range limits {
+		result[limit.UserID] = limit
+	}
+	return result, nil
+}
+
+// loadDevicePenalties returns the running device limit penalties keyed by subscription.
+func (l *SyncLogic) loadDevicePenalties(subs []repository.Subscription, now time.Time) (map[uint64]repository.DeviceLimitViolation, error) {
+	subIDs := make([]uint64, 0, len(subs))
+	for _, sub := range subs {
+		subIDs = append(subIDs, sub.ID)
+	}
+	violations, err := l.svcCtx.Repositories.Device.ListPenalizedBySubscriptionIDs(l.ctx, subIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint64]repository.DeviceLimitViolation, len(violations))
+	for _, violation := range violations {
+		result[violation.SubscriptionID] = violation
+	}
+	return result, nil
+}
+
+// maxRate returns the more generous of two rates; zero means unlimited.
+func maxRate(a, b int64) int64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// capRate lowers a rate to limit; zero means unlimited on both sides.
+func capRate(rate, limit int64) int64 {
+	if limit <= 0 {
+		return rate
+	}
+	if rate <= 0 || rate > limit {
+		return limit
+	}
+	return rate
+}
+
 func (l *SyncLogic) resolveControlClient(binding repository.ProtocolBinding) (*kernel.ControlClient, error) {
 	endpoint := strings.TrimSpace(binding.Node.ControlEndpoint)
 	token := resolveControlToken(binding.Node)
@@ -443,7 +806,7 @@ func (l *SyncLogic) resolveControlClient(binding repository.ProtocolBinding) (*k
 		Token:   token,
 		Timeout: resolveKernelHTTPTimeout(binding.Node),
 	}
-	return kernel.NewControlClient(opts)
+	return kernel.NewControlClient(l.svcCtx.KernelHTTPOptions(opts))
 }
 
 func resolveControlToken(node repository.Node) string {
@@ -7,6 +7,8 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/portutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
@@ -36,10 +38,13 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateProtocolBindingRequest) (*typ
 		name := strings.TrimSpace(*req.Name)
 		input.Name = &name
 	}
+	var node *repository.Node
 	if req.NodeID != nil && *req.NodeID > 0 {
-		if _, err := l.svcCtx.Repositories.Node.Get(l.ctx, *req.NodeID); err != nil {
+		loaded, err := l.svcCtx.Repositories.Node.Get(l.ctx, *req.NodeID)
+		if err != nil {
 			return nil, err
 		}
+		node = &loaded
 		input.NodeID = req.NodeID
 	}
 	if req.Protocol != nil {
@@ -84,6 +89,10 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateProtocolBindingRequest) (*typ
 		}
 		input.KernelID = &kernelID
 	}
+	if req.UpstreamBindingID != nil {
+		upstreamID := *req.UpstreamBindingID
+		input.UpstreamBindingID = &upstreamID
+	}
 	if req.SyncStatus != nil {
 		syncStatus, err := normalizeBindingSyncStatus(*req.SyncStatus)
 		if err != nil {
@@ -127,11 +136,116 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateProtocolBindingRequest) (*typ
 		input.Metadata = &metadata
 	}
 
-	updated, err := l.svcCtx.Repositories.ProtocolBinding.Update(l.ctx, req.BindingID, input)
+	profileChanged := input.Protocol != nil || input.Role != nil || input.Profile != nil
+	endpointChanged := req.AutoPort || input.NodeID != nil || input.Role != nil || input.Protocol != nil ||
+		input.Listen != nil || input.AccessPort != nil || input.KernelID != nil
+	upstreamChanged := input.UpstreamBindingID != nil
+	var (
+		previousUpstreamID uint64
+		updated            repository.ProtocolBinding
+	)
+	// Endpoint changes lock the target node so that port checks and allocation
+	// cannot race with other bindings being saved on the same node.
+	err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		if profileChanged || endpointChanged || upstreamChanged {
+			current, err := txRepos.ProtocolBinding.Get(l.ctx, req.BindingID)
+			if err != nil {
+				return err
+			}
+			if current.UpstreamBindingID != nil {
+				previousUpstreamID = *current.UpstreamBindingID
+			}
+			candidate := mergeBindingUpdate(current, input)
+			if node != nil {
+				candidate.Node = *node
+			}
+			if endpointChanged {
+				locked, err := txRepos.Node.GetForUpdate(l.ctx, candidate.NodeID)
+				if err != nil {
+					return err
+				}
+				candidate.Node = locked
+			}
+			if profileChanged {
+				if err := validateBindingProfile(candidate.Protocol, candidate.Role, candidate.Profile); err != nil {
+					return err
+				}
+			}
+			if req.AutoPort {
+				if err := assignAutoPort(l.ctx, txRepos, &candidate); err != nil {
+					return err
+				}
+				input.AccessPort = &candidate.AccessPort
+				input.Listen = &candidate.Listen
+			}
+			if endpointChanged {
+				if err := portutil.CheckBinding(l.ctx, txRepos, candidate); err != nil {
+					return err
+				}
+			}
+			if upstreamChanged && *input.UpstreamBindingID != 0 {
+				if err := relayutil.ValidateUpstream(l.ctx, txRepos, candidate, *input.UpstreamBindingID); err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		updated, err = txRepos.ProtocolBinding.Update(l.ctx, req.BindingID, input)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Keep relay chains consistent: upstreams refresh their relay users and
+	// relays behind this binding pick up its new address or credentials.
+	if upstreamChanged && *input.UpstreamBindingID != previousUpstreamID {
+		if err := relayutil.RequestUpstreamSync(l.ctx, l.svcCtx.Repositories, previousUpstreamID, *input.UpstreamBindingID); err != nil {
+			l.Errorf("queue upstream sync failed binding_id=%d: %v", updated.ID, err)
+		}
+	}
+	if endpointChanged || profileChanged || input.Status != nil {
+		if err := relayutil.RequestDownstreamSync(l.ctx, l.svcCtx.Repositories, []uint64{updated.ID}); err != nil {
+			l.Errorf("queue relay sync failed binding_id=%d: %v", updated.ID, err)
+		}
+	}
+
 	summary := mapProtocolBindingSummary(updated)
 	return &summary, nil
 }
+
+// mergeBindingUpdate applies the endpoint and profile fields of input to the
+// current binding so that the result can be validated before it is saved.
+func mergeBindingUpdate(current repository.ProtocolBinding, input repository.UpdateProtocolBindingInput) repository.ProtocolBinding {
+	if input.NodeID != nil && *input.NodeID != current.NodeID {
+		current.NodeID = *input.NodeID
+		current.Node = repository.Node{}
+	}
+	if input.Protocol != nil {
+		current.Protocol = *input.Protocol
+	}
+	if input.Role != nil {
+		current.Role = *input.Role
+	}
+	if input.Listen != nil {
+		current.Listen = *input.Listen
+	}
+	if input.AccessPort != nil {
+		current.AccessPort = *input.AccessPort
+	}
+	if input.KernelID != nil {
+		current.KernelID = *input.KernelID
+	}
+	if input.UpstreamBindingID != nil {
+		current.UpstreamBindingID = nil
+		if *input.UpstreamBindingID != 0 {
+			upstreamID := *input.UpstreamBindingID
+			current.UpstreamBindingID = &upstreamID
+		}
+	}
+	if input.Profile != nil {
+		current.Profile = *input.Profile
+	}
+	return current
+}
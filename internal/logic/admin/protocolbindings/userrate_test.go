@@ -0,0 +1,70 @@
+package protocolbindings
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestSubscriptionUsersShareOneRate(t *testing.T) {
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	user := repository.User{Email: "rate@example.com", Status: 1, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&user).Error)
+	node := repository.Node{Name: "edge", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+
+	// One node, two bindings, each reached through a different plan of the same user.
+	bindings := make([]repository.ProtocolBinding, 0, 2)
+	for i, plan := range []struct {
+		id   uint64
+		rate int64
+	}{{id: 11, rate: 1024}, {id: 12, rate: 4096}} {
+		binding := repository.ProtocolBinding{
+			Name:      "b" + string(rune('a'+i)),
+			NodeID:    node.ID,
+			Protocol:  "vless",
+			Role:      "listener",
+			KernelID:  "kernel-" + string(rune('a'+i)),
+			Status:    status.ProtocolBindingStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, db.Create(&binding).Error)
+		bindings = append(bindings, binding)
+		require.NoError(t, repos.PlanProtocolBinding.Replace(ctx, plan.id, []uint64{binding.ID}))
+
+		sub := repository.Subscription{
+			UserID:       user.ID,
+			PlanID:       plan.id,
+			Status:       status.SubscriptionStatusActive,
+			PlanSnapshot: map[string]any{"upload_rate_bytes": plan.rate, "download_rate_bytes": plan.rate},
+			ExpiresAt:    now.Add(24 * time.Hour),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		require.NoError(t, db.Create(&sub).Error)
+	}
+
+	logic := NewSyncLogic(ctx, svcCtx)
+	for _, binding := range bindings {
+		users, err := logic.buildSubscriptionUsers(binding)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.NotNil(t, users[0].Rate)
+		require.Equal(t, int64(4096), *users[0].Rate.Up, "binding %s", binding.KernelID)
+		require.Equal(t, int64(4096), *users[0].Rate.Down)
+	}
+
+	require.Equal(t, int64(0), maxRate(0, 1024), "unlimited wins")
+	require.Equal(t, int64(2048), maxRate(2048, 1024))
+}
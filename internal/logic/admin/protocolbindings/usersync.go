@@ -0,0 +1,256 @@
+package protocolbindings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const (
+	// SyncModeFull re-posts the protocol with its complete user list.
+	SyncModeFull = "full"
+	// SyncModeIncremental applies user differences through the single-user
+	// endpoints and upserts only when the protocol itself changed.
+	SyncModeIncremental = "incremental"
+)
+
+const kernelUserCredentialKey = "credential_fp"
+
+type userSyncStats struct {
+	added   int
+	updated int
+	removed int
+}
+
+func normalizeSyncMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", SyncModeFull:
+		return SyncModeFull, nil
+	case SyncModeIncremental:
+		return SyncModeIncremental, nil
+	default:
+		return "", repository.ErrInvalidArgument
+	}
+}
+
+// errSpecChanged reports that a protocol's non-user fields differ from the
+// kernel's, so an incremental sync has to fall back to a full upsert.
+var errSpecChanged = errors.New("protocol spec changed")
+
+// syncUsersIncremental diffs the expected users against the kernel's protocol
+// user list and applies only the difference through the single-user
+// /v1/users endpoints. Users other bindings on the kernel expect are never
+// removed. kernel.ErrNotFound is returned when the protocol does not exist on
+// the kernel and errSpecChanged when listen, connect, profile or upstream
+// differ from what the kernel runs; both call for a full upsert.
+func (l *SyncLogic) syncUsersIncremental(binding repository.ProtocolBinding, control *kernel.ControlClient, req kernel.ProtocolUpsertRequest) (userSyncStats, error) {
+	var stats userSyncStats
+
+	// The kernel reports listen and connect; profile and upstream are checked
+	// against what the panel last applied.
+	if binding.AppliedSpec != protocolSpecFingerprint(req) {
+		return stats, errSpecChanged
+	}
+	protocols, err := control.ListProtocols(l.ctx)
+	if err != nil {
+		return stats, err
+	}
+	summary, ok := findKernelProtocol(protocols, req.Profile.ID)
+	if !ok {
+		return stats, kernel.ErrNotFound
+	}
+	if protocolSpecMismatch(summary, req) {
+		return stats, errSpecChanged
+	}
+
+	current, err := control.ListProtocolUsers(l.ctx, req.Profile.ID)
+	if err != nil {
+		return stats, err
+	}
+	diff := diffKernelUsers(current, req.Users)
+	if err := l.dropSharedUsers(binding, &diff); err != nil {
+		return stats, err
+	}
+	if diff.empty() {
+		return stats, nil
+	}
+
+	l.snapshotBeforeSync(control, binding, SyncModeIncremental)
+	for _, user := range diff.missing {
+		if _, err := control.CreateUser(l.ctx, kernel.UserCreateRequest(user)); err != nil {
+			return stats, fmt.Errorf("create user %s: %w", user.ID, err)
+		}
+		stats.added++
+	}
+	for _, change := range diff.mismatched {
+		if _, err := control.PatchUser(l.ctx, change.expected.ID, kernelUserPatch(change.expected)); err != nil {
+			return stats, fmt.Errorf("patch user %s: %w", change.expected.ID, err)
+		}
+		stats.updated++
+	}
+	for _, user := range diff.extra {
+		if err := control.DeleteUser(l.ctx, user.ID); err != nil && !errors.Is(err, kernel.ErrNotFound) {
+			return stats, fmt.Errorf("delete user %s: %w", user.ID, err)
+		}
+		stats.removed++
+	}
+	return stats, nil
+}
+
+// kernelUserPatch rewrites every field the panel manages; an empty rate
+// clears the kernel's limits.
+func kernelUserPatch(user kernel.User) kernel.UserPatchRequest {
+	password := user.Password
+	tags := user.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	rate := user.Rate
+	if rate == nil {
+		rate = &kernel.UserRate{}
+	}
+	return kernel.UserPatchRequest{
+		Password: &password,
+		Rate:     rate,
+		Metadata: user.Metadata,
+		Tags:     &tags,
+	}
+}
+
+// protocolSpecFingerprint hashes the non-user fields of an upsert.
+func protocolSpecFingerprint(req kernel.ProtocolUpsertRequest) string {
+	payload, err := json.Marshal(kernel.ProtocolUpsertRequest{
+		Listen:  req.Listen,
+		Connect: req.Connect,
+		Profile: req.Profile,
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// protocolSpecMismatch compares the non-user fields the kernel reports back;
+// empty kernel values are treated as unknown, as in drift scans.
+func protocolSpecMismatch(summary kernel.ProtocolSummary, req kernel.ProtocolUpsertRequest) bool {
+	differs := func(got, want string) bool {
+		got = strings.TrimSpace(got)
+		return got != "" && !strings.EqualFold(got, strings.TrimSpace(want))
+	}
+	return differs(summary.Protocol, req.Profile.Protocol) ||
+		differs(summary.Role, req.Profile.Role) ||
+		differs(summary.Listen, req.Listen) ||
+		differs(summary.Connect, req.Connect)
+}
+
+func findKernelProtocol(protocols []kernel.ProtocolSummary, kernelID string) (kernel.ProtocolSummary, bool) {
+	for _, protocol := range protocols {
+		if strings.TrimSpace(protocol.ID) == kernelID {
+			return protocol, true
+		}
+	}
+	return kernel.ProtocolSummary{}, false
+}
+
+type kernelUserChange struct {
+	existing kernel.UserView
+	expected kernel.User
+}
+
+// kernelUserDiff describes how a kernel protocol's users differ from the panel.
+type kernelUserDiff struct {
+	missing    []kernel.User
+	mismatched []kernelUserChange
+	extra      []kernel.UserView
+}
+
+func (d kernelUserDiff) empty() bool {
+	return len(d.missing) == 0 && len(d.mismatched) == 0 && len(d.extra) == 0
+}
+
+func diffKernelUsers(current []kernel.UserView, expected []kernel.User) kernelUserDiff {
+	var diff kernelUserDiff
+	currentByID := make(map[string]kernel.UserView, len(current))
+	for _, user := range current {
+		currentByID[user.ID] = user
+	}
+
+	expectedIDs := make(map[string]struct{}, len(expected))
+	for _, user := range expected {
+		expectedIDs[user.ID] = struct{}{}
+		existing, ok := currentByID[user.ID]
+		switch {
+		case !ok:
+			diff.missing = append(diff.missing, user)
+		case !kernelUserMatches(existing, user):
+			diff.mismatched = append(diff.mismatched, kernelUserChange{existing: existing, expected: user})
+		}
+	}
+
+	for _, user := range current {
+		if _, ok := expectedIDs[user.ID]; !ok {
+			diff.extra = append(diff.extra, user)
+		}
+	}
+	return diff
+}
+
+// kernelUserMatches reports whether the kernel view is already up to date.
+// Passwords are not returned by the kernel, so the credential fingerprint in
+// metadata stands in for them.
+func kernelUserMatches(existing kernel.UserView, expected kernel.User) bool {
+	if strings.TrimSpace(existing.Username) != expected.Username {
+		return false
+	}
+	if !sameKernelRate(existing.Rate, expected.Rate) {
+		return false
+	}
+	for key, value := range expected.Metadata {
+		if fmt.Sprint(existing.Metadata[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// kernelUserRate builds the kernel rate payload; zero limits are unlimited.
+func kernelUserRate(upload, download int64) *kernel.UserRate {
+	if upload <= 0 && download <= 0 {
+		return nil
+	}
+	rate := &kernel.UserRate{}
+	if upload > 0 {
+		rate.Up = &upload
+	}
+	if download > 0 {
+		rate.Down = &download
+	}
+	return rate
+}
+
+// sameKernelRate compares rates treating nil and non-positive limits as unlimited.
+func sameKernelRate(a, b *kernel.UserRate) bool {
+	return rateLimitValue(a, true) == rateLimitValue(b, true) &&
+		rateLimitValue(a, false) == rateLimitValue(b, false)
+}
+
+func rateLimitValue(rate *kernel.UserRate, up bool) int64 {
+	if rate == nil {
+		return 0
+	}
+	limit := rate.Down
+	if up {
+		limit = rate.Up
+	}
+	if limit == nil || *limit <= 0 {
+		return 0
+	}
+	return *limit
+}
@@ -0,0 +1,200 @@
+package protocolbindings
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+// newIncrementalTestLogic returns a sync logic backed by a test database and
+// the active binding "edge" of a node on the fake kernel.
+func newIncrementalTestLogic(t *testing.T) (*fakeKernel, *SyncLogic, *kernel.ControlClient, repository.ProtocolBinding) {
+	t.Helper()
+	svcCtx, cleanup := setupProtocolBindingTestContext(t)
+	t.Cleanup(cleanup)
+	fake := newFakeKernel(t)
+	control, err := kernel.NewControlClient(kernel.HTTPOptions{BaseURL: fake.URL})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", ControlEndpoint: fake.URL, KernelEventMode: "pull", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&node).Error)
+	binding := repository.ProtocolBinding{
+		Name:       "edge",
+		NodeID:     node.ID,
+		Protocol:   "vless",
+		Role:       "listener",
+		Listen:     "0.0.0.0:443",
+		KernelID:   "edge",
+		Status:     status.ProtocolBindingStatusActive,
+		SyncStatus: status.ProtocolBindingSyncStatusSynced,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&binding).Error)
+	binding.Node = node
+	fake.protocols["edge"] = kernel.ProtocolSummary{ID: "edge", Role: "listener", Protocol: "vless", Listen: "0.0.0.0:443"}
+
+	return fake, NewSyncLogic(context.Background(), svcCtx), control, binding
+}
+
+func incrementalRequest(users ...kernel.User) kernel.ProtocolUpsertRequest {
+	return kernel.ProtocolUpsertRequest{
+		Listen:  "0.0.0.0:443",
+		Users:   users,
+		Profile: kernel.NodeProfile{ID: "edge", Role: "listener", Protocol: "vless"},
+	}
+}
+
+func TestSyncUsersIncremental(t *testing.T) {
+	fake, logic, control, binding := newIncrementalTestLogic(t)
+	fake.users["edge"] = []kernel.UserView{
+		// up to date
+		{ID: "1", Username: "u1", Metadata: map[string]any{"credential_fp": "fp1"}},
+		// rotated credential
+		{ID: "2", Username: "u2", Metadata: map[string]any{"credential_fp": "old"}},
+		// expired
+		{ID: "3", Username: "u3"},
+	}
+
+	req := incrementalRequest(
+		kernel.User{ID: "1", Username: "u1", Password: "p1", Metadata: map[string]any{"credential_fp": "fp1"}},
+		kernel.User{ID: "2", Username: "u2", Password: "p2", Metadata: map[string]any{"credential_fp": "fp2"}},
+		kernel.User{ID: "6", Username: "u6", Password: "p6", Metadata: map[string]any{"credential_fp": "fp6"}},
+	)
+	binding.AppliedSpec = protocolSpecFingerprint(req)
+
+	stats, err := logic.syncUsersIncremental(binding, control, req)
+	require.NoError(t, err)
+	require.Equal(t, userSyncStats{added: 1, updated: 1, removed: 1}, stats)
+
+	// Only the differences go out, one user per request.
+	require.Empty(t, fake.upserts)
+	require.Equal(t, 1, fake.calls(http.MethodPost, "/v1/users"))
+	require.Equal(t, 1, fake.calls(http.MethodPatch, "/v1/users/2"))
+	require.Equal(t, 1, fake.calls(http.MethodDelete, "/v1/users/3"))
+	require.Equal(t, "p6", fake.registry["6"].Password)
+	require.Equal(t, "fp2", fake.users["edge"][1].Metadata["credential_fp"])
+
+	// Nothing changed since, so nothing is written.
+	fake.reset()
+	stats, err = logic.syncUsersIncremental(binding, control, req)
+	require.NoError(t, err)
+	require.Equal(t, userSyncStats{}, stats)
+	require.Equal(t, 2, len(fake.requests), "only the protocol and user listings")
+
+	// Changes beyond the users call for a full upsert.
+	changed := req
+	changed.Connect = "10.0.0.2:443"
+	_, err = logic.syncUsersIncremental(binding, control, changed)
+	require.ErrorIs(t, err, errSpecChanged)
+
+	fake.protocols["edge"] = kernel.ProtocolSummary{ID: "edge", Role: "listener", Protocol: "vless", Listen: "0.0.0.0:8443"}
+	_, err = logic.syncUsersIncremental(binding, control, req)
+	require.ErrorIs(t, err, errSpecChanged)
+
+	delete(fake.protocols, "edge")
+	_, err = logic.syncUsersIncremental(binding, control, req)
+	require.ErrorIs(t, err, kernel.ErrNotFound)
+}
+
+func TestSyncUsersIncrementalKeepsSharedUsers(t *testing.T) {
+	fake, logic, control, binding := newIncrementalTestLogic(t)
+	svcCtx := logic.svcCtx
+	ctx := context.Background()
+
+	// A binding of another node on the same kernel serves one subscriber.
+	now := time.Now().UTC()
+	sibling := repository.Node{Name: "edge-2", ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&sibling).Error)
+	exit := repository.ProtocolBinding{Name: "exit", NodeID: sibling.ID, Protocol: "vless", Role: "listener", KernelID: "exit", Status: status.ProtocolBindingStatusActive, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&exit).Error)
+	require.NoError(t, svcCtx.Repositories.PlanProtocolBinding.Replace(ctx, 30, []uint64{exit.ID}))
+	user := repository.User{Email: "exit@example.com", Status: 1, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&user).Error)
+	require.NoError(t, svcCtx.DB.Create(&repository.Subscription{
+		UserID: user.ID, PlanID: 30, Status: status.SubscriptionStatusActive,
+		ExpiresAt: now.Add(24 * time.Hour), CreatedAt: now, UpdatedAt: now,
+	}).Error)
+	exit.Node = sibling
+	exitUsers, err := logic.buildKernelUsers(exit)
+	require.NoError(t, err)
+	require.Len(t, exitUsers, 1)
+
+	// Kernel users serve every protocol, so the sibling's user shows up on
+	// edge as well, next to a user nobody expects.
+	fake.registry[exitUsers[0].ID] = exitUsers[0]
+	fake.registry["stale"] = kernel.User{ID: "stale", Username: "stale"}
+
+	req := incrementalRequest()
+	binding.AppliedSpec = protocolSpecFingerprint(req)
+	stats, err := logic.syncUsersIncremental(binding, control, req)
+	require.NoError(t, err)
+	require.Equal(t, userSyncStats{removed: 1}, stats)
+	require.Contains(t, fake.registry, exitUsers[0].ID)
+	require.NotContains(t, fake.registry, "stale")
+}
+
+func TestSyncUsersIncrementalRate(t *testing.T) {
+	limit := int64(1048576)
+	fake, logic, control, binding := newIncrementalTestLogic(t)
+	fake.users["edge"] = []kernel.UserView{
+		// throttled by a plan limit that no longer applies
+		{ID: "1", Username: "u1", Rate: &kernel.UserRate{Up: &limit}},
+		// unlimited on the kernel, now throttled
+		{ID: "2", Username: "u2"},
+		// already matching; a null side equals unlimited
+		{ID: "3", Username: "u3", Rate: &kernel.UserRate{Down: &limit}},
+	}
+
+	req := incrementalRequest(
+		kernel.User{ID: "1", Username: "u1"},
+		kernel.User{ID: "2", Username: "u2", Rate: kernelUserRate(limit, limit)},
+		kernel.User{ID: "3", Username: "u3", Rate: kernelUserRate(0, limit)},
+	)
+	binding.AppliedSpec = protocolSpecFingerprint(req)
+
+	stats, err := logic.syncUsersIncremental(binding, control, req)
+	require.NoError(t, err)
+	require.Equal(t, userSyncStats{updated: 2}, stats)
+
+	require.Empty(t, fake.upserts)
+	require.True(t, sameKernelRate(fake.users["edge"][0].Rate, nil))
+	require.Equal(t, limit, *fake.users["edge"][1].Rate.Up)
+}
+
+func TestSyncBindingIncrementalFallsBackOnSpecChange(t *testing.T) {
+	fake, logic, _, binding := newIncrementalTestLogic(t)
+
+	// Nothing recorded yet: the first incremental sync upserts in full.
+	result := logic.syncBinding(binding, SyncModeIncremental)
+	require.Equal(t, status.SyncResultStatusSynced, result.Status)
+	require.Equal(t, SyncModeFull, result.Mode)
+	require.Equal(t, 1, fake.upsertCount())
+
+	binding, err := logic.svcCtx.Repositories.ProtocolBinding.Get(context.Background(), binding.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, binding.AppliedSpec)
+	result = logic.syncBinding(binding, SyncModeIncremental)
+	require.Equal(t, SyncModeIncremental, result.Mode)
+	require.Equal(t, 1, fake.upsertCount())
+
+	// A profile change reaches the kernel although only users are diffed.
+	_, err = logic.svcCtx.Repositories.ProtocolBinding.Update(context.Background(), binding.ID, repository.UpdateProtocolBindingInput{
+		Profile: &map[string]any{"flow": "xtls-rprx-vision"},
+	})
+	require.NoError(t, err)
+	binding, err = logic.svcCtx.Repositories.ProtocolBinding.Get(context.Background(), binding.ID)
+	require.NoError(t, err)
+	result = logic.syncBinding(binding, SyncModeIncremental)
+	require.Equal(t, SyncModeFull, result.Mode)
+	require.Equal(t, 2, fake.upsertCount())
+	require.Equal(t, "xtls-rprx-vision", fake.upserts[1].Profile.Profile["flow"])
+}
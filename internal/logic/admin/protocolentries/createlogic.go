@@ -6,6 +6,8 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/portutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/protocolschema"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -69,6 +71,16 @@ func (l *CreateLogic) Create(req *types.AdminCreateProtocolEntryRequest) (*types
 	}
 
 	profile := cloneEntryProfile(req.Profile)
+	if preset := strings.TrimSpace(req.Preset); preset != "" {
+		merged, err := protocolschema.ApplyPreset(protocol, preset, true, profile)
+		if err != nil {
+			return nil, repository.NewInvalidArgument(err.Error())
+		}
+		profile = merged
+	}
+	if err := validateEntryProfile(protocol, profile); err != nil {
+		return nil, err
+	}
 
 	entry := repository.ProtocolEntry{
 		Name:         name,
@@ -77,16 +89,35 @@ func (l *CreateLogic) Create(req *types.AdminCreateProtocolEntryRequest) (*types
 		Status:       statusCode,
 		EntryAddress: entryAddress,
 		EntryPort:    req.EntryPort,
+		Priority:     req.Priority,
 		Tags:         append([]string(nil), req.Tags...),
 		Description:  strings.TrimSpace(req.Description),
 		Profile:      profile,
 	}
-
-	created, err := l.svcCtx.Repositories.ProtocolEntry.Create(l.ctx, entry)
+	// Lock the binding's node so the port check and insert are atomic with
+	// binding changes on the same node.
+	var created repository.ProtocolEntry
+	err = l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		node, err := txRepos.Node.GetForUpdate(l.ctx, binding.NodeID)
+		if err != nil {
+			return err
+		}
+		binding.Node = node
+		if err := portutil.CheckEntry(l.ctx, txRepos, entry, binding); err != nil {
+			return err
+		}
+		created, err = txRepos.ProtocolEntry.Create(l.ctx, entry)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	summary := mapProtocolEntrySummary(created)
-	return &summary, nil
+	requestRelaySync(l.ctx, l.svcCtx.Repositories, l.Logger, created.BindingID)
+
+	summaries := []types.ProtocolEntrySummary{mapProtocolEntrySummary(created)}
+	if err := applyChainHealth(l.ctx, l.svcCtx.Repositories, []repository.ProtocolEntry{created}, summaries); err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
 }
@@ -31,5 +31,13 @@ func (l *DeleteLogic) Delete(req *types.AdminDeleteProtocolEntryRequest) error {
 	if req.EntryID == 0 {
 		return repository.ErrInvalidArgument
 	}
-	return l.svcCtx.Repositories.ProtocolEntry.Delete(l.ctx, req.EntryID)
+	entry, err := l.svcCtx.Repositories.ProtocolEntry.Get(l.ctx, req.EntryID)
+	if err != nil {
+		return err
+	}
+	if err := l.svcCtx.Repositories.ProtocolEntry.Delete(l.ctx, entry.ID); err != nil {
+		return err
+	}
+	requestRelaySync(l.ctx, l.svcCtx.Repositories, l.Logger, entry.BindingID)
+	return nil
 }
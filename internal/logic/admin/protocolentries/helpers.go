@@ -1,9 +1,14 @@
 package protocolentries
 
 import (
+	"context"
 	"strings"
 	"time"
 
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/protocolschema"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
@@ -25,6 +30,7 @@ func mapProtocolEntrySummary(entry repository.ProtocolEntry) types.ProtocolEntry
 		HealthStatus:  binding.HealthStatus,
 		EntryAddress:  entry.EntryAddress,
 		EntryPort:     entry.EntryPort,
+		Priority:      entry.Priority,
 		Tags:          append([]string(nil), entry.Tags...),
 		Description:   entry.Description,
 		Profile:       cloneEntryProfile(entry.Profile),
@@ -33,6 +39,33 @@ func mapProtocolEntrySummary(entry repository.ProtocolEntry) types.ProtocolEntry
 	}
 }
 
+// applyChainHealth reports the health of the whole relay chain behind each
+// entry's binding: an entry is only as healthy as the worst hop it relays through.
+func applyChainHealth(ctx context.Context, repos *repository.Repositories, entries []repository.ProtocolEntry, summaries []types.ProtocolEntrySummary) error {
+	bindings := make([]repository.ProtocolBinding, 0, len(entries))
+	for _, entry := range entries {
+		bindings = append(bindings, entry.Binding)
+	}
+	health, err := relayutil.ChainHealth(ctx, repos, bindings)
+	if err != nil {
+		return err
+	}
+	for i := range summaries {
+		if value, ok := health[summaries[i].BindingID]; ok {
+			summaries[i].HealthStatus = value
+		}
+	}
+	return nil
+}
+
+// requestRelaySync queues the relays behind a binding, whose upstream profile
+// is rendered from the binding's entries.
+func requestRelaySync(ctx context.Context, repos *repository.Repositories, logger logx.Logger, bindingID uint64) {
+	if err := relayutil.RequestDownstreamSync(ctx, repos, []uint64{bindingID}); err != nil {
+		logger.Errorf("queue relay sync failed binding_id=%d: %v", bindingID, err)
+	}
+}
+
 func normalizeEntryProtocol(entry repository.ProtocolEntry, binding repository.ProtocolBinding) string {
 	if value := strings.ToLower(strings.TrimSpace(entry.Protocol)); value != "" {
 		return value
@@ -40,6 +73,14 @@ func normalizeEntryProtocol(entry repository.ProtocolEntry, binding repository.P
 	return strings.ToLower(strings.TrimSpace(binding.Protocol))
 }
 
+// validateEntryProfile checks a profile against the schema of its protocol.
+func validateEntryProfile(protocol string, profile map[string]any) error {
+	if err := protocolschema.ValidateClient(protocol, profile); err != nil {
+		return repository.NewInvalidArgument(err.Error())
+	}
+	return nil
+}
+
 func cloneEntryProfile(profile map[string]any) map[string]any {
 	if profile == nil {
 		return nil
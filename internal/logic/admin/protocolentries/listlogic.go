@@ -50,6 +50,9 @@ func (l *ListLogic) List(req *types.AdminListProtocolEntriesRequest) (*types.Adm
 	for _, entry := range entries {
 		summaries = append(summaries, mapProtocolEntrySummary(entry))
 	}
+	if err := applyChainHealth(l.ctx, l.svcCtx.Repositories, entries, summaries); err != nil {
+		return nil, err
+	}
 
 	page, perPage := normalizePage(req.Page, req.PerPage)
 	pagination := types.PaginationMeta{
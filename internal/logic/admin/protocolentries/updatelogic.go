@@ -6,6 +6,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/portutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
@@ -81,6 +82,9 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateProtocolEntryRequest) (*types
 		}
 		input.EntryPort = req.EntryPort
 	}
+	if req.Priority != nil {
+		input.Priority = req.Priority
+	}
 	if req.Tags != nil {
 		tags := append([]string(nil), req.Tags...)
 		input.Tags = &tags
@@ -94,11 +98,68 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateProtocolEntryRequest) (*types
 		input.Profile = &profile
 	}
 
-	updated, err := l.svcCtx.Repositories.ProtocolEntry.Update(l.ctx, req.EntryID, input)
+	profileChanged := input.Protocol != nil || input.Profile != nil
+	endpointChanged := input.BindingID != nil || input.EntryAddress != nil || input.EntryPort != nil
+	// Endpoint changes lock the binding's node so the port check and update
+	// are atomic with other changes on the same node.
+	var updated repository.ProtocolEntry
+	err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		if profileChanged || endpointChanged {
+			current, err := txRepos.ProtocolEntry.Get(l.ctx, req.EntryID)
+			if err != nil {
+				return err
+			}
+			if profileChanged {
+				protocol, profile := normalizeEntryProtocol(current, current.Binding), current.Profile
+				if input.Protocol != nil {
+					protocol = *input.Protocol
+				}
+				if input.Profile != nil {
+					profile = *input.Profile
+				}
+				if err := validateEntryProfile(protocol, profile); err != nil {
+					return err
+				}
+			}
+			if endpointChanged {
+				candidate := current
+				if binding.ID != 0 {
+					candidate.BindingID = binding.ID
+					candidate.Binding = binding
+				}
+				if input.Protocol != nil {
+					candidate.Protocol = *input.Protocol
+				}
+				if input.EntryAddress != nil {
+					candidate.EntryAddress = *input.EntryAddress
+				}
+				if input.EntryPort != nil {
+					candidate.EntryPort = *input.EntryPort
+				}
+				node, err := txRepos.Node.GetForUpdate(l.ctx, candidate.Binding.NodeID)
+				if err != nil {
+					return err
+				}
+				candidate.Binding.Node = node
+				if err := portutil.CheckEntry(l.ctx, txRepos, candidate, candidate.Binding); err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		updated, err = txRepos.ProtocolEntry.Update(l.ctx, req.EntryID, input)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	summary := mapProtocolEntrySummary(updated)
-	return &summary, nil
+	requestRelaySync(l.ctx, l.svcCtx.Repositories, l.Logger, updated.BindingID)
+
+	summaries := []types.ProtocolEntrySummary{mapProtocolEntrySummary(updated)}
+	if err := applyChainHealth(l.ctx, l.svcCtx.Repositories, []repository.ProtocolEntry{updated}, summaries); err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/protocolschema"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -25,14 +26,72 @@ func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
 	}
 }
 
-// List returns distinct protocols configured in protocol bindings.
+// List returns distinct protocols configured in protocol bindings together
+// with the profile schemas of every registered protocol.
 func (l *ListLogic) List() (*types.AdminProtocolListResponse, error) {
 	protocols, err := l.svcCtx.Repositories.ProtocolBinding.ListProtocols(l.ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	registered := protocolschema.All()
+	schemas := make([]types.ProtocolSchema, 0, len(registered))
+	for _, schema := range registered {
+		schemas = append(schemas, mapProtocolSchema(schema))
+	}
+
 	return &types.AdminProtocolListResponse{
 		Protocols: protocols,
+		Schemas:   schemas,
 	}, nil
 }
+
+func mapProtocolSchema(schema protocolschema.Schema) types.ProtocolSchema {
+	presets := make([]types.ProtocolPreset, 0, len(schema.Presets))
+	for _, preset := range schema.Presets {
+		presets = append(presets, types.ProtocolPreset{
+			Name:          preset.Name,
+			Description:   preset.Description,
+			ServerProfile: protocolschema.MergeProfile(preset.Server, nil),
+			ClientProfile: protocolschema.MergeProfile(preset.Client, nil),
+		})
+	}
+	aliases := append([]string{}, schema.Aliases...)
+	return types.ProtocolSchema{
+		Protocol:     schema.Protocol,
+		Aliases:      aliases,
+		Description:  schema.Description,
+		ServerFields: mapSchemaFields(schema.Server),
+		ClientFields: mapSchemaFields(schema.Client),
+		Presets:      presets,
+	}
+}
+
+func mapSchemaFields(fields []protocolschema.Field) []types.ProtocolSchemaField {
+	if fields == nil {
+		return nil
+	}
+	mapped := make([]types.ProtocolSchemaField, 0, len(fields))
+	for _, field := range fields {
+		summary := types.ProtocolSchemaField{
+			Name:        field.Name,
+			Type:        string(field.Type),
+			Description: field.Description,
+			Required:    field.Required,
+			Enum:        field.Enum,
+			Items:       string(field.Items),
+			Min:         field.Min,
+			Max:         field.Max,
+			Default:     field.Default,
+			Fields:      mapSchemaFields(field.Fields),
+		}
+		if field.RequiredWhen != nil {
+			summary.RequiredWhen = &types.ProtocolFieldCondition{
+				Field:  field.RequiredWhen.Field,
+				Values: field.RequiredWhen.Values,
+			}
+		}
+		mapped = append(mapped, summary)
+	}
+	return mapped
+}
@@ -1,18 +1,22 @@
 package site
 
 import (
+	subscriptionutil "github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
 
 func toSiteSetting(setting repository.SiteSetting) types.SiteSetting {
+	policy := subscriptionutil.PolicyFromSite(setting)
 	return types.SiteSetting{
-		ID:                 setting.ID,
-		Name:               setting.Name,
-		LogoURL:            setting.LogoURL,
-		ServiceDomain:      setting.ServiceDomain,
-		SubscriptionDomain: setting.SubscriptionDomain,
-		CreatedAt:          setting.CreatedAt.Unix(),
-		UpdatedAt:          setting.UpdatedAt.Unix(),
+		ID:                   setting.ID,
+		Name:                 setting.Name,
+		LogoURL:              setting.LogoURL,
+		ServiceDomain:        setting.ServiceDomain,
+		SubscriptionDomain:   setting.SubscriptionDomain,
+		UnhealthyEntryPolicy: policy.Mode,
+		UnhealthyEntrySuffix: policy.Suffix,
+		CreatedAt:            setting.CreatedAt.Unix(),
+		UpdatedAt:            setting.UpdatedAt.Unix(),
 	}
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	subscriptionutil "github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
@@ -50,6 +51,16 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateSiteSettingRequest) (*types.A
 	if req.SubscriptionDomain != nil {
 		setting.SubscriptionDomain = strings.TrimSpace(*req.SubscriptionDomain)
 	}
+	if req.UnhealthyEntryPolicy != nil {
+		policy, ok := subscriptionutil.NormalizeUnhealthyEntryPolicy(*req.UnhealthyEntryPolicy)
+		if !ok {
+			return nil, repository.InvalidArgumentf("unsupported unhealthy_entry_policy %q", *req.UnhealthyEntryPolicy)
+		}
+		setting.UnhealthyEntryPolicy = policy
+	}
+	if req.UnhealthyEntrySuffix != nil {
+		setting.UnhealthyEntrySuffix = *req.UnhealthyEntrySuffix
+	}
 
 	updated, err := l.svcCtx.Repositories.Site.UpsertSiteSetting(l.ctx, setting)
 	if err != nil {
@@ -140,6 +140,9 @@ func (l *CreateLogic) Create(req *types.AdminCreateSubscriptionRequest) (*types.
 				return err
 			}
 		}
+		if err := subscriptionutil.RequestUserBindingSync(l.ctx, txRepos, created.UserID, subscriptionutil.BindingSyncReasonSubscriptionCreate); err != nil {
+			return err
+		}
 
 		actor, ok := security.UserFromContext(l.ctx)
 		var actorID *uint64
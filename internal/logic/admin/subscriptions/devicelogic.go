@@ -0,0 +1,132 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	subscriptionutil "github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// DevicesLogic inspects and resets the devices seen for a subscription.
+type DevicesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDevicesLogic constructs DevicesLogic.
+func NewDevicesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DevicesLogic {
+	return &DevicesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List returns the client IPs seen for a subscription together with its violation.
+func (l *DevicesLogic) List(req *types.AdminSubscriptionDevicesRequest) (*types.AdminSubscriptionDevicesResponse, error) {
+	sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := l.svcCtx.Repositories.Device.GetPolicy(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.buildResponse(sub, policy)
+}
+
+// Reset clears recorded devices and any violation so penalties lift immediately.
+func (l *DevicesLogic) Reset(req *types.AdminSubscriptionDevicesRequest) (*types.AdminSubscriptionDevicesResponse, error) {
+	sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		if err := txRepos.Device.DeleteBySubscription(l.ctx, sub.ID); err != nil {
+			return err
+		}
+		cleared := true
+		if err := txRepos.Device.DeleteViolation(l.ctx, sub.ID); err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			cleared = false
+		}
+		if cleared {
+			if err := subscriptionutil.RequestSubscriptionBindingSync(l.ctx, txRepos, sub, subscriptionutil.BindingSyncReasonDeviceRestore); err != nil {
+				return err
+			}
+		}
+
+		return auditutil.Record(l.ctx, txRepos, "admin.subscription.devices.reset", "subscription", fmt.Sprintf("%d", sub.ID), map[string]any{
+			"violation_cleared": cleared,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	policy, err := l.svcCtx.Repositories.Device.GetPolicy(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.buildResponse(sub, policy)
+}
+
+func (l *DevicesLogic) buildResponse(sub repository.Subscription, policy repository.DevicePolicySetting) (*types.AdminSubscriptionDevicesResponse, error) {
+	devices, err := l.svcCtx.Repositories.Device.ListBySubscription(l.ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().UTC().Add(-time.Duration(policy.WindowSeconds) * time.Second)
+	resp := &types.AdminSubscriptionDevicesResponse{
+		SubscriptionID: sub.ID,
+		DevicesLimit:   sub.DevicesLimit,
+		WindowSeconds:  policy.WindowSeconds,
+		Devices:        make([]types.SubscriptionDeviceSummary, 0, len(devices)),
+	}
+	for _, device := range devices {
+		active := !device.LastSeenAt.Before(since)
+		if active {
+			resp.ActiveDevices++
+		}
+		resp.Devices = append(resp.Devices, types.SubscriptionDeviceSummary{
+			ClientIP:    device.ClientIP,
+			Source:      device.Source,
+			UserAgent:   device.UserAgent,
+			Hits:        device.Hits,
+			Active:      active,
+			FirstSeenAt: toUnixOrZero(device.FirstSeenAt),
+			LastSeenAt:  toUnixOrZero(device.LastSeenAt),
+		})
+	}
+
+	violation, err := l.svcCtx.Repositories.Device.GetViolation(l.ctx, sub.ID)
+	switch {
+	case err == nil:
+		summary := types.DeviceLimitViolationSummary{
+			DeviceCount:       violation.DeviceCount,
+			DevicesLimit:      violation.DevicesLimit,
+			Action:            violation.Action,
+			ThrottleRateBytes: violation.ThrottleRateBytes,
+			DetectedAt:        toUnixOrZero(violation.DetectedAt),
+		}
+		if violation.PenaltyUntil != nil {
+			summary.PenaltyUntil = toUnixOrZero(*violation.PenaltyUntil)
+		}
+		resp.Violation = &summary
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package subscriptions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// DevicePolicyLogic reads and updates the DevicesLimit enforcement policy.
+type DevicePolicyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDevicePolicyLogic constructs DevicePolicyLogic.
+func NewDevicePolicyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DevicePolicyLogic {
+	return &DevicePolicyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Get returns the current device policy.
+func (l *DevicePolicyLogic) Get(_ *types.AdminGetDevicePolicyRequest) (*types.AdminDevicePolicyResponse, error) {
+	policy, err := l.svcCtx.Repositories.Device.GetPolicy(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AdminDevicePolicyResponse{Policy: toDevicePolicySummary(policy)}, nil
+}
+
+// Update changes the device policy. Existing violations keep the action they
+// were raised with until they are resolved.
+func (l *DevicePolicyLogic) Update(req *types.AdminUpdateDevicePolicyRequest) (*types.AdminDevicePolicyResponse, error) {
+	policy, err := l.svcCtx.Repositories.Device.GetPolicy(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Action != nil {
+		action := strings.ToLower(strings.TrimSpace(*req.Action))
+		switch action {
+		case repository.DevicePolicyOff,
+			repository.DevicePolicyWarn,
+			repository.DevicePolicyThrottle,
+			repository.DevicePolicySuspend:
+			policy.Action = action
+		default:
+			return nil, repository.ErrInvalidArgument
+		}
+	}
+	if req.WindowSeconds != nil {
+		if *req.WindowSeconds <= 0 {
+			return nil, repository.ErrInvalidArgument
+		}
+		policy.WindowSeconds = *req.WindowSeconds
+	}
+	if req.PenaltySeconds != nil {
+		if *req.PenaltySeconds <= 0 {
+			return nil, repository.ErrInvalidArgument
+		}
+		policy.PenaltySeconds = *req.PenaltySeconds
+	}
+	if req.ThrottleRateBytes != nil {
+		if *req.ThrottleRateBytes <= 0 {
+			return nil, repository.ErrInvalidArgument
+		}
+		policy.ThrottleRateBytes = *req.ThrottleRateBytes
+	}
+
+	var updated repository.DevicePolicySetting
+	if err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		result, err := txRepos.Device.UpsertPolicy(l.ctx, policy)
+		if err != nil {
+			return err
+		}
+		updated = result
+
+		return auditutil.Record(l.ctx, txRepos, "admin.device_policy.update", "device_policy", "", map[string]any{
+			"action":              updated.Action,
+			"window_seconds":      updated.WindowSeconds,
+			"penalty_seconds":     updated.PenaltySeconds,
+			"throttle_rate_bytes": updated.ThrottleRateBytes,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return &types.AdminDevicePolicyResponse{Policy: toDevicePolicySummary(updated)}, nil
+}
+
+func toDevicePolicySummary(policy repository.DevicePolicySetting) types.DevicePolicySummary {
+	return types.DevicePolicySummary{
+		Action:            policy.Action,
+		WindowSeconds:     policy.WindowSeconds,
+		PenaltySeconds:    policy.PenaltySeconds,
+		ThrottleRateBytes: policy.ThrottleRateBytes,
+		UpdatedAt:         toUnixOrZero(policy.UpdatedAt),
+	}
+}
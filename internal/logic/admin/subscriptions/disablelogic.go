@@ -6,6 +6,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	subscriptionutil "github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
@@ -42,6 +43,9 @@ func (l *DisableLogic) Disable(req *types.AdminDisableSubscriptionRequest) (*typ
 			return err
 		}
 		updated = result
+		if err := subscriptionutil.RequestSubscriptionBindingSync(l.ctx, txRepos, updated, subscriptionutil.BindingSyncReasonSubscriptionDisable); err != nil {
+			return err
+		}
 
 		actor, ok := security.UserFromContext(l.ctx)
 		var actorID *uint64
@@ -79,6 +79,9 @@ func (l *ExtendLogic) Extend(req *types.AdminExtendSubscriptionRequest) (*types.
 				return err
 			}
 		}
+		if err := subscriptionutil.RequestUserBindingSync(l.ctx, txRepos, updated.UserID, subscriptionutil.BindingSyncReasonSubscriptionExtend); err != nil {
+			return err
+		}
 
 		actor, ok := security.UserFromContext(l.ctx)
 		var actorID *uint64
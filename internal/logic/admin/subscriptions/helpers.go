@@ -16,7 +16,8 @@ func normalizeStatus(statusCode int) (int, error) {
 	switch statusCode {
 	case status.SubscriptionStatusActive,
 		status.SubscriptionStatusDisabled,
-		status.SubscriptionStatusExpired:
+		status.SubscriptionStatusExpired,
+		status.SubscriptionStatusExhausted:
 		return statusCode, nil
 	default:
 		return 0, repository.ErrInvalidArgument
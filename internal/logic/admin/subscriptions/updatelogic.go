@@ -160,6 +160,13 @@ func (l *UpdateLogic) Update(req *types.AdminUpdateSubscriptionRequest) (*types.
 				return err
 			}
 		}
+		// Bindings of the previous plan must drop the user as well.
+		if err := subscriptionutil.RequestSubscriptionBindingSync(l.ctx, txRepos, sub, subscriptionutil.BindingSyncReasonSubscriptionUpdate); err != nil {
+			return err
+		}
+		if err := subscriptionutil.RequestUserBindingSync(l.ctx, txRepos, updated.UserID, subscriptionutil.BindingSyncReasonSubscriptionUpdate); err != nil {
+			return err
+		}
 
 		actor, ok := security.UserFromContext(l.ctx)
 		var actorID *uint64
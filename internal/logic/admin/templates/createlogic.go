@@ -28,6 +28,10 @@ func NewCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateLogi
 
 // Create 执行创建。
 func (l *CreateLogic) Create(req *types.AdminCreateSubscriptionTemplateRequest) (*types.SubscriptionTemplateSummary, error) {
+	if err := requireTemplateContent(req.Format, req.Content); err != nil {
+		return nil, err
+	}
+
 	input := repository.CreateSubscriptionTemplateInput{
 		Name:        req.Name,
 		Description: req.Description,
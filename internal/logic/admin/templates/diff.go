@@ -0,0 +1,110 @@
+package templates
+
+import (
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// 差异行类型。
+const (
+	diffOpEqual  = "equal"
+	diffOpInsert = "insert"
+	diffOpDelete = "delete"
+)
+
+// maxDiffCells 限制最长公共子序列表的规模，超出时整体按删除后新增输出。
+const maxDiffCells = 4 << 20
+
+// diffLines 计算逐行差异：先去除公共前后缀，再对中间部分求最长公共子序列。
+func diffLines(from, to string) []types.TemplateDiffLine {
+	a, b := splitLines(from), splitLines(to)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]types.TemplateDiffLine, 0, len(a)+len(b))
+	oldLine, newLine := 0, 0
+	equal := func(text string) {
+		oldLine++
+		newLine++
+		lines = append(lines, types.TemplateDiffLine{Op: diffOpEqual, OldLine: oldLine, NewLine: newLine, Text: text})
+	}
+	remove := func(text string) {
+		oldLine++
+		lines = append(lines, types.TemplateDiffLine{Op: diffOpDelete, OldLine: oldLine, Text: text})
+	}
+	insert := func(text string) {
+		newLine++
+		lines = append(lines, types.TemplateDiffLine{Op: diffOpInsert, NewLine: newLine, Text: text})
+	}
+
+	for _, text := range a[:prefix] {
+		equal(text)
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) > maxDiffCells {
+		for _, text := range midA {
+			remove(text)
+		}
+		for _, text := range midB {
+			insert(text)
+		}
+	} else {
+		// lcs[i][j] 为 midA[i:] 与 midB[j:] 的最长公共子序列长度。
+		cols := len(midB) + 1
+		lcs := make([]int32, (len(midA)+1)*cols)
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				switch {
+				case midA[i] == midB[j]:
+					lcs[i*cols+j] = lcs[(i+1)*cols+j+1] + 1
+				case lcs[(i+1)*cols+j] >= lcs[i*cols+j+1]:
+					lcs[i*cols+j] = lcs[(i+1)*cols+j]
+				default:
+					lcs[i*cols+j] = lcs[i*cols+j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) && j < len(midB) {
+			switch {
+			case midA[i] == midB[j]:
+				equal(midA[i])
+				i++
+				j++
+			case lcs[(i+1)*cols+j] >= lcs[i*cols+j+1]:
+				remove(midA[i])
+				i++
+			default:
+				insert(midB[j])
+				j++
+			}
+		}
+		for ; i < len(midA); i++ {
+			remove(midA[i])
+		}
+		for ; j < len(midB); j++ {
+			insert(midB[j])
+		}
+	}
+
+	for _, text := range a[len(a)-suffix:] {
+		equal(text)
+	}
+	return lines
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// DiffLogic 比较模板版本。
+type DiffLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDiffLogic 构造函数。
+func NewDiffLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DiffLogic {
+	return &DiffLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// templateRevision 为参与比较的一个版本。
+type templateRevision struct {
+	format    string
+	content   string
+	variables map[string]repository.TemplateVariable
+}
+
+// Diff 返回两个版本之间内容与变量的逐行差异；版本为 0 时使用当前草稿。
+func (l *DiffLogic) Diff(req *types.AdminSubscriptionTemplateDiffRequest) (*types.AdminSubscriptionTemplateDiffResponse, error) {
+	from, err := l.revision(req.TemplateID, req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := l.revision(req.TemplateID, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	fromVariables, err := variablesText(from.variables)
+	if err != nil {
+		return nil, err
+	}
+	toVariables, err := variablesText(to.variables)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.AdminSubscriptionTemplateDiffResponse{
+		TemplateID: req.TemplateID,
+		From:       req.From,
+		To:         req.To,
+		FromFormat: from.format,
+		ToFormat:   to.format,
+		Content:    diffLines(from.content, to.content),
+		Variables:  diffLines(fromVariables, toVariables),
+	}
+	for _, lines := range [][]types.TemplateDiffLine{resp.Content, resp.Variables} {
+		for _, line := range lines {
+			switch line.Op {
+			case diffOpInsert:
+				resp.Added++
+			case diffOpDelete:
+				resp.Removed++
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (l *DiffLogic) revision(templateID uint64, version uint32) (templateRevision, error) {
+	if version == 0 {
+		tpl, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, templateID)
+		if err != nil {
+			return templateRevision{}, err
+		}
+		return templateRevision{format: tpl.Format, content: tpl.Content, variables: tpl.Variables}, nil
+	}
+	history, err := l.svcCtx.Repositories.SubscriptionTemplate.GetHistory(l.ctx, templateID, version)
+	if err != nil {
+		return templateRevision{}, err
+	}
+	return templateRevision{format: history.Format, content: history.Content, variables: history.Variables}, nil
+}
+
+// variablesText 将变量定义序列化为按名称排序的缩进 JSON，便于逐行比较。
+func variablesText(variables map[string]repository.TemplateVariable) (string, error) {
+	if len(variables) == 0 {
+		return "", nil
+	}
+	data, err := json.MarshalIndent(variables, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
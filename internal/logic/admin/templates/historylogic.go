@@ -5,6 +5,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -25,16 +26,30 @@ func NewHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *HistoryLo
 	}
 }
 
-// History 返回模板历史。
+// History 返回模板历史，附带各版本的下载统计。
 func (l *HistoryLogic) History(req *types.AdminSubscriptionTemplateHistoryRequest) (*types.AdminSubscriptionTemplateHistoryResponse, error) {
 	history, err := l.svcCtx.Repositories.SubscriptionTemplate.History(l.ctx, req.TemplateID)
 	if err != nil {
 		return nil, err
 	}
 
+	usage, err := l.svcCtx.Repositories.SubscriptionTemplate.Usage(l.ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint32]repository.SubscriptionTemplateUsage, len(usage))
+	for _, u := range usage {
+		byVersion[u.Version] = u
+	}
+
 	entries := make([]types.SubscriptionTemplateHistoryEntry, 0, len(history))
 	for _, h := range history {
-		entries = append(entries, toHistoryEntry(h))
+		entry := toHistoryEntry(h)
+		if u, ok := byVersion[h.Version]; ok {
+			entry.Downloads = u.Downloads
+			entry.LastUsedAt = u.LastUsedAt.Unix()
+		}
+		entries = append(entries, entry)
 	}
 
 	return &types.AdminSubscriptionTemplateHistoryResponse{
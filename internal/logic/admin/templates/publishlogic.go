@@ -28,20 +28,23 @@ func NewPublishLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PublishLo
 	}
 }
 
-// Publish 执行发布。
+// Publish 执行发布；草稿未通过校验时拒绝发布，除非指定 force。
 func (l *PublishLogic) Publish(req *types.AdminPublishSubscriptionTemplateRequest) (*types.AdminPublishSubscriptionTemplateResponse, error) {
-	operator := strings.TrimSpace(req.Operator)
-	if operator == "" {
-		if user, ok := security.UserFromContext(l.ctx); ok {
-			operator = strings.TrimSpace(user.DisplayName)
-			if operator == "" {
-				operator = strings.TrimSpace(user.Email)
-			}
+	if !req.Force {
+		draft, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		report, err := validateTemplate(l.ctx, l.svcCtx, draft, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := validationFailure(report); err != nil {
+			return nil, err
 		}
 	}
-	if operator == "" {
-		operator = "system"
-	}
+
+	operator := resolveOperator(l.ctx, req.Operator)
 
 	input := repository.PublishSubscriptionTemplateInput{
 		Changelog: strings.TrimSpace(req.Changelog),
@@ -56,10 +59,27 @@ func (l *PublishLogic) Publish(req *types.AdminPublishSubscriptionTemplateReques
 	summary := toTemplateSummary(tpl)
 	historyEntry := toHistoryEntry(history)
 
-	l.Infof("audit: template publish template_id=%d operator=%s changelog=%s", tpl.ID, operator, strings.TrimSpace(req.Changelog))
+	l.Infof("audit: template publish template_id=%d operator=%s changelog=%s force=%t", tpl.ID, operator, strings.TrimSpace(req.Changelog), req.Force)
 
 	return &types.AdminPublishSubscriptionTemplateResponse{
 		Template: summary,
 		History:  historyEntry,
 	}, nil
 }
+
+// resolveOperator 返回请求中的操作人，未提供时取当前管理员，均缺失时为 system。
+func resolveOperator(ctx context.Context, operator string) string {
+	operator = strings.TrimSpace(operator)
+	if operator == "" {
+		if user, ok := security.UserFromContext(ctx); ok {
+			operator = strings.TrimSpace(user.DisplayName)
+			if operator == "" {
+				operator = strings.TrimSpace(user.Email)
+			}
+		}
+	}
+	if operator == "" {
+		operator = "system"
+	}
+	return operator
+}
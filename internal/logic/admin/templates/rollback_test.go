@@ -0,0 +1,94 @@
+package templates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestRollbackDiffAndUsage(t *testing.T) {
+	ctx := context.Background()
+	svcCtx, cleanup := setupTemplateTestContext(t)
+	defer cleanup()
+	repos := svcCtx.Repositories
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "rollback",
+		ClientType: "generic",
+		Format:     "text",
+		Content:    "a\nb\nc\n",
+	})
+	require.NoError(t, err)
+
+	publish := NewPublishLogic(ctx, svcCtx)
+	v1, err := publish.Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: tpl.ID, Changelog: "first"})
+	require.NoError(t, err)
+
+	content := "a\nx\nc\n"
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{
+		Content:   &content,
+		Variables: map[string]repository.TemplateVariable{"region": {ValueType: "string"}},
+	})
+	require.NoError(t, err)
+	v2, err := publish.Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: tpl.ID, Changelog: "second", Force: true})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	require.NoError(t, repos.SubscriptionTemplate.RecordUsage(ctx, tpl.ID, v1.History.Version, now))
+	require.NoError(t, repos.SubscriptionTemplate.RecordUsage(ctx, tpl.ID, v1.History.Version, now))
+	require.NoError(t, repos.SubscriptionTemplate.RecordUsage(ctx, tpl.ID, v2.History.Version, now))
+
+	diff, err := NewDiffLogic(ctx, svcCtx).Diff(&types.AdminSubscriptionTemplateDiffRequest{
+		TemplateID: tpl.ID,
+		From:       v1.History.Version,
+		To:         v2.History.Version,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []types.TemplateDiffLine{
+		{Op: diffOpEqual, OldLine: 1, NewLine: 1, Text: "a"},
+		{Op: diffOpDelete, OldLine: 2, Text: "b"},
+		{Op: diffOpInsert, NewLine: 2, Text: "x"},
+		{Op: diffOpEqual, OldLine: 3, NewLine: 3, Text: "c"},
+	}, diff.Content)
+	require.NotEmpty(t, diff.Variables)
+	require.Equal(t, diffOpInsert, diff.Variables[0].Op)
+	require.Equal(t, 1+len(diff.Variables), diff.Added)
+	require.Equal(t, 1, diff.Removed)
+
+	rollback := NewRollbackLogic(ctx, svcCtx)
+	_, err = rollback.Rollback(&types.AdminRollbackSubscriptionTemplateRequest{TemplateID: tpl.ID, Version: 99})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	draft := "a\ny\nc\n"
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{Content: &draft})
+	require.NoError(t, err)
+	_, err = rollback.Rollback(&types.AdminRollbackSubscriptionTemplateRequest{TemplateID: tpl.ID, Version: v1.History.Version})
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	restored, err := rollback.Rollback(&types.AdminRollbackSubscriptionTemplateRequest{TemplateID: tpl.ID, Version: v1.History.Version, DiscardDraft: true})
+	require.NoError(t, err)
+	require.Equal(t, v2.History.Version+1, restored.Template.Version)
+	require.Equal(t, "a\nb\nc\n", restored.Template.Content)
+	require.Empty(t, restored.Template.Variables)
+	require.Equal(t, "rollback to version 1", restored.History.Changelog)
+
+	logs, _, err := repos.AuditLog.List(ctx, repository.AuditLogListOptions{Action: "admin.subscription_template.rollback"})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.EqualValues(t, v1.History.Version, logs[0].Metadata["restored_version"])
+	require.Equal(t, true, logs[0].Metadata["discard_draft"])
+
+	history, err := NewHistoryLogic(ctx, svcCtx).History(&types.AdminSubscriptionTemplateHistoryRequest{TemplateID: tpl.ID})
+	require.NoError(t, err)
+	require.Len(t, history.History, 3)
+	downloads := map[uint32]int64{}
+	for _, entry := range history.History {
+		downloads[entry.Version] = entry.Downloads
+	}
+	require.Equal(t, map[uint32]int64{1: 2, 2: 1, 3: 0}, downloads)
+}
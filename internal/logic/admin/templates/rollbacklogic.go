@@ -0,0 +1,96 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// RollbackLogic 回滚模板到历史版本。
+type RollbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRollbackLogic 构造函数。
+func NewRollbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RollbackLogic {
+	return &RollbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Rollback 将历史版本恢复为草稿并作为新版本发布，同时写入审计日志。
+// 与发布一致，恢复的快照未通过校验时拒绝回滚，除非指定 force；
+// 草稿存在未发布修改时拒绝回滚，除非指定 discard_draft。
+func (l *RollbackLogic) Rollback(req *types.AdminRollbackSubscriptionTemplateRequest) (*types.AdminPublishSubscriptionTemplateResponse, error) {
+	if req.Version == 0 {
+		return nil, repository.NewInvalidArgument("version is required")
+	}
+
+	current, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := l.svcCtx.Repositories.SubscriptionTemplate.GetHistory(l.ctx, req.TemplateID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Force {
+		restored := current
+		restored.Content = snapshot.Content
+		restored.Format = snapshot.Format
+		restored.Variables = snapshot.Variables
+		report, err := validateTemplate(l.ctx, l.svcCtx, restored, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := validationFailure(report); err != nil {
+			return nil, err
+		}
+	}
+
+	operator := resolveOperator(l.ctx, req.Operator)
+	var (
+		tpl     repository.SubscriptionTemplate
+		history repository.SubscriptionTemplateHistory
+	)
+	err = l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		var err error
+		tpl, history, err = txRepos.SubscriptionTemplate.Rollback(l.ctx, req.TemplateID, req.Version, repository.RollbackSubscriptionTemplateInput{
+			Changelog:    strings.TrimSpace(req.Changelog),
+			Operator:     operator,
+			DiscardDraft: req.DiscardDraft,
+		})
+		if err != nil {
+			return err
+		}
+		return auditutil.Record(l.ctx, txRepos, "admin.subscription_template.rollback", "subscription_template", fmt.Sprintf("%d", tpl.ID), map[string]any{
+			"previous_version": current.Version,
+			"restored_version": req.Version,
+			"new_version":      history.Version,
+			"changelog":        history.Changelog,
+			"operator":         operator,
+			"force":            req.Force,
+			"discard_draft":    req.DiscardDraft,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AdminPublishSubscriptionTemplateResponse{
+		Template: toTemplateSummary(tpl),
+		History:  toHistoryEntry(history),
+	}, nil
+}
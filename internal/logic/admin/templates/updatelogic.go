@@ -28,6 +28,23 @@ func NewUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateLogi
 
 // Update 执行更新操作。
 func (l *UpdateLogic) Update(req *types.AdminUpdateSubscriptionTemplateRequest) (*types.SubscriptionTemplateSummary, error) {
+	if req.Format != nil || req.Content != nil {
+		current, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		format, content := current.Format, current.Content
+		if req.Format != nil {
+			format = *req.Format
+		}
+		if req.Content != nil {
+			content = *req.Content
+		}
+		if err := requireTemplateContent(format, content); err != nil {
+			return nil, err
+		}
+	}
+
 	input := repository.UpdateSubscriptionTemplateInput{
 		Name:        req.Name,
 		Description: req.Description,
@@ -0,0 +1,49 @@
+package templates
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ValidateLogic 校验模板并试渲染。
+type ValidateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewValidateLogic 构造函数。
+func NewValidateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ValidateLogic {
+	return &ValidateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Validate 校验当前草稿，请求中提供的格式、内容与变量会覆盖草稿，便于保存前预检。
+func (l *ValidateLogic) Validate(req *types.AdminValidateSubscriptionTemplateRequest) (*types.AdminValidateSubscriptionTemplateResponse, error) {
+	tpl, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Format != nil {
+		tpl.Format = strings.ToLower(strings.TrimSpace(*req.Format))
+		if tpl.Format == "" {
+			tpl.Format = "text"
+		}
+	}
+	if req.Content != nil {
+		tpl.Content = *req.Content
+	}
+	if req.Variables != nil {
+		tpl.Variables = toRepositoryVariables(req.Variables)
+	}
+
+	return validateTemplate(l.ctx, l.svcCtx, tpl, req.SubscriptionIDs, req.SampleSize)
+}
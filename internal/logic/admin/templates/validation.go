@@ -0,0 +1,357 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/credentialutil"
+	subscriptionutil "github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// 校验阶段。
+const (
+	validationStageParse     = "parse"
+	validationStageVariables = "variables"
+	validationStageRender    = "render"
+	validationStageOutput    = "output"
+)
+
+// 试渲染来源。
+const (
+	dryRunSourceSynthetic    = "synthetic"
+	dryRunSourceSubscription = "subscription"
+)
+
+const (
+	defaultValidationSamples = 3
+	maxValidationSamples     = 10
+	dryRunPreviewLimit       = 2048
+)
+
+// requireTemplateContent 要求模板内容非空；uri-list 格式由面板直接生成分享链接，无需模板内容。
+func requireTemplateContent(format, content string) error {
+	if strings.TrimSpace(content) == "" && strings.ToLower(strings.TrimSpace(format)) != subtemplate.FormatURIList {
+		return repository.ErrInvalidArgument
+	}
+	return nil
+}
+
+// validateTemplate 解析模板并检查变量引用，随后分别基于合成上下文与真实的有效订阅试渲染，
+// 对 json/yaml 格式同时校验输出文档。试渲染只读取数据，不会为缺少凭据的用户创建凭据。
+func validateTemplate(ctx context.Context, svcCtx *svc.ServiceContext, tpl repository.SubscriptionTemplate, subscriptionIDs []uint64, sampleSize int) (*types.AdminValidateSubscriptionTemplateResponse, error) {
+	resp := &types.AdminValidateSubscriptionTemplateResponse{
+		TemplateID: tpl.ID,
+		Errors:     []types.TemplateValidationIssue{},
+		Warnings:   []types.TemplateValidationIssue{},
+		DryRuns:    []types.TemplateDryRunResult{},
+	}
+
+	if err := subtemplate.Parse(tpl.Content); err != nil {
+		line, column := subtemplate.ErrorLocation(err)
+		resp.Errors = append(resp.Errors, types.TemplateValidationIssue{
+			Stage:   validationStageParse,
+			Message: err.Error(),
+			Line:    line,
+			Column:  column,
+		})
+		return resp, nil
+	}
+
+	references, err := subtemplate.References(tpl.Content)
+	if err != nil {
+		return nil, err
+	}
+	lintReferences(resp, tpl.Variables, references)
+
+	now := time.Now().UTC()
+	sub, identity := sampleSubscription(now)
+	nodes := subscriptionutil.EntryContext(subscriptionutil.ArrangeEntries(sampleEntries(now), subscriptionutil.EntryHealthPolicy{Mode: subscriptionutil.UnhealthyEntryPolicyShow}))
+	dryRun(resp, tpl, dryRunSourceSynthetic, 0, subscriptionutil.RenderData(sub, tpl, nodes, identity, now))
+
+	subscriptions, err := loadValidationSubscriptions(ctx, svcCtx, tpl.ID, subscriptionIDs, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) > 0 {
+		policy, err := loadEntryHealthPolicy(ctx, svcCtx)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subscriptions {
+			entries, err := subscriptionutil.LoadSubscriptionEntries(ctx, svcCtx.Repositories, sub)
+			if err != nil {
+				return nil, err
+			}
+			userIdentity, err := subscriptionIdentity(ctx, svcCtx, sub.UserID, identity)
+			if err != nil {
+				return nil, err
+			}
+			nodes := subscriptionutil.EntryContext(subscriptionutil.ArrangeEntries(entries, policy))
+			data := subscriptionutil.RenderData(sub, tpl, nodes, userIdentity, now)
+			dryRun(resp, tpl, dryRunSourceSubscription, sub.ID, data)
+		}
+	}
+
+	resp.Valid = len(resp.Errors) == 0
+	return resp, nil
+}
+
+// validationFailure 将未通过的校验结果转换为 400 错误，通过时返回 nil。
+func validationFailure(report *types.AdminValidateSubscriptionTemplateResponse) error {
+	if report.Valid {
+		return nil
+	}
+	issue := report.Errors[0]
+	if issue.Line > 0 {
+		return repository.InvalidArgumentf("template validation failed: %s (line %d)", issue.Message, issue.Line)
+	}
+	return repository.InvalidArgumentf("template validation failed: %s", issue.Message)
+}
+
+// lintReferences 检查根字段与 variables 引用：未知根字段与未声明的变量视为错误，
+// 声明但未引用的变量以及必填却无默认值的变量视为警告。
+func lintReferences(resp *types.AdminValidateSubscriptionTemplateResponse, variables map[string]repository.TemplateVariable, references []subtemplate.Reference) {
+	roots := make(map[string]struct{}, len(subscriptionutil.RenderRoots))
+	for _, root := range subscriptionutil.RenderRoots {
+		roots[root] = struct{}{}
+	}
+
+	used := make(map[string]struct{})
+	wholeVariables := false
+	for _, ref := range references {
+		root := ref.Path[0]
+		if _, ok := roots[root]; !ok {
+			resp.Errors = append(resp.Errors, types.TemplateValidationIssue{
+				Stage:   validationStageVariables,
+				Message: fmt.Sprintf("unknown field .%s", strings.Join(ref.Path, ".")),
+				Line:    ref.Line,
+				Column:  ref.Column,
+			})
+			continue
+		}
+		if root != "variables" {
+			continue
+		}
+		if len(ref.Path) < 2 {
+			wholeVariables = true
+			continue
+		}
+		name := ref.Path[1]
+		if _, ok := variables[name]; !ok {
+			resp.Errors = append(resp.Errors, types.TemplateValidationIssue{
+				Stage:   validationStageVariables,
+				Message: fmt.Sprintf("variable %q is not declared", name),
+				Line:    ref.Line,
+				Column:  ref.Column,
+			})
+			continue
+		}
+		used[name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := used[name]; !ok && !wholeVariables {
+			resp.Warnings = append(resp.Warnings, types.TemplateValidationIssue{
+				Stage:   validationStageVariables,
+				Message: fmt.Sprintf("variable %q is declared but not used", name),
+			})
+		}
+		if variable := variables[name]; variable.Required && variable.DefaultValue == nil {
+			resp.Warnings = append(resp.Warnings, types.TemplateValidationIssue{
+				Stage:   validationStageVariables,
+				Message: fmt.Sprintf("required variable %q has no default value", name),
+			})
+		}
+	}
+}
+
+// dryRun 渲染一次并记录结果；渲染失败同时记为错误。
+func dryRun(resp *types.AdminValidateSubscriptionTemplateResponse, tpl repository.SubscriptionTemplate, source string, subscriptionID uint64, data map[string]any) {
+	result := types.TemplateDryRunResult{Source: source, SubscriptionID: subscriptionID}
+
+	content, err := subtemplate.Render(tpl.Format, tpl.Content, data)
+	if err != nil {
+		stage := validationStageRender
+		var outputErr *subtemplate.OutputError
+		if errors.As(err, &outputErr) {
+			stage = validationStageOutput
+		}
+		line, column := subtemplate.ErrorLocation(err)
+		resp.Errors = append(resp.Errors, types.TemplateValidationIssue{
+			Stage:          stage,
+			Message:        err.Error(),
+			Line:           line,
+			Column:         column,
+			SubscriptionID: result.SubscriptionID,
+		})
+		result.Error = err.Error()
+		resp.DryRuns = append(resp.DryRuns, result)
+		return
+	}
+
+	result.OK = true
+	result.OutputBytes = len(content)
+	result.Preview = content
+	if len(content) > dryRunPreviewLimit {
+		cut := dryRunPreviewLimit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		result.Preview = content[:cut]
+		result.Truncated = true
+	}
+	resp.DryRuns = append(resp.DryRuns, result)
+}
+
+// loadValidationSubscriptions 返回指定的订阅；未指定时优先选取使用该模板的有效订阅，
+// 没有则选取任意有效订阅。
+func loadValidationSubscriptions(ctx context.Context, svcCtx *svc.ServiceContext, templateID uint64, ids []uint64, sampleSize int) ([]repository.Subscription, error) {
+	if sampleSize <= 0 {
+		sampleSize = defaultValidationSamples
+	}
+	if sampleSize > maxValidationSamples {
+		sampleSize = maxValidationSamples
+	}
+
+	if len(ids) > 0 {
+		if len(ids) > maxValidationSamples {
+			return nil, repository.InvalidArgumentf("at most %d subscriptions can be validated", maxValidationSamples)
+		}
+		subscriptions := make([]repository.Subscription, 0, len(ids))
+		for _, id := range ids {
+			sub, err := svcCtx.Repositories.Subscription.Get(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			subscriptions = append(subscriptions, sub)
+		}
+		return subscriptions, nil
+	}
+
+	opts := repository.ListSubscriptionsOptions{
+		PerPage:    sampleSize,
+		Status:     status.SubscriptionStatusActive,
+		TemplateID: templateID,
+	}
+	subscriptions, _, err := svcCtx.Repositories.Subscription.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) > 0 {
+		return subscriptions, nil
+	}
+	opts.TemplateID = 0
+	subscriptions, _, err = svcCtx.Repositories.Subscription.List(ctx, opts)
+	return subscriptions, err
+}
+
+// subscriptionIdentity 读取用户当前凭据；用户尚无凭据时使用合成身份。
+func subscriptionIdentity(ctx context.Context, svcCtx *svc.ServiceContext, userID uint64, fallback map[string]any) (map[string]any, error) {
+	credential, err := svcCtx.Repositories.UserCredential.GetActiveByUser(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fallback, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	identity, err := credentialutil.BuildIdentity(svcCtx.Credentials, userID, credential)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptionutil.IdentityContext(credential, identity), nil
+}
+
+func loadEntryHealthPolicy(ctx context.Context, svcCtx *svc.ServiceContext) (subscriptionutil.EntryHealthPolicy, error) {
+	defaults := repository.SiteSettingDefaults{
+		Name:    svcCtx.Config.Site.Name,
+		LogoURL: svcCtx.Config.Site.LogoURL,
+	}
+	setting, err := svcCtx.Repositories.Site.GetSiteSetting(ctx, defaults)
+	if err != nil {
+		return subscriptionutil.EntryHealthPolicy{}, err
+	}
+	return subscriptionutil.PolicyFromSite(setting), nil
+}
+
+// sampleSubscription 构造合成订阅与身份。
+func sampleSubscription(now time.Time) (repository.Subscription, map[string]any) {
+	sub := repository.Subscription{
+		Name:              "Sample Subscription",
+		PlanName:          "Sample Plan",
+		PlanSnapshot:      map[string]any{"name": "Sample Plan"},
+		Status:            status.SubscriptionStatusActive,
+		Token:             "sample-token",
+		ExpiresAt:         now.Add(30 * 24 * time.Hour),
+		TrafficTotalBytes: 100 << 30,
+		TrafficUsedBytes:  10 << 30,
+		DevicesLimit:      3,
+	}
+	identity := map[string]any{
+		"version":    1,
+		"status":     status.UserCredentialStatusActive,
+		"account_id": "sample-account",
+		"account":    "sample-account",
+		"password":   "sample-password",
+		"id":         "00000000-0000-4000-8000-000000000000",
+		"uuid":       "00000000-0000-4000-8000-000000000000",
+		"username":   "sample-user",
+		"secret":     "sample-secret",
+	}
+	return sub, identity
+}
+
+// sampleEntries 构造覆盖常见协议的合成入口。
+func sampleEntries(now time.Time) []repository.ProtocolEntry {
+	node := repository.Node{Name: "Sample Node", Region: "sample", Country: "ZZ", AccessAddress: "node.example.com", CapacityMbps: 1000}
+	samples := []struct {
+		protocol string
+		port     int
+		profile  map[string]any
+	}{
+		{"vless", 443, map[string]any{"security": "reality", "flow": "xtls-rprx-vision", "sni": "www.example.com", "reality": map[string]any{"public_key": "sample-public-key", "short_id": "01"}}},
+		{"vmess", 8443, map[string]any{"security": "tls", "network": "ws", "path": "/ws", "sni": "node.example.com"}},
+		{"trojan", 9443, map[string]any{"sni": "node.example.com"}},
+		{"shadowsocks", 8388, map[string]any{"cipher": "aes-128-gcm"}},
+		{"hysteria2", 10443, map[string]any{"sni": "node.example.com"}},
+	}
+
+	entries := make([]repository.ProtocolEntry, 0, len(samples))
+	for i, sample := range samples {
+		id := uint64(i + 1)
+		entries = append(entries, repository.ProtocolEntry{
+			ID:           id,
+			Name:         fmt.Sprintf("Sample %s", sample.protocol),
+			BindingID:    id,
+			Protocol:     sample.protocol,
+			Status:       status.ProtocolEntryStatusActive,
+			EntryAddress: node.AccessAddress,
+			EntryPort:    sample.port,
+			Profile:      sample.profile,
+			UpdatedAt:    now,
+			Binding: repository.ProtocolBinding{
+				ID:           id,
+				Protocol:     sample.protocol,
+				Role:         "listener",
+				Status:       status.ProtocolBindingStatusActive,
+				HealthStatus: status.ProtocolBindingHealthStatusHealthy,
+				Node:         node,
+			},
+		})
+	}
+	return entries
+}
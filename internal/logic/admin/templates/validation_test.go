@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestValidateAndPublishTemplate(t *testing.T) {
+	ctx := context.Background()
+	svcCtx, cleanup := setupTemplateTestContext(t)
+	defer cleanup()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "validation",
+		ClientType: "clash",
+		Format:     "yaml",
+		Content:    "region: {{ .variables.region }}\nproxies:\n{{- range .nodes }}\n  - {{ .name }}\n{{- end }}\n",
+		Variables: map[string]repository.TemplateVariable{
+			"region": {ValueType: "string", DefaultValue: "hk"},
+			"unused": {ValueType: "string"},
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	sub := repository.Subscription{
+		UserID:     1,
+		Name:       "active",
+		PlanID:     1,
+		Status:     status.SubscriptionStatusActive,
+		TemplateID: tpl.ID,
+		Token:      "validation-token",
+		ExpiresAt:  now.Add(24 * time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	require.NoError(t, db.Create(&sub).Error)
+
+	validate := NewValidateLogic(ctx, svcCtx)
+	resp, err := validate.Validate(&types.AdminValidateSubscriptionTemplateRequest{TemplateID: tpl.ID})
+	require.NoError(t, err)
+	require.True(t, resp.Valid, "%+v", resp.Errors)
+	require.Len(t, resp.Warnings, 1)
+	require.Contains(t, resp.Warnings[0].Message, `"unused"`)
+	require.Len(t, resp.DryRuns, 2)
+	require.Equal(t, dryRunSourceSynthetic, resp.DryRuns[0].Source)
+	require.True(t, resp.DryRuns[0].OK)
+	require.Equal(t, sub.ID, resp.DryRuns[1].SubscriptionID)
+	require.True(t, resp.DryRuns[1].OK)
+	require.Contains(t, resp.DryRuns[0].Preview, "region: hk")
+	require.Contains(t, resp.DryRuns[0].Preview, "Sample vless")
+
+	content := "name: {{ .variables.missing }}\nplan: {{ .plan }}\n"
+	resp, err = validate.Validate(&types.AdminValidateSubscriptionTemplateRequest{TemplateID: tpl.ID, Content: &content})
+	require.NoError(t, err)
+	require.False(t, resp.Valid)
+	require.Len(t, resp.Errors, 2)
+	require.Equal(t, validationStageVariables, resp.Errors[0].Stage)
+	require.Contains(t, resp.Errors[0].Message, `"missing"`)
+	require.Equal(t, 1, resp.Errors[0].Line)
+	require.Contains(t, resp.Errors[1].Message, "unknown field .plan")
+	require.Equal(t, 2, resp.Errors[1].Line)
+
+	content = "region: {{ .variables.region }}\nproxies: [\n"
+	resp, err = validate.Validate(&types.AdminValidateSubscriptionTemplateRequest{TemplateID: tpl.ID, Content: &content})
+	require.NoError(t, err)
+	require.False(t, resp.Valid)
+	require.Equal(t, validationStageOutput, resp.Errors[0].Stage)
+	require.False(t, resp.DryRuns[0].OK)
+
+	content = "{{ if .nodes }}\n{{ .nodes"
+	resp, err = validate.Validate(&types.AdminValidateSubscriptionTemplateRequest{TemplateID: tpl.ID, Content: &content})
+	require.NoError(t, err)
+	require.False(t, resp.Valid)
+	require.Equal(t, validationStageParse, resp.Errors[0].Stage)
+	require.Equal(t, 2, resp.Errors[0].Line)
+	require.Empty(t, resp.DryRuns)
+
+	broken := "region: {{ .variables.region }}\nproxies: [\n"
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{Content: &broken})
+	require.NoError(t, err)
+
+	publish := NewPublishLogic(ctx, svcCtx)
+	_, err = publish.Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: tpl.ID})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, repository.ErrInvalidArgument))
+
+	published, err := publish.Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: tpl.ID, Force: true})
+	require.NoError(t, err)
+	require.Equal(t, tpl.Version+1, published.Template.Version)
+}
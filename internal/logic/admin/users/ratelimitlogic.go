@@ -0,0 +1,141 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/auditutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// RateLimitLogic manages per-user speed limit overrides. Changes are queued
+// for every binding the user is on so the reconciler pushes the new rate to
+// the kernels.
+type RateLimitLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRateLimitLogic constructs RateLimitLogic.
+func NewRateLimitLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RateLimitLogic {
+	return &RateLimitLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Get returns the user's active override, if any.
+func (l *RateLimitLogic) Get(req *types.AdminGetUserRateLimitRequest) (*types.AdminUserRateLimitResponse, error) {
+	if _, err := l.svcCtx.Repositories.User.Get(l.ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	limit, err := l.svcCtx.Repositories.UserRateLimit.Get(l.ctx, req.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &types.AdminUserRateLimitResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !limit.Active(time.Now().UTC()) {
+		return &types.AdminUserRateLimitResponse{}, nil
+	}
+	return &types.AdminUserRateLimitResponse{RateLimit: toUserRateLimitSummary(limit)}, nil
+}
+
+// Set creates or replaces the user's override.
+func (l *RateLimitLogic) Set(req *types.AdminSetUserRateLimitRequest) (*types.AdminUserRateLimitResponse, error) {
+	if req.UploadRateBytes < 0 || req.DownloadRateBytes < 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+	now := time.Now().UTC()
+	var expiresAt *time.Time
+	if req.ExpiresAt > 0 {
+		value := time.Unix(req.ExpiresAt, 0).UTC()
+		if !value.After(now) {
+			return nil, repository.ErrInvalidArgument
+		}
+		expiresAt = &value
+	}
+
+	actor, _ := security.UserFromContext(l.ctx)
+	var saved repository.UserRateLimit
+	if err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		if _, err := txRepos.User.Get(l.ctx, req.UserID); err != nil {
+			return err
+		}
+		limit, err := txRepos.UserRateLimit.Upsert(l.ctx, repository.UserRateLimit{
+			UserID:            req.UserID,
+			UploadRateBytes:   req.UploadRateBytes,
+			DownloadRateBytes: req.DownloadRateBytes,
+			Reason:            req.Reason,
+			ExpiresAt:         expiresAt,
+			CreatedBy:         actor.Email,
+		})
+		if err != nil {
+			return err
+		}
+		saved = limit
+
+		metadata := map[string]any{
+			"upload_rate_bytes":   limit.UploadRateBytes,
+			"download_rate_bytes": limit.DownloadRateBytes,
+			"reason":              limit.Reason,
+		}
+		if limit.ExpiresAt != nil {
+			metadata["expires_at"] = limit.ExpiresAt.Unix()
+		}
+		if err := auditutil.Record(l.ctx, txRepos, "admin.user.rate_limit.set", "user", fmt.Sprintf("%d", req.UserID), metadata); err != nil {
+			return err
+		}
+		return subscriptionutil.RequestUserBindingSync(l.ctx, txRepos, req.UserID, subscriptionutil.BindingSyncReasonRateLimitUpdate)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &types.AdminUserRateLimitResponse{RateLimit: toUserRateLimitSummary(saved)}, nil
+}
+
+// Delete removes the override so plan limits apply again.
+func (l *RateLimitLogic) Delete(req *types.AdminDeleteUserRateLimitRequest) (*types.AdminUserRateLimitResponse, error) {
+	if err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		if err := txRepos.UserRateLimit.Delete(l.ctx, req.UserID); err != nil {
+			return err
+		}
+		if err := auditutil.Record(l.ctx, txRepos, "admin.user.rate_limit.clear", "user", fmt.Sprintf("%d", req.UserID), nil); err != nil {
+			return err
+		}
+		return subscriptionutil.RequestUserBindingSync(l.ctx, txRepos, req.UserID, subscriptionutil.BindingSyncReasonRateLimitClear)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &types.AdminUserRateLimitResponse{}, nil
+}
+
+func toUserRateLimitSummary(limit repository.UserRateLimit) *types.UserRateLimitSummary {
+	summary := &types.UserRateLimitSummary{
+		UserID:            limit.UserID,
+		UploadRateBytes:   limit.UploadRateBytes,
+		DownloadRateBytes: limit.DownloadRateBytes,
+		Reason:            limit.Reason,
+		CreatedBy:         limit.CreatedBy,
+		CreatedAt:         limit.CreatedAt.Unix(),
+		UpdatedAt:         limit.UpdatedAt.Unix(),
+	}
+	if limit.ExpiresAt != nil {
+		expiresAt := limit.ExpiresAt.Unix()
+		summary.ExpiresAt = &expiresAt
+	}
+	return summary
+}
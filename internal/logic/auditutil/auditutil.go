@@ -0,0 +1,28 @@
+package auditutil
+
+import (
+	"context"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+)
+
+// Record writes an audit entry attributed to the user in ctx. Pass the
+// transaction's repositories to commit the entry together with the change.
+func Record(ctx context.Context, repos *repository.Repositories, action, resourceType, resourceID string, metadata map[string]any) error {
+	entry := repository.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Metadata:     metadata,
+	}
+	if actor, ok := security.UserFromContext(ctx); ok {
+		if actor.ID != 0 {
+			entry.ActorID = &actor.ID
+		}
+		entry.ActorEmail = actor.Email
+		entry.ActorRoles = actor.Roles
+	}
+	_, err := repos.AuditLog.Create(ctx, entry)
+	return err
+}
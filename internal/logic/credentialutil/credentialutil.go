@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/internal/status"
@@ -76,7 +77,10 @@ func RotateCredential(ctx context.Context, repos *repository.Repositories, manag
 		newCredential.RotatedFromID = rotatedFrom
 
 		created, err = txRepos.UserCredential.Create(ctx, newCredential)
-		return err
+		if err != nil {
+			return err
+		}
+		return subscriptionutil.RequestUserBindingSync(ctx, txRepos, userID, subscriptionutil.BindingSyncReasonCredentialRotate)
 	})
 	if err != nil {
 		return repository.UserCredential{}, err
@@ -0,0 +1,296 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const (
+	auditCollectInterval = 60 * time.Second
+	auditPageSize        = 500
+	auditMaxPages        = 10
+	// auditInitialLookback bounds the first pull from a node without a cursor.
+	auditInitialLookback = 24 * time.Hour
+)
+
+// RunAuditCollector pulls kernel audit records into the panel audit log.
+// Nodes sharing a control endpoint are pulled once; their records are kept
+// under the node with the lowest id, and every node of the group carries the
+// same (since_ms, record id) cursor. The kernel audit sink health is sampled
+// on every pass so failures surface on the node status.
+func RunAuditCollector(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(auditCollectInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := collectAudit(ctx, svcCtx); err != nil {
+			logger.Errorf("kernel audit collection failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func collectAudit(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	nodes, err := svcCtx.Repositories.Node.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	// Group owners must not change with node updates.
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	groups := make(map[controlKey][]repository.Node)
+	var keys []controlKey
+	for _, node := range nodes {
+		if !isAuditCollectEligible(node) {
+			continue
+		}
+		key := controlKey{
+			endpoint: strings.TrimSpace(node.ControlEndpoint),
+			token:    resolveControlToken(node),
+			timeout:  resolveKernelHTTPTimeout(node),
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], node)
+	}
+
+	for _, key := range keys {
+		if err := collectKernelAudit(ctx, svcCtx, key, groups[key]); err != nil {
+			logx.WithContext(ctx).Errorf("kernel audit collection failed for node %d: %v", groups[key][0].ID, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// isAuditCollectEligible reports whether audit records are pulled from the
+// node kernel. Offline kernels are left to the offline probe.
+func isAuditCollectEligible(node repository.Node) bool {
+	if node.Status == status.NodeStatusDisabled || node.Status == status.NodeStatusOffline {
+		return false
+	}
+	return strings.TrimSpace(node.ControlEndpoint) != ""
+}
+
+// collectKernelAudit pulls one kernel on behalf of the nodes behind its
+// endpoint. The pull resumes from the furthest cursor of the group, so a
+// change of the first node does not import the same records again.
+func collectKernelAudit(ctx context.Context, svcCtx *svc.ServiceContext, key controlKey, nodes []repository.Node) error {
+	repos := svcCtx.Repositories
+	owner := nodes[0]
+	nodeIDs := make([]uint64, 0, len(nodes))
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	existing, err := repos.KernelAuditCursor.ListByNodeIDs(ctx, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	cursor := repository.KernelAuditCursor{SinceMS: time.Now().Add(-auditInitialLookback).UnixMilli()}
+	saved := make(map[uint64]repository.KernelAuditCursor, len(existing))
+	for i, item := range existing {
+		saved[item.NodeID] = item
+		if i == 0 || auditRecordAfter(item.SinceMS, item.LastID, cursor) {
+			cursor = item
+		}
+	}
+
+	client, err := kernel.NewControlClient(svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
+		BaseURL: key.endpoint,
+		Token:   key.token,
+		Timeout: key.timeout,
+	}))
+	if err != nil {
+		return err
+	}
+
+	pullErr := pullNodeAudit(ctx, repos, client, owner, &cursor)
+	now := time.Now().UTC()
+	cursor.PulledAt = &now
+	cursor.PullError = ""
+	if pullErr != nil {
+		cursor.PullError = pullErr.Error()
+	}
+
+	health, healthErr := client.GetAuditHealth(ctx)
+	if healthErr != nil {
+		cursor.SinkHealthy = false
+		cursor.SinkLastError = healthErr.Error()
+	} else {
+		applyAuditHealth(&cursor, health)
+	}
+	cursor.SinkCheckedAt = &now
+
+	for _, nodeID := range nodeIDs {
+		item := cursor
+		item.ID = saved[nodeID].ID
+		item.NodeID = nodeID
+		item.CreatedAt = saved[nodeID].CreatedAt
+		if _, err := repos.KernelAuditCursor.Save(ctx, item); err != nil {
+			return err
+		}
+	}
+	if pullErr != nil {
+		return pullErr
+	}
+	return healthErr
+}
+
+// pullNodeAudit pages through /v1/audit starting at the cursor. The kernel only
+// filters by since_ms, so records at the cursor timestamp come back again; the
+// record id (time-ordered, like the UUIDv7 ids in core.yaml) breaks the tie and
+// ImportExternal drops any duplicate. core.yaml
+// does not define the order of the response, so each page is sorted here.
+//
+// Two limits of the API are handled explicitly:
+//   - a full page with nothing past the cursor means more than auditPageSize
+//     records share one millisecond; the cursor moves past it so ingest cannot
+//     stall, and the skipped remainder is reported.
+//   - a full page returned newest first means the kernel truncated the oldest
+//     records; they cannot be requested again and the gap is reported.
+func pullNodeAudit(ctx context.Context, repos *repository.Repositories, client *kernel.ControlClient, node repository.Node, cursor *repository.KernelAuditCursor) error {
+	var warnings []string
+	for page := 0; page < auditMaxPages; page++ {
+		records, err := client.ListAudit(ctx, kernel.AuditQuery{Limit: auditPageSize, SinceMS: cursor.SinceMS})
+		if err != nil {
+			return err
+		}
+		full := len(records) >= auditPageSize
+		if full && records[0].TimestampMS > records[len(records)-1].TimestampMS {
+			warnings = append(warnings, fmt.Sprintf("kernel returned the newest %d audit records first; records from %d ms up to %d ms may be missing",
+				len(records), cursor.SinceMS, records[len(records)-1].TimestampMS))
+		}
+		sort.SliceStable(records, func(i, j int) bool {
+			if records[i].TimestampMS != records[j].TimestampMS {
+				return records[i].TimestampMS < records[j].TimestampMS
+			}
+			return records[i].ID < records[j].ID
+		})
+
+		previous := *cursor
+		entries := make([]repository.AuditLog, 0, len(records))
+		for _, record := range records {
+			id := strings.TrimSpace(record.ID)
+			if id == "" || !auditRecordAfter(record.TimestampMS, id, previous) {
+				continue
+			}
+			entries = append(entries, mapKernelAuditRecord(node.ID, record))
+			cursor.SinceMS = record.TimestampMS
+			cursor.LastID = id
+		}
+		if _, err := repos.AuditLog.ImportExternal(ctx, entries); err != nil {
+			cursor.SinceMS = previous.SinceMS
+			cursor.LastID = previous.LastID
+			return err
+		}
+
+		if !full {
+			break
+		}
+		if len(entries) == 0 {
+			warnings = append(warnings, fmt.Sprintf("more than %d audit records at %d ms; the rest of that millisecond is skipped",
+				auditPageSize, cursor.SinceMS))
+			cursor.SinceMS++
+			cursor.LastID = ""
+		}
+	}
+
+	if len(warnings) > 0 {
+		return errors.New(strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
+// auditRecordAfter reports whether a record lies past the cursor.
+func auditRecordAfter(timestampMS int64, id string, cursor repository.KernelAuditCursor) bool {
+	if timestampMS != cursor.SinceMS {
+		return timestampMS > cursor.SinceMS
+	}
+	return id > cursor.LastID
+}
+
+// applyAuditHealth treats the sink as unhealthy while it reports an error or
+// its failure/drop counters grew since the previous check.
+func applyAuditHealth(cursor *repository.KernelAuditCursor, health kernel.AuditHealthSnapshot) {
+	healthy := strings.TrimSpace(health.LastError) == ""
+	if cursor.SinkCheckedAt != nil {
+		if health.SinkFailures > cursor.SinkFailures || health.Dropped > cursor.SinkDropped {
+			healthy = false
+		}
+	} else if health.SinkFailures > 0 || health.Dropped > 0 {
+		healthy = false
+	}
+
+	cursor.SinkHealthy = healthy
+	cursor.SinkQueued = health.Queued
+	cursor.SinkDropped = health.Dropped
+	cursor.SinkFailures = health.SinkFailures
+	cursor.SinkLastError = strings.TrimSpace(health.LastError)
+}
+
+func mapKernelAuditRecord(nodeID uint64, record kernel.AuditRecord) repository.AuditLog {
+	metadata := map[string]any{
+		"channel":  record.Channel,
+		"endpoint": record.Endpoint,
+		"success":  record.Success,
+	}
+	if record.Actor != "" {
+		metadata["actor"] = record.Actor
+	}
+	if record.Target != "" {
+		metadata["target"] = record.Target
+	}
+	if record.Detail != "" {
+		metadata["detail"] = record.Detail
+	}
+	if len(record.Metadata) > 0 {
+		metadata["kernel"] = record.Metadata
+	}
+
+	action := truncateAuditField(record.Action, 64)
+	if action == "" {
+		action = "kernel.audit"
+	}
+	id := nodeID
+	return repository.AuditLog{
+		Action:       action,
+		ResourceType: "kernel",
+		ResourceID:   truncateAuditField(record.Target, 64),
+		Metadata:     metadata,
+		Source:       repository.AuditLogSourceKernel,
+		NodeID:       &id,
+		ExternalID:   truncateAuditField(record.ID, 64),
+		CreatedAt:    time.UnixMilli(record.TimestampMS).UTC(),
+	}
+}
+
+func truncateAuditField(value string, limit int) string {
+	value = strings.TrimSpace(value)
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
@@ -0,0 +1,143 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func TestCollectAudit(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	base := time.Now().Add(-time.Hour).UnixMilli()
+	fake := newFakeKernel(t)
+	fake.addAudit(kernel.AuditRecord{ID: "a1", TimestampMS: base, Channel: "http", Endpoint: "/v1/users", Action: "user.create", Actor: "ops", Target: "u1", Success: true})
+	fake.addAudit(kernel.AuditRecord{ID: "a2", TimestampMS: base + 10, Action: "tls.patch", Target: "edge-tls", Success: false, Detail: "bad cert", Metadata: map[string]string{"mode": "server"}})
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	_, err := repos.AuditLog.Create(ctx, repository.AuditLog{Action: "admin.node.update", ResourceType: "node"})
+	require.NoError(t, err)
+
+	require.NoError(t, collectAudit(ctx, svcCtx))
+
+	logs, total, err := repos.AuditLog.List(ctx, repository.AuditLogListOptions{Source: repository.AuditLogSourceKernel, NodeID: &node.ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total)
+	require.Equal(t, "tls.patch", logs[0].Action)
+	require.Equal(t, "edge-tls", logs[0].ResourceID)
+	require.Equal(t, "bad cert", logs[0].Metadata["detail"])
+	require.Equal(t, "a1", logs[1].ExternalID)
+	require.Equal(t, base, logs[1].CreatedAt.UnixMilli())
+
+	cursor, err := repos.KernelAuditCursor.Get(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, base+10, cursor.SinceMS)
+	require.True(t, cursor.SinkHealthy)
+
+	// Records at the cursor timestamp come back again and are deduplicated.
+	fake.addAudit(kernel.AuditRecord{ID: "a3", TimestampMS: base + 20, Action: "user.delete", Success: true})
+	fake.setAuditHealth(kernel.AuditHealthSnapshot{SinkFailures: 2, LastError: "sink unreachable"})
+	require.NoError(t, collectAudit(ctx, svcCtx))
+	require.Equal(t, base+10, fake.since[len(fake.since)-1])
+
+	_, total, err = repos.AuditLog.List(ctx, repository.AuditLogListOptions{NodeID: &node.ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	_, total, err = repos.AuditLog.List(ctx, repository.AuditLogListOptions{Source: repository.AuditLogSourcePanel})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+
+	cursor, err = repos.KernelAuditCursor.Get(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, base+20, cursor.SinceMS)
+	require.False(t, cursor.SinkHealthy)
+	require.Equal(t, "sink unreachable", cursor.SinkLastError)
+
+	// Recovered sink with unchanged counters is healthy again.
+	fake.setAuditHealth(kernel.AuditHealthSnapshot{SinkFailures: 2})
+	require.NoError(t, collectAudit(ctx, svcCtx))
+	cursor, err = repos.KernelAuditCursor.Get(ctx, node.ID)
+	require.NoError(t, err)
+	require.True(t, cursor.SinkHealthy)
+	require.Empty(t, cursor.PullError)
+
+	// A millisecond holding more than a page cannot stall the collector: the
+	// ids page through one page, then the cursor moves past the millisecond.
+	crowded := base + 30
+	for i := 0; i < auditPageSize+20; i++ {
+		fake.addAudit(kernel.AuditRecord{ID: fmt.Sprintf("b%04d", i), TimestampMS: crowded, Action: "user.patch", Success: true})
+	}
+	fake.addAudit(kernel.AuditRecord{ID: "c1", TimestampMS: crowded + 1, Action: "user.delete", Success: true})
+	calls := len(fake.since)
+	require.NoError(t, collectAudit(ctx, svcCtx))
+	require.Less(t, len(fake.since)-calls, auditMaxPages)
+	cursor, err = repos.KernelAuditCursor.Get(ctx, node.ID)
+	require.NoError(t, err)
+	require.Equal(t, crowded+1, cursor.SinceMS)
+	require.Equal(t, "c1", cursor.LastID)
+	require.Contains(t, cursor.PullError, "the rest of that millisecond is skipped")
+	_, total, err = repos.AuditLog.List(ctx, repository.AuditLogListOptions{NodeID: &node.ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 3+auditPageSize+1, total)
+
+	// A full page returned newest first reports the records it may have cut.
+	fake.mu.Lock()
+	fake.newestFirst = true
+	fake.mu.Unlock()
+	for i := 0; i < auditPageSize+1; i++ {
+		fake.addAudit(kernel.AuditRecord{ID: fmt.Sprintf("d%04d", i), TimestampMS: crowded + 2 + int64(i), Action: "user.patch", Success: true})
+	}
+	require.NoError(t, collectAudit(ctx, svcCtx))
+	cursor, err = repos.KernelAuditCursor.Get(ctx, node.ID)
+	require.NoError(t, err)
+	require.Contains(t, cursor.PullError, "newest")
+	require.Equal(t, crowded+2+auditPageSize, cursor.SinceMS)
+}
+
+func TestCollectAuditSharedEndpoint(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	fake := newFakeKernel(t)
+	fake.addAudit(kernel.AuditRecord{ID: "a1", TimestampMS: time.Now().Add(-time.Minute).UnixMilli(), Action: "user.create", Success: true})
+
+	now := time.Now().UTC()
+	first := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&first).Error)
+	second := repository.Node{Name: "edge-2", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&second).Error)
+
+	// One kernel behind two nodes is pulled once and its records kept once.
+	require.NoError(t, collectAudit(ctx, svcCtx))
+	require.Len(t, fake.since, 1)
+	_, total, err := repos.AuditLog.List(ctx, repository.AuditLogListOptions{Source: repository.AuditLogSourceKernel})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+
+	// Both nodes report the sink, and the second resumes where the first
+	// stopped once it takes over.
+	cursor, err := repos.KernelAuditCursor.Get(ctx, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, "a1", cursor.LastID)
+	require.True(t, cursor.SinkHealthy)
+
+	require.NoError(t, db.Model(&first).Update("status", status.NodeStatusDisabled).Error)
+	require.NoError(t, collectAudit(ctx, svcCtx))
+	_, total, err = repos.AuditLog.List(ctx, repository.AuditLogListOptions{Source: repository.AuditLogSourceKernel})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+}
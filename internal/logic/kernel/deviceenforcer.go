@@ -0,0 +1,212 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+const (
+	deviceEnforceInterval   = 60 * time.Second
+	deviceSightingRetention = 7 * 24 * time.Hour
+)
+
+// RunDeviceEnforcer compares the distinct client IPs seen per subscription with
+// its DevicesLimit and applies the configured device policy. Throttle and
+// suspend penalties are applied by queueing the subscription bindings so the
+// kernel users pick up the reduced rate or disappear until the penalty ends.
+func RunDeviceEnforcer(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(deviceEnforceInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := enforceDeviceLimits(ctx, svcCtx, time.Now().UTC()); err != nil {
+			logger.Errorf("device limit enforcement failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func enforceDeviceLimits(ctx context.Context, svcCtx *svc.ServiceContext, now time.Time) error {
+	repos := svcCtx.Repositories
+	policy, err := repos.Device.GetPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	counts := map[uint64]int{}
+	if policy.Action != repository.DevicePolicyOff {
+		window := time.Duration(policy.WindowSeconds) * time.Second
+		rows, err := repos.Device.CountSince(ctx, now.Add(-window))
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			counts[row.SubscriptionID] = row.Devices
+		}
+	}
+
+	violations, err := repos.Device.ListViolations(ctx)
+	if err != nil {
+		return err
+	}
+	flagged := make(map[uint64]struct{}, len(violations))
+	for _, violation := range violations {
+		keep, err := resolveDeviceViolation(ctx, repos, policy, violation, counts[violation.SubscriptionID], now)
+		if err != nil {
+			return err
+		}
+		if keep {
+			flagged[violation.SubscriptionID] = struct{}{}
+		}
+	}
+
+	if policy.Action != repository.DevicePolicyOff {
+		for subscriptionID, devices := range counts {
+			if _, ok := flagged[subscriptionID]; ok {
+				continue
+			}
+			if err := detectDeviceViolation(ctx, repos, policy, subscriptionID, devices, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := repos.Device.PruneBefore(ctx, now.Add(-deviceSightingRetention)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// resolveDeviceViolation refreshes an existing violation and reports whether it
+// is kept. Penalties run to PenaltyUntil even if the device count drops; warn
+// violations disappear as soon as the subscription is back within its limit.
+func resolveDeviceViolation(ctx context.Context, repos *repository.Repositories, policy repository.DevicePolicySetting, violation repository.DeviceLimitViolation, devices int, now time.Time) (bool, error) {
+	if policy.Action == repository.DevicePolicyOff {
+		return false, clearDeviceViolation(ctx, repos, violation, now)
+	}
+
+	sub, err := repos.Subscription.Get(ctx, violation.SubscriptionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, clearDeviceViolation(ctx, repos, violation, now)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	penalized := violation.Action == repository.DevicePolicyThrottle || violation.Action == repository.DevicePolicySuspend
+	switch {
+	case penalized && !violation.PenaltyActive(now):
+		return false, clearDeviceViolation(ctx, repos, violation, now)
+	case !penalized && !deviceLimitExceeded(sub, devices, now):
+		return false, clearDeviceViolation(ctx, repos, violation, now)
+	}
+
+	if devices > 0 && devices != violation.DeviceCount {
+		violation.DeviceCount = devices
+		if _, err := repos.Device.SaveViolation(ctx, violation); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func detectDeviceViolation(ctx context.Context, repos *repository.Repositories, policy repository.DevicePolicySetting, subscriptionID uint64, devices int, now time.Time) error {
+	sub, err := repos.Subscription.Get(ctx, subscriptionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !deviceLimitExceeded(sub, devices, now) {
+		return nil
+	}
+
+	violation := repository.DeviceLimitViolation{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		DeviceCount:    devices,
+		DevicesLimit:   sub.DevicesLimit,
+		Action:         policy.Action,
+		DetectedAt:     now,
+	}
+	if policy.Action == repository.DevicePolicyWarn {
+		if _, err := repos.Device.SaveViolation(ctx, violation); err != nil {
+			return err
+		}
+		logx.WithContext(ctx).Infof("subscription %d seen on %d devices (limit %d)", sub.ID, devices, sub.DevicesLimit)
+		return nil
+	}
+
+	penaltyUntil := now.Add(time.Duration(policy.PenaltySeconds) * time.Second)
+	violation.PenaltyUntil = &penaltyUntil
+	if policy.Action == repository.DevicePolicyThrottle {
+		violation.ThrottleRateBytes = policy.ThrottleRateBytes
+	}
+	err = repos.Transaction(ctx, func(txRepos *repository.Repositories) error {
+		if _, err := txRepos.Device.SaveViolation(ctx, violation); err != nil {
+			return err
+		}
+		return subscriptionutil.RequestSubscriptionBindingSync(ctx, txRepos, sub, subscriptionutil.BindingSyncReasonDeviceLimit)
+	})
+	if err != nil {
+		return err
+	}
+	logx.WithContext(ctx).Infof("subscription %d seen on %d devices (limit %d), %s until %s", sub.ID, devices, sub.DevicesLimit, policy.Action, penaltyUntil.Format(time.RFC3339))
+	return nil
+}
+
+// clearDeviceViolation drops a violation; bindings are re-queued when the
+// kernel users may still carry its penalty.
+func clearDeviceViolation(ctx context.Context, repos *repository.Repositories, violation repository.DeviceLimitViolation, now time.Time) error {
+	penalized := violation.PenaltyUntil != nil &&
+		(violation.Action == repository.DevicePolicyThrottle || violation.Action == repository.DevicePolicySuspend)
+
+	err := repos.Transaction(ctx, func(txRepos *repository.Repositories) error {
+		if err := txRepos.Device.DeleteViolation(ctx, violation.SubscriptionID); err != nil {
+			return err
+		}
+		if !penalized {
+			return nil
+		}
+		sub, err := txRepos.Subscription.Get(ctx, violation.SubscriptionID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return subscriptionutil.RequestSubscriptionBindingSync(ctx, txRepos, sub, subscriptionutil.BindingSyncReasonDeviceRestore)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	logx.WithContext(ctx).Infof("subscription %d device limit violation cleared", violation.SubscriptionID)
+	return nil
+}
+
+func deviceLimitExceeded(sub repository.Subscription, devices int, now time.Time) bool {
+	if sub.DevicesLimit <= 0 || !subscriptionutil.IsSubscriptionEffective(sub, now) {
+		return false
+	}
+	return devices > sub.DevicesLimit
+}
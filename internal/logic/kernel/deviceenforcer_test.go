@@ -0,0 +1,108 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestEnforceDeviceLimits(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	binding := repository.ProtocolBinding{Name: "edge", NodeID: node.ID, Protocol: "vless", KernelID: "edge", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&binding).Error)
+	plan := repository.Plan{Name: "Basic", Slug: "basic", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&plan).Error)
+	require.NoError(t, repos.PlanProtocolBinding.Replace(ctx, plan.ID, []uint64{binding.ID}))
+
+	newSub := func(userID uint64, devicesLimit, devices int) repository.Subscription {
+		sub := repository.Subscription{
+			UserID:       userID,
+			Name:         "Basic",
+			PlanName:     "Basic",
+			PlanID:       plan.ID,
+			Status:       status.SubscriptionStatusActive,
+			DevicesLimit: devicesLimit,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		require.NoError(t, db.Create(&sub).Error)
+		for i := 0; i < devices; i++ {
+			require.NoError(t, repos.Device.RecordSighting(ctx, repository.SubscriptionDevice{
+				SubscriptionID: sub.ID,
+				UserID:         userID,
+				ClientIP:       fmt.Sprintf("203.0.113.%d", i+1),
+				Source:         repository.DeviceSourceSubscription,
+				LastSeenAt:     now.Add(-time.Minute),
+			}))
+		}
+		return sub
+	}
+
+	_, err := repos.Device.UpsertPolicy(ctx, repository.DevicePolicySetting{
+		Action:            repository.DevicePolicyThrottle,
+		WindowSeconds:     3600,
+		PenaltySeconds:    600,
+		ThrottleRateBytes: 1024,
+	})
+	require.NoError(t, err)
+
+	over := newSub(1, 2, 3)
+	within := newSub(2, 2, 2)
+	unlimited := newSub(3, 0, 5)
+
+	require.NoError(t, enforceDeviceLimits(ctx, svcCtx, now))
+
+	violation, err := repos.Device.GetViolation(ctx, over.ID)
+	require.NoError(t, err)
+	require.Equal(t, repository.DevicePolicyThrottle, violation.Action)
+	require.Equal(t, 3, violation.DeviceCount)
+	require.Equal(t, int64(1024), violation.ThrottleRateBytes)
+	require.True(t, violation.PenaltyActive(now))
+	for _, id := range []uint64{within.ID, unlimited.ID} {
+		_, err := repos.Device.GetViolation(ctx, id)
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	}
+
+	penalized, err := repos.Device.ListPenalizedBySubscriptionIDs(ctx, []uint64{over.ID, within.ID}, now)
+	require.NoError(t, err)
+	require.Len(t, penalized, 1)
+
+	queued, err := repos.ProtocolBinding.Get(ctx, binding.ID)
+	require.NoError(t, err)
+	require.NotNil(t, queued.SyncRequestedAt)
+
+	// Once the penalty ends and the sightings left the window the violation is lifted.
+	later := now.Add(2 * time.Hour)
+	require.NoError(t, enforceDeviceLimits(ctx, svcCtx, later))
+	_, err = repos.Device.GetViolation(ctx, over.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	// Warn violations are recorded without a penalty and disappear when back within the limit.
+	_, err = repos.Device.UpsertPolicy(ctx, repository.DevicePolicySetting{Action: repository.DevicePolicyWarn})
+	require.NoError(t, err)
+	require.NoError(t, enforceDeviceLimits(ctx, svcCtx, now))
+	violation, err = repos.Device.GetViolation(ctx, over.ID)
+	require.NoError(t, err)
+	require.Equal(t, repository.DevicePolicyWarn, violation.Action)
+	require.Nil(t, violation.PenaltyUntil)
+
+	limit := 5
+	_, err = repos.Subscription.Update(ctx, over.ID, repository.UpdateSubscriptionInput{DevicesLimit: &limit})
+	require.NoError(t, err)
+	require.NoError(t, enforceDeviceLimits(ctx, svcCtx, now))
+	_, err = repos.Device.GetViolation(ctx, over.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
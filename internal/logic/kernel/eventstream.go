@@ -0,0 +1,215 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const (
+	eventStreamRefreshInterval = 30 * time.Second
+	eventStreamRetryBase       = 5 * time.Second
+	eventStreamRetryMax        = 5 * time.Minute
+	// Events are sporadic; a silent stream is recycled rather than treated as a failure.
+	eventStreamIdleTimeout = 10 * time.Minute
+)
+
+// RunEventStreamConsumer consumes /v1/events/stream from every pull-mode node
+// and dispatches the events into EventLogic and ServiceEventLogic, so kernels
+// behind NAT do not need to reach the panel's callback endpoints.
+func RunEventStreamConsumer(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	consumer := newEventStreamConsumer(svcCtx)
+	ticker := time.NewTicker(eventStreamRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		nodes, err := svcCtx.Repositories.Node.ListAll(ctx)
+		if err != nil {
+			logger.Errorf("kernel event stream refresh failed: %v", err)
+		} else {
+			consumer.Update(ctx, nodes)
+		}
+
+		select {
+		case <-ctx.Done():
+			consumer.Update(ctx, nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type eventStreamConsumer struct {
+	svcCtx *svc.ServiceContext
+
+	mu      sync.Mutex
+	streams map[string]context.CancelFunc
+}
+
+func newEventStreamConsumer(svcCtx *svc.ServiceContext) *eventStreamConsumer {
+	return &eventStreamConsumer{
+		svcCtx:  svcCtx,
+		streams: make(map[string]context.CancelFunc),
+	}
+}
+
+// Update opens one stream per control endpoint of pull-mode nodes and closes
+// streams whose nodes were removed or switched back to push.
+func (c *eventStreamConsumer) Update(ctx context.Context, nodes []repository.Node) {
+	wanted := make(map[string]repository.Node)
+	for _, node := range nodes {
+		if !isEventStreamEligible(node) {
+			continue
+		}
+		key := eventStreamKey(node)
+		if _, ok := wanted[key]; !ok {
+			wanted[key] = node
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, node := range wanted {
+		if _, ok := c.streams[key]; ok {
+			continue
+		}
+		streamCtx, cancel := context.WithCancel(ctx)
+		c.streams[key] = cancel
+		go c.run(streamCtx, node)
+	}
+	for key, cancel := range c.streams {
+		if _, ok := wanted[key]; !ok {
+			cancel()
+			delete(c.streams, key)
+		}
+	}
+}
+
+func (c *eventStreamConsumer) run(ctx context.Context, node repository.Node) {
+	logger := logx.WithContext(ctx)
+	endpoint := strings.TrimSpace(node.ControlEndpoint)
+	backoff := newStatusBackoff(eventStreamRetryBase, nodecfg.KernelBackoffConfig{
+		Enabled:            true,
+		MaxIntervalSeconds: int(eventStreamRetryMax / time.Second),
+		Multiplier:         2,
+		Jitter:             0.2,
+	})
+
+	var lastEventID string
+	for {
+		client, err := kernel.NewControlClient(c.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
+			BaseURL: endpoint,
+			Token:   resolveControlToken(node),
+			Timeout: resolveKernelHTTPTimeout(node),
+		}))
+		if err != nil {
+			logger.Errorf("kernel event stream client for %s: %v", endpoint, err)
+			return
+		}
+
+		err = client.StreamEvents(ctx, kernel.EventStreamOptions{
+			Events:      []string{"node", "service"},
+			LastEventID: lastEventID,
+			IdleTimeout: eventStreamIdleTimeout,
+		}, func(message kernel.EventStreamMessage) error {
+			backoff.Reset()
+			if message.EventID != "" {
+				lastEventID = message.EventID
+			}
+			if err := dispatchKernelEvent(ctx, c.svcCtx, message); err != nil {
+				logger.Errorf("kernel event %s from %s failed: %v", message.Event, endpoint, err)
+			}
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := backoff.NextDelay()
+		if errors.Is(err, kernel.ErrStreamIdle) {
+			delay = 0
+		} else {
+			logger.Errorf("kernel event stream %s disconnected, retrying in %s: %v", endpoint, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchKernelEvent routes a streamed envelope to the same handlers used by
+// the push callbacks.
+func dispatchKernelEvent(ctx context.Context, svcCtx *svc.ServiceContext, message kernel.EventStreamMessage) error {
+	name := normalizeKernelEventName(message.Event)
+	switch {
+	case name == "":
+		return nil
+	case strings.HasPrefix(name, "node_"):
+		var req types.KernelNodeEventRequest
+		if len(message.Payload) > 0 {
+			if err := json.Unmarshal(message.Payload, &req); err != nil {
+				return fmt.Errorf("decode node event payload: %w", err)
+			}
+		}
+		if strings.TrimSpace(req.Event) == "" {
+			req.Event = name
+		}
+		if req.ObservedAt == 0 && message.OccurredAtMS > 0 {
+			req.ObservedAt = message.OccurredAtMS / 1000
+		}
+		_, err := NewEventLogic(ctx, svcCtx).Handle(&req)
+		return err
+	case strings.HasPrefix(name, "runtime_"):
+		return nil
+	default:
+		_, err := NewServiceEventLogic(ctx, svcCtx).Handle(&types.KernelServiceEventRequest{
+			Event:        message.Event,
+			EventID:      message.EventID,
+			OccurredAtMS: message.OccurredAtMS,
+			Payload:      message.Payload,
+		})
+		return err
+	}
+}
+
+func isEventStreamEligible(node repository.Node) bool {
+	if node.Status == status.NodeStatusDisabled {
+		return false
+	}
+	if strings.TrimSpace(node.ControlEndpoint) == "" {
+		return false
+	}
+	mode, _ := nodecfg.NormalizeKernelEventMode(node.KernelEventMode)
+	return mode == nodecfg.KernelEventModePull
+}
+
+func eventStreamKey(node repository.Node) string {
+	return fmt.Sprintf(
+		"%s|%s|%d",
+		strings.TrimSpace(node.ControlEndpoint),
+		fingerprint(resolveControlToken(node)),
+		node.KernelHTTPTimeoutSeconds,
+	)
+}
@@ -0,0 +1,57 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestEventStreamConsumerDispatchesPullEvents(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	sub := repository.Subscription{
+		UserID:    7,
+		Name:      "Basic",
+		PlanName:  "Basic",
+		Status:    status.SubscriptionStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(&sub).Error)
+
+	fake := newFakeKernel(t)
+	fake.setStream("/v1/events/stream", "event: node.degraded\n"+
+		`data: {"event":"node.degraded","event_id":"1","occurred_at_ms":1733965196123,"payload":{"event":"node_degraded","node_id":"edge-1"}}`+"\n\n"+
+		"event: user.traffic.reported\n"+
+		fmt.Sprintf(`data: {"event":"user.traffic.reported","event_id":"2","payload":{"subscription_id":"%d","current":{"used":4096}}}`+"\n\n", sub.ID))
+
+	pull := repository.Node{ID: 1, ControlEndpoint: fake.URL, Status: status.NodeStatusOnline, KernelEventMode: nodecfg.KernelEventModePull}
+	push := repository.Node{ID: 2, ControlEndpoint: fake.URL + "/push", Status: status.NodeStatusOnline, KernelEventMode: nodecfg.KernelEventModePush}
+
+	consumer := newEventStreamConsumer(svcCtx)
+	consumer.Update(ctx, []repository.Node{pull, push})
+
+	require.Eventually(t, func() bool {
+		return len(fake.requests()) > 0
+	}, 5*time.Second, 20*time.Millisecond, "event stream was not opened")
+	require.Equal(t, "/v1/events/stream?events=node%2Cservice", fake.requests()[0])
+	require.Eventually(t, func() bool {
+		current, err := repos.Subscription.Get(ctx, sub.ID)
+		return err == nil && current.TrafficUsedBytes == 4096
+	}, 5*time.Second, 20*time.Millisecond)
+
+	consumer.Update(ctx, nil)
+	require.Len(t, fake.requests(), 1, "push-mode nodes must not be streamed")
+}
@@ -0,0 +1,192 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+func setupKernelTestContext(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+	testutil.RequireSQLite(t)
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+// fakeKernel serves the control API endpoints the background workers poll
+// and stream from. Streams are only served once their body is set, so a fake
+// without one behaves like a kernel that predates the endpoint.
+type fakeKernel struct {
+	URL string
+
+	mu        sync.Mutex
+	requested []string
+
+	// audit
+	records     []kernel.AuditRecord
+	health      kernel.AuditHealthSnapshot
+	since       []int64
+	newestFirst bool
+
+	// traffic for protocol "edge" and its single user; shared also serves
+	// that user, with the same counter, on protocol "exit"
+	used           int64
+	subscriptionID uint64
+	userPulls      int
+	shared         bool
+
+	// protocol upserts
+	upserts []kernel.ProtocolUpsertRequest
+
+	// SSE bodies keyed by stream path
+	streams map[string]string
+}
+
+func newFakeKernel(t *testing.T) *fakeKernel {
+	t.Helper()
+	fake := &fakeKernel{streams: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	fake.URL = server.URL
+	return fake
+}
+
+func (f *fakeKernel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requested = append(f.requested, r.URL.String())
+	if body, ok := f.streams[r.URL.Path]; ok {
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, body)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		return
+	}
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/audit":
+		since, _ := strconv.ParseInt(r.URL.Query().Get("since_ms"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		f.since = append(f.since, since)
+		out := []kernel.AuditRecord{}
+		for i := range f.records {
+			record := f.records[i]
+			if f.newestFirst {
+				record = f.records[len(f.records)-1-i]
+			}
+			if record.TimestampMS >= since && (limit <= 0 || len(out) < limit) {
+				out = append(out, record)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	case "/v1/audit/health":
+		_ = json.NewEncoder(w).Encode(f.health)
+	case "/v1/traffic":
+		protocols := []kernel.NodeProtocolTraffic{{NodeID: "edge", Protocol: "vless", BytesDown: f.used}}
+		if f.shared {
+			protocols = append(protocols, kernel.NodeProtocolTraffic{NodeID: "exit", Protocol: "vless", BytesDown: f.used})
+		}
+		_ = json.NewEncoder(w).Encode(kernel.TrafficSummaryResponse{
+			GeneratedAtMS:  time.Now().UnixMilli(),
+			BytesDown:      f.used,
+			ByNodeProtocol: protocols,
+		})
+	case "/v1/protocols":
+		if r.Method != http.MethodPost {
+			_ = json.NewEncoder(w).Encode([]kernel.ProtocolSummary{})
+			return
+		}
+		var req kernel.ProtocolUpsertRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.upserts = append(f.upserts, req)
+		_ = json.NewEncoder(w).Encode(kernel.ProtocolSummary{ID: req.Profile.ID, Role: req.Profile.Role, Protocol: req.Profile.Protocol, Listen: req.Listen, Connect: req.Connect})
+	case "/v1/protocols/exit/users":
+		if !f.shared {
+			http.NotFound(w, r)
+			return
+		}
+		fallthrough
+	case "/v1/protocols/edge/users":
+		f.userPulls++
+		_ = json.NewEncoder(w).Encode([]kernel.UserView{{
+			ID:       "1",
+			Username: "u1",
+			Metadata: map[string]any{"subscription_id": strconv.FormatUint(f.subscriptionID, 10)},
+			Traffic:  &kernel.UserTraffic{Used: f.used},
+		}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeKernel) addAudit(record kernel.AuditRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records = append(f.records, record)
+}
+
+func (f *fakeKernel) setAuditHealth(health kernel.AuditHealthSnapshot) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.health = health
+}
+
+func (f *fakeKernel) setTraffic(used int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.used = used
+}
+
+func (f *fakeKernel) setStream(path, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams[path] = body
+}
+
+// requests returns the URLs requested so far.
+func (f *fakeKernel) requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requested...)
+}
@@ -2,6 +2,7 @@ package kernel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -276,16 +277,20 @@ func (p *offlineProbe) run(ctx context.Context, svcCtx *svc.ServiceContext) {
 }
 
 func probeControlEndpoint(ctx context.Context, svcCtx *svc.ServiceContext, key controlKey, nodeIDs []uint64, meta authDebug) error {
-	client, err := kernel.NewControlClient(kernel.HTTPOptions{
+	client, err := kernel.NewControlClient(svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 		BaseURL: key.endpoint,
 		Token:   key.token,
 		Timeout: key.timeout,
-	})
+	}))
 	if err != nil {
 		return err
 	}
 
 	_, err = client.GetStatus(ctx)
+	if errors.Is(err, kernel.ErrCircuitOpen) {
+		markNodesDegraded(ctx, svcCtx, nodeIDs, err)
+		return err
+	}
 	if err != nil {
 		if isUnauthorized(err) {
 			logx.WithContext(ctx).Errorf(
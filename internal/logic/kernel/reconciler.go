@@ -0,0 +1,124 @@
+package kernel
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminprotocolbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+const (
+	bindingReconcileTickInterval  = 2 * time.Second
+	bindingReconcileDebounce      = 5 * time.Second
+	bindingReconcileRetryInterval = 30 * time.Second
+	bindingReconcileBatchSize     = 50
+)
+
+// RunBindingReconciler pushes queued protocol binding changes to their kernels.
+// Requests are recorded on the bindings by subscription lifecycle changes; each
+// one waits for the debounce window so bursts collapse into a single sync.
+func RunBindingReconciler(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	reconciler := newBindingReconciler(ctx, svcCtx)
+	reconciler.run(ctx)
+}
+
+type bindingReconciler struct {
+	svcCtx *svc.ServiceContext
+	sync   *adminprotocolbindings.SyncLogic
+}
+
+func newBindingReconciler(ctx context.Context, svcCtx *svc.ServiceContext) *bindingReconciler {
+	return &bindingReconciler{
+		svcCtx: svcCtx,
+		sync:   adminprotocolbindings.NewSyncLogic(ctx, svcCtx),
+	}
+}
+
+func (r *bindingReconciler) run(ctx context.Context) {
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(bindingReconcileTickInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.reconcileDue(ctx); err != nil {
+			logger.Errorf("binding reconcile failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *bindingReconciler) reconcileDue(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-bindingReconcileDebounce)
+	bindings, err := r.svcCtx.Repositories.ProtocolBinding.ListSyncRequested(ctx, cutoff, bindingReconcileBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		claimed, err := r.svcCtx.Repositories.ProtocolBinding.ClaimSyncRequest(ctx, binding.ID, cutoff, binding.SyncRequestFull)
+		if err != nil {
+			return err
+		}
+		if !claimed || !isReconcileEligible(binding) {
+			continue
+		}
+		r.reconcileBinding(ctx, binding)
+	}
+	return nil
+}
+
+func (r *bindingReconciler) reconcileBinding(ctx context.Context, binding repository.ProtocolBinding) {
+	logger := logx.WithContext(ctx)
+	mode := adminprotocolbindings.SyncModeIncremental
+	if binding.SyncRequestFull {
+		mode = adminprotocolbindings.SyncModeFull
+	}
+	result, err := r.sync.SyncSingle(&types.AdminSyncProtocolBindingRequest{
+		BindingID: binding.ID,
+		Mode:      mode,
+	})
+	if err == nil && result.Status == status.SyncResultStatusSynced {
+		return
+	}
+
+	var message string
+	if err != nil {
+		message = err.Error()
+	} else {
+		message = result.Message
+	}
+	logger.Errorf("binding reconcile failed binding_id=%d reason=%s: %s", binding.ID, binding.SyncRequestReason, message)
+
+	retryAt := time.Now().UTC().Add(bindingReconcileRetryInterval - bindingReconcileDebounce)
+	requeue := r.svcCtx.Repositories.ProtocolBinding.RequestSync
+	if binding.SyncRequestFull {
+		requeue = r.svcCtx.Repositories.ProtocolBinding.RequestFullSync
+	}
+	if err := requeue(ctx, []uint64{binding.ID}, binding.SyncRequestReason, retryAt); err != nil {
+		logger.Errorf("binding reconcile requeue failed binding_id=%d: %v", binding.ID, err)
+	}
+}
+
+func isReconcileEligible(binding repository.ProtocolBinding) bool {
+	if binding.Status == status.ProtocolBindingStatusDisabled {
+		return false
+	}
+	return binding.Node.Status != status.NodeStatusDisabled
+}
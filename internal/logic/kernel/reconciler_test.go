@@ -0,0 +1,83 @@
+package kernel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	adminprotocolbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestReconcilerResyncsRelayAfterUpstreamMove(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+	credentials, err := security.NewCredentialManager("reconciler-test-key")
+	require.NoError(t, err)
+	svcCtx.Credentials = credentials
+
+	fake := newFakeKernel(t)
+	now := time.Now().UTC()
+	exit := repository.Node{Name: "exit", AccessAddress: "198.51.100.20", CreatedAt: now, UpdatedAt: now}
+	edge := repository.Node{Name: "edge", ControlEndpoint: fake.URL, KernelEventMode: "pull", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&exit).Error)
+	require.NoError(t, db.Create(&edge).Error)
+
+	create := adminprotocolbindings.NewCreateLogic(ctx, svcCtx)
+	upstream, err := create.Create(&types.AdminCreateProtocolBindingRequest{
+		NodeID:     exit.ID,
+		Protocol:   "vless",
+		Role:       "listener",
+		AccessPort: 8443,
+		KernelID:   "exit-in",
+		Profile:    map[string]any{},
+	})
+	require.NoError(t, err)
+	relay, err := create.Create(&types.AdminCreateProtocolBindingRequest{
+		NodeID:            edge.ID,
+		Protocol:          "vless",
+		Role:              "connector",
+		KernelID:          "edge-out",
+		UpstreamBindingID: upstream.ID,
+		Profile:           map[string]any{},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&repository.ProtocolBinding{}).Where("id = ?", relay.ID).
+		Update("sync_status", status.ProtocolBindingSyncStatusSynced).Error)
+
+	// Moving the upstream queues the relay for a full sync.
+	port := 9443
+	_, err = adminprotocolbindings.NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdateProtocolBindingRequest{BindingID: upstream.ID, AccessPort: &port})
+	require.NoError(t, err)
+	queued, err := repos.ProtocolBinding.Get(ctx, relay.ID)
+	require.NoError(t, err)
+	require.NotNil(t, queued.SyncRequestedAt)
+	require.True(t, queued.SyncRequestFull)
+
+	// Only the relay is due; the upstream's node has no control endpoint.
+	require.NoError(t, db.Model(&repository.ProtocolBinding{}).Where("id = ?", upstream.ID).
+		Update("sync_requested_at", nil).Error)
+	require.NoError(t, db.Model(&repository.ProtocolBinding{}).Where("id = ?", relay.ID).
+		Update("sync_requested_at", now.Add(-time.Minute)).Error)
+	require.NoError(t, newBindingReconciler(ctx, svcCtx).reconcileDue(ctx))
+
+	fake.mu.Lock()
+	require.Len(t, fake.upserts, 1)
+	upsert := fake.upserts[0]
+	fake.mu.Unlock()
+	require.Equal(t, "198.51.100.20:9443", upsert.Connect)
+	require.Len(t, upsert.Profile.Upstream, 1)
+	require.EqualValues(t, 9443, upsert.Profile.Upstream[0].Profile["port"])
+
+	synced, err := repos.ProtocolBinding.Get(ctx, relay.ID)
+	require.NoError(t, err)
+	require.Nil(t, synced.SyncRequestedAt)
+	require.False(t, synced.SyncRequestFull)
+}
@@ -0,0 +1,53 @@
+package kernel
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminprotocolbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+const bindingRolloutTickInterval = 5 * time.Second
+
+// RunBindingRolloutWorker advances running binding rollouts: it applies their
+// stages, watches binding health during the soak periods, rolls back on
+// degradation and carries out rollbacks requested by admins.
+func RunBindingRolloutWorker(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(bindingRolloutTickInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := advanceBindingRollouts(ctx, svcCtx); err != nil && ctx.Err() == nil {
+			logger.Errorf("binding rollout processing failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func advanceBindingRollouts(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	rollouts, err := svcCtx.Repositories.BindingRollout.ListUnfinished(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rollout := range rollouts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := adminprotocolbindings.NewRolloutLogic(ctx, svcCtx).Advance(rollout.ID); err != nil {
+			logx.WithContext(ctx).Errorf("binding rollout failed rollout_id=%d: %v", rollout.ID, err)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package kernel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -29,7 +30,8 @@ type nodePollState struct {
 	lastStatus int
 }
 
-// RunStatusPoller schedules per-node kernel status polling.
+// RunStatusPoller schedules per-node kernel status polling. Endpoints that serve
+// /v1/status/stream are followed over SSE and polled only while the stream is down.
 func RunStatusPoller(ctx context.Context, svcCtx *svc.ServiceContext) {
 	if svcCtx == nil {
 		return
@@ -44,6 +46,7 @@ type statusPoller struct {
 	nodes        []repository.Node
 	states       map[uint64]*nodePollState
 	offlineProbe *OfflineProbeManager
+	streams      *statusStreamManager
 }
 
 func newStatusPoller(svcCtx *svc.ServiceContext) *statusPoller {
@@ -51,6 +54,7 @@ func newStatusPoller(svcCtx *svc.ServiceContext) *statusPoller {
 		svcCtx:       svcCtx,
 		states:       make(map[uint64]*nodePollState),
 		offlineProbe: NewOfflineProbeManager(svcCtx),
+		streams:      newStatusStreamManager(svcCtx),
 	}
 }
 
@@ -85,6 +89,7 @@ func (p *statusPoller) refreshNodes(ctx context.Context) error {
 	p.nodes = nodes
 	p.lastRefresh = time.Now().UTC()
 	p.syncStates(nodes)
+	p.streams.Update(ctx, nodes)
 	p.offlineProbe.Update(ctx)
 	return nil
 }
@@ -149,6 +154,12 @@ func (p *statusPoller) pollDue(ctx context.Context) {
 		if state == nil {
 			continue
 		}
+		if p.streams.Streaming(node.ID) {
+			// Status arrives over the stream; resume polling once it drops.
+			state.next = time.Time{}
+			state.lastStatus = status.NodeStatusOnline
+			continue
+		}
 		if !state.next.IsZero() && now.Before(state.next) {
 			continue
 		}
@@ -176,17 +187,21 @@ func pollNodeStatus(ctx context.Context, svcCtx *svc.ServiceContext, node reposi
 		return status.NodeStatusOffline, fmt.Errorf("node control endpoint not configured")
 	}
 	token := resolveControlToken(node)
-	client, err := kernel.NewControlClient(kernel.HTTPOptions{
+	client, err := kernel.NewControlClient(svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 		BaseURL: endpoint,
 		Token:   token,
 		Timeout: resolveKernelHTTPTimeout(node),
-	})
+	}))
 	if err != nil {
 		markNodeStatus(ctx, svcCtx, []uint64{node.ID}, status.NodeStatusOffline)
 		return status.NodeStatusOffline, err
 	}
 
 	_, err = client.GetStatus(ctx)
+	if errors.Is(err, kernel.ErrCircuitOpen) {
+		markNodesDegraded(ctx, svcCtx, []uint64{node.ID}, err)
+		return previousStatus, err
+	}
 	if err != nil {
 		logx.WithContext(ctx).Errorf("kernel status poll failed for %s: %v", endpoint, err)
 		if isUnauthorized(err) {
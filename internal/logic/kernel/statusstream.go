@@ -0,0 +1,225 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminprotocolbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
+	"github.com/zero-net-panel/zero-net-panel/internal/nodecfg"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const (
+	statusStreamRetryBase        = 30 * time.Second
+	statusStreamRetryMax         = 5 * time.Minute
+	statusStreamUnsupportedRetry = 10 * time.Minute
+	statusStreamMaxInterval      = 60
+)
+
+// statusStreamManager keeps one /v1/status/stream subscription per control
+// endpoint. While a stream delivers snapshots its nodes are skipped by the
+// poller; once it drops the poller takes over until the stream reconnects.
+type statusStreamManager struct {
+	svcCtx *svc.ServiceContext
+
+	mu      sync.Mutex
+	streams map[string]*statusStream
+	byNode  map[uint64]*statusStream
+}
+
+type statusStream struct {
+	key       string
+	node      repository.Node
+	nodeIDs   []uint64
+	statuses  map[uint64]int
+	cancel    context.CancelFunc
+	connected bool
+}
+
+func newStatusStreamManager(svcCtx *svc.ServiceContext) *statusStreamManager {
+	return &statusStreamManager{
+		svcCtx:  svcCtx,
+		streams: make(map[string]*statusStream),
+		byNode:  make(map[uint64]*statusStream),
+	}
+}
+
+// Update starts streams for new control endpoints, refreshes node membership
+// and stops streams whose nodes are gone.
+func (m *statusStreamManager) Update(ctx context.Context, nodes []repository.Node) {
+	groups := make(map[string][]repository.Node)
+	for _, node := range nodes {
+		if !isPollEligible(node) {
+			continue
+		}
+		key := statusStreamKey(node)
+		groups[key] = append(groups[key], node)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.byNode = make(map[uint64]*statusStream)
+	for key, group := range groups {
+		stream := m.streams[key]
+		if stream == nil {
+			streamCtx, cancel := context.WithCancel(ctx)
+			stream = &statusStream{key: key, node: group[0], cancel: cancel}
+			m.streams[key] = stream
+			go m.run(streamCtx, stream)
+		}
+		stream.nodeIDs = stream.nodeIDs[:0]
+		stream.statuses = make(map[uint64]int, len(group))
+		for _, node := range group {
+			stream.nodeIDs = append(stream.nodeIDs, node.ID)
+			stream.statuses[node.ID] = node.Status
+			m.byNode[node.ID] = stream
+		}
+	}
+
+	for key, stream := range m.streams {
+		if _, ok := groups[key]; !ok {
+			stream.cancel()
+			delete(m.streams, key)
+		}
+	}
+}
+
+// Streaming reports whether the node currently receives status over a stream.
+func (m *statusStreamManager) Streaming(nodeID uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stream := m.byNode[nodeID]
+	return stream != nil && stream.connected
+}
+
+func (m *statusStreamManager) run(ctx context.Context, stream *statusStream) {
+	logger := logx.WithContext(ctx)
+	backoff := newStatusBackoff(statusStreamRetryBase, nodecfg.KernelBackoffConfig{
+		Enabled:            true,
+		MaxIntervalSeconds: int(statusStreamRetryMax / time.Second),
+		Multiplier:         2,
+		Jitter:             0.2,
+	})
+
+	for {
+		err := m.subscribe(ctx, stream, backoff)
+		m.setConnected(stream, false)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := backoff.NextDelay()
+		if errors.Is(err, kernel.ErrNotFound) {
+			// The kernel does not expose the stream; stay on polling.
+			delay = statusStreamUnsupportedRetry
+		}
+		logger.Infof("kernel status stream %s unavailable, falling back to polling for %s: %v", strings.TrimSpace(stream.node.ControlEndpoint), delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (m *statusStreamManager) subscribe(ctx context.Context, stream *statusStream, backoff *statusBackoff) error {
+	node := stream.node
+	client, err := kernel.NewControlClient(m.svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
+		BaseURL: strings.TrimSpace(node.ControlEndpoint),
+		Token:   resolveControlToken(node),
+		Timeout: resolveKernelHTTPTimeout(node),
+	}))
+	if err != nil {
+		return err
+	}
+
+	interval := node.KernelStatusPollIntervalSeconds
+	if interval > statusStreamMaxInterval {
+		interval = statusStreamMaxInterval
+	}
+	return client.StreamStatus(ctx, kernel.StatusStreamOptions{
+		IntervalSeconds: interval,
+		Mode:            "full",
+		// Allow a few missed pushes before treating the stream as dead.
+		IdleTimeout: time.Duration(interval)*3*time.Second + resolveKernelHTTPTimeout(node),
+	}, func(event kernel.StatusStreamEvent) error {
+		if !m.setConnected(stream, true) {
+			backoff.Reset()
+			m.markStreamOnline(ctx, stream)
+		}
+		return m.applySnapshot(ctx, stream, event)
+	})
+}
+
+// setConnected updates the connected flag and returns the previous value.
+func (m *statusStreamManager) setConnected(stream *statusStream, connected bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous := stream.connected
+	stream.connected = connected
+	return previous
+}
+
+func (m *statusStreamManager) streamNodes(stream *statusStream) ([]uint64, map[uint64]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nodeIDs := append([]uint64(nil), stream.nodeIDs...)
+	statuses := make(map[uint64]int, len(stream.statuses))
+	for id, code := range stream.statuses {
+		statuses[id] = code
+	}
+	return nodeIDs, statuses
+}
+
+func (m *statusStreamManager) markStreamOnline(ctx context.Context, stream *statusStream) {
+	nodeIDs, statuses := m.streamNodes(stream)
+	markNodeStatus(ctx, m.svcCtx, nodeIDs, status.NodeStatusOnline)
+	triggerKernelRecovery(ctx, m.svcCtx, resolveRecoveredNodes(nodeIDs, statuses))
+
+	m.mu.Lock()
+	for _, id := range nodeIDs {
+		if _, ok := stream.statuses[id]; ok {
+			stream.statuses[id] = status.NodeStatusOnline
+		}
+	}
+	m.mu.Unlock()
+}
+
+func (m *statusStreamManager) applySnapshot(ctx context.Context, stream *statusStream, event kernel.StatusStreamEvent) error {
+	nodeIDs, _ := m.streamNodes(stream)
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+	bindings, err := m.svcCtx.Repositories.ProtocolBinding.ListByNodeIDs(ctx, nodeIDs)
+	if err != nil {
+		return err
+	}
+	partial := strings.EqualFold(event.Type, "diff")
+	if _, err := adminprotocolbindings.ApplyKernelHealth(ctx, m.svcCtx.Repositories, bindings, event.Snapshot, partial, time.Now().UTC()); err != nil {
+		logx.WithContext(ctx).Errorf("kernel status stream health update failed nodes=%v: %v", nodeIDs, err)
+	}
+	return nil
+}
+
+func statusStreamKey(node repository.Node) string {
+	return fmt.Sprintf(
+		"%s|%s|%d|%d",
+		strings.TrimSpace(node.ControlEndpoint),
+		fingerprint(resolveControlToken(node)),
+		node.KernelStatusPollIntervalSeconds,
+		node.KernelHTTPTimeoutSeconds,
+	)
+}
@@ -0,0 +1,75 @@
+package kernel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestStatusStreamAppliesHealthAndFallsBack(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	streaming := newFakeKernel(t)
+	streaming.setStream("/v1/status/stream", ": keep-alive\n\n"+
+		"event: status.full\n"+
+		`data: {"type":"full","snapshot":{"nodes":[{"id":"edge","health":{"status":{"Degraded":{"consecutive_failures":2}}}}]}}`+"\n\n")
+	legacy := newFakeKernel(t)
+
+	now := time.Now().UTC()
+	newNode := func(name, endpoint string) repository.Node {
+		node := repository.Node{
+			Name:                            name,
+			Status:                          status.NodeStatusOffline,
+			ControlEndpoint:                 endpoint,
+			StatusSyncEnabled:               true,
+			KernelStatusPollIntervalSeconds: 5,
+			CreatedAt:                       now,
+			UpdatedAt:                       now,
+		}
+		require.NoError(t, db.Create(&node).Error)
+		return node
+	}
+	streamed := newNode("edge-1", streaming.URL)
+	polled := newNode("edge-2", legacy.URL)
+	binding := repository.ProtocolBinding{
+		Name:      "edge",
+		NodeID:    streamed.ID,
+		Protocol:  "vless",
+		KernelID:  "edge",
+		Status:    status.ProtocolBindingStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(&binding).Error)
+
+	manager := newStatusStreamManager(svcCtx)
+	manager.Update(ctx, []repository.Node{streamed, polled})
+
+	require.Eventually(t, func() bool {
+		return manager.Streaming(streamed.ID)
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Eventually(t, func() bool {
+		current, err := repos.ProtocolBinding.Get(ctx, binding.ID)
+		return err == nil && current.HealthStatus == status.ProtocolBindingHealthStatusDegraded
+	}, 5*time.Second, 20*time.Millisecond)
+
+	node, err := repos.Node.Get(ctx, streamed.ID)
+	require.NoError(t, err)
+	require.Equal(t, status.NodeStatusOnline, node.Status)
+
+	// Kernels without the stream endpoint stay on the poller.
+	require.False(t, manager.Streaming(polled.ID))
+
+	// Dropping the node from the refreshed list stops its stream.
+	manager.Update(ctx, []repository.Node{polled})
+	require.False(t, manager.Streaming(streamed.ID))
+}
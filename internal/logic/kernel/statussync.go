@@ -55,11 +55,11 @@ func SyncStatus(ctx context.Context, svcCtx *svc.ServiceContext) error {
 	hadSuccess := false
 
 	for key, nodeIDs := range pairs {
-		client, err := kernel.NewControlClient(kernel.HTTPOptions{
+		client, err := kernel.NewControlClient(svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
 			BaseURL: key.endpoint,
 			Token:   key.token,
 			Timeout: key.timeout,
-		})
+		}))
 		if err != nil {
 			lastErr = err
 			logx.WithContext(ctx).Errorf("kernel control client init failed for %s: %v", key.endpoint, err)
@@ -209,3 +209,15 @@ func markNodeStatus(ctx context.Context, svcCtx *svc.ServiceContext, nodeIDs []u
 		logx.WithContext(ctx).Errorf("node status update failed (%d): %v", statusCode, err)
 	}
 }
+
+// markNodesDegraded flags the bindings of nodes whose control circuit is open.
+// Node status is left alone: the breaker only says recent calls failed.
+func markNodesDegraded(ctx context.Context, svcCtx *svc.ServiceContext, nodeIDs []uint64, reason error) {
+	if svcCtx == nil || len(nodeIDs) == 0 {
+		return
+	}
+	logx.WithContext(ctx).Infof("nodes %v degraded: %v", nodeIDs, reason)
+	if _, err := svcCtx.Repositories.ProtocolBinding.UpdateHealthByNodeIDs(ctx, nodeIDs, status.ProtocolBindingHealthStatusDegraded); err != nil {
+		logx.WithContext(ctx).Errorf("mark nodes degraded failed: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+const (
+	subscriptionEnforceInterval  = 30 * time.Second
+	subscriptionEnforceBatchSize = 200
+)
+
+// RunSubscriptionEnforcer moves subscriptions between active, expired and
+// exhausted as time passes and traffic accrues, and drops expired speed limit
+// overrides. Every change queues the affected bindings so the reconciler
+// updates the user on the kernels.
+func RunSubscriptionEnforcer(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(subscriptionEnforceInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		if err := enforceSubscriptions(ctx, svcCtx, now); err != nil {
+			logger.Errorf("subscription enforcement failed: %v", err)
+		}
+		if err := expireRateLimits(ctx, svcCtx, now); err != nil {
+			logger.Errorf("rate limit expiry failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func enforceSubscriptions(ctx context.Context, svcCtx *svc.ServiceContext, now time.Time) error {
+	repos := svcCtx.Repositories
+
+	expired, err := repos.Subscription.ListExpiredActive(ctx, now, subscriptionEnforceBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, sub := range expired {
+		if err := transitionSubscription(ctx, repos, sub, status.SubscriptionStatusExpired, subscriptionutil.BindingSyncReasonSubscriptionExpire); err != nil {
+			return err
+		}
+	}
+
+	exhausted, err := repos.Subscription.ListExhaustedActive(ctx, subscriptionEnforceBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, sub := range exhausted {
+		if err := transitionSubscription(ctx, repos, sub, status.SubscriptionStatusExhausted, subscriptionutil.BindingSyncReasonSubscriptionExhaust); err != nil {
+			return err
+		}
+	}
+
+	restorable, err := repos.Subscription.ListRestorable(ctx, now, subscriptionEnforceBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, sub := range restorable {
+		// ListRestorable already skips superseded rows; this catches two rows of
+		// the same user restored within one batch.
+		superseded, err := hasOtherEffectiveSubscription(ctx, repos, sub, now)
+		if err != nil {
+			return err
+		}
+		if superseded {
+			continue
+		}
+		if err := transitionSubscription(ctx, repos, sub, status.SubscriptionStatusActive, subscriptionutil.BindingSyncReasonSubscriptionRestore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expireRateLimits removes overrides past their expiry so plan limits apply again.
+func expireRateLimits(ctx context.Context, svcCtx *svc.ServiceContext, now time.Time) error {
+	repos := svcCtx.Repositories
+	expired, err := repos.UserRateLimit.ListExpired(ctx, now, subscriptionEnforceBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, limit := range expired {
+		err := repos.Transaction(ctx, func(txRepos *repository.Repositories) error {
+			if err := txRepos.UserRateLimit.Delete(ctx, limit.UserID); err != nil {
+				return err
+			}
+			return subscriptionutil.RequestUserBindingSync(ctx, txRepos, limit.UserID, subscriptionutil.BindingSyncReasonRateLimitExpire)
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		logx.WithContext(ctx).Infof("user %d rate limit override expired", limit.UserID)
+	}
+	return nil
+}
+
+func transitionSubscription(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, statusCode int, reason string) error {
+	// Parked rows are tagged so only they are restored later.
+	statusReason := repository.SubscriptionStatusReasonEnforcer
+	if statusCode == status.SubscriptionStatusActive {
+		statusReason = ""
+	}
+	err := repos.Transaction(ctx, func(txRepos *repository.Repositories) error {
+		updated, err := txRepos.Subscription.Update(ctx, sub.ID, repository.UpdateSubscriptionInput{
+			Status:       &statusCode,
+			StatusReason: &statusReason,
+		})
+		if err != nil {
+			return err
+		}
+		return subscriptionutil.RequestSubscriptionBindingSync(ctx, txRepos, updated, reason)
+	})
+	if err != nil {
+		return err
+	}
+	logx.WithContext(ctx).Infof("subscription %d status %d -> %d (%s)", sub.ID, sub.Status, statusCode, reason)
+	return nil
+}
+
+func hasOtherEffectiveSubscription(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, now time.Time) (bool, error) {
+	subs, _, err := repos.Subscription.ListByUser(ctx, sub.UserID, repository.ListSubscriptionsOptions{
+		PerPage: 100,
+		Status:  status.SubscriptionStatusActive,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, candidate := range subs {
+		if candidate.ID != sub.ID && subscriptionutil.IsSubscriptionEffective(candidate, now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
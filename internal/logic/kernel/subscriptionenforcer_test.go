@@ -0,0 +1,132 @@
+package kernel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestEnforceSubscriptions(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	binding := repository.ProtocolBinding{Name: "edge", NodeID: node.ID, Protocol: "vless", KernelID: "edge", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&binding).Error)
+	plan := repository.Plan{Name: "Basic", Slug: "basic", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&plan).Error)
+	require.NoError(t, repos.PlanProtocolBinding.Replace(ctx, plan.ID, []uint64{binding.ID}))
+
+	newSub := func(userID uint64, statusCode int, reason string, expiresAt time.Time, total, used int64) repository.Subscription {
+		sub := repository.Subscription{
+			UserID:            userID,
+			Name:              "Basic",
+			PlanName:          "Basic",
+			PlanID:            plan.ID,
+			Status:            statusCode,
+			StatusReason:      reason,
+			ExpiresAt:         expiresAt,
+			TrafficTotalBytes: total,
+			TrafficUsedBytes:  used,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		require.NoError(t, db.Create(&sub).Error)
+		return sub
+	}
+
+	enforcer := repository.SubscriptionStatusReasonEnforcer
+	expired := newSub(1, status.SubscriptionStatusActive, "", now.Add(-time.Minute), 0, 0)
+	exhausted := newSub(2, status.SubscriptionStatusActive, "", time.Time{}, 100, 100)
+	healthy := newSub(3, status.SubscriptionStatusActive, "", time.Time{}, 100, 10)
+	toppedUp := newSub(4, status.SubscriptionStatusExhausted, enforcer, now.Add(time.Hour), 200, 100)
+	superseded := newSub(5, status.SubscriptionStatusExpired, enforcer, now.Add(time.Hour), 0, 0)
+	newSub(5, status.SubscriptionStatusActive, "", now.Add(2*time.Hour), 0, 0)
+	// Set to expired by an admin; a future expiry must not bring it back.
+	manual := newSub(6, status.SubscriptionStatusExpired, "", now.Add(time.Hour), 0, 0)
+
+	require.NoError(t, enforceSubscriptions(ctx, svcCtx, now))
+
+	expectStatus := func(id uint64, expected int) {
+		t.Helper()
+		sub, err := repos.Subscription.Get(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, expected, sub.Status)
+	}
+	expectStatus(expired.ID, status.SubscriptionStatusExpired)
+	expectStatus(exhausted.ID, status.SubscriptionStatusExhausted)
+	expectStatus(healthy.ID, status.SubscriptionStatusActive)
+	expectStatus(toppedUp.ID, status.SubscriptionStatusActive)
+	expectStatus(superseded.ID, status.SubscriptionStatusExpired)
+	expectStatus(manual.ID, status.SubscriptionStatusExpired)
+
+	// Rows parked by the enforcer carry its reason; restored rows drop it.
+	parked, err := repos.Subscription.Get(ctx, expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, enforcer, parked.StatusReason)
+	restored, err := repos.Subscription.Get(ctx, toppedUp.ID)
+	require.NoError(t, err)
+	require.Empty(t, restored.StatusReason)
+
+	// Superseded rows are excluded in SQL so they cannot fill the batch.
+	restorable, err := repos.Subscription.ListRestorable(ctx, now, 1)
+	require.NoError(t, err)
+	require.Empty(t, restorable)
+
+	queued, err := repos.ProtocolBinding.Get(ctx, binding.ID)
+	require.NoError(t, err)
+	require.NotNil(t, queued.SyncRequestedAt)
+
+	// A second pass is a no-op once every subscription is in its final state.
+	require.NoError(t, enforceSubscriptions(ctx, svcCtx, now))
+	expectStatus(exhausted.ID, status.SubscriptionStatusExhausted)
+	expectStatus(toppedUp.ID, status.SubscriptionStatusActive)
+}
+
+func TestExpireRateLimits(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	binding := repository.ProtocolBinding{Name: "edge", NodeID: node.ID, Protocol: "vless", KernelID: "edge", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&binding).Error)
+	plan := repository.Plan{Name: "Basic", Slug: "basic", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&plan).Error)
+	require.NoError(t, repos.PlanProtocolBinding.Replace(ctx, plan.ID, []uint64{binding.ID}))
+	sub := repository.Subscription{UserID: 1, Name: "Basic", PlanName: "Basic", PlanID: plan.ID, Status: status.SubscriptionStatusActive, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&sub).Error)
+
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+	_, err := repos.UserRateLimit.Upsert(ctx, repository.UserRateLimit{UserID: 1, UploadRateBytes: 1024, ExpiresAt: &past})
+	require.NoError(t, err)
+	_, err = repos.UserRateLimit.Upsert(ctx, repository.UserRateLimit{UserID: 2, UploadRateBytes: 1024, ExpiresAt: &future})
+	require.NoError(t, err)
+	_, err = repos.UserRateLimit.Upsert(ctx, repository.UserRateLimit{UserID: 3, DownloadRateBytes: 1024})
+	require.NoError(t, err)
+
+	require.NoError(t, expireRateLimits(ctx, svcCtx, now))
+
+	_, err = repos.UserRateLimit.Get(ctx, 1)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+	active, err := repos.UserRateLimit.ListActiveByUserIDs(ctx, []uint64{1, 2, 3}, now)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+
+	queued, err := repos.ProtocolBinding.Get(ctx, binding.ID)
+	require.NoError(t, err)
+	require.NotNil(t, queued.SyncRequestedAt)
+}
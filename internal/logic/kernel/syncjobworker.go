@@ -0,0 +1,61 @@
+package kernel
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminprotocolbindings "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/protocolbindings"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+const (
+	syncJobTickInterval = 2 * time.Second
+	syncJobBatchSize    = 10
+)
+
+// RunSyncJobWorker processes queued binding sync jobs in submission order.
+// Bindings a previous panel process left running are queued again on start.
+func RunSyncJobWorker(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	if reset, err := adminprotocolbindings.ResetInterruptedSyncJobItems(ctx, svcCtx); err != nil {
+		logger.Errorf("sync job recovery failed: %v", err)
+	} else if reset > 0 {
+		logger.Infof("sync job recovery re-queued %d interrupted bindings", reset)
+	}
+
+	ticker := time.NewTicker(syncJobTickInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := processSyncJobs(ctx, svcCtx); err != nil && ctx.Err() == nil {
+			logger.Errorf("sync job processing failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func processSyncJobs(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	jobs, err := svcCtx.Repositories.SyncJob.ListActive(ctx, syncJobBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := adminprotocolbindings.NewSyncJobLogic(ctx, svcCtx).Process(job.ID); err != nil {
+			logx.WithContext(ctx).Errorf("sync job failed job_id=%d: %v", job.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package kernel
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	adminnodes "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/nodes"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+// RunTLSCertificateChecker refreshes kernel TLS certificate info for every
+// node with a control endpoint so the node list can flag certificates that
+// expire within KernelTLS.ExpiryWindow.
+func RunTLSCertificateChecker(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	interval := svcCtx.Config.KernelTLS.CheckInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := checkTLSCertificates(ctx, svcCtx, time.Now().UTC()); err != nil {
+			logger.Errorf("kernel tls certificate check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func checkTLSCertificates(ctx context.Context, svcCtx *svc.ServiceContext, now time.Time) error {
+	nodes, err := svcCtx.Repositories.Node.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	logger := logx.WithContext(ctx)
+	window := svcCtx.Config.KernelTLS.ExpiryWindow
+
+	for _, node := range nodes {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if node.Status == status.NodeStatusDisabled || strings.TrimSpace(node.ControlEndpoint) == "" {
+			continue
+		}
+		certs, err := adminnodes.CheckNodeTLS(ctx, svcCtx, node)
+		if err != nil {
+			// Kernels without TLS nodes support answer 404; keep the last result.
+			logger.Debugf("kernel tls check skipped node_id=%d: %v", node.ID, err)
+			continue
+		}
+		for _, cert := range certs {
+			if cert.NotAfter != nil && cert.NotAfter.Before(now.Add(window)) {
+				logger.Infof("kernel tls certificate expiring node_id=%d tls_node=%s not_after=%s", node.ID, cert.TLSNodeID, cert.NotAfter.Format(time.RFC3339))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+const trafficCollectInterval = 60 * time.Second
+
+// RunTrafficCollector pulls traffic counters from kernels that cannot push.
+// GET /v1/traffic serves as a per-protocol change detector; only protocols whose
+// totals moved are expanded into per-user counters via /v1/protocols/{id}/users.
+func RunTrafficCollector(ctx context.Context, svcCtx *svc.ServiceContext) {
+	if svcCtx == nil {
+		return
+	}
+	logger := logx.WithContext(ctx)
+	ticker := time.NewTicker(trafficCollectInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := collectTraffic(ctx, svcCtx); err != nil {
+			logger.Errorf("kernel traffic collection failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func collectTraffic(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	nodes, err := svcCtx.Repositories.Node.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if !isTrafficCollectEligible(node) {
+			continue
+		}
+		if err := collectNodeTraffic(ctx, svcCtx, node); err != nil {
+			logx.WithContext(ctx).Errorf("kernel traffic collection failed for node %d: %v", node.ID, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func isTrafficCollectEligible(node repository.Node) bool {
+	if node.Status == status.NodeStatusDisabled || node.Status == status.NodeStatusOffline {
+		return false
+	}
+	return strings.TrimSpace(node.ControlEndpoint) != ""
+}
+
+func collectNodeTraffic(ctx context.Context, svcCtx *svc.ServiceContext, node repository.Node) error {
+	client, err := kernel.NewControlClient(svcCtx.KernelHTTPOptions(kernel.HTTPOptions{
+		BaseURL: strings.TrimSpace(node.ControlEndpoint),
+		Token:   resolveControlToken(node),
+		Timeout: resolveKernelHTTPTimeout(node),
+	}))
+	if err != nil {
+		return err
+	}
+
+	summary, err := client.GetTraffic(ctx)
+	if err != nil {
+		return err
+	}
+
+	repos := svcCtx.Repositories
+	nodeCursor, err := repos.KernelTrafficCursor.Get(ctx, node.ID, 0, 0)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	nodeKnown := err == nil && !nodeCursor.PulledAt.IsZero()
+	if err != nil {
+		nodeCursor = repository.KernelTrafficCursor{NodeID: node.ID}
+	}
+
+	totals := make(map[string]int64, len(summary.ByNodeProtocol))
+	for _, item := range summary.ByNodeProtocol {
+		totals[strings.TrimSpace(item.NodeID)] += item.BytesUp + item.BytesDown
+	}
+
+	bindings, err := repos.ProtocolBinding.ListByNodeIDs(ctx, []uint64{node.ID})
+	if err != nil {
+		return err
+	}
+
+	ingest := NewTrafficIngestLogic(ctx, svcCtx)
+	for _, binding := range bindings {
+		kernelID := strings.TrimSpace(binding.KernelID)
+		if kernelID == "" {
+			continue
+		}
+		if err := collectBindingTraffic(ctx, repos, client, ingest, node, binding, totals, nodeKnown); err != nil {
+			logx.WithContext(ctx).Errorf("kernel traffic collection failed for binding %d: %v", binding.ID, err)
+		}
+	}
+
+	nodeCursor.Counter = summary.BytesUp + summary.BytesDown
+	nodeCursor.PulledAt = time.Now().UTC()
+	if summary.GeneratedAtMS > 0 {
+		nodeCursor.PulledAt = time.UnixMilli(summary.GeneratedAtMS).UTC()
+	}
+	_, err = repos.KernelTrafficCursor.Save(ctx, nodeCursor)
+	return err
+}
+
+func collectBindingTraffic(
+	ctx context.Context,
+	repos *repository.Repositories,
+	client *kernel.ControlClient,
+	ingest *TrafficIngestLogic,
+	node repository.Node,
+	binding repository.ProtocolBinding,
+	totals map[string]int64,
+	nodeKnown bool,
+) error {
+	protocolCursor, err := repos.KernelTrafficCursor.Get(ctx, node.ID, binding.ID, 0)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	total, reported := totals[strings.TrimSpace(binding.KernelID)]
+	if err == nil && reported && !protocolCursor.PulledAt.IsZero() && protocolCursor.Counter == total {
+		// Nothing moved on this protocol since the previous pull.
+		return nil
+	}
+	if err != nil {
+		protocolCursor = repository.KernelTrafficCursor{NodeID: node.ID, ProtocolBindingID: binding.ID}
+	}
+
+	// Bytes observed before the request are part of the returned counters.
+	pulledAt := time.Now().UTC()
+	users, err := client.ListProtocolUsers(ctx, binding.KernelID)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.Traffic == nil {
+			continue
+		}
+		userID, err := strconv.ParseUint(strings.TrimSpace(user.ID), 10, 64)
+		if err != nil || userID == 0 {
+			continue
+		}
+		if _, err := ingest.ingestCounter(trafficCounterSample{
+			NodeID:         node.ID,
+			BindingID:      binding.ID,
+			UserID:         userID,
+			SubscriptionID: metadataUint(user.Metadata, "subscription_id"),
+			Protocol:       binding.Protocol,
+			Counter:        user.Traffic.Used,
+			PulledAt:       pulledAt,
+			NodeKnown:      nodeKnown,
+		}); err != nil {
+			logx.WithContext(ctx).Errorf("kernel traffic counter ingest failed user_id=%d binding_id=%d: %v", userID, binding.ID, err)
+		}
+	}
+
+	protocolCursor.Counter = total
+	protocolCursor.PulledAt = pulledAt
+	_, err = repos.KernelTrafficCursor.Save(ctx, protocolCursor)
+	return err
+}
+
+func metadataUint(metadata map[string]any, key string) uint64 {
+	if metadata == nil {
+		return 0
+	}
+	value, ok := metadata[key]
+	if !ok {
+		return 0
+	}
+	parsed, err := strconv.ParseUint(strings.TrimSpace(fmt.Sprint(value)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
@@ -0,0 +1,128 @@
+package kernel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestCollectTrafficDedupesPushedBytes(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	fake := newFakeKernel(t)
+	fake.setTraffic(1000)
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	binding := repository.ProtocolBinding{Name: "edge", NodeID: node.ID, Protocol: "vless", KernelID: "edge", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&binding).Error)
+	sub := repository.Subscription{
+		UserID:    1,
+		Name:      "Basic",
+		PlanName:  "Basic",
+		Status:    status.SubscriptionStatusActive,
+		ExpiresAt: now.Add(24 * time.Hour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(&sub).Error)
+	fake.subscriptionID = sub.ID
+
+	expectUsed := func(expected int64) {
+		t.Helper()
+		current, err := repos.Subscription.Get(ctx, sub.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, current.TrafficUsedBytes)
+	}
+
+	// The first pull of a node only records baselines.
+	require.NoError(t, collectTraffic(ctx, svcCtx))
+	expectUsed(0)
+
+	push := func(bytes int64, observedAt time.Time) *types.KernelTrafficIngestResponse {
+		resp, err := NewTrafficIngestLogic(ctx, svcCtx).Ingest(&types.KernelTrafficReportRequest{
+			Records: []types.KernelTrafficRecord{{
+				UserID:            1,
+				SubscriptionID:    sub.ID,
+				Protocol:          "vless",
+				NodeID:            node.ID,
+				ProtocolBindingID: binding.ID,
+				BytesDown:         bytes,
+				ObservedAt:        observedAt.Unix(),
+			}},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	require.Equal(t, 1, push(300, time.Now().Add(time.Minute)).Accepted)
+	expectUsed(300)
+	require.Equal(t, 1, push(400, time.Now().Add(-time.Hour)).Duplicated)
+	expectUsed(300)
+
+	// 500 new bytes of which 300 were already pushed.
+	fake.setTraffic(1500)
+	require.NoError(t, collectTraffic(ctx, svcCtx))
+	expectUsed(500)
+
+	// Unchanged protocol totals skip the per-user listing.
+	pulls := fake.userPulls
+	require.NoError(t, collectTraffic(ctx, svcCtx))
+	require.Equal(t, pulls, fake.userPulls)
+	expectUsed(500)
+
+	// A kernel restart resets counters; the new counter is charged as-is.
+	fake.setTraffic(200)
+	require.NoError(t, collectTraffic(ctx, svcCtx))
+	expectUsed(700)
+}
+
+func TestCollectTrafficChargesSharedUserOnce(t *testing.T) {
+	svcCtx, cleanup := setupKernelTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	// One kernel user served by two bindings reports the same counter on both.
+	fake := newFakeKernel(t)
+	fake.shared = true
+	fake.setTraffic(1000)
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, ControlEndpoint: fake.URL, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	for _, kernelID := range []string{"edge", "exit"} {
+		binding := repository.ProtocolBinding{Name: kernelID, NodeID: node.ID, Protocol: "vless", KernelID: kernelID, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(&binding).Error)
+	}
+	sub := repository.Subscription{
+		UserID:    1,
+		Name:      "Basic",
+		PlanName:  "Basic",
+		Status:    status.SubscriptionStatusActive,
+		ExpiresAt: now.Add(24 * time.Hour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(&sub).Error)
+	fake.subscriptionID = sub.ID
+
+	require.NoError(t, collectTraffic(ctx, svcCtx))
+	fake.setTraffic(1600)
+	require.NoError(t, collectTraffic(ctx, svcCtx))
+	require.Equal(t, 4, fake.userPulls)
+
+	current, err := repos.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(600), current.TrafficUsedBytes)
+}
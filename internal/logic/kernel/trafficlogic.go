@@ -2,6 +2,7 @@ package kernel
 
 import (
 	"context"
+	"errors"
 	"math"
 	"strings"
 	"time"
@@ -37,8 +38,13 @@ func (l *TrafficIngestLogic) Ingest(req *types.KernelTrafficReportRequest) (*typ
 
 	accepted := 0
 	failed := 0
+	duplicated := 0
 	for _, record := range req.Records {
 		if err := l.ingestRecord(record); err != nil {
+			if errors.Is(err, errTrafficAlreadyCollected) {
+				duplicated++
+				continue
+			}
 			failed++
 			l.Errorf("kernel traffic ingest failed: %v", err)
 			continue
@@ -47,19 +53,159 @@ func (l *TrafficIngestLogic) Ingest(req *types.KernelTrafficReportRequest) (*typ
 	}
 
 	return &types.KernelTrafficIngestResponse{
-		Accepted: accepted,
-		Failed:   failed,
+		Accepted:   accepted,
+		Failed:     failed,
+		Duplicated: duplicated,
 	}, nil
 }
 
+// errTrafficAlreadyCollected marks pushed bytes that a previous pull already counted.
+var errTrafficAlreadyCollected = errors.New("kernel traffic already collected")
+
 func (l *TrafficIngestLogic) ingestRecord(record types.KernelTrafficRecord) error {
 	subscription, err := l.resolveSubscription(record)
 	if err != nil {
 		return err
 	}
 
-	protocol := strings.ToLower(strings.TrimSpace(record.Protocol))
-	multiplier := l.resolveMultiplier(subscription, protocol)
+	usage := l.buildUsage(subscription, record, l.resolveMultiplier(subscription, normalizeTrafficProtocol(record.Protocol)))
+	return l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		if record.NodeID != 0 && record.ProtocolBindingID != 0 {
+			if err := l.trackPushedBytes(txRepos, record.NodeID, subscription.UserID, usage); err != nil {
+				return err
+			}
+		}
+		return l.persistUsage(txRepos, usage)
+	})
+}
+
+// trackPushedBytes de-duplicates push callbacks against pulled counters: bytes
+// observed before the last pull are already part of the pulled counter, newer
+// bytes are remembered so the next pull only charges the remainder. The
+// cursor is the user's node cursor, matching the kernel's single counter.
+func (l *TrafficIngestLogic) trackPushedBytes(txRepos *repository.Repositories, nodeID, userID uint64, usage repository.TrafficUsageRecord) error {
+	cursor, err := txRepos.KernelTrafficCursor.GetForUpdate(l.ctx, nodeID, 0, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		cursor = repository.KernelTrafficCursor{NodeID: nodeID, UserID: userID}
+	}
+	if !cursor.PulledAt.IsZero() && !usage.ObservedAt.After(cursor.PulledAt) {
+		return errTrafficAlreadyCollected
+	}
+	cursor.PushedBytes += usage.RawBytes
+	_, err = txRepos.KernelTrafficCursor.Save(l.ctx, cursor)
+	return err
+}
+
+// trafficCounterSample is a cumulative per-user counter pulled from a kernel.
+type trafficCounterSample struct {
+	NodeID         uint64
+	BindingID      uint64
+	UserID         uint64
+	SubscriptionID uint64
+	Protocol       string
+	Counter        int64
+	PulledAt       time.Time
+	// NodeKnown reports whether the node was pulled before; the first pull of a
+	// node only records baselines so history already pushed is not charged twice.
+	NodeKnown bool
+}
+
+// ingestCounter converts a pulled counter into a delta and charges it through
+// the same path as pushed records. It returns the charged raw bytes. Kernels
+// keep one counter per user, so the cursor is keyed by node and user: a user
+// served by several bindings is charged once, under the first binding that
+// reads the counter.
+func (l *TrafficIngestLogic) ingestCounter(sample trafficCounterSample) (int64, error) {
+	if sample.NodeID == 0 || sample.BindingID == 0 || sample.UserID == 0 {
+		return 0, repository.ErrInvalidArgument
+	}
+
+	// Resolve outside the transaction; the cursor still advances when the bytes
+	// cannot be attributed so they are not retried forever.
+	subscription, resolveErr := l.resolveSubscription(types.KernelTrafficRecord{
+		UserID:         sample.UserID,
+		SubscriptionID: sample.SubscriptionID,
+	})
+	var multiplier float64
+	if resolveErr == nil {
+		multiplier = l.resolveMultiplier(subscription, normalizeTrafficProtocol(sample.Protocol))
+	}
+
+	var charged int64
+	err := l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
+		cursor, err := txRepos.KernelTrafficCursor.GetForUpdate(l.ctx, sample.NodeID, 0, sample.UserID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if err != nil {
+			cursor = repository.KernelTrafficCursor{NodeID: sample.NodeID, UserID: sample.UserID}
+		}
+
+		delta, leftover := counterDelta(cursor, sample)
+		cursor.Counter = sample.Counter
+		cursor.PushedBytes = leftover
+		cursor.PulledAt = sample.PulledAt
+		if _, err := txRepos.KernelTrafficCursor.Save(l.ctx, cursor); err != nil {
+			return err
+		}
+		if delta <= 0 {
+			return nil
+		}
+
+		if resolveErr != nil {
+			l.Errorf("kernel traffic pull dropped %d bytes user_id=%d binding_id=%d: %v", delta, sample.UserID, sample.BindingID, resolveErr)
+			return nil
+		}
+		usage := l.buildUsage(subscription, types.KernelTrafficRecord{
+			UserID:            sample.UserID,
+			SubscriptionID:    subscription.ID,
+			Protocol:          sample.Protocol,
+			NodeID:            sample.NodeID,
+			ProtocolBindingID: sample.BindingID,
+			// Kernels only expose a combined per-user counter.
+			BytesDown:  delta,
+			ObservedAt: sample.PulledAt.Unix(),
+		}, multiplier)
+		charged = delta
+		return l.persistUsage(txRepos, usage)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return charged, nil
+}
+
+// counterDelta returns the bytes to charge for a pulled counter and the pushed
+// bytes that remain unmatched afterwards.
+func counterDelta(cursor repository.KernelTrafficCursor, sample trafficCounterSample) (int64, int64) {
+	var grown int64
+	switch {
+	case cursor.PulledAt.IsZero() && !sample.NodeKnown:
+		return 0, 0
+	case cursor.PulledAt.IsZero():
+		// A user that appeared after the node's previous pull.
+		grown = sample.Counter
+	case sample.Counter < cursor.Counter:
+		// The kernel counter was reset (e.g. restart).
+		grown = sample.Counter
+	default:
+		grown = sample.Counter - cursor.Counter
+	}
+	if grown <= cursor.PushedBytes {
+		return 0, cursor.PushedBytes - grown
+	}
+	return grown - cursor.PushedBytes, 0
+}
+
+func normalizeTrafficProtocol(protocol string) string {
+	return strings.ToLower(strings.TrimSpace(protocol))
+}
+
+func (l *TrafficIngestLogic) buildUsage(subscription repository.Subscription, record types.KernelTrafficRecord, multiplier float64) repository.TrafficUsageRecord {
+	protocol := normalizeTrafficProtocol(record.Protocol)
 	raw := maxInt64(record.BytesUp+record.BytesDown, 0)
 	charged := int64(math.Round(float64(raw) * multiplier))
 
@@ -68,7 +214,7 @@ func (l *TrafficIngestLogic) ingestRecord(record types.KernelTrafficRecord) erro
 		observedAt = time.Unix(record.ObservedAt, 0).UTC()
 	}
 
-	usage := repository.TrafficUsageRecord{
+	return repository.TrafficUsageRecord{
 		UserID:            subscription.UserID,
 		SubscriptionID:    subscription.ID,
 		ProtocolBindingID: record.ProtocolBindingID,
@@ -81,14 +227,14 @@ func (l *TrafficIngestLogic) ingestRecord(record types.KernelTrafficRecord) erro
 		Multiplier:        multiplier,
 		ObservedAt:        observedAt,
 	}
+}
 
-	return l.svcCtx.Repositories.Transaction(l.ctx, func(txRepos *repository.Repositories) error {
-		if _, err := txRepos.TrafficUsage.Create(l.ctx, usage); err != nil {
-			return err
-		}
-		_, err := txRepos.Subscription.IncrementTrafficUsage(l.ctx, subscription.ID, charged)
+func (l *TrafficIngestLogic) persistUsage(txRepos *repository.Repositories, usage repository.TrafficUsageRecord) error {
+	if _, err := txRepos.TrafficUsage.Create(l.ctx, usage); err != nil {
 		return err
-	})
+	}
+	_, err := txRepos.Subscription.IncrementTrafficUsage(l.ctx, usage.SubscriptionID, usage.ChargedBytes)
+	return err
 }
 
 func (l *TrafficIngestLogic) resolveSubscription(record types.KernelTrafficRecord) (repository.Subscription, error) {
@@ -0,0 +1,375 @@
+// Package portutil detects listen address and port conflicts between the
+// bindings and entries of a node and allocates free ports from the node's
+// configured range.
+package portutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+const (
+	roleListener   = "listener"
+	resolveTimeout = 2 * time.Second
+)
+
+// lookupHost resolves host names when matching entry addresses to nodes.
+var lookupHost = net.DefaultResolver.LookupHost
+
+type endpoint struct {
+	host       string
+	port       int
+	transports []string
+}
+
+func (e endpoint) overlaps(other endpoint) bool {
+	if e.port <= 0 || e.port != other.port {
+		return false
+	}
+	if e.host != "" && other.host != "" && !strings.EqualFold(e.host, other.host) {
+		return false
+	}
+	for _, transport := range e.transports {
+		for _, candidate := range other.transports {
+			if transport == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Transports returns the transport protocols a protocol listens on.
+func Transports(protocol string) []string {
+	switch strings.ToLower(strings.TrimSpace(protocol)) {
+	case "hysteria2", "hy2", "tuic":
+		return []string{"udp"}
+	case "ss", "shadowsocks", "mixed", "socks":
+		return []string{"tcp", "udp"}
+	default:
+		return []string{"tcp"}
+	}
+}
+
+// SplitListen returns the host and port a binding listens on. Wildcard hosts
+// are returned as "" and a missing port falls back to accessPort.
+func SplitListen(listen string, accessPort int) (string, int) {
+	listen = strings.TrimSpace(listen)
+	if listen == "" {
+		return "", accessPort
+	}
+	host, rawPort, err := net.SplitHostPort(listen)
+	if err != nil {
+		if port, convErr := strconv.Atoi(strings.TrimPrefix(listen, ":")); convErr == nil {
+			return "", port
+		}
+		return normalizeHost(listen), accessPort
+	}
+	port, err := strconv.Atoi(rawPort)
+	if err != nil || port == 0 {
+		port = accessPort
+	}
+	return normalizeHost(host), port
+}
+
+// JoinListen rewrites the port of a listen address, keeping its host. Listen
+// values without a host are cleared so that the access port applies.
+func JoinListen(listen string, port int) string {
+	listen = strings.TrimSpace(listen)
+	if listen == "" {
+		return ""
+	}
+	host := listen
+	if splitHost, _, err := net.SplitHostPort(listen); err == nil {
+		host = splitHost
+	} else if _, convErr := strconv.Atoi(strings.TrimPrefix(listen, ":")); convErr == nil {
+		return ""
+	}
+	if host = strings.Trim(host, "[]"); host == "" {
+		return ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func normalizeHost(host string) string {
+	host = strings.Trim(strings.TrimSpace(host), "[]")
+	switch host {
+	case "", "0.0.0.0", "::", "*":
+		return ""
+	}
+	return strings.ToLower(host)
+}
+
+func bindingEndpoint(binding repository.ProtocolBinding) (endpoint, bool) {
+	if !strings.EqualFold(strings.TrimSpace(binding.Role), roleListener) {
+		return endpoint{}, false
+	}
+	host, port := SplitListen(binding.Listen, binding.AccessPort)
+	if port <= 0 {
+		return endpoint{}, false
+	}
+	return endpoint{host: host, port: port, transports: Transports(binding.Protocol)}, true
+}
+
+// entryEndpoint treats entry ports as occupying every local address.
+func entryEndpoint(entry repository.ProtocolEntry) endpoint {
+	return endpoint{port: entry.EntryPort, transports: Transports(entry.Protocol)}
+}
+
+func sameKernel(a, b repository.ProtocolBinding) bool {
+	kernelID := strings.TrimSpace(a.KernelID)
+	return kernelID != "" && kernelID == strings.TrimSpace(b.KernelID)
+}
+
+func describeBinding(binding repository.ProtocolBinding) string {
+	if name := strings.TrimSpace(binding.Name); name != "" {
+		return fmt.Sprintf("binding %d (%s)", binding.ID, name)
+	}
+	return fmt.Sprintf("binding %d", binding.ID)
+}
+
+// CheckBinding reports ErrConflict when a listener binding would share its
+// listen address and port with another binding on the same node, or with an
+// entry published on the node's own address. Bindings that share a kernel_id
+// describe the same inbound and never conflict with each other.
+func CheckBinding(ctx context.Context, repos *repository.Repositories, binding repository.ProtocolBinding) error {
+	candidate, ok := bindingEndpoint(binding)
+	if !ok {
+		return nil
+	}
+	siblings, err := repos.ProtocolBinding.ListByNodeIDs(ctx, []uint64{binding.NodeID})
+	if err != nil {
+		return err
+	}
+	others := make([]repository.ProtocolBinding, 0, len(siblings))
+	for _, other := range siblings {
+		if other.ID == binding.ID || sameKernel(binding, other) {
+			continue
+		}
+		if existing, ok := bindingEndpoint(other); ok && candidate.overlaps(existing) {
+			return fmt.Errorf("%w: port %d on node %d is already used by %s", repository.ErrConflict, candidate.port, binding.NodeID, describeBinding(other))
+		}
+		others = append(others, other)
+	}
+
+	entries, err := nodeEntries(ctx, repos, others)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	node, err := bindingNode(ctx, repos, binding)
+	if err != nil {
+		return err
+	}
+	matcher := newNodeMatcher(ctx, node)
+	for _, entry := range entries {
+		if candidate.overlaps(entryEndpoint(entry)) && matcher.matches(entry.EntryAddress) {
+			return fmt.Errorf("%w: port %d on node %d is already published by entry %d", repository.ErrConflict, candidate.port, binding.NodeID, entry.ID)
+		}
+	}
+	return nil
+}
+
+// CheckEntry applies the binding rules to an entry whose address resolves to
+// the node of its binding. Entries pointing at other hosts, such as relays or
+// CDN fronts, are not checked.
+func CheckEntry(ctx context.Context, repos *repository.Repositories, entry repository.ProtocolEntry, binding repository.ProtocolBinding) error {
+	if entry.EntryPort <= 0 {
+		return nil
+	}
+	node, err := bindingNode(ctx, repos, binding)
+	if err != nil {
+		return err
+	}
+	matcher := newNodeMatcher(ctx, node)
+	if !matcher.matches(entry.EntryAddress) {
+		return nil
+	}
+	candidate := entryEndpoint(entry)
+
+	siblings, err := repos.ProtocolBinding.ListByNodeIDs(ctx, []uint64{node.ID})
+	if err != nil {
+		return err
+	}
+	others := make([]repository.ProtocolBinding, 0, len(siblings))
+	for _, other := range siblings {
+		if other.ID == binding.ID || sameKernel(binding, other) {
+			continue
+		}
+		if existing, ok := bindingEndpoint(other); ok && candidate.overlaps(existing) {
+			return fmt.Errorf("%w: port %d on node %d is already used by %s", repository.ErrConflict, candidate.port, node.ID, describeBinding(other))
+		}
+		others = append(others, other)
+	}
+
+	entries, err := nodeEntries(ctx, repos, others)
+	if err != nil {
+		return err
+	}
+	for _, other := range entries {
+		if other.ID == entry.ID {
+			continue
+		}
+		if candidate.overlaps(entryEndpoint(other)) && matcher.matches(other.EntryAddress) {
+			return fmt.Errorf("%w: port %d on node %d is already published by entry %d", repository.ErrConflict, candidate.port, node.ID, other.ID)
+		}
+	}
+	return nil
+}
+
+// Allocate returns the lowest port in the node's range that no binding or
+// node-local entry uses. excludeBindingID releases the ports of a binding that
+// is being reassigned.
+func Allocate(ctx context.Context, repos *repository.Repositories, node repository.Node, excludeBindingID uint64) (int, error) {
+	if node.PortRangeStart <= 0 || node.PortRangeEnd < node.PortRangeStart {
+		return 0, repository.InvalidArgumentf("node %d has no port range configured", node.ID)
+	}
+	bindings, err := repos.ProtocolBinding.ListByNodeIDs(ctx, []uint64{node.ID})
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[int]struct{})
+	others := make([]repository.ProtocolBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding.ID == excludeBindingID {
+			continue
+		}
+		if existing, ok := bindingEndpoint(binding); ok {
+			used[existing.port] = struct{}{}
+		}
+		others = append(others, binding)
+	}
+	entries, err := nodeEntries(ctx, repos, others)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) > 0 {
+		matcher := newNodeMatcher(ctx, node)
+		for _, entry := range entries {
+			if entry.EntryPort <= 0 {
+				continue
+			}
+			if _, ok := used[entry.EntryPort]; ok {
+				continue
+			}
+			if matcher.matches(entry.EntryAddress) {
+				used[entry.EntryPort] = struct{}{}
+			}
+		}
+	}
+	for port := node.PortRangeStart; port <= node.PortRangeEnd; port++ {
+		if _, ok := used[port]; !ok {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: port range %d-%d on node %d is exhausted", repository.ErrConflict, node.PortRangeStart, node.PortRangeEnd, node.ID)
+}
+
+func nodeEntries(ctx context.Context, repos *repository.Repositories, bindings []repository.ProtocolBinding) ([]repository.ProtocolEntry, error) {
+	if len(bindings) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint64, 0, len(bindings))
+	for _, binding := range bindings {
+		ids = append(ids, binding.ID)
+	}
+	return repos.ProtocolEntry.ListByBindingIDs(ctx, ids)
+}
+
+func bindingNode(ctx context.Context, repos *repository.Repositories, binding repository.ProtocolBinding) (repository.Node, error) {
+	if binding.Node.ID == binding.NodeID && binding.NodeID != 0 {
+		return binding.Node, nil
+	}
+	return repos.Node.Get(ctx, binding.NodeID)
+}
+
+// nodeMatcher decides whether an address names the node, either literally or
+// through a shared resolved IP. Node addresses are resolved at most once.
+type nodeMatcher struct {
+	ctx      context.Context
+	hosts    map[string]struct{}
+	ips      map[string]struct{}
+	resolved bool
+}
+
+func newNodeMatcher(ctx context.Context, node repository.Node) *nodeMatcher {
+	hosts := make(map[string]struct{}, 2)
+	for _, address := range []string{node.AccessAddress, node.ControlEndpoint} {
+		if host := AddressHost(address); host != "" {
+			hosts[host] = struct{}{}
+		}
+	}
+	return &nodeMatcher{ctx: ctx, hosts: hosts}
+}
+
+func (m *nodeMatcher) matches(address string) bool {
+	host := AddressHost(address)
+	if host == "" || len(m.hosts) == 0 {
+		return false
+	}
+	if _, ok := m.hosts[host]; ok {
+		return true
+	}
+	if !m.resolved {
+		m.resolved = true
+		m.ips = make(map[string]struct{})
+		for candidate := range m.hosts {
+			for _, ip := range resolve(m.ctx, candidate) {
+				m.ips[ip] = struct{}{}
+			}
+		}
+	}
+	if len(m.ips) == 0 {
+		return false
+	}
+	for _, ip := range resolve(m.ctx, host) {
+		if _, ok := m.ips[ip]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func resolve(ctx context.Context, host string) []string {
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{ip.String()}
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := lookupHost(lookupCtx, host)
+	if err != nil {
+		return nil
+	}
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil {
+			ips = append(ips, ip.String())
+		}
+	}
+	return ips
+}
+
+// AddressHost extracts the lower-cased host of a URL, host:port or bare host.
+func AddressHost(address string) string {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return ""
+	}
+	if strings.Contains(address, "://") {
+		parsed, err := url.Parse(address)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(parsed.Hostname())
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return strings.ToLower(strings.Trim(address, "[]"))
+}
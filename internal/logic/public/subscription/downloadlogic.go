@@ -4,8 +4,6 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"net"
-	"strconv"
 	"strings"
 	"time"
 
@@ -45,7 +43,8 @@ func NewDownloadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Download
 }
 
 // Download renders a subscription using the client User-Agent to pick templates.
-func (l *DownloadLogic) Download(token, userAgent string) (DownloadResult, error) {
+// The client IP is recorded as a device sighting for DevicesLimit enforcement.
+func (l *DownloadLogic) Download(token, userAgent, clientIP string) (DownloadResult, error) {
 	token = strings.TrimSpace(token)
 	if token == "" {
 		return DownloadResult{}, repository.ErrInvalidArgument
@@ -65,6 +64,7 @@ func (l *DownloadLogic) Download(token, userAgent string) (DownloadResult, error
 	if err != nil {
 		return DownloadResult{}, err
 	}
+	l.recordDevice(sub, userAgent, clientIP, now)
 
 	entries, err := subscriptionutil.LoadSubscriptionEntries(l.ctx, l.svcCtx.Repositories, sub)
 	if err != nil {
@@ -80,56 +80,20 @@ func (l *DownloadLogic) Download(token, userAgent string) (DownloadResult, error
 		return DownloadResult{}, err
 	}
 
-	identityData := map[string]any{
-		"version":    credential.Version,
-		"status":     credential.Status,
-		"account_id": identity.AccountID,
-		"account":    identity.AccountID,
-		"password":   identity.Password,
-		"id":         identity.ID,
-		"uuid":       identity.UUID,
-		"username":   identity.Username,
-		"secret":     identity.Secret,
-	}
-
-	entryContext := normalizeEntryContext(entries)
-	planSnapshot := sub.PlanSnapshot
-	if planSnapshot == nil {
-		planSnapshot = map[string]any{}
-	}
-	data := map[string]any{
-		"subscription": map[string]any{
-			"id":                      sub.ID,
-			"name":                    sub.Name,
-			"plan":                    sub.PlanName,
-			"plan_id":                 sub.PlanID,
-			"plan_snapshot":           planSnapshot,
-			"status":                  sub.Status,
-			"token":                   sub.Token,
-			"expires_at":              sub.ExpiresAt.Format(time.RFC3339),
-			"traffic_total_bytes":     sub.TrafficTotalBytes,
-			"traffic_used_bytes":      sub.TrafficUsedBytes,
-			"traffic_remaining_bytes": maxInt64(sub.TrafficTotalBytes-sub.TrafficUsedBytes, 0),
-			"devices_limit":           sub.DevicesLimit,
-			"available_template_ids":  sub.AvailableTemplateIDs,
-		},
-		"nodes":             entryContext,
-		"protocol_bindings": entryContext,
-		"user_identity":     identityData,
-		"template": map[string]any{
-			"id":      tpl.ID,
-			"name":    tpl.Name,
-			"format":  tpl.Format,
-			"version": tpl.Version,
-		},
-		"generated_at": now.Format(time.RFC3339),
+	policy, err := l.loadEntryHealthPolicy()
+	if err != nil {
+		return DownloadResult{}, err
 	}
+	nodes := subscriptionutil.EntryContext(subscriptionutil.ArrangeEntries(entries, policy))
+	data := subscriptionutil.RenderData(sub, tpl, nodes, subscriptionutil.IdentityContext(credential, identity), now)
 
 	content, err := subtemplate.Render(tpl.Format, tpl.Content, data)
 	if err != nil {
 		return DownloadResult{}, repository.InvalidArgumentf("template render failed (template_id=%d): %v", tpl.ID, err)
 	}
 
+	l.recordTemplateUsage(tpl, now)
+
 	hash := sha256.Sum256([]byte(content))
 	etag := hex.EncodeToString(hash[:])
 
@@ -219,50 +183,16 @@ func isSubscriptionActive(sub repository.Subscription, now time.Time) bool {
 	return sub.ExpiresAt.After(now)
 }
 
-func normalizeEntryContext(entries []repository.ProtocolEntry) []map[string]any {
-	result := make([]map[string]any, 0, len(entries))
-	for _, entry := range entries {
-		if !entryVisible(entry) {
-			continue
-		}
-		binding := entry.Binding
-		node := binding.Node
-		address := selectEntryAddress(entry)
-		host, port := splitHostPort(address)
-		result = append(result, map[string]any{
-			"id":             binding.ID,
-			"binding_id":     binding.ID,
-			"entry_id":       entry.ID,
-			"kernel_id":      binding.KernelID,
-			"protocol":       binding.Protocol,
-			"role":           binding.Role,
-			"hostname":       host,
-			"port":           port,
-			"listen":         binding.Listen,
-			"connect":        binding.Connect,
-			"access_address": entry.EntryAddress,
-			"access_port":    entry.EntryPort,
-			"entry_address":  entry.EntryAddress,
-			"entry_port":     entry.EntryPort,
-			"node_id":        binding.NodeID,
-			"node_name":      node.Name,
-			"region":         node.Region,
-			"country":        node.Country,
-			"status":         entry.Status,
-			"binding_status": binding.Status,
-			"health_status":  binding.HealthStatus,
-			"profile":        cloneEntryProfile(entry.Profile),
-			"updated_at":     entry.UpdatedAt.Format(time.RFC3339),
-		})
+func (l *DownloadLogic) loadEntryHealthPolicy() (subscriptionutil.EntryHealthPolicy, error) {
+	defaults := repository.SiteSettingDefaults{
+		Name:    l.svcCtx.Config.Site.Name,
+		LogoURL: l.svcCtx.Config.Site.LogoURL,
 	}
-	return result
-}
-
-func maxInt64(a, b int64) int64 {
-	if a > b {
-		return a
+	setting, err := l.svcCtx.Repositories.Site.GetSiteSetting(l.ctx, defaults)
+	if err != nil {
+		return subscriptionutil.EntryHealthPolicy{}, err
 	}
-	return b
+	return subscriptionutil.PolicyFromSite(setting), nil
 }
 
 func containsUint64(list []uint64, target uint64) bool {
@@ -274,51 +204,38 @@ func containsUint64(list []uint64, target uint64) bool {
 	return false
 }
 
-func entryVisible(entry repository.ProtocolEntry) bool {
-	if entry.Status != status.ProtocolEntryStatusActive {
-		return false
+// recordTemplateUsage counts the download against the published template
+// version. Downloads rendered from a draft that differs from the published
+// snapshot are not counted.
+func (l *DownloadLogic) recordTemplateUsage(tpl repository.SubscriptionTemplate, now time.Time) {
+	if tpl.Version == 0 {
+		return
 	}
-	if entry.Binding.Status != status.ProtocolBindingStatusActive {
-		return false
-	}
-	return true
-}
-
-func selectEntryAddress(entry repository.ProtocolEntry) string {
-	address := strings.TrimSpace(entry.EntryAddress)
-	if address != "" && entry.EntryPort > 0 {
-		return net.JoinHostPort(address, strconv.Itoa(entry.EntryPort))
-	}
-	if address != "" {
-		return address
+	published, err := l.svcCtx.Repositories.SubscriptionTemplate.GetHistory(l.ctx, tpl.ID, tpl.Version)
+	if err != nil {
+		l.Errorf("load published template failed template_id=%d version=%d: %v", tpl.ID, tpl.Version, err)
+		return
 	}
-	nodeAddress := strings.TrimSpace(entry.Binding.Node.AccessAddress)
-	if nodeAddress != "" && entry.EntryPort > 0 {
-		return net.JoinHostPort(nodeAddress, strconv.Itoa(entry.EntryPort))
+	if !repository.DraftMatchesHistory(tpl, published) {
+		return
 	}
-	return nodeAddress
-}
-
-func splitHostPort(address string) (string, int) {
-	address = strings.TrimSpace(address)
-	if address == "" {
-		return "", 0
-	}
-	host, port, err := net.SplitHostPort(address)
-	if err != nil {
-		return address, 0
+	if err := l.svcCtx.Repositories.SubscriptionTemplate.RecordUsage(l.ctx, tpl.ID, tpl.Version, now); err != nil {
+		l.Errorf("record template usage failed template_id=%d version=%d: %v", tpl.ID, tpl.Version, err)
 	}
-	value, _ := strconv.Atoi(port)
-	return host, value
 }
 
-func cloneEntryProfile(profile map[string]any) map[string]any {
-	if profile == nil {
-		return map[string]any{}
+func (l *DownloadLogic) recordDevice(sub repository.Subscription, userAgent, clientIP string, now time.Time) {
+	if strings.TrimSpace(clientIP) == "" {
+		return
 	}
-	cloned := make(map[string]any, len(profile))
-	for key, value := range profile {
-		cloned[key] = value
+	if err := l.svcCtx.Repositories.Device.RecordSighting(l.ctx, repository.SubscriptionDevice{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		ClientIP:       clientIP,
+		Source:         repository.DeviceSourceSubscription,
+		UserAgent:      userAgent,
+		LastSeenAt:     now,
+	}); err != nil {
+		l.Errorf("record subscription device failed subscription_id=%d: %v", sub.ID, err)
 	}
-	return cloned
 }
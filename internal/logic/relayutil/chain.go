@@ -0,0 +1,233 @@
+// Package relayutil resolves relay chains formed by bindings that forward to
+// an upstream binding, and derives the health the chain exposes to entries.
+package relayutil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/portutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+// MaxChainDepth bounds the number of upstream hops behind a binding.
+const MaxChainDepth = 8
+
+// Binding sync request reasons recorded for relay chains.
+const (
+	// BindingSyncReasonUpstreamChange marks relays queued because an upstream moved.
+	BindingSyncReasonUpstreamChange = "relay.upstream_change"
+	// BindingSyncReasonDownstreamChange marks upstreams whose relay users changed.
+	BindingSyncReasonDownstreamChange = "relay.downstream_change"
+)
+
+// Chain returns the upstream hops of a binding, nearest first.
+func Chain(ctx context.Context, repos *repository.Repositories, binding repository.ProtocolBinding) ([]repository.ProtocolBinding, error) {
+	var chain []repository.ProtocolBinding
+	seen := map[uint64]struct{}{binding.ID: {}}
+	next := upstreamID(binding)
+	for next != 0 {
+		if _, ok := seen[next]; ok {
+			return nil, repository.InvalidArgumentf("relay chain of binding %d loops back to binding %d", binding.ID, next)
+		}
+		if len(chain) >= MaxChainDepth {
+			return nil, repository.InvalidArgumentf("relay chain of binding %d exceeds %d hops", binding.ID, MaxChainDepth)
+		}
+		upstream, err := repos.ProtocolBinding.Get(ctx, next)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, repository.InvalidArgumentf("upstream binding %d not found", next)
+			}
+			return nil, err
+		}
+		seen[next] = struct{}{}
+		chain = append(chain, upstream)
+		next = upstreamID(upstream)
+	}
+	return chain, nil
+}
+
+// ValidateUpstream checks that binding may forward to upstream: the upstream
+// must be a listener with a reachable port and the chain must stay acyclic and
+// within MaxChainDepth hops.
+func ValidateUpstream(ctx context.Context, repos *repository.Repositories, binding repository.ProtocolBinding, upstreamBindingID uint64) error {
+	if binding.ID != 0 && upstreamBindingID == binding.ID {
+		return repository.NewInvalidArgument("a binding cannot be its own upstream")
+	}
+	upstream, err := repos.ProtocolBinding.Get(ctx, upstreamBindingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return repository.InvalidArgumentf("upstream binding %d not found", upstreamBindingID)
+		}
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(upstream.Role), "listener") {
+		return repository.InvalidArgumentf("upstream binding %d must be a listener", upstream.ID)
+	}
+	if host, port := Endpoint(upstream); host == "" || port <= 0 {
+		return repository.InvalidArgumentf("upstream binding %d has no reachable address", upstream.ID)
+	}
+	binding.UpstreamBindingID = &upstreamBindingID
+	_, err = Chain(ctx, repos, binding)
+	return err
+}
+
+// Endpoint returns the host and port relays dial to reach an upstream binding:
+// the node's access address, falling back to a concrete listen host and then
+// to the control endpoint host.
+func Endpoint(upstream repository.ProtocolBinding) (string, int) {
+	listenHost, port := portutil.SplitListen(upstream.Listen, upstream.AccessPort)
+	host := portutil.AddressHost(upstream.Node.AccessAddress)
+	if host == "" {
+		host = listenHost
+	}
+	if host == "" {
+		host = portutil.AddressHost(upstream.Node.ControlEndpoint)
+	}
+	return host, port
+}
+
+// Downstream returns every binding that relays through one of ids, directly
+// or through further hops.
+func Downstream(ctx context.Context, repos *repository.Repositories, ids []uint64) ([]repository.ProtocolBinding, error) {
+	seen := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	var result []repository.ProtocolBinding
+	frontier := ids
+	for depth := 0; depth < MaxChainDepth && len(frontier) > 0; depth++ {
+		bindings, err := repos.ProtocolBinding.ListByUpstreamIDs(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
+		frontier = nil
+		for _, binding := range bindings {
+			if _, ok := seen[binding.ID]; ok {
+				continue
+			}
+			seen[binding.ID] = struct{}{}
+			result = append(result, binding)
+			frontier = append(frontier, binding.ID)
+		}
+	}
+	return result, nil
+}
+
+// RequestDownstreamSync queues the relays behind ids for a full sync so that
+// they pick up the new address, port or credentials of their upstream.
+func RequestDownstreamSync(ctx context.Context, repos *repository.Repositories, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	downstream, err := Downstream(ctx, repos, ids)
+	if err != nil || len(downstream) == 0 {
+		return err
+	}
+	queued := make([]uint64, 0, len(downstream))
+	for _, binding := range downstream {
+		queued = append(queued, binding.ID)
+	}
+	return repos.ProtocolBinding.RequestFullSync(ctx, queued, BindingSyncReasonUpstreamChange, time.Now().UTC())
+}
+
+// RequestUpstreamSync queues upstream bindings for a full sync so that they add
+// or drop the relay users of bindings that started or stopped forwarding
+// through them.
+func RequestUpstreamSync(ctx context.Context, repos *repository.Repositories, ids ...uint64) error {
+	queued := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			queued = append(queued, id)
+		}
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+	return repos.ProtocolBinding.RequestFullSync(ctx, queued, BindingSyncReasonDownstreamChange, time.Now().UTC())
+}
+
+// ChainHealth returns the health of each binding combined with every hop of
+// its relay chain: the worst status along the chain wins, and a disabled or
+// missing upstream counts as offline.
+func ChainHealth(ctx context.Context, repos *repository.Repositories, bindings []repository.ProtocolBinding) (map[uint64]int, error) {
+	known := make(map[uint64]repository.ProtocolBinding, len(bindings))
+	for _, binding := range bindings {
+		known[binding.ID] = binding
+	}
+	pending := bindings
+	for depth := 0; depth < MaxChainDepth && len(pending) > 0; depth++ {
+		var missing []uint64
+		for _, binding := range pending {
+			if id := upstreamID(binding); id != 0 {
+				if _, ok := known[id]; !ok {
+					missing = append(missing, id)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		loaded, err := repos.ProtocolBinding.ListByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, binding := range loaded {
+			known[binding.ID] = binding
+		}
+		pending = loaded
+	}
+
+	health := make(map[uint64]int, len(bindings))
+	for _, binding := range bindings {
+		current := binding.HealthStatus
+		seen := map[uint64]struct{}{binding.ID: {}}
+		for next := upstreamID(binding); next != 0; {
+			upstream, ok := known[next]
+			if _, looped := seen[next]; looped || !ok || upstream.Status != status.ProtocolBindingStatusActive {
+				current = status.ProtocolBindingHealthStatusOffline
+				break
+			}
+			seen[next] = struct{}{}
+			current = WorseHealth(current, upstream.HealthStatus)
+			next = upstreamID(upstream)
+		}
+		health[binding.ID] = current
+	}
+	return health, nil
+}
+
+// WorseHealth returns the less healthy of two binding health statuses.
+func WorseHealth(a, b int) int {
+	if HealthSeverity(b) > HealthSeverity(a) {
+		return b
+	}
+	return a
+}
+
+// HealthSeverity ranks a binding health status from 0 (healthy) to 4
+// (offline); unknown statuses rank as offline.
+func HealthSeverity(health int) int {
+	switch health {
+	case status.ProtocolBindingHealthStatusHealthy:
+		return 0
+	case status.ProtocolBindingHealthStatusUnknown:
+		return 1
+	case status.ProtocolBindingHealthStatusDegraded:
+		return 2
+	case status.ProtocolBindingHealthStatusUnhealthy:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func upstreamID(binding repository.ProtocolBinding) uint64 {
+	if binding.UpstreamBindingID == nil {
+		return 0
+	}
+	return *binding.UpstreamBindingID
+}
@@ -3,10 +3,12 @@ package subscriptionutil
 import (
 	"context"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 )
 
 // LoadSubscriptionEntries returns protocol entries assigned to a subscription snapshot.
+// Binding health reflects the whole relay chain behind each entry.
 func LoadSubscriptionEntries(ctx context.Context, repos *repository.Repositories, sub repository.Subscription) ([]repository.ProtocolEntry, error) {
 	if sub.ID == 0 {
 		return nil, repository.ErrInvalidArgument
@@ -25,5 +27,31 @@ func LoadSubscriptionEntries(ctx context.Context, repos *repository.Repositories
 	}
 
 	unique := uniqueBindingIDs(ids)
-	return repos.ProtocolEntry.ListByBindingIDs(ctx, unique)
+	entries, err := repos.ProtocolEntry.ListByBindingIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	return applyChainHealth(ctx, repos, entries)
+}
+
+func applyChainHealth(ctx context.Context, repos *repository.Repositories, entries []repository.ProtocolEntry) ([]repository.ProtocolEntry, error) {
+	bindings := make([]repository.ProtocolBinding, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Binding.ID != 0 {
+			bindings = append(bindings, entry.Binding)
+		}
+	}
+	if len(bindings) == 0 {
+		return entries, nil
+	}
+	health, err := relayutil.ChainHealth(ctx, repos, bindings)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if value, ok := health[entries[i].Binding.ID]; ok {
+			entries[i].Binding.HealthStatus = value
+		}
+	}
+	return entries, nil
 }
@@ -0,0 +1,115 @@
+package subscriptionutil
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/relayutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+// Unhealthy entry policies decide how entries whose binding chain is unhealthy
+// or offline are presented to users.
+const (
+	// UnhealthyEntryPolicyShow keeps unavailable entries in place.
+	UnhealthyEntryPolicyShow = "show"
+	// UnhealthyEntryPolicyHide drops unavailable entries.
+	UnhealthyEntryPolicyHide = "hide"
+	// UnhealthyEntryPolicyDemote moves unavailable entries after all others.
+	UnhealthyEntryPolicyDemote = "demote"
+	// UnhealthyEntryPolicyMark demotes unavailable entries and appends the
+	// configured suffix to their names.
+	UnhealthyEntryPolicyMark = "mark"
+)
+
+// DefaultUnhealthyEntrySuffix is appended to entry names under the mark policy
+// when the site does not configure one.
+const DefaultUnhealthyEntrySuffix = " [unavailable]"
+
+// EntryHealthPolicy is the site-wide presentation policy for entries.
+type EntryHealthPolicy struct {
+	Mode   string
+	Suffix string
+}
+
+// NormalizeUnhealthyEntryPolicy validates a policy name; empty selects show.
+func NormalizeUnhealthyEntryPolicy(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "":
+		return UnhealthyEntryPolicyShow, true
+	case UnhealthyEntryPolicyShow, UnhealthyEntryPolicyHide, UnhealthyEntryPolicyDemote, UnhealthyEntryPolicyMark:
+		return value, true
+	default:
+		return "", false
+	}
+}
+
+// PolicyFromSite builds the entry policy stored in site settings.
+func PolicyFromSite(setting repository.SiteSetting) EntryHealthPolicy {
+	mode, ok := NormalizeUnhealthyEntryPolicy(setting.UnhealthyEntryPolicy)
+	if !ok {
+		mode = UnhealthyEntryPolicyShow
+	}
+	suffix := setting.UnhealthyEntrySuffix
+	if strings.TrimSpace(suffix) == "" {
+		suffix = DefaultUnhealthyEntrySuffix
+	}
+	return EntryHealthPolicy{Mode: mode, Suffix: suffix}
+}
+
+// EntryUnavailable reports whether the binding behind an entry is unhealthy
+// or offline.
+func EntryUnavailable(entry repository.ProtocolEntry) bool {
+	health := entry.Binding.HealthStatus
+	return health == status.ProtocolBindingHealthStatusUnhealthy || health == status.ProtocolBindingHealthStatusOffline
+}
+
+// ArrangedEntry is a visible entry with the name it is presented under.
+type ArrangedEntry struct {
+	Entry       repository.ProtocolEntry
+	Name        string
+	Unavailable bool
+}
+
+// ArrangeEntries filters out disabled entries and applies policy. The result
+// is ordered by higher priority, then healthier binding, then larger node
+// capacity; demote and mark first move unavailable entries to the end.
+// Ties keep the input order.
+func ArrangeEntries(entries []repository.ProtocolEntry, policy EntryHealthPolicy) []ArrangedEntry {
+	arranged := make([]ArrangedEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Status != status.ProtocolEntryStatusActive || entry.Binding.Status != status.ProtocolBindingStatusActive {
+			continue
+		}
+		unavailable := EntryUnavailable(entry)
+		if unavailable && policy.Mode == UnhealthyEntryPolicyHide {
+			continue
+		}
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			name = entry.Binding.Node.Name
+		}
+		if unavailable && policy.Mode == UnhealthyEntryPolicyMark {
+			name += policy.Suffix
+		}
+		arranged = append(arranged, ArrangedEntry{Entry: entry, Name: name, Unavailable: unavailable})
+	}
+
+	demote := policy.Mode == UnhealthyEntryPolicyDemote || policy.Mode == UnhealthyEntryPolicyMark
+	sort.SliceStable(arranged, func(i, j int) bool {
+		a, b := arranged[i], arranged[j]
+		if demote && a.Unavailable != b.Unavailable {
+			return !a.Unavailable
+		}
+		if a.Entry.Priority != b.Entry.Priority {
+			return a.Entry.Priority > b.Entry.Priority
+		}
+		if ha, hb := relayutil.HealthSeverity(a.Entry.Binding.HealthStatus), relayutil.HealthSeverity(b.Entry.Binding.HealthStatus); ha != hb {
+			return ha < hb
+		}
+		return a.Entry.Binding.Node.CapacityMbps > b.Entry.Binding.Node.CapacityMbps
+	})
+	return arranged
+}
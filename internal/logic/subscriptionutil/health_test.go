@@ -0,0 +1,59 @@
+package subscriptionutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestArrangeEntries(t *testing.T) {
+	entry := func(id uint64, name string, health, priority, capacity int) repository.ProtocolEntry {
+		return repository.ProtocolEntry{
+			ID:       id,
+			Name:     name,
+			Status:   status.ProtocolEntryStatusActive,
+			Priority: priority,
+			Binding: repository.ProtocolBinding{
+				ID:           id,
+				Status:       status.ProtocolBindingStatusActive,
+				HealthStatus: health,
+				Node:         repository.Node{CapacityMbps: capacity},
+			},
+		}
+	}
+	disabled := entry(9, "disabled", status.ProtocolBindingHealthStatusHealthy, 10, 0)
+	disabled.Status = status.ProtocolEntryStatusDisabled
+	entries := []repository.ProtocolEntry{
+		entry(1, "offline", status.ProtocolBindingHealthStatusOffline, 5, 0),
+		entry(2, "degraded", status.ProtocolBindingHealthStatusDegraded, 0, 0),
+		entry(3, "small", status.ProtocolBindingHealthStatusHealthy, 0, 100),
+		entry(4, "large", status.ProtocolBindingHealthStatusHealthy, 0, 1000),
+		disabled,
+	}
+	names := func(arranged []ArrangedEntry) []string {
+		result := make([]string, 0, len(arranged))
+		for _, item := range arranged {
+			result = append(result, item.Name)
+		}
+		return result
+	}
+
+	show := ArrangeEntries(entries, EntryHealthPolicy{Mode: UnhealthyEntryPolicyShow})
+	require.Equal(t, []string{"offline", "large", "small", "degraded"}, names(show))
+
+	hide := ArrangeEntries(entries, EntryHealthPolicy{Mode: UnhealthyEntryPolicyHide})
+	require.Equal(t, []string{"large", "small", "degraded"}, names(hide))
+
+	demote := ArrangeEntries(entries, EntryHealthPolicy{Mode: UnhealthyEntryPolicyDemote})
+	require.Equal(t, []string{"large", "small", "degraded", "offline"}, names(demote))
+
+	mark := ArrangeEntries(entries, PolicyFromSite(repository.SiteSetting{UnhealthyEntryPolicy: "MARK"}))
+	require.Equal(t, []string{"large", "small", "degraded", "offline [unavailable]"}, names(mark))
+	require.True(t, mark[3].Unavailable)
+
+	_, ok := NormalizeUnhealthyEntryPolicy("drop")
+	require.False(t, ok)
+}
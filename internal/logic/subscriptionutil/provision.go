@@ -116,6 +116,10 @@ func EnsureOrderSubscription(ctx context.Context, repos *repository.Repositories
 		return result, err
 	}
 
+	if err := RequestUserBindingSync(ctx, repos, subscription.UserID, BindingSyncReasonOrder); err != nil {
+		return result, err
+	}
+
 	result.Order = updatedOrder
 	result.Subscription = subscription
 	result.Action = action
@@ -363,7 +367,7 @@ func renewSubscription(ctx context.Context, repos *repository.Repositories, sub
 	}
 
 	statusCode := sub.Status
-	if statusCode == 0 || statusCode == status.SubscriptionStatusExpired {
+	if statusCode == 0 || statusCode == status.SubscriptionStatusExpired || statusCode == status.SubscriptionStatusExhausted {
 		statusCode = status.SubscriptionStatusActive
 	}
 	if !expiresAt.IsZero() && expiresAt.After(now) {
@@ -375,6 +379,10 @@ func renewSubscription(ctx context.Context, repos *repository.Repositories, sub
 	if !sub.ExpiresAt.IsZero() && sub.ExpiresAt.Before(paidAt) {
 		trafficUsed = 0
 	}
+	if sub.Status == status.SubscriptionStatusExhausted {
+		// Renewing an exhausted subscription starts a fresh quota period.
+		trafficUsed = 0
+	}
 	if trafficTotal < trafficUsed {
 		trafficTotal = trafficUsed
 	}
@@ -0,0 +1,95 @@
+package subscriptionutil
+
+import (
+	"context"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// Binding sync request reasons recorded on protocol bindings.
+const (
+	BindingSyncReasonOrder               = "order.provision"
+	BindingSyncReasonSubscriptionCreate  = "subscription.create"
+	BindingSyncReasonSubscriptionUpdate  = "subscription.update"
+	BindingSyncReasonSubscriptionDisable = "subscription.disable"
+	BindingSyncReasonSubscriptionExtend  = "subscription.extend"
+	BindingSyncReasonSubscriptionExpire  = "subscription.expire"
+	BindingSyncReasonSubscriptionExhaust = "subscription.exhaust"
+	BindingSyncReasonSubscriptionRestore = "subscription.restore"
+	BindingSyncReasonCredentialRotate    = "credential.rotate"
+	BindingSyncReasonRateLimitUpdate     = "rate_limit.update"
+	BindingSyncReasonRateLimitClear      = "rate_limit.clear"
+	BindingSyncReasonRateLimitExpire     = "rate_limit.expire"
+	BindingSyncReasonDeviceLimit         = "device.limit"
+	BindingSyncReasonDeviceRestore       = "device.restore"
+)
+
+// RequestSubscriptionBindingSync queues the bindings of a subscription for kernel
+// reconciliation. The kernel user's rate is resolved across every subscription
+// of the user, so the bindings of sibling subscriptions are queued as well.
+func RequestSubscriptionBindingSync(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, reason string) error {
+	if repos == nil {
+		return repository.ErrInvalidState
+	}
+	bindings, err := LoadSubscriptionBindings(ctx, repos, sub)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint64, 0, len(bindings))
+	for _, binding := range bindings {
+		ids = append(ids, binding.ID)
+	}
+	if sub.UserID != 0 {
+		siblings, err := userBindingIDs(ctx, repos, sub.UserID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, siblings...)
+	}
+	return repos.ProtocolBinding.RequestSync(ctx, uniqueBindingIDs(ids), reason, time.Now().UTC())
+}
+
+// RequestUserBindingSync queues the bindings of every subscription a user holds.
+// Lifecycle changes may toggle sibling subscriptions (see DisableOtherActive), so
+// the whole user is reconciled rather than a single subscription.
+func RequestUserBindingSync(ctx context.Context, repos *repository.Repositories, userID uint64, reason string) error {
+	if repos == nil {
+		return repository.ErrInvalidState
+	}
+	if userID == 0 {
+		return repository.ErrInvalidArgument
+	}
+	ids, err := userBindingIDs(ctx, repos, userID)
+	if err != nil {
+		return err
+	}
+	return repos.ProtocolBinding.RequestSync(ctx, uniqueBindingIDs(ids), reason, time.Now().UTC())
+}
+
+func userBindingIDs(ctx context.Context, repos *repository.Repositories, userID uint64) ([]uint64, error) {
+	var ids []uint64
+	for page := 1; ; page++ {
+		subs, total, err := repos.Subscription.ListByUser(ctx, userID, repository.ListSubscriptionsOptions{
+			Page:      page,
+			PerPage:   repository.MaxPerPage,
+			Sort:      "created_at",
+			Direction: "asc",
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subs {
+			bindings, err := LoadSubscriptionBindings(ctx, repos, sub)
+			if err != nil {
+				return nil, err
+			}
+			for _, binding := range bindings {
+				ids = append(ids, binding.ID)
+			}
+		}
+		if len(subs) < repository.MaxPerPage || int64(page*repository.MaxPerPage) >= total {
+			return ids, nil
+		}
+	}
+}
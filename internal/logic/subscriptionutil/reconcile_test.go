@@ -0,0 +1,93 @@
+package subscriptionutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+)
+
+func TestRequestUserBindingSyncQueuesBindings(t *testing.T) {
+	svcCtx, cleanup := setupSubscriptionUtilTestContext(t)
+	defer cleanup()
+	ctx := context.Background()
+	db, repos := svcCtx.DB, svcCtx.Repositories
+
+	now := time.Now().UTC()
+	node := repository.Node{Name: "edge-1", Status: status.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	bound := repository.ProtocolBinding{Name: "bound", NodeID: node.ID, Protocol: "vless", KernelID: "bound", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&bound).Error)
+	other := repository.ProtocolBinding{Name: "other", NodeID: node.ID, Protocol: "vless", KernelID: "other", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&other).Error)
+
+	plan := repository.Plan{Name: "Basic", Slug: "basic", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&plan).Error)
+	require.NoError(t, repos.PlanProtocolBinding.Replace(ctx, plan.ID, []uint64{bound.ID}))
+
+	sub := repository.Subscription{
+		UserID:    42,
+		Name:      "Basic",
+		PlanName:  "Basic",
+		PlanID:    plan.ID,
+		Status:    status.SubscriptionStatusActive,
+		Token:     "token-42",
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, db.Create(&sub).Error)
+
+	require.NoError(t, RequestUserBindingSync(ctx, repos, sub.UserID, BindingSyncReasonOrder))
+
+	queued, err := repos.ProtocolBinding.Get(ctx, bound.ID)
+	require.NoError(t, err)
+	require.NotNil(t, queued.SyncRequestedAt)
+	require.Equal(t, BindingSyncReasonOrder, queued.SyncRequestReason)
+	first := *queued.SyncRequestedAt
+
+	untouched, err := repos.ProtocolBinding.Get(ctx, other.ID)
+	require.NoError(t, err)
+	require.Nil(t, untouched.SyncRequestedAt)
+
+	// A follow-up change keeps the original timestamp so it cannot be postponed.
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, RequestSubscriptionBindingSync(ctx, repos, sub, BindingSyncReasonSubscriptionDisable))
+	queued, err = repos.ProtocolBinding.Get(ctx, bound.ID)
+	require.NoError(t, err)
+	require.True(t, queued.SyncRequestedAt.Equal(first))
+	require.Equal(t, BindingSyncReasonSubscriptionDisable, queued.SyncRequestReason)
+
+	due, err := repos.ProtocolBinding.ListSyncRequested(ctx, first.Add(-time.Second), 10)
+	require.NoError(t, err)
+	require.Empty(t, due)
+
+	cutoff := time.Now().UTC()
+	due, err = repos.ProtocolBinding.ListSyncRequested(ctx, cutoff, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, bound.ID, due[0].ID)
+	require.Equal(t, node.ID, due[0].Node.ID)
+
+	// A request upgraded to full after it was listed is left for the next round.
+	require.NoError(t, repos.ProtocolBinding.RequestFullSync(ctx, []uint64{bound.ID}, "relay.upstream_change", cutoff))
+	claimed, err := repos.ProtocolBinding.ClaimSyncRequest(ctx, bound.ID, cutoff, due[0].SyncRequestFull)
+	require.NoError(t, err)
+	require.False(t, claimed)
+
+	claimed, err = repos.ProtocolBinding.ClaimSyncRequest(ctx, bound.ID, cutoff, true)
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = repos.ProtocolBinding.ClaimSyncRequest(ctx, bound.ID, cutoff, true)
+	require.NoError(t, err)
+	require.False(t, claimed)
+
+	queued, err = repos.ProtocolBinding.Get(ctx, bound.ID)
+	require.NoError(t, err)
+	require.Nil(t, queued.SyncRequestedAt)
+	require.False(t, queued.SyncRequestFull)
+}
@@ -0,0 +1,163 @@
+package subscriptionutil
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+)
+
+// RenderRoots lists the top-level keys available to subscription templates.
+var RenderRoots = []string{
+	"subscription",
+	"nodes",
+	"protocol_bindings",
+	"user_identity",
+	"template",
+	"variables",
+	"generated_at",
+}
+
+// IdentityContext exposes a user's derived credentials to templates.
+func IdentityContext(credential repository.UserCredential, identity security.DerivedIdentity) map[string]any {
+	return map[string]any{
+		"version":    credential.Version,
+		"status":     credential.Status,
+		"account_id": identity.AccountID,
+		"account":    identity.AccountID,
+		"password":   identity.Password,
+		"id":         identity.ID,
+		"uuid":       identity.UUID,
+		"username":   identity.Username,
+		"secret":     identity.Secret,
+	}
+}
+
+// EntryContext converts arranged entries into the template `nodes` list.
+func EntryContext(entries []ArrangedEntry) []map[string]any {
+	result := make([]map[string]any, 0, len(entries))
+	for _, arranged := range entries {
+		entry := arranged.Entry
+		binding := entry.Binding
+		node := binding.Node
+		address := selectEntryAddress(entry)
+		host, port := splitHostPort(address)
+		result = append(result, map[string]any{
+			"id":             binding.ID,
+			"binding_id":     binding.ID,
+			"entry_id":       entry.ID,
+			"name":           arranged.Name,
+			"kernel_id":      binding.KernelID,
+			"protocol":       binding.Protocol,
+			"role":           binding.Role,
+			"hostname":       host,
+			"port":           port,
+			"listen":         binding.Listen,
+			"connect":        binding.Connect,
+			"access_address": entry.EntryAddress,
+			"access_port":    entry.EntryPort,
+			"entry_address":  entry.EntryAddress,
+			"entry_port":     entry.EntryPort,
+			"node_id":        binding.NodeID,
+			"node_name":      node.Name,
+			"region":         node.Region,
+			"country":        node.Country,
+			"capacity_mbps":  node.CapacityMbps,
+			"priority":       entry.Priority,
+			"unavailable":    arranged.Unavailable,
+			"status":         entry.Status,
+			"binding_status": binding.Status,
+			"health_status":  binding.HealthStatus,
+			"profile":        cloneEntryProfile(entry.Profile),
+			"updated_at":     entry.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return result
+}
+
+// RenderData assembles the template context for a subscription. Declared
+// template variables are exposed under `variables` with their default values.
+func RenderData(sub repository.Subscription, tpl repository.SubscriptionTemplate, nodes []map[string]any, identity map[string]any, now time.Time) map[string]any {
+	planSnapshot := sub.PlanSnapshot
+	if planSnapshot == nil {
+		planSnapshot = map[string]any{}
+	}
+	variables := make(map[string]any, len(tpl.Variables))
+	for name, variable := range tpl.Variables {
+		variables[name] = variable.DefaultValue
+	}
+	remaining := sub.TrafficTotalBytes - sub.TrafficUsedBytes
+	if remaining < 0 {
+		remaining = 0
+	}
+	return map[string]any{
+		"subscription": map[string]any{
+			"id":                      sub.ID,
+			"name":                    sub.Name,
+			"plan":                    sub.PlanName,
+			"plan_id":                 sub.PlanID,
+			"plan_snapshot":           planSnapshot,
+			"status":                  sub.Status,
+			"token":                   sub.Token,
+			"expires_at":              sub.ExpiresAt.Format(time.RFC3339),
+			"traffic_total_bytes":     sub.TrafficTotalBytes,
+			"traffic_used_bytes":      sub.TrafficUsedBytes,
+			"traffic_remaining_bytes": remaining,
+			"devices_limit":           sub.DevicesLimit,
+			"available_template_ids":  sub.AvailableTemplateIDs,
+		},
+		"nodes":             nodes,
+		"protocol_bindings": nodes,
+		"user_identity":     identity,
+		"template": map[string]any{
+			"id":      tpl.ID,
+			"name":    tpl.Name,
+			"format":  tpl.Format,
+			"version": tpl.Version,
+		},
+		"variables":    variables,
+		"generated_at": now.Format(time.RFC3339),
+	}
+}
+
+func selectEntryAddress(entry repository.ProtocolEntry) string {
+	address := strings.TrimSpace(entry.EntryAddress)
+	if address != "" && entry.EntryPort > 0 {
+		return net.JoinHostPort(address, strconv.Itoa(entry.EntryPort))
+	}
+	if address != "" {
+		return address
+	}
+	nodeAddress := strings.TrimSpace(entry.Binding.Node.AccessAddress)
+	if nodeAddress != "" && entry.EntryPort > 0 {
+		return net.JoinHostPort(nodeAddress, strconv.Itoa(entry.EntryPort))
+	}
+	return nodeAddress
+}
+
+func splitHostPort(address string) (string, int) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", 0
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address, 0
+	}
+	value, _ := strconv.Atoi(port)
+	return host, value
+}
+
+func cloneEntryProfile(profile map[string]any) map[string]any {
+	if profile == nil {
+		return map[string]any{}
+	}
+	cloned := make(map[string]any, len(profile))
+	for key, value := range profile {
+		cloned[key] = value
+	}
+	return cloned
+}
@@ -17,6 +17,8 @@ func BuildPlanSnapshot(plan repository.Plan, bindingIDs []uint64) map[string]any
 		"traffic_limit_bytes": plan.TrafficLimitBytes,
 		"traffic_multipliers": cloneTrafficMultipliers(plan.TrafficMultipliers),
 		"devices_limit":       plan.DevicesLimit,
+		"upload_rate_bytes":   plan.UploadRateBytes,
+		"download_rate_bytes": plan.DownloadRateBytes,
 		"features":            append([]string(nil), plan.Features...),
 		"tags":                append([]string(nil), plan.Tags...),
 	}
@@ -96,3 +98,18 @@ func cloneTrafficMultipliers(input map[string]float64) map[string]float64 {
 	}
 	return result
 }
+
+// ExtractRateLimits reads upload/download speed limits (bytes per second) from a
+// snapshot payload; zero means unlimited.
+func ExtractRateLimits(snapshot map[string]any) (upload int64, download int64) {
+	if snapshot == nil {
+		return 0, 0
+	}
+	if value, ok := int64FromAny(snapshot["upload_rate_bytes"]); ok && value > 0 {
+		upload = value
+	}
+	if value, ok := int64FromAny(snapshot["download_rate_bytes"]); ok && value > 0 {
+		download = value
+	}
+	return upload, download
+}
@@ -3,6 +3,7 @@ package nodes
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -10,7 +11,6 @@ import (
 	subscriptionutil "github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
-	"github.com/zero-net-panel/zero-net-panel/internal/status"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -51,17 +51,33 @@ func (l *ListLogic) List(req *types.UserNodeStatusListRequest) (*types.UserNodeS
 		return nil, err
 	}
 
+	defaults := repository.SiteSettingDefaults{
+		Name:    l.svcCtx.Config.Site.Name,
+		LogoURL: l.svcCtx.Config.Site.LogoURL,
+	}
+	setting, err := l.svcCtx.Repositories.Site.GetSiteSetting(l.ctx, defaults)
+	if err != nil {
+		return nil, err
+	}
+	policy := subscriptionutil.PolicyFromSite(setting)
+
+	// 节点按其最优协议发布的排序位置排列，与订阅输出保持一致。
 	filterProtocol := strings.ToLower(strings.TrimSpace(req.Protocol))
 	bindingsByNode := make(map[uint64][]repository.ProtocolBinding)
+	nodeRank := make(map[uint64]int)
+	availableByNode := make(map[uint64]bool)
 	seen := make(map[uint64]struct{})
-	for _, entry := range entries {
-		if entry.Status != status.ProtocolEntryStatusActive || entry.Binding.Status != status.ProtocolBindingStatusActive {
-			continue
-		}
-		binding := entry.Binding
+	for _, arranged := range subscriptionutil.ArrangeEntries(entries, policy) {
+		binding := arranged.Entry.Binding
 		if filterProtocol != "" && !strings.EqualFold(binding.Protocol, filterProtocol) {
 			continue
 		}
+		if _, ok := nodeRank[binding.NodeID]; !ok {
+			nodeRank[binding.NodeID] = len(nodeRank)
+		}
+		if !arranged.Unavailable {
+			availableByNode[binding.NodeID] = true
+		}
 		if _, ok := seen[binding.ID]; ok {
 			continue
 		}
@@ -73,34 +89,46 @@ func (l *ListLogic) List(req *types.UserNodeStatusListRequest) (*types.UserNodeS
 		return emptyNodeResponse(req.Page, req.PerPage), nil
 	}
 
-	opts := repository.ListNodesOptions{
-		Page:     req.Page,
-		PerPage:  req.PerPage,
-		Status:   req.Status,
-		Protocol: req.Protocol,
-	}
-
 	nodeIDs := make([]uint64, 0, len(bindingsByNode))
 	for nodeID := range bindingsByNode {
 		nodeIDs = append(nodeIDs, nodeID)
 	}
-	opts.NodeIDs = nodeIDs
-
-	nodes, total, err := l.svcCtx.Repositories.Node.List(l.ctx, opts)
+	nodes, err := l.listNodes(repository.ListNodesOptions{
+		Status:   req.Status,
+		Protocol: req.Protocol,
+		NodeIDs:  nodeIDs,
+	})
 	if err != nil {
 		return nil, err
 	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodeRank[nodes[i].ID] < nodeRank[nodes[j].ID]
+	})
 
-	result := make([]types.UserNodeStatusSummary, 0, len(nodes))
-	for _, node := range nodes {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	total := int64(len(nodes))
+	start := (page - 1) * perPage
+	if start > len(nodes) {
+		start = len(nodes)
+	}
+	end := start + perPage
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	result := make([]types.UserNodeStatusSummary, 0, end-start)
+	for _, node := range nodes[start:end] {
 		kernels, err := l.svcCtx.Repositories.Node.GetKernels(l.ctx, node.ID)
 		if err != nil {
 			return nil, err
 		}
-		result = append(result, mapUserNodeStatus(node, kernels, bindingsByNode[node.ID]))
+		summary := mapUserNodeStatus(node, kernels, bindingsByNode[node.ID])
+		if policy.Mode == subscriptionutil.UnhealthyEntryPolicyMark && !availableByNode[node.ID] {
+			summary.Name += policy.Suffix
+		}
+		result = append(result, summary)
 	}
 
-	page, perPage := normalizePage(req.Page, req.PerPage)
 	pagination := types.PaginationMeta{
 		Page:       page,
 		PerPage:    perPage,
@@ -115,6 +143,22 @@ func (l *ListLogic) List(req *types.UserNodeStatusListRequest) (*types.UserNodeS
 	}, nil
 }
 
+// listNodes 分页读取全部符合条件的节点，以便按协议发布顺序整体排序。
+func (l *ListLogic) listNodes(opts repository.ListNodesOptions) ([]repository.Node, error) {
+	opts.PerPage = 100
+	var nodes []repository.Node
+	for opts.Page = 1; ; opts.Page++ {
+		batch, total, err := l.svcCtx.Repositories.Node.List(l.ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, batch...)
+		if len(batch) == 0 || int64(len(nodes)) >= total {
+			return nodes, nil
+		}
+	}
+}
+
 func emptyNodeResponse(page, perPage int) *types.UserNodeStatusListResponse {
 	page, perPage = normalizePage(page, perPage)
 	return &types.UserNodeStatusListResponse{
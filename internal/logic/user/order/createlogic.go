@@ -218,6 +218,8 @@ func (l *CreateLogic) Create(req *types.UserCreateOrderRequest) (resp *types.Use
 			"traffic_limit_bytes": plan.TrafficLimitBytes,
 			"traffic_multipliers": cloneTrafficMultipliers(plan.TrafficMultipliers),
 			"devices_limit":       plan.DevicesLimit,
+			"upload_rate_bytes":   plan.UploadRateBytes,
+			"download_rate_bytes": plan.DownloadRateBytes,
 			"features":            plan.Features,
 			"tags":                plan.Tags,
 		}
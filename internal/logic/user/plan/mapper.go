@@ -17,6 +17,8 @@ func toUserPlanSummary(plan repository.Plan, options []repository.PlanBillingOpt
 		DurationDays:      plan.DurationDays,
 		TrafficLimitBytes: plan.TrafficLimitBytes,
 		DevicesLimit:      plan.DevicesLimit,
+		UploadRateBytes:   plan.UploadRateBytes,
+		DownloadRateBytes: plan.DownloadRateBytes,
 		Tags:              append([]string(nil), plan.Tags...),
 	}
 }
@@ -12,14 +12,14 @@ import (
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
 
-// ListLogic 查询用户订阅列表?
+// ListLogic 查询用户订阅列表。
 type ListLogic struct {
 	logx.Logger
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
 }
 
-// NewListLogic 构造函数?
+// NewListLogic 构造函数。
 func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
 	return &ListLogic{
 		Logger: logx.WithContext(ctx),
@@ -28,7 +28,7 @@ func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
 	}
 }
 
-// List 返回订阅列表?
+// List 返回订阅列表。
 func (l *ListLogic) List(req *types.UserListSubscriptionsRequest, subscriptionBase string) (*types.UserSubscriptionListResponse, error) {
 	user, ok := security.UserFromContext(l.ctx)
 	if !ok {
@@ -1,20 +1,42 @@
 package nodecfg
 
+import "strings"
+
 const (
-	DefaultKernelProtocol                     = "http"
-	DefaultKernelHTTPTimeoutSeconds           = 5
-	DefaultKernelStatusPollIntervalSeconds    = 30
-	DefaultKernelStatusPollBackoffEnabled     = true
+	DefaultKernelProtocol                            = "http"
+	DefaultKernelHTTPTimeoutSeconds                  = 5
+	DefaultKernelStatusPollIntervalSeconds           = 30
+	DefaultKernelStatusPollBackoffEnabled            = true
 	DefaultKernelStatusPollBackoffMaxIntervalSeconds = 300
-	DefaultKernelStatusPollBackoffMultiplier  = 2
-	DefaultKernelStatusPollBackoffJitter      = 0.2
-	DefaultKernelOfflineProbeMaxIntervalSeconds = 0
+	DefaultKernelStatusPollBackoffMultiplier         = 2
+	DefaultKernelStatusPollBackoffJitter             = 0.2
+	DefaultKernelOfflineProbeMaxIntervalSeconds      = 0
+	DefaultKernelEventMode                           = KernelEventModePush
+)
+
+// Kernel event delivery modes: push relies on callbacks registered on the
+// kernel, pull lets the panel consume /v1/events/stream from the kernel.
+const (
+	KernelEventModePush = "push"
+	KernelEventModePull = "pull"
 )
 
+// NormalizeKernelEventMode validates an event mode, returning ok=false for unknown values.
+func NormalizeKernelEventMode(mode string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", KernelEventModePush:
+		return KernelEventModePush, true
+	case KernelEventModePull:
+		return KernelEventModePull, true
+	default:
+		return "", false
+	}
+}
+
 // KernelBackoffConfig describes per-node poll backoff behavior.
 type KernelBackoffConfig struct {
-	Enabled             bool
-	MaxIntervalSeconds  int
-	Multiplier          float64
-	Jitter              float64
+	Enabled            bool
+	MaxIntervalSeconds int
+	Multiplier         float64
+	Jitter             float64
 }
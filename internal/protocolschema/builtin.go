@@ -0,0 +1,261 @@
+package protocolschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	fingerprints  = []string{"chrome", "firefox", "safari", "ios", "edge", "random"}
+	networks      = []string{"tcp", "ws", "grpc", "httpupgrade", "h2"}
+	ssCiphers     = []string{"aes-128-gcm", "aes-256-gcm", "chacha20-ietf-poly1305", "2022-blake3-aes-128-gcm", "2022-blake3-aes-256-gcm", "2022-blake3-chacha20-poly1305", "none"}
+	vmessCiphers  = []string{"auto", "aes-128-gcm", "chacha20-poly1305", "none", "zero"}
+	vlessFlows    = []string{"xtls-rprx-vision"}
+	congestions   = []string{"cubic", "new_reno", "bbr"}
+	udpRelayModes = []string{"native", "quic"}
+)
+
+func intPtr(value int) *int { return &value }
+
+func builtinSchemas() []Schema {
+	return []Schema{
+		{
+			Protocol:    "vless",
+			Description: "VLESS with optional TLS or REALITY and XTLS flow control",
+			Server: joinFields(
+				[]Field{{Name: "flow", Type: TypeString, Enum: vlessFlows, Description: "XTLS flow; vision needs tcp with tls or reality"}},
+				securityFields([]string{"none", "tls", "reality"}, "none", false),
+				transportFields(),
+			),
+			Client: joinFields(
+				[]Field{{Name: "flow", Type: TypeString, Enum: vlessFlows, Description: "XTLS flow advertised to clients"}},
+				securityFields([]string{"none", "tls", "reality"}, "none", true),
+				transportFields(),
+			),
+			Presets: []Preset{
+				{
+					Name:        "vless-reality-vision",
+					Description: "VLESS over tcp with REALITY and the vision flow",
+					Server:      map[string]any{"flow": "xtls-rprx-vision", "security": "reality", "network": "tcp"},
+					Client:      map[string]any{"flow": "xtls-rprx-vision", "security": "reality", "network": "tcp", "fingerprint": "chrome"},
+				},
+				{
+					Name:        "vless-ws-tls",
+					Description: "VLESS over websocket with TLS",
+					Server:      map[string]any{"security": "tls", "network": "ws", "path": "/vless"},
+					Client:      map[string]any{"security": "tls", "network": "ws", "path": "/vless", "fingerprint": "chrome"},
+				},
+			},
+			Rules: []Rule{visionRule},
+		},
+		{
+			Protocol:    "vmess",
+			Description: "VMess with optional TLS",
+			Server: joinFields(
+				[]Field{
+					{Name: "cipher", Type: TypeString, Enum: vmessCiphers, Default: "auto", Description: "VMess body cipher"},
+					{Name: "alter_id", Type: TypeInteger, Min: intPtr(0), Default: 0, Description: "Legacy alterId; 0 enables AEAD headers"},
+				},
+				securityFields([]string{"none", "tls"}, "none", false),
+				transportFields(),
+			),
+			Client: joinFields(
+				[]Field{
+					{Name: "cipher", Type: TypeString, Enum: vmessCiphers, Default: "auto", Description: "VMess body cipher"},
+					{Name: "alter_id", Type: TypeInteger, Min: intPtr(0), Default: 0, Description: "Legacy alterId; 0 enables AEAD headers"},
+				},
+				securityFields([]string{"none", "tls"}, "none", true),
+				transportFields(),
+			),
+			Presets: []Preset{
+				{
+					Name:        "vmess-ws-tls",
+					Description: "VMess over websocket with TLS",
+					Server:      map[string]any{"cipher": "auto", "security": "tls", "network": "ws", "path": "/vmess"},
+					Client:      map[string]any{"cipher": "auto", "security": "tls", "network": "ws", "path": "/vmess"},
+				},
+			},
+		},
+		{
+			Protocol:    "trojan",
+			Description: "Trojan over TLS or REALITY",
+			Server:      joinFields(securityFields([]string{"tls", "reality"}, "tls", false), transportFields()),
+			Client:      joinFields(securityFields([]string{"tls", "reality"}, "tls", true), transportFields()),
+			Presets: []Preset{
+				{
+					Name:        "trojan-tcp-tls",
+					Description: "Trojan over tcp with TLS",
+					Server:      map[string]any{"security": "tls", "network": "tcp"},
+					Client:      map[string]any{"security": "tls", "network": "tcp"},
+				},
+				{
+					Name:        "trojan-grpc-tls",
+					Description: "Trojan over gRPC with TLS",
+					Server:      map[string]any{"security": "tls", "network": "grpc", "service_name": "trojan"},
+					Client:      map[string]any{"security": "tls", "network": "grpc", "service_name": "trojan"},
+				},
+			},
+		},
+		{
+			Protocol:    "shadowsocks",
+			Aliases:     []string{"ss"},
+			Description: "Shadowsocks AEAD and 2022 ciphers",
+			Server:      shadowsocksFields(true),
+			Client:      shadowsocksFields(false),
+			Presets: []Preset{
+				{
+					Name:        "ss-2022",
+					Description: "Shadowsocks 2022 with AES-128",
+					Server:      map[string]any{"cipher": "2022-blake3-aes-128-gcm", "udp": true},
+					Client:      map[string]any{"cipher": "2022-blake3-aes-128-gcm", "udp": true},
+				},
+				{
+					Name:        "ss-aead",
+					Description: "Classic Shadowsocks AEAD with AES-128",
+					Server:      map[string]any{"cipher": "aes-128-gcm", "udp": true},
+					Client:      map[string]any{"cipher": "aes-128-gcm", "udp": true},
+				},
+			},
+		},
+		{
+			Protocol:    "hysteria2",
+			Aliases:     []string{"hy2"},
+			Description: "Hysteria 2 over QUIC",
+			Server: []Field{
+				{Name: "up_mbps", Type: TypeInteger, Min: intPtr(0), Description: "Server upload limit in Mbps; 0 means unlimited"},
+				{Name: "down_mbps", Type: TypeInteger, Min: intPtr(0), Description: "Server download limit in Mbps; 0 means unlimited"},
+				{Name: "ignore_client_bandwidth", Type: TypeBoolean, Default: false, Description: "Ignore bandwidth hints sent by clients"},
+				{Name: "obfs", Type: TypeString, Enum: []string{"salamander"}, Description: "Packet obfuscation"},
+				{Name: "obfs_password", Type: TypeString, RequiredWhen: &Condition{Field: "obfs", Values: []string{"salamander"}}, Description: "Obfuscation password"},
+				{Name: "masquerade", Type: TypeString, Description: "URL served to non-Hysteria HTTP/3 clients"},
+				{Name: "alpn", Type: TypeArray, Items: TypeString, Description: "TLS ALPN values"},
+			},
+			Client: []Field{
+				{Name: "up_mbps", Type: TypeInteger, Min: intPtr(0), Description: "Client upload hint in Mbps"},
+				{Name: "down_mbps", Type: TypeInteger, Min: intPtr(0), Description: "Client download hint in Mbps"},
+				{Name: "obfs", Type: TypeString, Enum: []string{"salamander"}, Description: "Packet obfuscation"},
+				{Name: "obfs_password", Type: TypeString, RequiredWhen: &Condition{Field: "obfs", Values: []string{"salamander"}}, Description: "Obfuscation password"},
+				{Name: "sni", Type: TypeString, Description: "TLS server name"},
+				{Name: "alpn", Type: TypeArray, Items: TypeString, Description: "TLS ALPN values"},
+				{Name: "allow_insecure", Type: TypeBoolean, Default: false, Description: "Skip certificate verification on clients"},
+			},
+			Presets: []Preset{
+				{
+					Name:        "hysteria2-salamander",
+					Description: "Hysteria 2 with salamander obfuscation; set obfs_password",
+					Server:      map[string]any{"obfs": "salamander"},
+					Client:      map[string]any{"obfs": "salamander"},
+				},
+			},
+		},
+		{
+			Protocol:    "tuic",
+			Description: "TUIC v5 over QUIC",
+			Server: []Field{
+				{Name: "congestion_control", Type: TypeString, Enum: congestions, Default: "bbr", Description: "QUIC congestion control"},
+				{Name: "zero_rtt_handshake", Type: TypeBoolean, Default: false, Description: "Accept 0-RTT handshakes"},
+				{Name: "alpn", Type: TypeArray, Items: TypeString, Description: "TLS ALPN values"},
+			},
+			Client: []Field{
+				{Name: "congestion_control", Type: TypeString, Enum: congestions, Default: "bbr", Description: "QUIC congestion control"},
+				{Name: "udp_relay_mode", Type: TypeString, Enum: udpRelayModes, Default: "native", Description: "UDP relay mode"},
+				{Name: "zero_rtt_handshake", Type: TypeBoolean, Default: false, Description: "Use 0-RTT handshakes"},
+				{Name: "sni", Type: TypeString, Description: "TLS server name"},
+				{Name: "alpn", Type: TypeArray, Items: TypeString, Description: "TLS ALPN values"},
+				{Name: "allow_insecure", Type: TypeBoolean, Default: false, Description: "Skip certificate verification on clients"},
+			},
+			Presets: []Preset{
+				{
+					Name:        "tuic-bbr",
+					Description: "TUIC with bbr and native UDP relay",
+					Server:      map[string]any{"congestion_control": "bbr", "alpn": []any{"h3"}},
+					Client:      map[string]any{"congestion_control": "bbr", "udp_relay_mode": "native", "alpn": []any{"h3"}},
+				},
+			},
+		},
+	}
+}
+
+// securityFields lists the TLS and REALITY keys. Servers hold the REALITY
+// private key and targets, clients the public key.
+func securityFields(modes []string, defaultMode string, client bool) []Field {
+	fields := []Field{
+		{Name: "security", Type: TypeString, Enum: modes, Default: defaultMode, Description: "Transport security"},
+		{Name: "sni", Type: TypeString, Description: "TLS server name"},
+		{Name: "alpn", Type: TypeArray, Items: TypeString, Description: "TLS ALPN values"},
+	}
+	if client {
+		fields = append(fields,
+			Field{Name: "fingerprint", Type: TypeString, Enum: fingerprints, Description: "uTLS client fingerprint"},
+			Field{Name: "allow_insecure", Type: TypeBoolean, Default: false, Description: "Skip certificate verification on clients"},
+		)
+	}
+	if !containsFold(modes, "reality") {
+		return fields
+	}
+
+	realityWhen := &Condition{Field: "security", Values: []string{"reality"}}
+	if client {
+		return append(fields, Field{Name: "reality", Type: TypeObject, RequiredWhen: realityWhen, Description: "REALITY client settings", Fields: []Field{
+			{Name: "public_key", Type: TypeString, Required: true, Description: "REALITY public key"},
+			{Name: "short_id", Type: TypeString, Description: "REALITY short ID"},
+			{Name: "spider_x", Type: TypeString, Description: "Initial crawler path"},
+		}})
+	}
+	return append(fields, Field{Name: "reality", Type: TypeObject, RequiredWhen: realityWhen, Description: "REALITY server settings", Fields: []Field{
+		{Name: "private_key", Type: TypeString, Required: true, Description: "REALITY private key"},
+		{Name: "short_ids", Type: TypeArray, Items: TypeString, Required: true, Description: "Accepted short IDs"},
+		{Name: "server_names", Type: TypeArray, Items: TypeString, Required: true, Description: "Accepted server names"},
+		{Name: "handshake_target", Type: TypeString, Required: true, Description: "Upstream host:port used for the handshake"},
+	}})
+}
+
+func transportFields() []Field {
+	grpcWhen := &Condition{Field: "network", Values: []string{"grpc"}}
+	return []Field{
+		{Name: "network", Type: TypeString, Enum: networks, Default: "tcp", Description: "Transport"},
+		{Name: "path", Type: TypeString, Description: "Websocket or HTTP path"},
+		{Name: "host", Type: TypeString, Description: "HTTP host header"},
+		{Name: "service_name", Type: TypeString, RequiredWhen: grpcWhen, Description: "gRPC service name"},
+	}
+}
+
+func shadowsocksFields(server bool) []Field {
+	return []Field{
+		{Name: "cipher", Type: TypeString, Enum: ssCiphers, Required: server, Default: "aes-128-gcm", Description: "Shadowsocks cipher"},
+		{Name: "udp", Type: TypeBoolean, Default: true, Description: "Relay UDP"},
+		{Name: "plugin", Type: TypeString, Description: "SIP003 plugin name"},
+		{Name: "plugin_opts", Type: TypeString, Description: "SIP003 plugin options"},
+	}
+}
+
+func joinFields(groups ...[]Field) []Field {
+	var fields []Field
+	seen := make(map[string]struct{})
+	for _, group := range groups {
+		for _, field := range group {
+			if _, ok := seen[field.Name]; ok {
+				continue
+			}
+			seen[field.Name] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// visionRule rejects the vision flow on transports that cannot carry it.
+func visionRule(profile map[string]any) string {
+	flow, _ := profile["flow"].(string)
+	if !strings.EqualFold(flow, "xtls-rprx-vision") {
+		return ""
+	}
+	if network, _ := profile["network"].(string); network != "" && !strings.EqualFold(network, "tcp") {
+		return fmt.Sprintf("flow: xtls-rprx-vision requires network tcp, got %s", network)
+	}
+	security, _ := profile["security"].(string)
+	if !strings.EqualFold(security, "tls") && !strings.EqualFold(security, "reality") {
+		return "flow: xtls-rprx-vision requires security tls or reality"
+	}
+	return ""
+}
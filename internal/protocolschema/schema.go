@@ -0,0 +1,336 @@
+// Package protocolschema describes the profile fields each protocol accepts,
+// validates profiles against them and ships presets for common configurations.
+// Server fields apply to listener bindings; client fields apply to connector
+// bindings and to protocol entries published to subscribers. Protocols without
+// a registered schema keep free-form profiles.
+package protocolschema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// FieldType is the JSON type of a profile field.
+type FieldType string
+
+// Supported field types.
+const (
+	TypeString  FieldType = "string"
+	TypeInteger FieldType = "integer"
+	TypeBoolean FieldType = "boolean"
+	TypeArray   FieldType = "array"
+	TypeObject  FieldType = "object"
+)
+
+// Condition makes a field required while a sibling field has one of Values.
+type Condition struct {
+	Field  string
+	Values []string
+}
+
+// Field describes one profile key.
+type Field struct {
+	Name         string
+	Type         FieldType
+	Description  string
+	Required     bool
+	RequiredWhen *Condition
+	Enum         []string
+	// Items is the element type of array fields.
+	Items   FieldType
+	Min     *int
+	Max     *int
+	Default any
+	// Fields lists the keys of object fields.
+	Fields []Field
+}
+
+// Preset is a ready-made server and client profile for a common configuration.
+type Preset struct {
+	Name        string
+	Description string
+	Server      map[string]any
+	Client      map[string]any
+}
+
+// Rule checks constraints spanning several fields and returns a problem or "".
+type Rule func(profile map[string]any) string
+
+// Schema lists the server and client profile fields of a protocol. Keys that
+// are not listed are rejected so that typos surface before the kernel sees them.
+type Schema struct {
+	Protocol    string
+	Aliases     []string
+	Description string
+	Server      []Field
+	Client      []Field
+	Presets     []Preset
+	Rules       []Rule
+}
+
+// ValidationError lists every problem found in a profile.
+type ValidationError struct {
+	Protocol string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s profile: %s", e.Protocol, strings.Join(e.Problems, "; "))
+}
+
+var registry = buildRegistry(builtinSchemas())
+
+func buildRegistry(schemas []Schema) map[string]Schema {
+	index := make(map[string]Schema, len(schemas))
+	for _, schema := range schemas {
+		index[schema.Protocol] = schema
+		for _, alias := range schema.Aliases {
+			index[alias] = schema
+		}
+	}
+	return index
+}
+
+// Lookup returns the schema of a protocol or one of its aliases.
+func Lookup(protocol string) (Schema, bool) {
+	schema, ok := registry[strings.ToLower(strings.TrimSpace(protocol))]
+	return schema, ok
+}
+
+// All returns the registered schemas ordered by protocol.
+func All() []Schema {
+	seen := make(map[string]struct{}, len(registry))
+	schemas := make([]Schema, 0, len(registry))
+	for _, schema := range registry {
+		if _, ok := seen[schema.Protocol]; ok {
+			continue
+		}
+		seen[schema.Protocol] = struct{}{}
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Protocol < schemas[j].Protocol })
+	return schemas
+}
+
+// Preset returns the preset with the given name.
+func (s Schema) Preset(name string) (Preset, bool) {
+	name = strings.TrimSpace(name)
+	for _, preset := range s.Presets {
+		if strings.EqualFold(preset.Name, name) {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// ValidateServer checks a server-side profile. Unregistered protocols pass.
+func ValidateServer(protocol string, profile map[string]any) error {
+	schema, ok := Lookup(protocol)
+	if !ok {
+		return nil
+	}
+	return schema.validate(schema.Server, profile)
+}
+
+// ValidateClient checks a client-side profile. Unregistered protocols pass.
+func ValidateClient(protocol string, profile map[string]any) error {
+	schema, ok := Lookup(protocol)
+	if !ok {
+		return nil
+	}
+	return schema.validate(schema.Client, profile)
+}
+
+// ApplyPreset overlays profile onto the server or client side of a preset.
+func ApplyPreset(protocol, name string, client bool, profile map[string]any) (map[string]any, error) {
+	schema, ok := Lookup(protocol)
+	if !ok {
+		return nil, fmt.Errorf("protocol %q has no presets", protocol)
+	}
+	preset, ok := schema.Preset(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown %s preset %q", schema.Protocol, name)
+	}
+	base := preset.Server
+	if client {
+		base = preset.Client
+	}
+	return MergeProfile(base, profile), nil
+}
+
+// MergeProfile deep-merges override onto base without modifying either.
+func MergeProfile(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for key, value := range base {
+		if nested, ok := value.(map[string]any); ok {
+			value = MergeProfile(nested, nil)
+		}
+		merged[key] = value
+	}
+	for key, value := range override {
+		nested, isMap := value.(map[string]any)
+		existing, hasMap := merged[key].(map[string]any)
+		if isMap && hasMap {
+			merged[key] = MergeProfile(existing, nested)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+func (s Schema) validate(fields []Field, profile map[string]any) error {
+	var problems []string
+	validateObject("", fields, profile, &problems)
+	if len(problems) == 0 {
+		for _, rule := range s.Rules {
+			if problem := rule(profile); problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Protocol: s.Protocol, Problems: problems}
+	}
+	return nil
+}
+
+func validateObject(prefix string, fields []Field, value map[string]any, problems *[]string) {
+	known := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		known[field.Name] = struct{}{}
+	}
+	unknown := make([]string, 0)
+	for key := range value {
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		*problems = append(*problems, fmt.Sprintf("%s%s: unknown field", prefix, key))
+	}
+
+	for _, field := range fields {
+		path := prefix + field.Name
+		raw, ok := value[field.Name]
+		if !ok || isEmpty(raw) {
+			if field.Required || field.RequiredWhen.matches(value) {
+				*problems = append(*problems, path+": is required")
+			}
+			continue
+		}
+		validateValue(path, field, raw, problems)
+	}
+}
+
+func validateValue(path string, field Field, raw any, problems *[]string) {
+	switch field.Type {
+	case TypeString:
+		text, ok := raw.(string)
+		if !ok {
+			*problems = append(*problems, path+": must be a string")
+			return
+		}
+		if len(field.Enum) > 0 && !containsFold(field.Enum, text) {
+			*problems = append(*problems, fmt.Sprintf("%s: must be one of %s", path, strings.Join(field.Enum, ", ")))
+		}
+	case TypeInteger:
+		number, ok := toInteger(raw)
+		if !ok {
+			*problems = append(*problems, path+": must be an integer")
+			return
+		}
+		if field.Min != nil && number < *field.Min {
+			*problems = append(*problems, fmt.Sprintf("%s: must be at least %d", path, *field.Min))
+		}
+		if field.Max != nil && number > *field.Max {
+			*problems = append(*problems, fmt.Sprintf("%s: must be at most %d", path, *field.Max))
+		}
+	case TypeBoolean:
+		if _, ok := raw.(bool); !ok {
+			*problems = append(*problems, path+": must be a boolean")
+		}
+	case TypeArray:
+		items, ok := toSlice(raw)
+		if !ok {
+			*problems = append(*problems, path+": must be an array")
+			return
+		}
+		for i, item := range items {
+			validateValue(fmt.Sprintf("%s[%d]", path, i), Field{Type: field.Items, Enum: field.Enum}, item, problems)
+		}
+	case TypeObject:
+		object, ok := raw.(map[string]any)
+		if !ok {
+			*problems = append(*problems, path+": must be an object")
+			return
+		}
+		if field.Fields != nil {
+			validateObject(path+".", field.Fields, object, problems)
+		}
+	}
+}
+
+func (c *Condition) matches(siblings map[string]any) bool {
+	if c == nil {
+		return false
+	}
+	value, _ := siblings[c.Field].(string)
+	return containsFold(c.Values, value)
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	default:
+		return false
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toInteger(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+func toSlice(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+	case []string:
+		items := make([]any, 0, len(v))
+		for _, item := range v {
+			items = append(items, item)
+		}
+		return items, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,106 @@
+package protocolschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decodeProfile(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var profile map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &profile))
+	return profile
+}
+
+func TestValidateServer(t *testing.T) {
+	require.NoError(t, ValidateServer("vless", map[string]any{"security": "none"}))
+	require.NoError(t, ValidateServer("SS", map[string]any{"cipher": "aes-128-gcm"}))
+	require.NoError(t, ValidateServer("mixed", map[string]any{"anything": 1}))
+	require.NoError(t, ValidateServer("vless", decodeProfile(t, `{
+		"flow": "xtls-rprx-vision",
+		"security": "reality",
+		"reality": {
+			"private_key": "key",
+			"short_ids": ["ab"],
+			"server_names": ["example.com"],
+			"handshake_target": "example.com:443"
+		}
+	}`)))
+
+	err := ValidateServer("vless", decodeProfile(t, `{"flow": "xtls-rprx-vison", "security": "reality", "netwrok": "tcp"}`))
+	var validation *ValidationError
+	require.ErrorAs(t, err, &validation)
+	require.Equal(t, []string{
+		"netwrok: unknown field",
+		"flow: must be one of xtls-rprx-vision",
+		"reality: is required",
+	}, validation.Problems)
+
+	err = ValidateServer("vless", decodeProfile(t, `{"security": "reality", "reality": {"private_key": "key", "short_ids": "ab"}}`))
+	require.ErrorAs(t, err, &validation)
+	require.Equal(t, []string{
+		"reality.short_ids: must be an array",
+		"reality.server_names: is required",
+		"reality.handshake_target: is required",
+	}, validation.Problems)
+
+	err = ValidateServer("vless", map[string]any{"flow": "xtls-rprx-vision", "security": "tls", "network": "ws"})
+	require.ErrorContains(t, err, "requires network tcp")
+
+	err = ValidateServer("shadowsocks", map[string]any{"udp": true})
+	require.ErrorContains(t, err, "cipher: is required")
+
+	err = ValidateServer("hysteria2", decodeProfile(t, `{"up_mbps": 1.5, "obfs": "salamander"}`))
+	require.ErrorAs(t, err, &validation)
+	require.Equal(t, []string{"up_mbps: must be an integer", "obfs_password: is required"}, validation.Problems)
+}
+
+func TestValidateClient(t *testing.T) {
+	require.NoError(t, ValidateClient("vless", nil))
+	require.NoError(t, ValidateClient("shadowsocks", map[string]any{}))
+
+	err := ValidateClient("vless", map[string]any{"security": "reality", "reality": map[string]any{"private_key": "key"}})
+	var validation *ValidationError
+	require.ErrorAs(t, err, &validation)
+	require.Equal(t, []string{"reality.private_key: unknown field", "reality.public_key: is required"}, validation.Problems)
+}
+
+func TestApplyPreset(t *testing.T) {
+	profile, err := ApplyPreset("vless", "vless-reality-vision", false, map[string]any{
+		"reality": map[string]any{
+			"private_key":      "key",
+			"short_ids":        []any{"ab"},
+			"server_names":     []any{"example.com"},
+			"handshake_target": "example.com:443",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "xtls-rprx-vision", profile["flow"])
+	require.NoError(t, ValidateServer("vless", profile))
+
+	profile, err = ApplyPreset("trojan", "trojan-grpc-tls", true, map[string]any{"service_name": "custom"})
+	require.NoError(t, err)
+	require.Equal(t, "custom", profile["service_name"])
+	require.NoError(t, ValidateClient("trojan", profile))
+
+	_, err = ApplyPreset("vless", "missing", false, nil)
+	require.Error(t, err)
+	_, err = ApplyPreset("mixed", "any", false, nil)
+	require.Error(t, err)
+
+	for _, schema := range All() {
+		for _, preset := range schema.Presets {
+			require.NotEmpty(t, preset.Server, "%s/%s", schema.Protocol, preset.Name)
+			require.NotEmpty(t, preset.Client, "%s/%s", schema.Protocol, preset.Name)
+		}
+	}
+}
+
+func TestMergeProfileDoesNotModifyInputs(t *testing.T) {
+	base := map[string]any{"reality": map[string]any{"short_id": "ab"}}
+	merged := MergeProfile(base, map[string]any{"reality": map[string]any{"public_key": "pk"}})
+	require.Equal(t, map[string]any{"short_id": "ab", "public_key": "pk"}, merged["reality"])
+	require.Equal(t, map[string]any{"short_id": "ab"}, base["reality"])
+}
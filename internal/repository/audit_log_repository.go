@@ -7,9 +7,18 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Audit log sources.
+const (
+	AuditLogSourcePanel  = "panel"
+	AuditLogSourceKernel = "kernel"
 )
 
 // AuditLog stores security-relevant actions for audit trails.
+// Entries pulled from node kernels carry Source=kernel, the originating NodeID
+// and the kernel record id in ExternalID.
 type AuditLog struct {
 	ID           uint64         `gorm:"primaryKey"`
 	ActorID      *uint64        `gorm:"column:actor_id"`
@@ -20,6 +29,9 @@ type AuditLog struct {
 	ResourceID   string         `gorm:"size:64"`
 	SourceIP     string         `gorm:"size:64"`
 	Metadata     map[string]any `gorm:"serializer:json"`
+	Source       string         `gorm:"size:16;default:panel;index"`
+	NodeID       *uint64        `gorm:"column:node_id;uniqueIndex:idx_audit_logs_node_external"`
+	ExternalID   string         `gorm:"column:external_id;size:64;uniqueIndex:idx_audit_logs_node_external"`
 	CreatedAt    time.Time
 }
 
@@ -34,6 +46,8 @@ type AuditLogListOptions struct {
 	Action       string
 	ResourceType string
 	ResourceID   string
+	Source       string
+	NodeID       *uint64
 	Since        *time.Time
 	Until        *time.Time
 }
@@ -42,6 +56,7 @@ type AuditLogListOptions struct {
 type AuditLogRepository interface {
 	Create(ctx context.Context, entry AuditLog) (AuditLog, error)
 	List(ctx context.Context, opts AuditLogListOptions) ([]AuditLog, int64, error)
+	ImportExternal(ctx context.Context, entries []AuditLog) (int64, error)
 }
 
 type auditLogRepository struct {
@@ -66,6 +81,11 @@ func (r *auditLogRepository) Create(ctx context.Context, entry AuditLog) (AuditL
 	entry.ResourceID = strings.TrimSpace(entry.ResourceID)
 	entry.ActorEmail = strings.TrimSpace(entry.ActorEmail)
 	entry.SourceIP = strings.TrimSpace(entry.SourceIP)
+	entry.Source = strings.ToLower(strings.TrimSpace(entry.Source))
+	entry.ExternalID = strings.TrimSpace(entry.ExternalID)
+	if entry.Source == "" {
+		entry.Source = AuditLogSourcePanel
+	}
 	if entry.Action == "" {
 		return AuditLog{}, ErrInvalidArgument
 	}
@@ -83,6 +103,43 @@ func (r *auditLogRepository) Create(ctx context.Context, entry AuditLog) (AuditL
 	return entry, nil
 }
 
+// ImportExternal stores entries collected from other systems. Entries already
+// imported (same node_id and external_id) are ignored; the inserted count is returned.
+func (r *auditLogRepository) ImportExternal(ctx context.Context, entries []AuditLog) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UTC()
+	for i := range entries {
+		entry := &entries[i]
+		entry.ID = 0
+		entry.Action = strings.TrimSpace(entry.Action)
+		entry.Source = strings.ToLower(strings.TrimSpace(entry.Source))
+		entry.ExternalID = strings.TrimSpace(entry.ExternalID)
+		if entry.Action == "" || entry.Source == "" || entry.NodeID == nil || entry.ExternalID == "" {
+			return 0, ErrInvalidArgument
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = now
+		}
+		if entry.Metadata == nil {
+			entry.Metadata = map[string]any{}
+		}
+	}
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entries)
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
 func (r *auditLogRepository) List(ctx context.Context, opts AuditLogListOptions) ([]AuditLog, int64, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, 0, err
@@ -108,6 +165,12 @@ func (r *auditLogRepository) List(ctx context.Context, opts AuditLogListOptions)
 	if resourceID := strings.TrimSpace(opts.ResourceID); resourceID != "" {
 		base = base.Where("resource_id = ?", resourceID)
 	}
+	if source := strings.TrimSpace(strings.ToLower(opts.Source)); source != "" {
+		base = base.Where("source = ?", source)
+	}
+	if opts.NodeID != nil {
+		base = base.Where("node_id = ?", *opts.NodeID)
+	}
 	if opts.Since != nil && !opts.Since.IsZero() {
 		base = base.Where("created_at >= ?", opts.Since.UTC())
 	}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Binding rollout states.
+const (
+	BindingRolloutStatusRunning     = "running"
+	BindingRolloutStatusSucceeded   = "succeeded"
+	BindingRolloutStatusRollingBack = "rolling_back"
+	BindingRolloutStatusRolledBack  = "rolled_back"
+)
+
+// Binding rollout item states.
+const (
+	BindingRolloutItemStatusPending        = "pending"
+	BindingRolloutItemStatusApplied        = "applied"
+	BindingRolloutItemStatusFailed         = "failed"
+	BindingRolloutItemStatusSkipped        = "skipped"
+	BindingRolloutItemStatusRolledBack     = "rolled_back"
+	BindingRolloutItemStatusRollbackFailed = "rollback_failed"
+	// The binding was edited after the rollout applied it; its previous
+	// profile is not restored over the newer edit.
+	BindingRolloutItemStatusRollbackSkipped = "rollback_skipped"
+)
+
+// BindingRollout pushes a new profile to a set of bindings in stages. Stage 0
+// is the canary; each stage soaks for SoakSeconds before the next one starts.
+type BindingRollout struct {
+	ID             uint64         `gorm:"primaryKey"`
+	KernelID       string         `gorm:"size:128;index"`
+	Status         string         `gorm:"size:16;index"`
+	Stage          int            `gorm:"column:stage"`
+	StageCount     int            `gorm:"column:stage_count"`
+	Profile        map[string]any `gorm:"serializer:json"`
+	CanaryTag      string         `gorm:"size:64"`
+	CanaryPercent  int            `gorm:"column:canary_percent"`
+	StagePercents  []int          `gorm:"serializer:json"`
+	SoakSeconds    int            `gorm:"column:soak_seconds"`
+	StageStartedAt *time.Time     `gorm:"column:stage_started_at"`
+	Message        string         `gorm:"type:text"`
+	ActorID        *uint64        `gorm:"column:actor_id"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FinishedAt     *time.Time
+}
+
+// TableName binds the binding rollout table name.
+func (BindingRollout) TableName() string { return "binding_rollouts" }
+
+// BindingRolloutItem is one binding of a rollout. PreviousProfile keeps the
+// profile the binding had before the rollout so it can be restored.
+type BindingRolloutItem struct {
+	ID              uint64         `gorm:"primaryKey"`
+	RolloutID       uint64         `gorm:"index"`
+	BindingID       uint64         `gorm:"column:binding_id;index"`
+	NodeID          uint64         `gorm:"column:node_id"`
+	Stage           int            `gorm:"column:stage"`
+	Status          string         `gorm:"size:16"`
+	PreviousProfile map[string]any `gorm:"serializer:json"`
+	Message         string         `gorm:"type:text"`
+	AppliedAt       *time.Time     `gorm:"column:applied_at"`
+	UpdatedAt       time.Time
+}
+
+// TableName binds the binding rollout item table name.
+func (BindingRolloutItem) TableName() string { return "binding_rollout_items" }
+
+// BindingRolloutRepository stores binding rollouts and their items.
+type BindingRolloutRepository interface {
+	Create(ctx context.Context, rollout BindingRollout, items []BindingRolloutItem) (BindingRollout, error)
+	Get(ctx context.Context, id uint64) (BindingRollout, error)
+	Save(ctx context.Context, rollout BindingRollout, expectedStatus string) (BindingRollout, error)
+	RequestRollback(ctx context.Context, id uint64, reason string) error
+	ListUnfinished(ctx context.Context) ([]BindingRollout, error)
+	ListActiveBindingIDs(ctx context.Context) ([]uint64, error)
+	ListItems(ctx context.Context, rolloutID uint64) ([]BindingRolloutItem, error)
+	SaveItem(ctx context.Context, item BindingRolloutItem) error
+}
+
+type bindingRolloutRepository struct {
+	db *gorm.DB
+}
+
+// NewBindingRolloutRepository constructs a binding rollout repository.
+func NewBindingRolloutRepository(db *gorm.DB) (BindingRolloutRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &bindingRolloutRepository{db: db}, nil
+}
+
+// Create stores a running rollout together with its pending items.
+func (r *bindingRolloutRepository) Create(ctx context.Context, rollout BindingRollout, items []BindingRolloutItem) (BindingRollout, error) {
+	if err := ctx.Err(); err != nil {
+		return BindingRollout{}, err
+	}
+	if len(items) == 0 || rollout.StageCount <= 0 {
+		return BindingRollout{}, ErrInvalidArgument
+	}
+	now := time.Now().UTC()
+	rollout.ID = 0
+	rollout.KernelID = strings.TrimSpace(rollout.KernelID)
+	rollout.Status = BindingRolloutStatusRunning
+	rollout.Stage = 0
+	rollout.StageStartedAt = nil
+	rollout.FinishedAt = nil
+	rollout.CreatedAt = now
+	rollout.UpdatedAt = now
+	if rollout.Profile == nil {
+		rollout.Profile = map[string]any{}
+	}
+	if rollout.StagePercents == nil {
+		rollout.StagePercents = []int{}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rollout).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].ID = 0
+			items[i].RolloutID = rollout.ID
+			items[i].Status = BindingRolloutItemStatusPending
+			items[i].UpdatedAt = now
+			if items[i].PreviousProfile == nil {
+				items[i].PreviousProfile = map[string]any{}
+			}
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return BindingRollout{}, translateError(err)
+	}
+	return rollout, nil
+}
+
+func (r *bindingRolloutRepository) Get(ctx context.Context, id uint64) (BindingRollout, error) {
+	if err := ctx.Err(); err != nil {
+		return BindingRollout{}, err
+	}
+	if id == 0 {
+		return BindingRollout{}, ErrInvalidArgument
+	}
+
+	var rollout BindingRollout
+	if err := r.db.WithContext(ctx).First(&rollout, id).Error; err != nil {
+		return BindingRollout{}, translateError(err)
+	}
+	return rollout, nil
+}
+
+// Save persists the progress of a rollout whose stored status is still
+// expectedStatus; ErrConflict means another writer changed it first.
+func (r *bindingRolloutRepository) Save(ctx context.Context, rollout BindingRollout, expectedStatus string) (BindingRollout, error) {
+	if err := ctx.Err(); err != nil {
+		return BindingRollout{}, err
+	}
+	if rollout.ID == 0 {
+		return BindingRollout{}, ErrInvalidArgument
+	}
+	rollout.UpdatedAt = time.Now().UTC()
+
+	result := r.db.WithContext(ctx).
+		Model(&BindingRollout{}).
+		Where("id = ? AND status = ?", rollout.ID, expectedStatus).
+		Updates(map[string]any{
+			"status":           rollout.Status,
+			"stage":            rollout.Stage,
+			"stage_started_at": rollout.StageStartedAt,
+			"message":          rollout.Message,
+			"finished_at":      rollout.FinishedAt,
+			"updated_at":       rollout.UpdatedAt,
+		})
+	if result.Error != nil {
+		return BindingRollout{}, translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return BindingRollout{}, ErrConflict
+	}
+	return rollout, nil
+}
+
+// RequestRollback moves a running or succeeded rollout to rolling_back; the
+// rollout worker restores its bindings. ErrInvalidState means the rollout is
+// already rolling back or rolled back.
+func (r *bindingRolloutRepository) RequestRollback(ctx context.Context, id uint64, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if id == 0 {
+		return ErrInvalidArgument
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&BindingRollout{}).
+		Where("id = ? AND status IN ?", id, []string{BindingRolloutStatusRunning, BindingRolloutStatusSucceeded}).
+		Updates(map[string]any{
+			"status":     BindingRolloutStatusRollingBack,
+			"message":    strings.TrimSpace(reason),
+			"updated_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidState
+	}
+	return nil
+}
+
+// ListUnfinished returns running and rolling back rollouts in creation order.
+func (r *bindingRolloutRepository) ListUnfinished(ctx context.Context) ([]BindingRollout, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var rollouts []BindingRollout
+	if err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{BindingRolloutStatusRunning, BindingRolloutStatusRollingBack}).
+		Order("id ASC").
+		Find(&rollouts).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return rollouts, nil
+}
+
+// ListActiveBindingIDs returns the bindings taking part in unfinished rollouts.
+func (r *bindingRolloutRepository) ListActiveBindingIDs(ctx context.Context) ([]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	if err := r.db.WithContext(ctx).
+		Model(&BindingRolloutItem{}).
+		Joins("JOIN binding_rollouts ON binding_rollouts.id = binding_rollout_items.rollout_id").
+		Where("binding_rollouts.status IN ?", []string{BindingRolloutStatusRunning, BindingRolloutStatusRollingBack}).
+		Distinct().
+		Pluck("binding_rollout_items.binding_id", &ids).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return ids, nil
+}
+
+func (r *bindingRolloutRepository) ListItems(ctx context.Context, rolloutID uint64) ([]BindingRolloutItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if rolloutID == 0 {
+		return nil, ErrInvalidArgument
+	}
+
+	var items []BindingRolloutItem
+	if err := r.db.WithContext(ctx).
+		Where("rollout_id = ?", rolloutID).
+		Order("stage ASC, id ASC").
+		Find(&items).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return items, nil
+}
+
+// SaveItem records the state of a rollout item.
+func (r *bindingRolloutRepository) SaveItem(ctx context.Context, item BindingRolloutItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if item.ID == 0 {
+		return ErrInvalidArgument
+	}
+	if item.PreviousProfile == nil {
+		item.PreviousProfile = map[string]any{}
+	}
+	item.UpdatedAt = time.Now().UTC()
+
+	return translateError(r.db.WithContext(ctx).Save(&item).Error)
+}
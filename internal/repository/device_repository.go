@@ -0,0 +1,378 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Device limit policy actions.
+const (
+	DevicePolicyOff      = "off"
+	DevicePolicyWarn     = "warn"
+	DevicePolicyThrottle = "throttle"
+	DevicePolicySuspend  = "suspend"
+)
+
+// DeviceSourceSubscription marks sightings from subscription downloads.
+const DeviceSourceSubscription = "subscription"
+
+// SubscriptionDevice records a client IP seen using a subscription. Sightings
+// are keyed by subscription and IP; LastSeenAt moves forward on every hit.
+type SubscriptionDevice struct {
+	ID             uint64    `gorm:"primaryKey"`
+	SubscriptionID uint64    `gorm:"uniqueIndex:idx_subscription_device"`
+	ClientIP       string    `gorm:"size:64;uniqueIndex:idx_subscription_device"`
+	UserID         uint64    `gorm:"index"`
+	Source         string    `gorm:"size:32"`
+	UserAgent      string    `gorm:"size:255"`
+	Hits           int64     `gorm:"column:hits"`
+	FirstSeenAt    time.Time `gorm:"column:first_seen_at"`
+	LastSeenAt     time.Time `gorm:"column:last_seen_at;index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName binds the subscription device table name.
+func (SubscriptionDevice) TableName() string { return "subscription_devices" }
+
+// DeviceLimitViolation flags a subscription seen on more devices than allowed.
+// Throttle and suspend violations carry a penalty that ends at PenaltyUntil.
+type DeviceLimitViolation struct {
+	ID                uint64     `gorm:"primaryKey"`
+	SubscriptionID    uint64     `gorm:"uniqueIndex"`
+	UserID            uint64     `gorm:"index"`
+	DeviceCount       int        `gorm:"column:device_count"`
+	DevicesLimit      int        `gorm:"column:devices_limit"`
+	Action            string     `gorm:"size:16"`
+	ThrottleRateBytes int64      `gorm:"column:throttle_rate_bytes"`
+	DetectedAt        time.Time  `gorm:"column:detected_at"`
+	PenaltyUntil      *time.Time `gorm:"column:penalty_until;index"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName binds the device limit violation table name.
+func (DeviceLimitViolation) TableName() string { return "device_limit_violations" }
+
+// PenaltyActive reports whether a throttle or suspend penalty still applies.
+func (v DeviceLimitViolation) PenaltyActive(now time.Time) bool {
+	if v.Action != DevicePolicyThrottle && v.Action != DevicePolicySuspend {
+		return false
+	}
+	return v.PenaltyUntil != nil && v.PenaltyUntil.After(now)
+}
+
+// DevicePolicySetting stores how DevicesLimit violations are handled.
+type DevicePolicySetting struct {
+	ID                uint64 `gorm:"primaryKey"`
+	Action            string `gorm:"size:16"`
+	WindowSeconds     int    `gorm:"column:window_seconds"`
+	PenaltySeconds    int    `gorm:"column:penalty_seconds"`
+	ThrottleRateBytes int64  `gorm:"column:throttle_rate_bytes"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName binds the device policy table name.
+func (DevicePolicySetting) TableName() string { return "device_policy_settings" }
+
+// SubscriptionDeviceCount is the number of distinct IPs seen for a subscription.
+type SubscriptionDeviceCount struct {
+	SubscriptionID uint64
+	UserID         uint64
+	Devices        int
+}
+
+// DeviceRepository manages device sightings, violations and the device policy.
+type DeviceRepository interface {
+	RecordSighting(ctx context.Context, device SubscriptionDevice) error
+	ListBySubscription(ctx context.Context, subscriptionID uint64) ([]SubscriptionDevice, error)
+	CountSince(ctx context.Context, since time.Time) ([]SubscriptionDeviceCount, error)
+	DeleteBySubscription(ctx context.Context, subscriptionID uint64) error
+	PruneBefore(ctx context.Context, before time.Time) (int64, error)
+
+	GetViolation(ctx context.Context, subscriptionID uint64) (DeviceLimitViolation, error)
+	ListViolations(ctx context.Context) ([]DeviceLimitViolation, error)
+	ListPenalizedBySubscriptionIDs(ctx context.Context, subscriptionIDs []uint64, now time.Time) ([]DeviceLimitViolation, error)
+	SaveViolation(ctx context.Context, violation DeviceLimitViolation) (DeviceLimitViolation, error)
+	DeleteViolation(ctx context.Context, subscriptionID uint64) error
+
+	GetPolicy(ctx context.Context) (DevicePolicySetting, error)
+	UpsertPolicy(ctx context.Context, setting DevicePolicySetting) (DevicePolicySetting, error)
+}
+
+type deviceRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceRepository constructs a device repository.
+func NewDeviceRepository(db *gorm.DB) (DeviceRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &deviceRepository{db: db}, nil
+}
+
+func (r *deviceRepository) RecordSighting(ctx context.Context, device SubscriptionDevice) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	device.ClientIP = strings.TrimSpace(device.ClientIP)
+	if device.SubscriptionID == 0 || device.ClientIP == "" {
+		return ErrInvalidArgument
+	}
+	device.Source = strings.TrimSpace(device.Source)
+	device.UserAgent = truncateString(strings.TrimSpace(device.UserAgent), 255)
+	seenAt := device.LastSeenAt.UTC()
+	if seenAt.IsZero() {
+		seenAt = time.Now().UTC()
+	}
+	now := time.Now().UTC()
+
+	result := r.db.WithContext(ctx).Model(&SubscriptionDevice{}).
+		Where("subscription_id = ? AND client_ip = ?", device.SubscriptionID, device.ClientIP).
+		Updates(map[string]any{
+			"source":       device.Source,
+			"user_agent":   device.UserAgent,
+			"hits":         gorm.Expr("hits + 1"),
+			"last_seen_at": seenAt,
+			"updated_at":   now,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	device.ID = 0
+	device.Hits = 1
+	device.FirstSeenAt = seenAt
+	device.LastSeenAt = seenAt
+	device.CreatedAt = now
+	device.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(&device).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (r *deviceRepository) ListBySubscription(ctx context.Context, subscriptionID uint64) ([]SubscriptionDevice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if subscriptionID == 0 {
+		return nil, ErrInvalidArgument
+	}
+
+	var devices []SubscriptionDevice
+	if err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("last_seen_at DESC").
+		Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (r *deviceRepository) CountSince(ctx context.Context, since time.Time) ([]SubscriptionDeviceCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var counts []SubscriptionDeviceCount
+	if err := r.db.WithContext(ctx).Model(&SubscriptionDevice{}).
+		Select("subscription_id, MAX(user_id) AS user_id, COUNT(DISTINCT client_ip) AS devices").
+		Where("last_seen_at >= ?", since.UTC()).
+		Group("subscription_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *deviceRepository) DeleteBySubscription(ctx context.Context, subscriptionID uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if subscriptionID == 0 {
+		return ErrInvalidArgument
+	}
+	return r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID).Delete(&SubscriptionDevice{}).Error
+}
+
+func (r *deviceRepository) PruneBefore(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	result := r.db.WithContext(ctx).Where("last_seen_at < ?", before.UTC()).Delete(&SubscriptionDevice{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *deviceRepository) GetViolation(ctx context.Context, subscriptionID uint64) (DeviceLimitViolation, error) {
+	if err := ctx.Err(); err != nil {
+		return DeviceLimitViolation{}, err
+	}
+	if subscriptionID == 0 {
+		return DeviceLimitViolation{}, ErrInvalidArgument
+	}
+
+	var violation DeviceLimitViolation
+	if err := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID).First(&violation).Error; err != nil {
+		return DeviceLimitViolation{}, translateError(err)
+	}
+	return violation, nil
+}
+
+func (r *deviceRepository) ListViolations(ctx context.Context) ([]DeviceLimitViolation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var violations []DeviceLimitViolation
+	if err := r.db.WithContext(ctx).Order("subscription_id ASC").Find(&violations).Error; err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+func (r *deviceRepository) ListPenalizedBySubscriptionIDs(ctx context.Context, subscriptionIDs []uint64, now time.Time) ([]DeviceLimitViolation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(subscriptionIDs) == 0 {
+		return []DeviceLimitViolation{}, nil
+	}
+
+	var violations []DeviceLimitViolation
+	if err := r.db.WithContext(ctx).
+		Where("subscription_id IN ?", subscriptionIDs).
+		Where("action IN ?", []string{DevicePolicyThrottle, DevicePolicySuspend}).
+		Where("penalty_until > ?", now.UTC()).
+		Find(&violations).Error; err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+func (r *deviceRepository) SaveViolation(ctx context.Context, violation DeviceLimitViolation) (DeviceLimitViolation, error) {
+	if err := ctx.Err(); err != nil {
+		return DeviceLimitViolation{}, err
+	}
+	if violation.SubscriptionID == 0 {
+		return DeviceLimitViolation{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	violation.UpdatedAt = now
+	if violation.ID == 0 {
+		existing, err := r.GetViolation(ctx, violation.SubscriptionID)
+		switch {
+		case err == nil:
+			violation.ID = existing.ID
+			violation.CreatedAt = existing.CreatedAt
+		case errors.Is(err, ErrNotFound):
+			violation.CreatedAt = now
+		default:
+			return DeviceLimitViolation{}, err
+		}
+	}
+
+	if err := r.db.WithContext(ctx).Save(&violation).Error; err != nil {
+		return DeviceLimitViolation{}, translateError(err)
+	}
+	return violation, nil
+}
+
+func (r *deviceRepository) DeleteViolation(ctx context.Context, subscriptionID uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if subscriptionID == 0 {
+		return ErrInvalidArgument
+	}
+
+	result := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID).Delete(&DeviceLimitViolation{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *deviceRepository) GetPolicy(ctx context.Context) (DevicePolicySetting, error) {
+	if err := ctx.Err(); err != nil {
+		return DevicePolicySetting{}, err
+	}
+
+	var setting DevicePolicySetting
+	if err := r.db.WithContext(ctx).Limit(1).First(&setting).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			now := time.Now().UTC()
+			setting = DevicePolicySetting{CreatedAt: now, UpdatedAt: now}
+			applyDevicePolicyDefaults(&setting)
+			if err := r.db.WithContext(ctx).Create(&setting).Error; err != nil {
+				return DevicePolicySetting{}, err
+			}
+			return setting, nil
+		}
+		return DevicePolicySetting{}, err
+	}
+
+	applyDevicePolicyDefaults(&setting)
+	return setting, nil
+}
+
+func (r *deviceRepository) UpsertPolicy(ctx context.Context, setting DevicePolicySetting) (DevicePolicySetting, error) {
+	if err := ctx.Err(); err != nil {
+		return DevicePolicySetting{}, err
+	}
+
+	current, err := r.GetPolicy(ctx)
+	if err != nil {
+		return DevicePolicySetting{}, err
+	}
+	applyDevicePolicyDefaults(&setting)
+
+	if err := r.db.WithContext(ctx).Model(&DevicePolicySetting{}).
+		Where("id = ?", current.ID).
+		Updates(map[string]any{
+			"action":              setting.Action,
+			"window_seconds":      setting.WindowSeconds,
+			"penalty_seconds":     setting.PenaltySeconds,
+			"throttle_rate_bytes": setting.ThrottleRateBytes,
+			"updated_at":          time.Now().UTC(),
+		}).Error; err != nil {
+		return DevicePolicySetting{}, err
+	}
+
+	return r.GetPolicy(ctx)
+}
+
+func applyDevicePolicyDefaults(setting *DevicePolicySetting) {
+	if strings.TrimSpace(setting.Action) == "" {
+		setting.Action = DevicePolicyWarn
+	}
+	if setting.WindowSeconds <= 0 {
+		setting.WindowSeconds = 3600
+	}
+	if setting.PenaltySeconds <= 0 {
+		setting.PenaltySeconds = 3600
+	}
+	if setting.ThrottleRateBytes <= 0 {
+		setting.ThrottleRateBytes = 128 * 1024
+	}
+}
+
+func truncateString(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return value[:limit]
+}
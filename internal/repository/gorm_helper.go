@@ -7,6 +7,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// MaxPerPage caps the page size of paginated list queries.
+const MaxPerPage = 100
+
 func translateError(err error) error {
 	if err == nil {
 		return nil
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// KernelAuditCursor tracks audit record collection for a node kernel.
+// SinceMS is the newest kernel audit timestamp already ingested; the Sink*
+// fields snapshot /v1/audit/health so sink failures surface on the node.
+type KernelAuditCursor struct {
+	ID            uint64     `gorm:"primaryKey"`
+	NodeID        uint64     `gorm:"uniqueIndex"`
+	SinceMS       int64      `gorm:"column:since_ms"`
+	LastID        string     `gorm:"column:last_id;size:64"`
+	PulledAt      *time.Time `gorm:"column:pulled_at"`
+	PullError     string     `gorm:"column:pull_error;type:text"`
+	SinkHealthy   bool       `gorm:"column:sink_healthy"`
+	SinkQueued    int64      `gorm:"column:sink_queued"`
+	SinkDropped   int64      `gorm:"column:sink_dropped"`
+	SinkFailures  int64      `gorm:"column:sink_failures"`
+	SinkLastError string     `gorm:"column:sink_last_error;type:text"`
+	SinkCheckedAt *time.Time `gorm:"column:sink_checked_at"`
+	UpdatedAt     time.Time
+	CreatedAt     time.Time
+}
+
+// TableName binds the kernel audit cursor table name.
+func (KernelAuditCursor) TableName() string { return "kernel_audit_cursors" }
+
+// KernelAuditCursorRepository manages pull cursors for kernel audit collection.
+type KernelAuditCursorRepository interface {
+	Get(ctx context.Context, nodeID uint64) (KernelAuditCursor, error)
+	ListByNodeIDs(ctx context.Context, nodeIDs []uint64) ([]KernelAuditCursor, error)
+	Save(ctx context.Context, cursor KernelAuditCursor) (KernelAuditCursor, error)
+}
+
+type kernelAuditCursorRepository struct {
+	db *gorm.DB
+}
+
+// NewKernelAuditCursorRepository constructs a kernel audit cursor repository.
+func NewKernelAuditCursorRepository(db *gorm.DB) (KernelAuditCursorRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &kernelAuditCursorRepository{db: db}, nil
+}
+
+func (r *kernelAuditCursorRepository) Get(ctx context.Context, nodeID uint64) (KernelAuditCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return KernelAuditCursor{}, err
+	}
+	if nodeID == 0 {
+		return KernelAuditCursor{}, ErrInvalidArgument
+	}
+
+	var cursor KernelAuditCursor
+	if err := r.db.WithContext(ctx).Where("node_id = ?", nodeID).First(&cursor).Error; err != nil {
+		return KernelAuditCursor{}, translateError(err)
+	}
+	return cursor, nil
+}
+
+func (r *kernelAuditCursorRepository) ListByNodeIDs(ctx context.Context, nodeIDs []uint64) ([]KernelAuditCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(nodeIDs) == 0 {
+		return []KernelAuditCursor{}, nil
+	}
+
+	var cursors []KernelAuditCursor
+	if err := r.db.WithContext(ctx).
+		Where("node_id IN ?", nodeIDs).
+		Order("node_id ASC").
+		Find(&cursors).Error; err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
+
+func (r *kernelAuditCursorRepository) Save(ctx context.Context, cursor KernelAuditCursor) (KernelAuditCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return KernelAuditCursor{}, err
+	}
+	if cursor.NodeID == 0 {
+		return KernelAuditCursor{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	if cursor.CreatedAt.IsZero() {
+		cursor.CreatedAt = now
+	}
+	cursor.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Save(&cursor).Error; err != nil {
+		return KernelAuditCursor{}, translateError(err)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KernelTrafficCursor stores the last pulled kernel traffic counter for a scope.
+// A row with ProtocolBindingID=0 and UserID=0 tracks the node summary, a row with
+// UserID=0 tracks a protocol total and a row with ProtocolBindingID=0 tracks a
+// user's counter; kernels keep one counter per user across all protocols.
+// Counter is the cumulative byte counter last reported by the kernel and
+// PushedBytes counts bytes ingested through push callbacks since the last pull.
+type KernelTrafficCursor struct {
+	ID                uint64    `gorm:"primaryKey"`
+	NodeID            uint64    `gorm:"uniqueIndex:idx_kernel_traffic_cursor_scope;index"`
+	ProtocolBindingID uint64    `gorm:"uniqueIndex:idx_kernel_traffic_cursor_scope"`
+	UserID            uint64    `gorm:"uniqueIndex:idx_kernel_traffic_cursor_scope"`
+	Counter           int64     `gorm:"column:counter"`
+	PushedBytes       int64     `gorm:"column:pushed_bytes"`
+	PulledAt          time.Time `gorm:"column:pulled_at"`
+	UpdatedAt         time.Time
+	CreatedAt         time.Time
+}
+
+// TableName binds the kernel traffic cursor table name.
+func (KernelTrafficCursor) TableName() string { return "kernel_traffic_cursors" }
+
+// KernelTrafficCursorRepository manages pull cursors for kernel traffic collection.
+type KernelTrafficCursorRepository interface {
+	Get(ctx context.Context, nodeID, bindingID, userID uint64) (KernelTrafficCursor, error)
+	GetForUpdate(ctx context.Context, nodeID, bindingID, userID uint64) (KernelTrafficCursor, error)
+	ListByNode(ctx context.Context, nodeID uint64) ([]KernelTrafficCursor, error)
+	Save(ctx context.Context, cursor KernelTrafficCursor) (KernelTrafficCursor, error)
+}
+
+type kernelTrafficCursorRepository struct {
+	db *gorm.DB
+}
+
+// NewKernelTrafficCursorRepository constructs a cursor repository.
+func NewKernelTrafficCursorRepository(db *gorm.DB) (KernelTrafficCursorRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &kernelTrafficCursorRepository{db: db}, nil
+}
+
+func (r *kernelTrafficCursorRepository) Get(ctx context.Context, nodeID, bindingID, userID uint64) (KernelTrafficCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return KernelTrafficCursor{}, err
+	}
+	return r.get(r.db.WithContext(ctx), nodeID, bindingID, userID)
+}
+
+func (r *kernelTrafficCursorRepository) GetForUpdate(ctx context.Context, nodeID, bindingID, userID uint64) (KernelTrafficCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return KernelTrafficCursor{}, err
+	}
+	return r.get(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), nodeID, bindingID, userID)
+}
+
+func (r *kernelTrafficCursorRepository) get(db *gorm.DB, nodeID, bindingID, userID uint64) (KernelTrafficCursor, error) {
+	if nodeID == 0 {
+		return KernelTrafficCursor{}, ErrInvalidArgument
+	}
+
+	var cursor KernelTrafficCursor
+	if err := db.
+		Where("node_id = ? AND protocol_binding_id = ? AND user_id = ?", nodeID, bindingID, userID).
+		First(&cursor).Error; err != nil {
+		return KernelTrafficCursor{}, translateError(err)
+	}
+	return cursor, nil
+}
+
+func (r *kernelTrafficCursorRepository) ListByNode(ctx context.Context, nodeID uint64) ([]KernelTrafficCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if nodeID == 0 {
+		return nil, ErrInvalidArgument
+	}
+
+	var cursors []KernelTrafficCursor
+	if err := r.db.WithContext(ctx).
+		Where("node_id = ?", nodeID).
+		Order("protocol_binding_id ASC, user_id ASC").
+		Find(&cursors).Error; err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
+
+func (r *kernelTrafficCursorRepository) Save(ctx context.Context, cursor KernelTrafficCursor) (KernelTrafficCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return KernelTrafficCursor{}, err
+	}
+	if cursor.NodeID == 0 {
+		return KernelTrafficCursor{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	if cursor.CreatedAt.IsZero() {
+		cursor.CreatedAt = now
+	}
+	cursor.UpdatedAt = now
+	cursor.PulledAt = cursor.PulledAt.UTC()
+
+	if err := r.db.WithContext(ctx).Save(&cursor).Error; err != nil {
+		return KernelTrafficCursor{}, translateError(err)
+	}
+	return cursor, nil
+}
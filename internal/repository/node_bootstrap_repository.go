@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Node bootstrap run states.
+const (
+	NodeBootstrapStatusRunning   = "running"
+	NodeBootstrapStatusSucceeded = "succeeded"
+	NodeBootstrapStatusPartial   = "partial"
+	NodeBootstrapStatusFailed    = "failed"
+)
+
+// Node bootstrap stages, used for progress and failures.
+const (
+	NodeBootstrapStageUsers     = "users"
+	NodeBootstrapStageReconcile = "reconcile"
+	NodeBootstrapStageProtocols = "protocols"
+	NodeBootstrapStageDone      = "done"
+)
+
+// NodeBootstrapFailure records a user or protocol that could not be loaded.
+type NodeBootstrapFailure struct {
+	Stage    string `json:"stage"`
+	UserID   string `json:"user_id,omitempty"`
+	KernelID string `json:"kernel_id,omitempty"`
+	Message  string `json:"message"`
+}
+
+// NodeBootstrap tracks a bulk load of users and protocols onto a node kernel.
+type NodeBootstrap struct {
+	ID             uint64                 `gorm:"primaryKey"`
+	NodeID         uint64                 `gorm:"index"`
+	Status         string                 `gorm:"size:16;index"`
+	Stage          string                 `gorm:"size:16"`
+	ChunkSize      int                    `gorm:"column:chunk_size"`
+	TotalUsers     int                    `gorm:"column:total_users"`
+	ImportedUsers  int                    `gorm:"column:imported_users"`
+	ChunksTotal    int                    `gorm:"column:chunks_total"`
+	ChunksDone     int                    `gorm:"column:chunks_done"`
+	ProtocolsTotal int                    `gorm:"column:protocols_total"`
+	ProtocolsDone  int                    `gorm:"column:protocols_done"`
+	FailureCount   int                    `gorm:"column:failure_count"`
+	Failures       []NodeBootstrapFailure `gorm:"serializer:json;type:text"`
+	Message        string                 `gorm:"type:text"`
+	ActorID        *uint64                `gorm:"column:actor_id"`
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName binds the node bootstrap table name.
+func (NodeBootstrap) TableName() string { return "node_bootstraps" }
+
+// NodeBootstrapRepository stores node bootstrap runs.
+type NodeBootstrapRepository interface {
+	Create(ctx context.Context, run NodeBootstrap) (NodeBootstrap, error)
+	Get(ctx context.Context, id uint64) (NodeBootstrap, error)
+	Latest(ctx context.Context, nodeID uint64) (NodeBootstrap, error)
+	Save(ctx context.Context, run NodeBootstrap) (NodeBootstrap, error)
+}
+
+type nodeBootstrapRepository struct {
+	db *gorm.DB
+}
+
+// NewNodeBootstrapRepository constructs a node bootstrap repository.
+func NewNodeBootstrapRepository(db *gorm.DB) (NodeBootstrapRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &nodeBootstrapRepository{db: db}, nil
+}
+
+func (r *nodeBootstrapRepository) Create(ctx context.Context, run NodeBootstrap) (NodeBootstrap, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeBootstrap{}, err
+	}
+	if run.NodeID == 0 {
+		return NodeBootstrap{}, ErrInvalidArgument
+	}
+	now := time.Now().UTC()
+	run.ID = 0
+	if run.Status == "" {
+		run.Status = NodeBootstrapStatusRunning
+	}
+	if run.Failures == nil {
+		run.Failures = []NodeBootstrapFailure{}
+	}
+	if run.StartedAt.IsZero() {
+		run.StartedAt = now
+	}
+	run.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&run).Error; err != nil {
+		return NodeBootstrap{}, translateError(err)
+	}
+	return run, nil
+}
+
+func (r *nodeBootstrapRepository) Get(ctx context.Context, id uint64) (NodeBootstrap, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeBootstrap{}, err
+	}
+	if id == 0 {
+		return NodeBootstrap{}, ErrInvalidArgument
+	}
+
+	var run NodeBootstrap
+	if err := r.db.WithContext(ctx).First(&run, id).Error; err != nil {
+		return NodeBootstrap{}, translateError(err)
+	}
+	return run, nil
+}
+
+func (r *nodeBootstrapRepository) Latest(ctx context.Context, nodeID uint64) (NodeBootstrap, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeBootstrap{}, err
+	}
+	if nodeID == 0 {
+		return NodeBootstrap{}, ErrInvalidArgument
+	}
+
+	var run NodeBootstrap
+	if err := r.db.WithContext(ctx).
+		Where("node_id = ?", nodeID).
+		Order("id DESC").
+		First(&run).Error; err != nil {
+		return NodeBootstrap{}, translateError(err)
+	}
+	return run, nil
+}
+
+// Save persists the progress of a run.
+func (r *nodeBootstrapRepository) Save(ctx context.Context, run NodeBootstrap) (NodeBootstrap, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeBootstrap{}, err
+	}
+	if run.ID == 0 {
+		return NodeBootstrap{}, ErrInvalidArgument
+	}
+	if run.Failures == nil {
+		run.Failures = []NodeBootstrapFailure{}
+	}
+	run.UpdatedAt = time.Now().UTC()
+
+	if err := r.db.WithContext(ctx).Save(&run).Error; err != nil {
+		return NodeBootstrap{}, translateError(err)
+	}
+	return run, nil
+}
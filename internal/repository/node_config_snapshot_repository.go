@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Node config snapshot triggers.
+const (
+	NodeSnapshotTriggerManual = "manual"
+	NodeSnapshotTriggerSync   = "sync"
+)
+
+// NodeConfigProtocol is one protocol as captured from a kernel (or as the panel
+// intends it). Users only lists kernel user ids; credentials are never stored.
+type NodeConfigProtocol struct {
+	KernelID    string         `json:"kernel_id"`
+	Role        string         `json:"role"`
+	Protocol    string         `json:"protocol"`
+	Tags        []string       `json:"tags"`
+	Description string         `json:"description"`
+	Listen      string         `json:"listen"`
+	Connect     string         `json:"connect"`
+	Users       []string       `json:"users"`
+	Profile     map[string]any `json:"profile,omitempty"`
+}
+
+// NodeConfigExport keeps the kernel /v1/export summary of a snapshot.
+type NodeConfigExport struct {
+	Path                  string   `json:"path"`
+	Version               string   `json:"version"`
+	Protocols             int      `json:"protocols"`
+	Users                 *int     `json:"users,omitempty"`
+	API                   *bool    `json:"api,omitempty"`
+	PipelineStrategies    int      `json:"pipeline_strategies"`
+	PipelineRules         int      `json:"pipeline_rules"`
+	PipelineResolvedRules *int     `json:"pipeline_resolved_rules,omitempty"`
+	FilterProtocols       []string `json:"filter_protocols,omitempty"`
+}
+
+// NodeConfigSnapshot is a versioned capture of what a node kernel was running.
+// Version increases per node; ContentHash identifies the captured protocols.
+type NodeConfigSnapshot struct {
+	ID          uint64               `gorm:"primaryKey"`
+	NodeID      uint64               `gorm:"uniqueIndex:idx_node_config_snapshot_version"`
+	Version     int                  `gorm:"uniqueIndex:idx_node_config_snapshot_version"`
+	Trigger     string               `gorm:"size:16"`
+	Reason      string               `gorm:"size:255"`
+	ContentHash string               `gorm:"size:64"`
+	Protocols   []NodeConfigProtocol `gorm:"serializer:json;type:text"`
+	Export      NodeConfigExport     `gorm:"serializer:json;type:text"`
+	ExportError string               `gorm:"column:export_error;type:text"`
+	ActorID     *uint64              `gorm:"column:actor_id"`
+	CreatedAt   time.Time            `gorm:"index"`
+}
+
+// TableName binds the node config snapshot table name.
+func (NodeConfigSnapshot) TableName() string { return "node_config_snapshots" }
+
+// NodeConfigSnapshotListOptions controls snapshot pagination.
+type NodeConfigSnapshotListOptions struct {
+	Page    int
+	PerPage int
+}
+
+// NodeConfigSnapshotRepository stores node configuration snapshots.
+type NodeConfigSnapshotRepository interface {
+	Create(ctx context.Context, snapshot NodeConfigSnapshot) (NodeConfigSnapshot, error)
+	Get(ctx context.Context, id uint64) (NodeConfigSnapshot, error)
+	Latest(ctx context.Context, nodeID uint64) (NodeConfigSnapshot, error)
+	List(ctx context.Context, nodeID uint64, opts NodeConfigSnapshotListOptions) ([]NodeConfigSnapshot, int64, error)
+	Prune(ctx context.Context, nodeID uint64, keep int) error
+}
+
+type nodeConfigSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewNodeConfigSnapshotRepository constructs a node config snapshot repository.
+func NewNodeConfigSnapshotRepository(db *gorm.DB) (NodeConfigSnapshotRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &nodeConfigSnapshotRepository{db: db}, nil
+}
+
+// Create assigns the next per-node version and stores the snapshot.
+func (r *nodeConfigSnapshotRepository) Create(ctx context.Context, snapshot NodeConfigSnapshot) (NodeConfigSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeConfigSnapshot{}, err
+	}
+	snapshot.Trigger = strings.TrimSpace(snapshot.Trigger)
+	snapshot.Reason = strings.TrimSpace(snapshot.Reason)
+	if snapshot.NodeID == 0 || snapshot.Trigger == "" {
+		return NodeConfigSnapshot{}, ErrInvalidArgument
+	}
+	if snapshot.Protocols == nil {
+		snapshot.Protocols = []NodeConfigProtocol{}
+	}
+	snapshot.ID = 0
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now().UTC()
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&NodeConfigSnapshot{}).
+			Where("node_id = ?", snapshot.NodeID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		snapshot.Version = latest + 1
+		return tx.Create(&snapshot).Error
+	})
+	if err != nil {
+		return NodeConfigSnapshot{}, translateError(err)
+	}
+	return snapshot, nil
+}
+
+func (r *nodeConfigSnapshotRepository) Get(ctx context.Context, id uint64) (NodeConfigSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeConfigSnapshot{}, err
+	}
+	if id == 0 {
+		return NodeConfigSnapshot{}, ErrInvalidArgument
+	}
+
+	var snapshot NodeConfigSnapshot
+	if err := r.db.WithContext(ctx).First(&snapshot, id).Error; err != nil {
+		return NodeConfigSnapshot{}, translateError(err)
+	}
+	return snapshot, nil
+}
+
+func (r *nodeConfigSnapshotRepository) Latest(ctx context.Context, nodeID uint64) (NodeConfigSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeConfigSnapshot{}, err
+	}
+	if nodeID == 0 {
+		return NodeConfigSnapshot{}, ErrInvalidArgument
+	}
+
+	var snapshot NodeConfigSnapshot
+	if err := r.db.WithContext(ctx).
+		Where("node_id = ?", nodeID).
+		Order("version DESC").
+		First(&snapshot).Error; err != nil {
+		return NodeConfigSnapshot{}, translateError(err)
+	}
+	return snapshot, nil
+}
+
+func (r *nodeConfigSnapshotRepository) List(ctx context.Context, nodeID uint64, opts NodeConfigSnapshotListOptions) ([]NodeConfigSnapshot, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	if nodeID == 0 {
+		return nil, 0, ErrInvalidArgument
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = 20
+	}
+	if opts.PerPage > 100 {
+		opts.PerPage = 100
+	}
+
+	base := r.db.WithContext(ctx).Model(&NodeConfigSnapshot{}).Where("node_id = ?", nodeID)
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []NodeConfigSnapshot{}, 0, nil
+	}
+
+	var snapshots []NodeConfigSnapshot
+	if err := base.Session(&gorm.Session{}).
+		Order("version DESC").
+		Limit(opts.PerPage).
+		Offset((opts.Page - 1) * opts.PerPage).
+		Find(&snapshots).Error; err != nil {
+		return nil, 0, err
+	}
+	return snapshots, total, nil
+}
+
+// Prune keeps the newest keep snapshots of a node and deletes the rest.
+func (r *nodeConfigSnapshotRepository) Prune(ctx context.Context, nodeID uint64, keep int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if nodeID == 0 || keep <= 0 {
+		return ErrInvalidArgument
+	}
+
+	var cutoff []int
+	if err := r.db.WithContext(ctx).
+		Model(&NodeConfigSnapshot{}).
+		Where("node_id = ?", nodeID).
+		Order("version DESC").
+		Offset(keep).
+		Limit(1).
+		Pluck("version", &cutoff).Error; err != nil {
+		return err
+	}
+	if len(cutoff) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Where("node_id = ? AND version <= ?", nodeID, cutoff[0]).
+		Delete(&NodeConfigSnapshot{}).Error
+}
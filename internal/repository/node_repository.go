@@ -24,6 +24,8 @@ type Node struct {
 	Status                                    int            `gorm:"column:status"`
 	Tags                                      []string       `gorm:"serializer:json"`
 	CapacityMbps                              int            `gorm:"column:capacity_mbps"`
+	PortRangeStart                            int            `gorm:"column:port_range_start"`
+	PortRangeEnd                              int            `gorm:"column:port_range_end"`
 	Description                               string         `gorm:"type:text"`
 	AccessAddress                             string         `gorm:"size:512"`
 	ControlEndpoint                           string         `gorm:"size:512"`
@@ -39,6 +41,7 @@ type Node struct {
 	KernelStatusPollBackoffJitter             float64        `gorm:"column:kernel_status_poll_backoff_jitter;default:0.2"`
 	KernelOfflineProbeMaxIntervalSeconds      int            `gorm:"column:kernel_offline_probe_max_interval_seconds;default:0"`
 	StatusSyncEnabled                         bool           `gorm:"column:status_sync_enabled;default:true"`
+	KernelEventMode                           string         `gorm:"column:kernel_event_mode;size:16;default:push"`
 	LastSyncedAt                              time.Time      `gorm:"column:last_synced_at"`
 	DeletedAt                                 gorm.DeletedAt `gorm:"index"`
 	UpdatedAt                                 time.Time
@@ -81,6 +84,7 @@ type NodeRepository interface {
 	List(ctx context.Context, opts ListNodesOptions) ([]Node, int64, error)
 	ListAll(ctx context.Context) ([]Node, error)
 	Get(ctx context.Context, nodeID uint64) (Node, error)
+	GetForUpdate(ctx context.Context, nodeID uint64) (Node, error)
 	Create(ctx context.Context, node Node) (Node, error)
 	Update(ctx context.Context, nodeID uint64, input UpdateNodeInput) (Node, error)
 	UpdateStatusByIDs(ctx context.Context, nodeIDs []uint64, status int) error
@@ -173,6 +177,22 @@ func (r *nodeRepository) Get(ctx context.Context, nodeID uint64) (Node, error) {
 	return node, nil
 }
 
+// GetForUpdate loads the node with a row lock. Callers use it inside a
+// transaction to serialise changes that depend on the node's bindings, such as
+// port allocation.
+func (r *nodeRepository) GetForUpdate(ctx context.Context, nodeID uint64) (Node, error) {
+	if err := ctx.Err(); err != nil {
+		return Node{}, err
+	}
+
+	var node Node
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&node, nodeID).Error; err != nil {
+		return Node{}, translateError(err)
+	}
+
+	return node, nil
+}
+
 func (r *nodeRepository) Create(ctx context.Context, node Node) (Node, error) {
 	if err := ctx.Err(); err != nil {
 		return Node{}, err
@@ -238,6 +258,12 @@ func (r *nodeRepository) Update(ctx context.Context, nodeID uint64, input Update
 	if input.CapacityMbps != nil {
 		updates["capacity_mbps"] = *input.CapacityMbps
 	}
+	if input.PortRangeStart != nil {
+		updates["port_range_start"] = *input.PortRangeStart
+	}
+	if input.PortRangeEnd != nil {
+		updates["port_range_end"] = *input.PortRangeEnd
+	}
 	if input.Description != nil {
 		updates["description"] = strings.TrimSpace(*input.Description)
 	}
@@ -283,6 +309,9 @@ func (r *nodeRepository) Update(ctx context.Context, nodeID uint64, input Update
 	if input.StatusSyncEnabled != nil {
 		updates["status_sync_enabled"] = *input.StatusSyncEnabled
 	}
+	if input.KernelEventMode != nil {
+		updates["kernel_event_mode"] = strings.TrimSpace(*input.KernelEventMode)
+	}
 	if input.LastSyncedAt != nil {
 		updates["last_synced_at"] = input.LastSyncedAt.UTC()
 	}
@@ -359,6 +388,8 @@ type UpdateNodeInput struct {
 	Status                                    *int
 	Tags                                      *[]string
 	CapacityMbps                              *int
+	PortRangeStart                            *int
+	PortRangeEnd                              *int
 	Description                               *string
 	AccessAddress                             *string
 	ControlEndpoint                           *string
@@ -374,6 +405,7 @@ type UpdateNodeInput struct {
 	KernelStatusPollBackoffJitter             *float64
 	KernelOfflineProbeMaxIntervalSeconds      *int
 	StatusSyncEnabled                         *bool
+	KernelEventMode                           *string
 	LastSyncedAt                              *time.Time
 }
 
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TLS certificate info sources.
+const (
+	TLSCertificateSourceProfile = "profile"
+	TLSCertificateSourceProbe   = "probe"
+)
+
+// NodeTLSCertificate caches what the panel knows about the certificate of a
+// kernel TLS node. NotAfter is nil when the certificate could not be read.
+type NodeTLSCertificate struct {
+	ID            uint64     `gorm:"primaryKey"`
+	NodeID        uint64     `gorm:"uniqueIndex:idx_node_tls_certificate"`
+	TLSNodeID     string     `gorm:"column:tls_node_id;size:128;uniqueIndex:idx_node_tls_certificate"`
+	Role          string     `gorm:"size:32"`
+	Mode          string     `gorm:"size:16"`
+	InnerProtocol string     `gorm:"size:32"`
+	HasReality    bool       `gorm:"column:has_reality"`
+	Listen        string     `gorm:"size:255"`
+	Subject       string     `gorm:"size:512"`
+	Issuer        string     `gorm:"size:512"`
+	DNSNames      []string   `gorm:"column:dns_names;serializer:json"`
+	Fingerprint   string     `gorm:"size:128"`
+	NotBefore     *time.Time `gorm:"column:not_before"`
+	NotAfter      *time.Time `gorm:"column:not_after;index"`
+	Source        string     `gorm:"size:16"`
+	CheckError    string     `gorm:"column:check_error;type:text"`
+	CheckedAt     time.Time  `gorm:"column:checked_at"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName binds the node TLS certificate table name.
+func (NodeTLSCertificate) TableName() string { return "node_tls_certificates" }
+
+// NodeTLSCertificateRepository stores TLS certificate info per kernel node.
+type NodeTLSCertificateRepository interface {
+	ListByNode(ctx context.Context, nodeID uint64) ([]NodeTLSCertificate, error)
+	ListByNodeIDs(ctx context.Context, nodeIDs []uint64) ([]NodeTLSCertificate, error)
+	Get(ctx context.Context, nodeID uint64, tlsNodeID string) (NodeTLSCertificate, error)
+	Upsert(ctx context.Context, cert NodeTLSCertificate) (NodeTLSCertificate, error)
+	DeleteMissing(ctx context.Context, nodeID uint64, keep []string) error
+}
+
+type nodeTLSCertificateRepository struct {
+	db *gorm.DB
+}
+
+// NewNodeTLSCertificateRepository constructs a node TLS certificate repository.
+func NewNodeTLSCertificateRepository(db *gorm.DB) (NodeTLSCertificateRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &nodeTLSCertificateRepository{db: db}, nil
+}
+
+func (r *nodeTLSCertificateRepository) ListByNode(ctx context.Context, nodeID uint64) ([]NodeTLSCertificate, error) {
+	if nodeID == 0 {
+		return nil, ErrInvalidArgument
+	}
+	return r.ListByNodeIDs(ctx, []uint64{nodeID})
+}
+
+func (r *nodeTLSCertificateRepository) ListByNodeIDs(ctx context.Context, nodeIDs []uint64) ([]NodeTLSCertificate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(nodeIDs) == 0 {
+		return []NodeTLSCertificate{}, nil
+	}
+
+	var certs []NodeTLSCertificate
+	if err := r.db.WithContext(ctx).
+		Where("node_id IN ?", nodeIDs).
+		Order("node_id ASC").
+		Order("tls_node_id ASC").
+		Find(&certs).Error; err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+func (r *nodeTLSCertificateRepository) Get(ctx context.Context, nodeID uint64, tlsNodeID string) (NodeTLSCertificate, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeTLSCertificate{}, err
+	}
+	tlsNodeID = strings.TrimSpace(tlsNodeID)
+	if nodeID == 0 || tlsNodeID == "" {
+		return NodeTLSCertificate{}, ErrInvalidArgument
+	}
+
+	var cert NodeTLSCertificate
+	if err := r.db.WithContext(ctx).
+		Where("node_id = ? AND tls_node_id = ?", nodeID, tlsNodeID).
+		First(&cert).Error; err != nil {
+		return NodeTLSCertificate{}, translateError(err)
+	}
+	return cert, nil
+}
+
+func (r *nodeTLSCertificateRepository) Upsert(ctx context.Context, cert NodeTLSCertificate) (NodeTLSCertificate, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeTLSCertificate{}, err
+	}
+	cert.TLSNodeID = strings.TrimSpace(cert.TLSNodeID)
+	if cert.NodeID == 0 || cert.TLSNodeID == "" {
+		return NodeTLSCertificate{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	existing, err := r.Get(ctx, cert.NodeID, cert.TLSNodeID)
+	switch {
+	case err == nil:
+		cert.ID = existing.ID
+		cert.CreatedAt = existing.CreatedAt
+	case errors.Is(err, ErrNotFound):
+		cert.ID = 0
+		cert.CreatedAt = now
+	default:
+		return NodeTLSCertificate{}, err
+	}
+	cert.UpdatedAt = now
+	if cert.CheckedAt.IsZero() {
+		cert.CheckedAt = now
+	}
+
+	if err := r.db.WithContext(ctx).Save(&cert).Error; err != nil {
+		return NodeTLSCertificate{}, translateError(err)
+	}
+	return cert, nil
+}
+
+func (r *nodeTLSCertificateRepository) DeleteMissing(ctx context.Context, nodeID uint64, keep []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if nodeID == 0 {
+		return ErrInvalidArgument
+	}
+
+	query := r.db.WithContext(ctx).Where("node_id = ?", nodeID)
+	if len(keep) > 0 {
+		query = query.Where("tls_node_id NOT IN ?", keep)
+	}
+	return query.Delete(&NodeTLSCertificate{}).Error
+}
@@ -26,6 +26,8 @@ type Plan struct {
 	TrafficLimitBytes  int64              `gorm:"column:traffic_limit_bytes"`
 	TrafficMultipliers map[string]float64 `gorm:"serializer:json"`
 	DevicesLimit       int                `gorm:"column:devices_limit"`
+	UploadRateBytes    int64              `gorm:"column:upload_rate_bytes"`
+	DownloadRateBytes  int64              `gorm:"column:download_rate_bytes"`
 	SortOrder          int                `gorm:"column:sort_order"`
 	Status             int                `gorm:"column:status"`
 	Visible            bool               `gorm:"column:is_visible"`
@@ -154,6 +156,8 @@ func (r *planRepository) Update(ctx context.Context, id uint64, updates Plan) (P
 		"traffic_limit_bytes",
 		"traffic_multipliers",
 		"devices_limit",
+		"upload_rate_bytes",
+		"download_rate_bytes",
 		"sort_order",
 		"status",
 		"is_visible",
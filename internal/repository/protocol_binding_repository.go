@@ -14,27 +14,33 @@ import (
 
 // ProtocolBinding binds a protocol configuration to a node instance.
 type ProtocolBinding struct {
-	ID              uint64         `gorm:"primaryKey"`
-	Name            string         `gorm:"size:255"`
-	NodeID          uint64         `gorm:"index"`
-	Protocol        string         `gorm:"size:32;index"`
-	Role            string         `gorm:"size:32"`
-	Listen          string         `gorm:"size:512"`
-	Connect         string         `gorm:"size:512"`
-	AccessPort      int            `gorm:"column:access_port"`
-	Status          int            `gorm:"column:status"`
-	KernelID        string         `gorm:"size:128;index"`
-	SyncStatus      int            `gorm:"column:sync_status"`
-	HealthStatus    int            `gorm:"column:health_status"`
-	LastSyncedAt    time.Time      `gorm:"column:last_synced_at"`
-	LastHeartbeatAt time.Time      `gorm:"column:last_heartbeat_at"`
-	LastSyncError   string         `gorm:"type:text"`
-	Tags            []string       `gorm:"serializer:json"`
-	Description     string         `gorm:"type:text"`
-	Profile         map[string]any `gorm:"serializer:json"`
-	Metadata        map[string]any `gorm:"serializer:json"`
-	UpdatedAt       time.Time
-	CreatedAt       time.Time
+	ID                uint64         `gorm:"primaryKey"`
+	Name              string         `gorm:"size:255"`
+	NodeID            uint64         `gorm:"index"`
+	Protocol          string         `gorm:"size:32;index"`
+	Role              string         `gorm:"size:32"`
+	Listen            string         `gorm:"size:512"`
+	Connect           string         `gorm:"size:512"`
+	AccessPort        int            `gorm:"column:access_port"`
+	Status            int            `gorm:"column:status"`
+	KernelID          string         `gorm:"size:128;index"`
+	UpstreamBindingID *uint64        `gorm:"column:upstream_binding_id;index"`
+	SyncStatus        int            `gorm:"column:sync_status"`
+	HealthStatus      int            `gorm:"column:health_status"`
+	LastSyncedAt      time.Time      `gorm:"column:last_synced_at"`
+	LastHeartbeatAt   time.Time      `gorm:"column:last_heartbeat_at"`
+	LastSyncError     string         `gorm:"type:text"`
+	SyncRequestedAt   *time.Time     `gorm:"column:sync_requested_at;index"`
+	SyncRequestReason string         `gorm:"column:sync_request_reason;size:64"`
+	SyncRequestFull   bool           `gorm:"column:sync_request_full"`
+	Tags              []string       `gorm:"serializer:json"`
+	Description       string         `gorm:"type:text"`
+	Profile           map[string]any `gorm:"serializer:json"`
+	AppliedProfile    map[string]any `gorm:"column:applied_profile;serializer:json"`
+	AppliedSpec       string         `gorm:"column:applied_spec;size:64"`
+	Metadata          map[string]any `gorm:"serializer:json"`
+	UpdatedAt         time.Time
+	CreatedAt         time.Time
 
 	Node Node `gorm:"foreignKey:NodeID;references:ID"`
 }
@@ -56,24 +62,27 @@ type ListProtocolBindingsOptions struct {
 
 // UpdateProtocolBindingInput defines mutable binding fields.
 type UpdateProtocolBindingInput struct {
-	Name            *string
-	NodeID          *uint64
-	Protocol        *string
-	Role            *string
-	Listen          *string
-	Connect         *string
-	AccessPort      *int
-	Status          *int
-	KernelID        *string
-	SyncStatus      *int
-	HealthStatus    *int
-	LastSyncedAt    *time.Time
-	LastHeartbeatAt *time.Time
-	LastSyncError   *string
-	Tags            *[]string
-	Description     *string
-	Profile         *map[string]any
-	Metadata        *map[string]any
+	Name              *string
+	NodeID            *uint64
+	Protocol          *string
+	Role              *string
+	Listen            *string
+	Connect           *string
+	AccessPort        *int
+	Status            *int
+	KernelID          *string
+	UpstreamBindingID *uint64 // zero clears the relay upstream
+	SyncStatus        *int
+	HealthStatus      *int
+	LastSyncedAt      *time.Time
+	LastHeartbeatAt   *time.Time
+	LastSyncError     *string
+	Tags              *[]string
+	Description       *string
+	Profile           *map[string]any
+	AppliedProfile    *map[string]any // profile last pushed to the kernel
+	AppliedSpec       *string         // fingerprint of the non-user fields last pushed
+	Metadata          *map[string]any
 }
 
 // ProtocolBindingRepository manages protocol binding persistence.
@@ -81,6 +90,7 @@ type ProtocolBindingRepository interface {
 	List(ctx context.Context, opts ListProtocolBindingsOptions) ([]ProtocolBinding, int64, error)
 	ListByIDs(ctx context.Context, ids []uint64) ([]ProtocolBinding, error)
 	ListByNodeIDs(ctx context.Context, nodeIDs []uint64) ([]ProtocolBinding, error)
+	ListByUpstreamIDs(ctx context.Context, upstreamIDs []uint64) ([]ProtocolBinding, error)
 	ListAll(ctx context.Context) ([]ProtocolBinding, error)
 	ListProtocols(ctx context.Context) ([]string, error)
 	Get(ctx context.Context, id uint64) (ProtocolBinding, error)
@@ -89,6 +99,12 @@ type ProtocolBindingRepository interface {
 	UpdateSyncState(ctx context.Context, id uint64, input UpdateProtocolBindingInput) (ProtocolBinding, error)
 	UpdateHealthByKernelID(ctx context.Context, kernelID string, statusCode int, observedAt time.Time, message string) (ProtocolBinding, error)
 	UpdateHealthByKernelIDForNodes(ctx context.Context, kernelID string, nodeIDs []uint64, statusCode int, observedAt time.Time, message string) (ProtocolBinding, error)
+	UpdateHealthByNodeIDs(ctx context.Context, nodeIDs []uint64, statusCode int) (int64, error)
+	ResetHealthByNodeIDs(ctx context.Context, nodeIDs []uint64, from int) (int64, error)
+	RequestSync(ctx context.Context, ids []uint64, reason string, requestedAt time.Time) error
+	RequestFullSync(ctx context.Context, ids []uint64, reason string, requestedAt time.Time) error
+	ListSyncRequested(ctx context.Context, before time.Time, limit int) ([]ProtocolBinding, error)
+	ClaimSyncRequest(ctx context.Context, id uint64, before time.Time, full bool) (bool, error)
 	Delete(ctx context.Context, id uint64) error
 }
 
@@ -167,6 +183,25 @@ func (r *protocolBindingRepository) ListByNodeIDs(ctx context.Context, nodeIDs [
 	return bindings, nil
 }
 
+func (r *protocolBindingRepository) ListByUpstreamIDs(ctx context.Context, upstreamIDs []uint64) ([]ProtocolBinding, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(upstreamIDs) == 0 {
+		return []ProtocolBinding{}, nil
+	}
+
+	var bindings []ProtocolBinding
+	if err := r.db.WithContext(ctx).
+		Where("upstream_binding_id IN ?", upstreamIDs).
+		Order("id ASC").
+		Preload("Node").
+		Find(&bindings).Error; err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
 func (r *protocolBindingRepository) ListByIDs(ctx context.Context, ids []uint64) ([]ProtocolBinding, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -320,6 +355,23 @@ func (r *protocolBindingRepository) UpdateSyncState(ctx context.Context, id uint
 	if input.KernelID != nil {
 		updates["kernel_id"] = strings.TrimSpace(*input.KernelID)
 	}
+	if input.UpstreamBindingID != nil {
+		if *input.UpstreamBindingID == 0 {
+			updates["upstream_binding_id"] = nil
+		} else {
+			updates["upstream_binding_id"] = *input.UpstreamBindingID
+		}
+	}
+	if input.AppliedProfile != nil {
+		serialized, err := serializeAnyMap(*input.AppliedProfile)
+		if err != nil {
+			return ProtocolBinding{}, err
+		}
+		updates["applied_profile"] = serialized
+	}
+	if input.AppliedSpec != nil {
+		updates["applied_spec"] = *input.AppliedSpec
+	}
 	if len(updates) == 0 {
 		return ProtocolBinding{}, ErrInvalidArgument
 	}
@@ -422,6 +474,135 @@ func (r *protocolBindingRepository) UpdateHealthByKernelIDForNodes(ctx context.C
 	return binding, nil
 }
 
+// UpdateHealthByNodeIDs sets the health of every binding on the given nodes and
+// returns the number of bindings updated. Sync errors are left untouched.
+func (r *protocolBindingRepository) UpdateHealthByNodeIDs(ctx context.Context, nodeIDs []uint64, statusCode int) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(nodeIDs) == 0 {
+		return 0, ErrInvalidArgument
+	}
+	if statusCode == 0 {
+		statusCode = status.ProtocolBindingHealthStatusUnknown
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&ProtocolBinding{}).
+		Where("node_id IN ?", nodeIDs).
+		Updates(map[string]any{
+			"health_status": statusCode,
+			"updated_at":    time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ResetHealthByNodeIDs moves bindings of the given nodes whose health is from
+// back to unknown, so the next heartbeat decides their health again.
+func (r *protocolBindingRepository) ResetHealthByNodeIDs(ctx context.Context, nodeIDs []uint64, from int) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(nodeIDs) == 0 {
+		return 0, ErrInvalidArgument
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&ProtocolBinding{}).
+		Where("node_id IN ? AND health_status = ?", nodeIDs, from).
+		Updates(map[string]any{
+			"health_status": status.ProtocolBindingHealthStatusUnknown,
+			"updated_at":    time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RequestSync queues bindings for reconciliation. An already pending request keeps
+// its original timestamp so a steady stream of changes cannot postpone it forever.
+func (r *protocolBindingRepository) RequestSync(ctx context.Context, ids []uint64, reason string, requestedAt time.Time) error {
+	return r.requestSync(ctx, ids, reason, requestedAt, false)
+}
+
+// RequestFullSync queues bindings like RequestSync and asks for a full upsert,
+// for changes beyond the binding's users. A pending full request is never
+// downgraded by a later RequestSync.
+func (r *protocolBindingRepository) RequestFullSync(ctx context.Context, ids []uint64, reason string, requestedAt time.Time) error {
+	return r.requestSync(ctx, ids, reason, requestedAt, true)
+}
+
+func (r *protocolBindingRepository) requestSync(ctx context.Context, ids []uint64, reason string, requestedAt time.Time, full bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if requestedAt.IsZero() {
+		requestedAt = time.Now()
+	}
+
+	updates := map[string]any{
+		"sync_requested_at":   gorm.Expr("COALESCE(sync_requested_at, ?)", requestedAt.UTC()),
+		"sync_request_reason": strings.TrimSpace(reason),
+	}
+	if full {
+		updates["sync_request_full"] = true
+	}
+	if err := r.db.WithContext(ctx).Model(&ProtocolBinding{}).
+		Where("id IN ?", ids).
+		UpdateColumns(updates).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (r *protocolBindingRepository) ListSyncRequested(ctx context.Context, before time.Time, limit int) ([]ProtocolBinding, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var bindings []ProtocolBinding
+	if err := r.db.WithContext(ctx).
+		Where("sync_requested_at IS NOT NULL AND sync_requested_at <= ?", before.UTC()).
+		Order("sync_requested_at ASC").
+		Limit(limit).
+		Preload("Node").
+		Find(&bindings).Error; err != nil {
+		return nil, err
+	}
+	return bindings, nil
+}
+
+// ClaimSyncRequest clears a due request before it is processed. Requests queued
+// after the claim start a new cycle, so no change is lost while a sync runs.
+// full is the mode the caller read; a request upgraded to full since then is
+// not claimed and comes back on the next listing.
+func (r *protocolBindingRepository) ClaimSyncRequest(ctx context.Context, id uint64, before time.Time, full bool) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if id == 0 {
+		return false, ErrInvalidArgument
+	}
+
+	result := r.db.WithContext(ctx).Model(&ProtocolBinding{}).
+		Where("id = ? AND sync_requested_at IS NOT NULL AND sync_requested_at <= ? AND sync_request_full = ?", id, before.UTC(), full).
+		UpdateColumns(map[string]any{"sync_requested_at": nil, "sync_request_full": false})
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (r *protocolBindingRepository) Delete(ctx context.Context, id uint64) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -21,6 +21,7 @@ type ProtocolEntry struct {
 	Status       int            `gorm:"column:status"`
 	EntryAddress string         `gorm:"size:512"`
 	EntryPort    int            `gorm:"column:entry_port"`
+	Priority     int            `gorm:"column:priority"`
 	Tags         []string       `gorm:"serializer:json"`
 	Description  string         `gorm:"type:text"`
 	Profile      map[string]any `gorm:"serializer:json"`
@@ -53,6 +54,7 @@ type UpdateProtocolEntryInput struct {
 	Status       *int
 	EntryAddress *string
 	EntryPort    *int
+	Priority     *int
 	Tags         *[]string
 	Description  *string
 	Profile      *map[string]any
@@ -247,6 +249,9 @@ func (r *protocolEntryRepository) buildEntryUpdates(input UpdateProtocolEntryInp
 	if input.EntryPort != nil {
 		updates["entry_port"] = *input.EntryPort
 	}
+	if input.Priority != nil {
+		updates["priority"] = *input.Priority
+	}
 	if input.Tags != nil {
 		serialized, err := serializeStringSlice(*input.Tags)
 		if err != nil {
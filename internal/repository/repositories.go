@@ -31,6 +31,15 @@ type Repositories struct {
 	ProtocolBinding      ProtocolBindingRepository
 	ProtocolEntry        ProtocolEntryRepository
 	TrafficUsage         TrafficUsageRepository
+	KernelTrafficCursor  KernelTrafficCursorRepository
+	UserRateLimit        UserRateLimitRepository
+	Device               DeviceRepository
+	NodeTLSCertificate   NodeTLSCertificateRepository
+	KernelAuditCursor    KernelAuditCursorRepository
+	NodeConfigSnapshot   NodeConfigSnapshotRepository
+	NodeBootstrap        NodeBootstrapRepository
+	SyncJob              SyncJobRepository
+	BindingRollout       BindingRolloutRepository
 }
 
 // NewRepositories 根据数据库实例创建仓储集合。
@@ -139,6 +148,51 @@ func NewRepositories(db *gorm.DB) (*Repositories, error) {
 		return nil, err
 	}
 
+	trafficCursorRepo, err := NewKernelTrafficCursorRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitRepo, err := NewUserRateLimitRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceRepo, err := NewDeviceRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTLSCertificateRepo, err := NewNodeTLSCertificateRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	auditCursorRepo, err := NewKernelAuditCursorRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotRepo, err := NewNodeConfigSnapshotRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrapRepo, err := NewNodeBootstrapRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	syncJobRepo, err := NewSyncJobRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	rolloutRepo, err := NewBindingRolloutRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Repositories{
 		db:                   db,
 		AdminModule:          adminModuleRepo,
@@ -161,6 +215,15 @@ func NewRepositories(db *gorm.DB) (*Repositories, error) {
 		ProtocolBinding:      protocolBindingRepo,
 		ProtocolEntry:        protocolEntryRepo,
 		TrafficUsage:         trafficRepo,
+		KernelTrafficCursor:  trafficCursorRepo,
+		UserRateLimit:        rateLimitRepo,
+		Device:               deviceRepo,
+		NodeTLSCertificate:   nodeTLSCertificateRepo,
+		KernelAuditCursor:    auditCursorRepo,
+		NodeConfigSnapshot:   snapshotRepo,
+		NodeBootstrap:        bootstrapRepo,
+		SyncJob:              syncJobRepo,
+		BindingRollout:       rolloutRepo,
 	}, nil
 }
 
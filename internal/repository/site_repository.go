@@ -9,15 +9,18 @@ import (
 	"gorm.io/gorm"
 )
 
-// SiteSetting stores branding configuration.
+// SiteSetting stores branding configuration and the site-wide policy for
+// entries whose bindings are unavailable.
 type SiteSetting struct {
-	ID                 uint64 `gorm:"primaryKey"`
-	Name               string `gorm:"size:128"`
-	LogoURL            string `gorm:"size:512"`
-	ServiceDomain      string `gorm:"column:access_domain;size:512"`
-	SubscriptionDomain string `gorm:"size:512"`
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	ID                   uint64 `gorm:"primaryKey"`
+	Name                 string `gorm:"size:128"`
+	LogoURL              string `gorm:"size:512"`
+	ServiceDomain        string `gorm:"column:access_domain;size:512"`
+	SubscriptionDomain   string `gorm:"size:512"`
+	UnhealthyEntryPolicy string `gorm:"size:16"`
+	UnhealthyEntrySuffix string `gorm:"size:64"`
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
 }
 
 // TableName custom binding.
@@ -86,6 +89,7 @@ func (r *siteRepository) UpsertSiteSetting(ctx context.Context, setting SiteSett
 	setting.LogoURL = strings.TrimSpace(setting.LogoURL)
 	setting.ServiceDomain = strings.TrimSpace(setting.ServiceDomain)
 	setting.SubscriptionDomain = strings.TrimSpace(setting.SubscriptionDomain)
+	setting.UnhealthyEntryPolicy = strings.ToLower(strings.TrimSpace(setting.UnhealthyEntryPolicy))
 
 	now := time.Now().UTC()
 	setting.UpdatedAt = now
@@ -101,11 +105,13 @@ func (r *siteRepository) UpsertSiteSetting(ctx context.Context, setting SiteSett
 	if err := r.db.WithContext(ctx).Model(&SiteSetting{}).
 		Where("id = ?", setting.ID).
 		Updates(map[string]any{
-			"name":                setting.Name,
-			"logo_url":            setting.LogoURL,
-			"access_domain":       setting.ServiceDomain,
-			"subscription_domain": setting.SubscriptionDomain,
-			"updated_at":          setting.UpdatedAt,
+			"name":                   setting.Name,
+			"logo_url":               setting.LogoURL,
+			"access_domain":          setting.ServiceDomain,
+			"subscription_domain":    setting.SubscriptionDomain,
+			"unhealthy_entry_policy": setting.UnhealthyEntryPolicy,
+			"unhealthy_entry_suffix": setting.UnhealthyEntrySuffix,
+			"updated_at":             setting.UpdatedAt,
 		}).Error; err != nil {
 		return SiteSetting{}, err
 	}
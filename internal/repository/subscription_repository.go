@@ -23,6 +23,7 @@ type Subscription struct {
 	PlanID               uint64         `gorm:"index"`
 	PlanSnapshot         map[string]any `gorm:"serializer:json"`
 	Status               int            `gorm:"column:status"`
+	StatusReason         string         `gorm:"column:status_reason;size:32"`
 	TemplateID           uint64
 	AvailableTemplateIDs []uint64 `gorm:"serializer:json"`
 	Token                string   `gorm:"size:255"`
@@ -35,6 +36,10 @@ type Subscription struct {
 	UpdatedAt            time.Time
 }
 
+// SubscriptionStatusReasonEnforcer marks statuses set by the subscription
+// enforcer; only those are restored automatically.
+const SubscriptionStatusReasonEnforcer = "enforcer"
+
 // TableName 自定义订阅表名。
 func (Subscription) TableName() string { return "subscriptions" }
 
@@ -58,6 +63,10 @@ type SubscriptionRepository interface {
 	List(ctx context.Context, opts ListSubscriptionsOptions) ([]Subscription, int64, error)
 	ListByUser(ctx context.Context, userID uint64, opts ListSubscriptionsOptions) ([]Subscription, int64, error)
 	ListActiveByPlanIDs(ctx context.Context, planIDs []uint64) ([]Subscription, error)
+	ListActiveByUserIDs(ctx context.Context, userIDs []uint64) ([]Subscription, error)
+	ListExpiredActive(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	ListExhaustedActive(ctx context.Context, limit int) ([]Subscription, error)
+	ListRestorable(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
 	Get(ctx context.Context, id uint64) (Subscription, error)
 	GetByToken(ctx context.Context, token string) (Subscription, error)
 	GetActiveByUser(ctx context.Context, userID uint64) (Subscription, error)
@@ -122,6 +131,89 @@ func (r *subscriptionRepository) ListActiveByPlanIDs(ctx context.Context, planID
 	return subscriptions, nil
 }
 
+// ListActiveByUserIDs returns the active subscriptions of the given users.
+func (r *subscriptionRepository) ListActiveByUserIDs(ctx context.Context, userIDs []uint64) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return []Subscription{}, nil
+	}
+
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).
+		Where("user_id IN ?", userIDs).
+		Where("status = ?", status.SubscriptionStatusActive).
+		Order("id ASC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListExpiredActive returns active subscriptions whose expiry has passed.
+// A zero ExpiresAt means the subscription never expires.
+func (r *subscriptionRepository) ListExpiredActive(ctx context.Context, now time.Time, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", status.SubscriptionStatusActive).
+		Where("expires_at > ? AND expires_at <= ?", time.Time{}, now.UTC()).
+		Order("expires_at ASC").
+		Limit(normalizeEnforcementLimit(limit)).
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListExhaustedActive returns active subscriptions that used up their traffic quota.
+func (r *subscriptionRepository) ListExhaustedActive(ctx context.Context, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", status.SubscriptionStatusActive).
+		Where("traffic_total_bytes > 0 AND traffic_used_bytes >= traffic_total_bytes").
+		Order("id ASC").
+		Limit(normalizeEnforcementLimit(limit)).
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListRestorable returns subscriptions the enforcer expired or exhausted that are
+// eligible again, e.g. after the expiry was moved forward or the quota was
+// topped up. Subscriptions set to expired by an admin are left alone, and so are
+// subscriptions whose user already holds another effective one.
+func (r *subscriptionRepository) ListRestorable(ctx context.Context, now time.Time, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now = now.UTC()
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).
+		Where("status IN ?", []int{status.SubscriptionStatusExpired, status.SubscriptionStatusExhausted}).
+		Where("status_reason = ?", SubscriptionStatusReasonEnforcer).
+		Where("(expires_at = ? OR expires_at > ?)", time.Time{}, now).
+		Where("(traffic_total_bytes <= 0 OR traffic_used_bytes < traffic_total_bytes)").
+		Where("NOT EXISTS (SELECT 1 FROM subscriptions AS other WHERE other.user_id = subscriptions.user_id AND other.id <> subscriptions.id AND other.status = ? AND (other.expires_at = ? OR other.expires_at > ?))",
+			status.SubscriptionStatusActive, time.Time{}, now).
+		Order("id ASC").
+		Limit(normalizeEnforcementLimit(limit)).
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
 func (r *subscriptionRepository) Get(ctx context.Context, id uint64) (Subscription, error) {
 	if err := ctx.Err(); err != nil {
 		return Subscription{}, err
@@ -182,8 +274,9 @@ func (r *subscriptionRepository) DisableOtherActive(ctx context.Context, userID
 	}
 
 	updates := map[string]any{
-		"status":     status.SubscriptionStatusDisabled,
-		"updated_at": time.Now().UTC(),
+		"status":        status.SubscriptionStatusDisabled,
+		"status_reason": "",
+		"updated_at":    time.Now().UTC(),
 	}
 
 	query := r.db.WithContext(ctx).Model(&Subscription{}).
@@ -204,6 +297,7 @@ type UpdateSubscriptionInput struct {
 	PlanID               *uint64
 	PlanSnapshot         *map[string]any
 	Status               *int
+	StatusReason         *string
 	TemplateID           *uint64
 	AvailableTemplateIDs *[]uint64
 	Token                *string
@@ -260,6 +354,12 @@ func (r *subscriptionRepository) Update(ctx context.Context, id uint64, input Up
 	}
 	if input.Status != nil {
 		updates["status"] = *input.Status
+		// A status change without a reason is manual and clears the previous one.
+		reason := ""
+		if input.StatusReason != nil {
+			reason = strings.TrimSpace(*input.StatusReason)
+		}
+		updates["status_reason"] = reason
 	}
 	if input.TemplateID != nil {
 		updates["template_id"] = *input.TemplateID
@@ -455,6 +555,13 @@ func buildSubscriptionOrderClause(field, direction string) string {
 	return fmt.Sprintf("%s %s", column, dir)
 }
 
+func normalizeEnforcementLimit(limit int) int {
+	if limit <= 0 || limit > 500 {
+		return 500
+	}
+	return limit
+}
+
 func normalizeListSubscriptionsOptions(opts ListSubscriptionsOptions) ListSubscriptionsOptions {
 	if opts.Page <= 0 {
 		opts.Page = 1
@@ -462,8 +569,8 @@ func normalizeListSubscriptionsOptions(opts ListSubscriptionsOptions) ListSubscr
 	if opts.PerPage <= 0 {
 		opts.PerPage = 20
 	}
-	if opts.PerPage > 100 {
-		opts.PerPage = 100
+	if opts.PerPage > MaxPerPage {
+		opts.PerPage = MaxPerPage
 	}
 	if opts.Sort == "" {
 		opts.Sort = "updated_at"
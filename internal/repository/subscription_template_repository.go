@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -54,6 +55,17 @@ type SubscriptionTemplateHistory struct {
 // TableName 自定义历史表名。
 func (SubscriptionTemplateHistory) TableName() string { return "subscription_template_histories" }
 
+// SubscriptionTemplateUsage 按版本统计模板被下载的次数。
+type SubscriptionTemplateUsage struct {
+	TemplateID uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Version    uint32 `gorm:"primaryKey;autoIncrement:false"`
+	Downloads  int64
+	LastUsedAt time.Time
+}
+
+// TableName 自定义使用统计表名。
+func (SubscriptionTemplateUsage) TableName() string { return "subscription_template_usages" }
+
 // ListTemplatesOptions 控制列表查询行为。
 type ListTemplatesOptions struct {
 	Page          int
@@ -93,6 +105,13 @@ type PublishSubscriptionTemplateInput struct {
 	Operator  string
 }
 
+// RollbackSubscriptionTemplateInput 回滚参数；DiscardDraft 允许覆盖未发布的草稿修改。
+type RollbackSubscriptionTemplateInput struct {
+	Changelog    string
+	Operator     string
+	DiscardDraft bool
+}
+
 // SubscriptionTemplateRepository 定义模板操作接口。
 type SubscriptionTemplateRepository interface {
 	List(ctx context.Context, opts ListTemplatesOptions) ([]SubscriptionTemplate, int64, error)
@@ -100,8 +119,12 @@ type SubscriptionTemplateRepository interface {
 	Create(ctx context.Context, input CreateSubscriptionTemplateInput) (SubscriptionTemplate, error)
 	Update(ctx context.Context, id uint64, input UpdateSubscriptionTemplateInput) (SubscriptionTemplate, error)
 	Publish(ctx context.Context, id uint64, input PublishSubscriptionTemplateInput) (SubscriptionTemplate, SubscriptionTemplateHistory, error)
+	Rollback(ctx context.Context, id uint64, version uint32, input RollbackSubscriptionTemplateInput) (SubscriptionTemplate, SubscriptionTemplateHistory, error)
 	History(ctx context.Context, id uint64) ([]SubscriptionTemplateHistory, error)
+	GetHistory(ctx context.Context, id uint64, version uint32) (SubscriptionTemplateHistory, error)
 	Get(ctx context.Context, id uint64) (SubscriptionTemplate, error)
+	RecordUsage(ctx context.Context, id uint64, version uint32, usedAt time.Time) error
+	Usage(ctx context.Context, id uint64) ([]SubscriptionTemplateUsage, error)
 }
 
 type subscriptionTemplateRepository struct {
@@ -182,8 +205,8 @@ func (r *subscriptionTemplateRepository) Create(ctx context.Context, input Creat
 
 	name := strings.TrimSpace(input.Name)
 	clientType := strings.ToLower(strings.TrimSpace(input.ClientType))
-	content := strings.TrimSpace(input.Content)
-	if name == "" || clientType == "" || content == "" {
+	// 内容是否必填取决于格式，由逻辑层校验。
+	if name == "" || clientType == "" {
 		return SubscriptionTemplate{}, ErrInvalidArgument
 	}
 
@@ -279,10 +302,6 @@ func (r *subscriptionTemplateRepository) Update(ctx context.Context, id uint64,
 			tpl.Format = newFormat
 		}
 		if input.Content != nil {
-			newContent := strings.TrimSpace(*input.Content)
-			if newContent == "" {
-				return ErrInvalidArgument
-			}
 			tpl.Content = *input.Content
 		}
 		if input.Variables != nil {
@@ -354,6 +373,90 @@ func (r *subscriptionTemplateRepository) Publish(ctx context.Context, id uint64,
 	return tpl, history, nil
 }
 
+// Rollback 将指定历史版本的内容、格式与变量恢复为草稿，并作为新版本发布。
+// 草稿与当前发布版本不一致时返回 ErrConflict，除非指定 DiscardDraft。
+func (r *subscriptionTemplateRepository) Rollback(ctx context.Context, id uint64, version uint32, input RollbackSubscriptionTemplateInput) (SubscriptionTemplate, SubscriptionTemplateHistory, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplate{}, SubscriptionTemplateHistory{}, err
+	}
+	if version == 0 {
+		return SubscriptionTemplate{}, SubscriptionTemplateHistory{}, ErrInvalidArgument
+	}
+
+	var tpl SubscriptionTemplate
+	var history SubscriptionTemplateHistory
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tpl, id).Error; err != nil {
+			return err
+		}
+
+		var snapshot SubscriptionTemplateHistory
+		if err := tx.Where("template_id = ? AND version = ?", id, version).First(&snapshot).Error; err != nil {
+			return err
+		}
+
+		if !input.DiscardDraft && tpl.Version > 0 {
+			var published SubscriptionTemplateHistory
+			if err := tx.Where("template_id = ? AND version = ?", id, tpl.Version).First(&published).Error; err != nil {
+				return err
+			}
+			if !DraftMatchesHistory(tpl, published) {
+				return fmt.Errorf("%w: draft has unpublished changes", ErrConflict)
+			}
+		}
+
+		changelog := strings.TrimSpace(input.Changelog)
+		if changelog == "" {
+			changelog = fmt.Sprintf("rollback to version %d", version)
+		}
+
+		now := time.Now().UTC()
+		tpl.Content = snapshot.Content
+		tpl.Format = snapshot.Format
+		tpl.Variables = cloneTemplateVariables(snapshot.Variables)
+		tpl.Version++
+		tpl.UpdatedAt = now
+		tpl.LastPublishedBy = strings.TrimSpace(input.Operator)
+		tpl.PublishedAt = &now
+
+		if err := tx.Save(&tpl).Error; err != nil {
+			return err
+		}
+
+		history = SubscriptionTemplateHistory{
+			TemplateID:  tpl.ID,
+			Version:     tpl.Version,
+			Content:     tpl.Content,
+			Variables:   cloneTemplateVariables(tpl.Variables),
+			Format:      tpl.Format,
+			Changelog:   changelog,
+			PublishedAt: now,
+			PublishedBy: tpl.LastPublishedBy,
+		}
+
+		return tx.Create(&history).Error
+	})
+
+	if err != nil {
+		return SubscriptionTemplate{}, SubscriptionTemplateHistory{}, translateError(err)
+	}
+
+	return tpl, history, nil
+}
+
+// DraftMatchesHistory reports whether the template draft is identical to the
+// given published snapshot.
+func DraftMatchesHistory(tpl SubscriptionTemplate, history SubscriptionTemplateHistory) bool {
+	if tpl.Content != history.Content || tpl.Format != history.Format {
+		return false
+	}
+	if len(tpl.Variables) == 0 && len(history.Variables) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(tpl.Variables, history.Variables)
+}
+
 func (r *subscriptionTemplateRepository) History(ctx context.Context, id uint64) ([]SubscriptionTemplateHistory, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -370,6 +473,21 @@ func (r *subscriptionTemplateRepository) History(ctx context.Context, id uint64)
 	return history, nil
 }
 
+func (r *subscriptionTemplateRepository) GetHistory(ctx context.Context, id uint64, version uint32) (SubscriptionTemplateHistory, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplateHistory{}, err
+	}
+
+	var history SubscriptionTemplateHistory
+	if err := r.db.WithContext(ctx).
+		Where("template_id = ? AND version = ?", id, version).
+		First(&history).Error; err != nil {
+		return SubscriptionTemplateHistory{}, translateError(err)
+	}
+
+	return history, nil
+}
+
 func (r *subscriptionTemplateRepository) Get(ctx context.Context, id uint64) (SubscriptionTemplate, error) {
 	if err := ctx.Err(); err != nil {
 		return SubscriptionTemplate{}, err
@@ -383,6 +501,54 @@ func (r *subscriptionTemplateRepository) Get(ctx context.Context, id uint64) (Su
 	return tpl, nil
 }
 
+// RecordUsage 累加模板某一版本的下载次数。
+func (r *subscriptionTemplateRepository) RecordUsage(ctx context.Context, id uint64, version uint32, usedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if id == 0 {
+		return ErrInvalidArgument
+	}
+	usedAt = usedAt.UTC()
+	if usedAt.IsZero() {
+		usedAt = time.Now().UTC()
+	}
+
+	usage := SubscriptionTemplateUsage{
+		TemplateID: id,
+		Version:    version,
+		Downloads:  1,
+		LastUsedAt: usedAt,
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "template_id"}, {Name: "version"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"downloads":    gorm.Expr("downloads + 1"),
+			"last_used_at": usedAt,
+		}),
+	}).Create(&usage).Error; err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// Usage 返回模板各版本的下载统计，按版本倒序。
+func (r *subscriptionTemplateRepository) Usage(ctx context.Context, id uint64) ([]SubscriptionTemplateUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var usage []SubscriptionTemplateUsage
+	if err := r.db.WithContext(ctx).
+		Where("template_id = ?", id).
+		Order("version DESC").
+		Find(&usage).Error; err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
 func buildTemplateOrderClause(field, direction string) string {
 	column := "updated_at"
 	switch strings.ToLower(field) {
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sync job states.
+const (
+	SyncJobStatusQueued    = "queued"
+	SyncJobStatusRunning   = "running"
+	SyncJobStatusSucceeded = "succeeded"
+	SyncJobStatusPartial   = "partial"
+	SyncJobStatusFailed    = "failed"
+	SyncJobStatusCancelled = "cancelled"
+)
+
+// Sync job item states.
+const (
+	SyncJobItemStatusPending   = "pending"
+	SyncJobItemStatusRunning   = "running"
+	SyncJobItemStatusSynced    = "synced"
+	SyncJobItemStatusError     = "error"
+	SyncJobItemStatusSkipped   = "skipped"
+	SyncJobItemStatusCancelled = "cancelled"
+)
+
+// SyncJob is a queued batch of protocol binding syncs processed in the background.
+type SyncJob struct {
+	ID         uint64  `gorm:"primaryKey"`
+	Mode       string  `gorm:"size:16"`
+	Status     string  `gorm:"size:16;index"`
+	Total      int     `gorm:"column:total"`
+	Message    string  `gorm:"type:text"`
+	ActorID    *uint64 `gorm:"column:actor_id"`
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName binds the sync job table name.
+func (SyncJob) TableName() string { return "sync_jobs" }
+
+// SyncJobItem is the sync of one binding within a job and its outcome.
+type SyncJobItem struct {
+	ID           uint64     `gorm:"primaryKey"`
+	JobID        uint64     `gorm:"index"`
+	BindingID    uint64     `gorm:"column:binding_id"`
+	NodeID       uint64     `gorm:"column:node_id"`
+	Status       string     `gorm:"size:16;index"`
+	ResultStatus int        `gorm:"column:result_status"`
+	Mode         string     `gorm:"size:16"`
+	Message      string     `gorm:"type:text"`
+	UsersAdded   int        `gorm:"column:users_added"`
+	UsersUpdated int        `gorm:"column:users_updated"`
+	UsersRemoved int        `gorm:"column:users_removed"`
+	Attempts     int        `gorm:"column:attempts"`
+	SyncedAt     *time.Time `gorm:"column:synced_at"`
+	UpdatedAt    time.Time
+}
+
+// TableName binds the sync job item table name.
+func (SyncJobItem) TableName() string { return "sync_job_items" }
+
+// SyncJobRepository stores sync jobs and their items.
+type SyncJobRepository interface {
+	Create(ctx context.Context, job SyncJob, items []SyncJobItem) (SyncJob, error)
+	Get(ctx context.Context, id uint64) (SyncJob, error)
+	ListActive(ctx context.Context, limit int) ([]SyncJob, error)
+	UpdateStatus(ctx context.Context, id uint64, from []string, to string, message string) (bool, error)
+	ListItems(ctx context.Context, jobID uint64) ([]SyncJobItem, error)
+	ClaimItem(ctx context.Context, itemID uint64) (bool, error)
+	SaveItemResult(ctx context.Context, item SyncJobItem) error
+	UpdateItemsStatus(ctx context.Context, jobID uint64, from []string, to string) (int64, error)
+}
+
+type syncJobRepository struct {
+	db *gorm.DB
+}
+
+// NewSyncJobRepository constructs a sync job repository.
+func NewSyncJobRepository(db *gorm.DB) (SyncJobRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &syncJobRepository{db: db}, nil
+}
+
+// Create stores a queued job together with its pending items.
+func (r *syncJobRepository) Create(ctx context.Context, job SyncJob, items []SyncJobItem) (SyncJob, error) {
+	if err := ctx.Err(); err != nil {
+		return SyncJob{}, err
+	}
+	if len(items) == 0 {
+		return SyncJob{}, ErrInvalidArgument
+	}
+	now := time.Now().UTC()
+	job.ID = 0
+	job.Status = SyncJobStatusQueued
+	job.Total = len(items)
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	job.StartedAt = nil
+	job.FinishedAt = nil
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&job).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].ID = 0
+			items[i].JobID = job.ID
+			items[i].Status = SyncJobItemStatusPending
+			items[i].UpdatedAt = now
+		}
+		return tx.CreateInBatches(items, 200).Error
+	})
+	if err != nil {
+		return SyncJob{}, translateError(err)
+	}
+	return job, nil
+}
+
+func (r *syncJobRepository) Get(ctx context.Context, id uint64) (SyncJob, error) {
+	if err := ctx.Err(); err != nil {
+		return SyncJob{}, err
+	}
+	if id == 0 {
+		return SyncJob{}, ErrInvalidArgument
+	}
+
+	var job SyncJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return SyncJob{}, translateError(err)
+	}
+	return job, nil
+}
+
+// ListActive returns queued and running jobs in submission order.
+func (r *syncJobRepository) ListActive(ctx context.Context, limit int) ([]SyncJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var jobs []SyncJob
+	if err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{SyncJobStatusQueued, SyncJobStatusRunning}).
+		Order("id ASC").
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return jobs, nil
+}
+
+// UpdateStatus moves a job to a new state when it is currently in one of from.
+// Entering running stamps StartedAt, re-queueing clears FinishedAt and any other
+// state stamps FinishedAt. It reports whether the job was updated.
+func (r *syncJobRepository) UpdateStatus(ctx context.Context, id uint64, from []string, to string, message string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if id == 0 || len(from) == 0 || to == "" {
+		return false, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	updates := map[string]any{
+		"status":     to,
+		"message":    message,
+		"updated_at": now,
+	}
+	switch to {
+	case SyncJobStatusRunning:
+		updates["started_at"] = gorm.Expr("COALESCE(started_at, ?)", now)
+	case SyncJobStatusQueued:
+		updates["finished_at"] = nil
+	default:
+		updates["finished_at"] = now
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&SyncJob{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *syncJobRepository) ListItems(ctx context.Context, jobID uint64) ([]SyncJobItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if jobID == 0 {
+		return nil, ErrInvalidArgument
+	}
+
+	var items []SyncJobItem
+	if err := r.db.WithContext(ctx).
+		Where("job_id = ?", jobID).
+		Order("id ASC").
+		Find(&items).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return items, nil
+}
+
+// ClaimItem marks a pending item as running; false means another worker or a
+// cancellation got to it first.
+func (r *syncJobRepository) ClaimItem(ctx context.Context, itemID uint64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if itemID == 0 {
+		return false, ErrInvalidArgument
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&SyncJobItem{}).
+		Where("id = ? AND status = ?", itemID, SyncJobItemStatusPending).
+		Updates(map[string]any{
+			"status":     SyncJobItemStatusRunning,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SaveItemResult records the outcome of a running item.
+func (r *syncJobRepository) SaveItemResult(ctx context.Context, item SyncJobItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if item.ID == 0 || item.Status == "" {
+		return ErrInvalidArgument
+	}
+
+	return translateError(r.db.WithContext(ctx).
+		Model(&SyncJobItem{}).
+		Where("id = ?", item.ID).
+		Updates(map[string]any{
+			"status":        item.Status,
+			"result_status": item.ResultStatus,
+			"mode":          item.Mode,
+			"message":       item.Message,
+			"users_added":   item.UsersAdded,
+			"users_updated": item.UsersUpdated,
+			"users_removed": item.UsersRemoved,
+			"synced_at":     item.SyncedAt,
+			"updated_at":    time.Now().UTC(),
+		}).Error)
+}
+
+// UpdateItemsStatus moves the items of a job (of every job when jobID is 0)
+// from one of the given states to another and returns how many moved.
+func (r *syncJobRepository) UpdateItemsStatus(ctx context.Context, jobID uint64, from []string, to string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(from) == 0 || to == "" {
+		return 0, ErrInvalidArgument
+	}
+
+	query := r.db.WithContext(ctx).Model(&SyncJobItem{}).Where("status IN ?", from)
+	if jobID != 0 {
+		query = query.Where("job_id = ?", jobID)
+	}
+	result := query.Updates(map[string]any{
+		"status":     to,
+		"updated_at": time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRateLimit is an admin override of a user's kernel speed limits. It
+// replaces the limits from the subscription plan on every binding the user is
+// on until it is removed or ExpiresAt passes. Rates are bytes per second and
+// zero means unlimited.
+type UserRateLimit struct {
+	ID                uint64     `gorm:"primaryKey"`
+	UserID            uint64     `gorm:"uniqueIndex"`
+	UploadRateBytes   int64      `gorm:"column:upload_rate_bytes"`
+	DownloadRateBytes int64      `gorm:"column:download_rate_bytes"`
+	Reason            string     `gorm:"size:255"`
+	ExpiresAt         *time.Time `gorm:"column:expires_at;index"`
+	CreatedBy         string     `gorm:"size:255"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TableName binds the user rate limit table name.
+func (UserRateLimit) TableName() string { return "user_rate_limits" }
+
+// Active reports whether the override still applies at the given time.
+func (l UserRateLimit) Active(now time.Time) bool {
+	return l.ExpiresAt == nil || l.ExpiresAt.After(now)
+}
+
+// UserRateLimitRepository manages per-user speed limit overrides.
+type UserRateLimitRepository interface {
+	Get(ctx context.Context, userID uint64) (UserRateLimit, error)
+	ListActiveByUserIDs(ctx context.Context, userIDs []uint64, now time.Time) ([]UserRateLimit, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]UserRateLimit, error)
+	Upsert(ctx context.Context, limit UserRateLimit) (UserRateLimit, error)
+	Delete(ctx context.Context, userID uint64) error
+}
+
+type userRateLimitRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRateLimitRepository constructs a user rate limit repository.
+func NewUserRateLimitRepository(db *gorm.DB) (UserRateLimitRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &userRateLimitRepository{db: db}, nil
+}
+
+func (r *userRateLimitRepository) Get(ctx context.Context, userID uint64) (UserRateLimit, error) {
+	if err := ctx.Err(); err != nil {
+		return UserRateLimit{}, err
+	}
+	if userID == 0 {
+		return UserRateLimit{}, ErrInvalidArgument
+	}
+
+	var limit UserRateLimit
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&limit).Error; err != nil {
+		return UserRateLimit{}, translateError(err)
+	}
+	return limit, nil
+}
+
+func (r *userRateLimitRepository) ListActiveByUserIDs(ctx context.Context, userIDs []uint64, now time.Time) ([]UserRateLimit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return []UserRateLimit{}, nil
+	}
+
+	var limits []UserRateLimit
+	if err := r.db.WithContext(ctx).
+		Where("user_id IN ?", userIDs).
+		Where("expires_at IS NULL OR expires_at > ?", now.UTC()).
+		Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+func (r *userRateLimitRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]UserRateLimit, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var limits []UserRateLimit
+	if err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now.UTC()).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&limits).Error; err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+func (r *userRateLimitRepository) Upsert(ctx context.Context, limit UserRateLimit) (UserRateLimit, error) {
+	if err := ctx.Err(); err != nil {
+		return UserRateLimit{}, err
+	}
+	if limit.UserID == 0 || limit.UploadRateBytes < 0 || limit.DownloadRateBytes < 0 {
+		return UserRateLimit{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	limit.Reason = strings.TrimSpace(limit.Reason)
+	limit.CreatedBy = strings.TrimSpace(limit.CreatedBy)
+	limit.UpdatedAt = now
+	if limit.ExpiresAt != nil {
+		expiresAt := limit.ExpiresAt.UTC()
+		limit.ExpiresAt = &expiresAt
+	}
+
+	existing, err := r.Get(ctx, limit.UserID)
+	if errors.Is(err, ErrNotFound) {
+		limit.ID = 0
+		limit.CreatedAt = now
+		if err := r.db.WithContext(ctx).Create(&limit).Error; err != nil {
+			return UserRateLimit{}, translateError(err)
+		}
+		return limit, nil
+	}
+	if err != nil {
+		return UserRateLimit{}, err
+	}
+
+	if err := r.db.WithContext(ctx).Model(&UserRateLimit{}).
+		Where("id = ?", existing.ID).
+		Updates(map[string]any{
+			"upload_rate_bytes":   limit.UploadRateBytes,
+			"download_rate_bytes": limit.DownloadRateBytes,
+			"reason":              limit.Reason,
+			"expires_at":          limit.ExpiresAt,
+			"created_by":          limit.CreatedBy,
+			"updated_at":          limit.UpdatedAt,
+		}).Error; err != nil {
+		return UserRateLimit{}, translateError(err)
+	}
+	return r.Get(ctx, limit.UserID)
+}
+
+func (r *userRateLimitRepository) Delete(ctx context.Context, userID uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if userID == 0 {
+		return ErrInvalidArgument
+	}
+
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&UserRateLimit{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
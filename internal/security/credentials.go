@@ -149,6 +149,16 @@ func (m *CredentialManager) DeriveIdentity(userID uint64, version int, secret []
 	}, nil
 }
 
+// DeriveRelayIdentity derives the credential a relay binding uses to reach its
+// upstream. It depends only on the master key and the binding ID, so both ends
+// of the chain derive the same identity without storing it.
+func (m *CredentialManager) DeriveRelayIdentity(bindingID uint64) (DerivedIdentity, error) {
+	if bindingID == 0 {
+		return DerivedIdentity{}, fmt.Errorf("credentials: binding id required")
+	}
+	return m.DeriveIdentity(bindingID, 1, m.deriveUserKey(bindingID, "relay"))
+}
+
 func (m *CredentialManager) deriveUserKey(userID uint64, purpose string) []byte {
 	mac := hmac.New(sha256.New, m.rootKey)
 	var buf [8]byte
@@ -49,10 +49,11 @@ const (
 )
 
 const (
-	SubscriptionStatusUnknown  = 0
-	SubscriptionStatusActive   = 1
-	SubscriptionStatusDisabled = 2
-	SubscriptionStatusExpired  = 3
+	SubscriptionStatusUnknown   = 0
+	SubscriptionStatusActive    = 1
+	SubscriptionStatusDisabled  = 2
+	SubscriptionStatusExpired   = 3
+	SubscriptionStatusExhausted = 4
 )
 
 const (
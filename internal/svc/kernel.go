@@ -0,0 +1,60 @@
+package svc
+
+import (
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/status"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+)
+
+// KernelHTTPOptions applies the configured retry policy and the shared circuit
+// breakers to kernel control client options.
+func (s *ServiceContext) KernelHTTPOptions(opts kernel.HTTPOptions) kernel.HTTPOptions {
+	if s == nil {
+		return opts
+	}
+	cfg := s.Config.KernelClient
+	opts.Retry = kernel.RetryPolicy{
+		MaxAttempts: cfg.RetryMaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+		Jitter:      cfg.RetryJitter,
+	}
+	opts.Breakers = s.KernelBreakers
+	return opts
+}
+
+// clearBreakerDegraded resets bindings flagged degraded on the nodes behind a
+// kernel endpoint whose circuit breaker has closed again.
+func (s *ServiceContext) clearBreakerDegraded(endpoint string) {
+	if s == nil || s.Repositories == nil {
+		return
+	}
+	ctx := s.Ctx
+	nodes, err := s.Repositories.Node.ListAll(ctx)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("breaker closed for %s: list nodes failed: %v", endpoint, err)
+		return
+	}
+
+	var nodeIDs []uint64
+	for _, node := range nodes {
+		if strings.TrimSuffix(strings.TrimSpace(node.ControlEndpoint), "/") == endpoint {
+			nodeIDs = append(nodeIDs, node.ID)
+		}
+	}
+	if len(nodeIDs) == 0 {
+		return
+	}
+
+	cleared, err := s.Repositories.ProtocolBinding.ResetHealthByNodeIDs(ctx, nodeIDs, status.ProtocolBindingHealthStatusDegraded)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("breaker closed for %s: clear degraded bindings failed: %v", endpoint, err)
+		return
+	}
+	if cleared > 0 {
+		logx.WithContext(ctx).Infof("breaker closed for %s: cleared degraded health on %d bindings", endpoint, cleared)
+	}
+}
@@ -0,0 +1,31 @@
+package svc
+
+import "sync"
+
+// KernelLocks serializes writes to a kernel. Panel nodes that share a control
+// endpoint and credentials drive the same kernel, so locks are keyed by that
+// pair rather than by node. Protocol pushes from the sync API, sync jobs, the
+// reconciler, rollouts and bootstraps, and protocol removals from drift fixes
+// and binding deletion all take the lock of their kernel. The zero value is
+// ready to use.
+type KernelLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// Lock acquires the lock of a kernel and returns its release func.
+func (k *KernelLocks) Lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		k.locks[key] = lock
+	}
+	k.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
@@ -13,15 +13,18 @@ import (
 	"github.com/zero-net-panel/zero-net-panel/pkg/auth"
 	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
 	"github.com/zero-net-panel/zero-net-panel/pkg/database"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
 )
 
 type ServiceContext struct {
-	Config        config.Config
-	DB            *gorm.DB
-	Cache         cache.Cache
-	Repositories  *repository.Repositories
-	Auth          *auth.Generator
-	Credentials   *security.CredentialManager
+	Config         config.Config
+	DB             *gorm.DB
+	Cache          cache.Cache
+	Repositories   *repository.Repositories
+	Auth           *auth.Generator
+	Credentials    *security.CredentialManager
+	KernelBreakers *kernel.CircuitBreakers
+	KernelLocks    KernelLocks
 
 	Ctx    context.Context
 	cancel context.CancelFunc
@@ -65,21 +68,27 @@ func NewServiceContext(c config.Config) (*ServiceContext, error) {
 
 	credentialManager, err := security.NewCredentialManager(c.Credentials.MasterKey)
 	if err != nil {
+		cancel()
 		_ = cacheProvider.Close()
 		dbClose()
 		return nil, fmt.Errorf("init credential manager: %w", err)
 	}
 
 	svcCtx := &ServiceContext{
-		Config:        c,
-		DB:            db,
-		Cache:         cacheProvider,
-		Repositories:  repos,
-		Auth:          authGenerator,
-		Credentials:   credentialManager,
-		Ctx:           ctx,
-		cancel:        cancel,
+		Config:       c,
+		DB:           db,
+		Cache:        cacheProvider,
+		Repositories: repos,
+		Auth:         authGenerator,
+		Credentials:  credentialManager,
+		Ctx:          ctx,
+		cancel:       cancel,
 	}
+	svcCtx.KernelBreakers = kernel.NewCircuitBreakers(kernel.BreakerOptions{
+		FailureThreshold: c.KernelClient.BreakerFailureThreshold,
+		OpenDuration:     c.KernelClient.BreakerOpenDuration,
+		OnClose:          svcCtx.clearBreakerDegraded,
+	})
 
 	svcCtx.cleanup = func() {
 		if svcCtx.cancel != nil {
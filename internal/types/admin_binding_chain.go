@@ -0,0 +1,32 @@
+package types
+
+// AdminProtocolBindingChainRequest addresses the relay chain of a binding.
+type AdminProtocolBindingChainRequest struct {
+	BindingID uint64 `path:"id"`
+}
+
+// ProtocolBindingChainHop describes one binding in a relay chain together with
+// the address relays dial to reach it.
+type ProtocolBindingChainHop struct {
+	BindingID         uint64 `json:"binding_id"`
+	Name              string `json:"name"`
+	NodeID            uint64 `json:"node_id"`
+	NodeName          string `json:"node_name"`
+	Protocol          string `json:"protocol"`
+	Role              string `json:"role"`
+	KernelID          string `json:"kernel_id"`
+	Address           string `json:"address"`
+	Port              int    `json:"port"`
+	UpstreamBindingID uint64 `json:"upstream_binding_id"`
+	Status            int    `json:"status"`
+	HealthStatus      int    `json:"health_status"`
+}
+
+// AdminProtocolBindingChainResponse lists the hops upstream of a binding,
+// nearest first, and the relays that forward through it.
+type AdminProtocolBindingChainResponse struct {
+	Binding           ProtocolBindingChainHop   `json:"binding"`
+	Upstream          []ProtocolBindingChainHop `json:"upstream"`
+	Downstream        []ProtocolBindingChainHop `json:"downstream"`
+	ChainHealthStatus int                       `json:"chain_health_status"`
+}
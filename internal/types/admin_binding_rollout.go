@@ -0,0 +1,64 @@
+package types
+
+// AdminCreateBindingRolloutRequest rolls a new profile out to bindings in stages.
+// Bindings are selected by ID, node or kernel ID. The canary stage holds the
+// bindings whose node carries CanaryTag, or CanaryPercent of them otherwise;
+// StagePercents are the cumulative shares of the remaining bindings reached by
+// the following stages.
+type AdminCreateBindingRolloutRequest struct {
+	BindingIDs    []uint64       `json:"binding_ids,optional"`
+	NodeIDs       []uint64       `json:"node_ids,optional"`
+	KernelID      string         `json:"kernel_id,optional"`
+	Profile       map[string]any `json:"profile"`
+	CanaryTag     string         `json:"canary_tag,optional"`
+	CanaryPercent int            `json:"canary_percent,optional"`
+	StagePercents []int          `json:"stage_percents,optional"`
+	SoakSeconds   int            `json:"soak_seconds,optional"`
+}
+
+// AdminBindingRolloutRequest addresses a binding rollout.
+type AdminBindingRolloutRequest struct {
+	RolloutID uint64 `path:"id"`
+}
+
+// AdminRollbackBindingRolloutRequest rolls a rollout back manually.
+type AdminRollbackBindingRolloutRequest struct {
+	RolloutID uint64 `path:"id"`
+	Reason    string `json:"reason,optional"`
+}
+
+// BindingRolloutItemSummary reports the state of one binding in a rollout.
+type BindingRolloutItemSummary struct {
+	BindingID uint64 `json:"binding_id"`
+	NodeID    uint64 `json:"node_id"`
+	Stage     int    `json:"stage"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	AppliedAt int64  `json:"applied_at"`
+}
+
+// BindingRolloutSummary reports the progress of a binding rollout.
+type BindingRolloutSummary struct {
+	ID             uint64                      `json:"id"`
+	KernelID       string                      `json:"kernel_id"`
+	Status         string                      `json:"status"`
+	Stage          int                         `json:"stage"`
+	StageCount     int                         `json:"stage_count"`
+	Profile        map[string]any              `json:"profile"`
+	CanaryTag      string                      `json:"canary_tag"`
+	CanaryPercent  int                         `json:"canary_percent"`
+	StagePercents  []int                       `json:"stage_percents"`
+	SoakSeconds    int                         `json:"soak_seconds"`
+	StageStartedAt int64                       `json:"stage_started_at"`
+	Message        string                      `json:"message"`
+	ActorID        *uint64                     `json:"actor_id"`
+	CreatedAt      int64                       `json:"created_at"`
+	UpdatedAt      int64                       `json:"updated_at"`
+	FinishedAt     int64                       `json:"finished_at"`
+	Items          []BindingRolloutItemSummary `json:"items"`
+}
+
+// AdminBindingRolloutResponse returns a binding rollout.
+type AdminBindingRolloutResponse struct {
+	Rollout BindingRolloutSummary `json:"rollout"`
+}
@@ -0,0 +1,48 @@
+package types
+
+// AdminBootstrapNodeRequest starts a bulk load of users and protocols onto a node kernel.
+type AdminBootstrapNodeRequest struct {
+	NodeID    uint64 `path:"id"`
+	ChunkSize int    `json:"chunk_size,optional"`
+}
+
+// AdminGetNodeBootstrapRequest fetches a bootstrap run; the latest one when BootstrapID is omitted.
+type AdminGetNodeBootstrapRequest struct {
+	NodeID      uint64 `path:"id"`
+	BootstrapID uint64 `form:"bootstrap_id,optional"`
+}
+
+// NodeBootstrapFailure is a user or protocol that could not be loaded.
+type NodeBootstrapFailure struct {
+	Stage    string `json:"stage"`
+	UserID   string `json:"user_id"`
+	KernelID string `json:"kernel_id"`
+	Message  string `json:"message"`
+}
+
+// NodeBootstrapSummary reports the progress of a bootstrap run.
+type NodeBootstrapSummary struct {
+	ID             uint64                 `json:"id"`
+	NodeID         uint64                 `json:"node_id"`
+	Status         string                 `json:"status"`
+	Stage          string                 `json:"stage"`
+	ChunkSize      int                    `json:"chunk_size"`
+	TotalUsers     int                    `json:"total_users"`
+	ImportedUsers  int                    `json:"imported_users"`
+	ChunksTotal    int                    `json:"chunks_total"`
+	ChunksDone     int                    `json:"chunks_done"`
+	ProtocolsTotal int                    `json:"protocols_total"`
+	ProtocolsDone  int                    `json:"protocols_done"`
+	FailureCount   int                    `json:"failure_count"`
+	Failures       []NodeBootstrapFailure `json:"failures"`
+	Message        string                 `json:"message"`
+	ActorID        *uint64                `json:"actor_id"`
+	StartedAt      int64                  `json:"started_at"`
+	FinishedAt     int64                  `json:"finished_at"`
+	UpdatedAt      int64                  `json:"updated_at"`
+}
+
+// AdminNodeBootstrapResponse returns a bootstrap run.
+type AdminNodeBootstrapResponse struct {
+	Bootstrap NodeBootstrapSummary `json:"bootstrap"`
+}
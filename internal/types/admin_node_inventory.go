@@ -0,0 +1,81 @@
+package types
+
+// AdminGetNodeRequest fetches a node with its live kernel inventory.
+type AdminGetNodeRequest struct {
+	NodeID uint64 `path:"id"`
+}
+
+// NodeInterfaceAddress is an address bound to a kernel host interface.
+type NodeInterfaceAddress struct {
+	Family  string `json:"family"`
+	Address string `json:"address"`
+	Prefix  int    `json:"prefix"`
+}
+
+// NodeInterfaceSummary describes a network interface of the kernel host.
+type NodeInterfaceSummary struct {
+	Name       string                 `json:"name"`
+	MAC        string                 `json:"mac"`
+	MTU        *int                   `json:"mtu"`
+	IsUp       bool                   `json:"is_up"`
+	IsLoopback bool                   `json:"is_loopback"`
+	Kind       string                 `json:"kind"`
+	Addresses  []NodeInterfaceAddress `json:"addresses"`
+}
+
+// NodeRuleProviderSummary describes a kernel rule provider and its state.
+type NodeRuleProviderSummary struct {
+	Name          string `json:"name"`
+	State         string `json:"state"`
+	Checksum      string `json:"checksum"`
+	ETag          string `json:"etag"`
+	LastSuccessAt int64  `json:"last_success_at"`
+	LastAttemptAt int64  `json:"last_attempt_at"`
+	LastError     string `json:"last_error"`
+}
+
+// AdminNodeDetailResponse returns a node with interfaces and rule providers read
+// live from its kernel. The *_error fields are set when a section is unavailable.
+type AdminNodeDetailResponse struct {
+	Node               NodeSummary               `json:"node"`
+	Interfaces         []NodeInterfaceSummary    `json:"interfaces"`
+	InterfacesError    string                    `json:"interfaces_error,omitempty"`
+	RuleProviders      []NodeRuleProviderSummary `json:"rule_providers"`
+	RuleProvidersError string                    `json:"rule_providers_error,omitempty"`
+}
+
+// AdminRefreshNodeRuleProviderRequest refreshes a rule provider on one node.
+type AdminRefreshNodeRuleProviderRequest struct {
+	NodeID uint64 `path:"id"`
+	Name   string `path:"name"`
+}
+
+// AdminNodeRuleProviderResponse returns the refreshed provider state.
+type AdminNodeRuleProviderResponse struct {
+	NodeID       uint64                  `json:"node_id"`
+	RuleProvider NodeRuleProviderSummary `json:"rule_provider"`
+}
+
+// AdminRefreshRuleProvidersRequest refreshes a rule provider on every node
+// carrying Tag, or on the listed nodes when NodeIDs is set.
+type AdminRefreshRuleProvidersRequest struct {
+	Name    string   `json:"name"`
+	Tag     string   `json:"tag,optional"`
+	NodeIDs []uint64 `json:"node_ids,optional"`
+}
+
+// NodeRuleProviderRefreshResult is the outcome of a refresh on one node.
+type NodeRuleProviderRefreshResult struct {
+	NodeID       uint64                   `json:"node_id"`
+	NodeName     string                   `json:"node_name"`
+	Status       int                      `json:"status"`
+	Message      string                   `json:"message"`
+	RuleProvider *NodeRuleProviderSummary `json:"rule_provider,omitempty"`
+}
+
+// AdminRefreshRuleProvidersResponse lists per-node refresh results.
+type AdminRefreshRuleProvidersResponse struct {
+	Name    string                          `json:"name"`
+	Tag     string                          `json:"tag"`
+	Results []NodeRuleProviderRefreshResult `json:"results"`
+}
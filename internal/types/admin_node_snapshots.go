@@ -0,0 +1,116 @@
+package types
+
+// AdminCreateNodeSnapshotRequest captures the running configuration of a node.
+type AdminCreateNodeSnapshotRequest struct {
+	NodeID uint64 `path:"id"`
+	Reason string `json:"reason,optional"`
+}
+
+// AdminListNodeSnapshotsRequest lists the snapshots of a node.
+type AdminListNodeSnapshotsRequest struct {
+	NodeID  uint64 `path:"id"`
+	Page    int    `form:"page,optional" json:"page,optional"`
+	PerPage int    `form:"per_page,optional" json:"per_page,optional"`
+}
+
+// AdminGetNodeSnapshotRequest fetches a single snapshot.
+type AdminGetNodeSnapshotRequest struct {
+	NodeID     uint64 `path:"id"`
+	SnapshotID uint64 `path:"snapshot_id"`
+}
+
+// AdminNodeSnapshotDiffRequest compares two snapshots, or a snapshot with the
+// configuration the panel intends to run when To is omitted.
+type AdminNodeSnapshotDiffRequest struct {
+	NodeID uint64 `path:"id"`
+	From   uint64 `form:"from"`
+	To     uint64 `form:"to,optional"`
+}
+
+// NodeSnapshotExport is the kernel /v1/export summary of a snapshot.
+type NodeSnapshotExport struct {
+	Path                  string   `json:"path"`
+	Version               string   `json:"version"`
+	Protocols             int      `json:"protocols"`
+	Users                 *int     `json:"users"`
+	API                   *bool    `json:"api"`
+	PipelineStrategies    int      `json:"pipeline_strategies"`
+	PipelineRules         int      `json:"pipeline_rules"`
+	PipelineResolvedRules *int     `json:"pipeline_resolved_rules"`
+	FilterProtocols       []string `json:"filter_protocols"`
+}
+
+// NodeSnapshotProtocol is one protocol captured in a snapshot.
+type NodeSnapshotProtocol struct {
+	KernelID    string         `json:"kernel_id"`
+	Role        string         `json:"role"`
+	Protocol    string         `json:"protocol"`
+	Tags        []string       `json:"tags"`
+	Description string         `json:"description"`
+	Listen      string         `json:"listen"`
+	Connect     string         `json:"connect"`
+	Users       []string       `json:"users"`
+	Profile     map[string]any `json:"profile"`
+}
+
+// NodeSnapshotSummary describes a stored snapshot.
+type NodeSnapshotSummary struct {
+	ID            uint64             `json:"id"`
+	NodeID        uint64             `json:"node_id"`
+	Version       int                `json:"version"`
+	Trigger       string             `json:"trigger"`
+	Reason        string             `json:"reason"`
+	ContentHash   string             `json:"content_hash"`
+	ProtocolCount int                `json:"protocol_count"`
+	UserCount     int                `json:"user_count"`
+	Export        NodeSnapshotExport `json:"export"`
+	ExportError   string             `json:"export_error"`
+	ActorID       *uint64            `json:"actor_id"`
+	CreatedAt     int64              `json:"created_at"`
+}
+
+// AdminNodeSnapshotListResponse lists snapshots, newest first.
+type AdminNodeSnapshotListResponse struct {
+	Snapshots  []NodeSnapshotSummary `json:"snapshots"`
+	Pagination PaginationMeta        `json:"pagination"`
+}
+
+// AdminNodeSnapshotResponse returns a snapshot with its protocols.
+type AdminNodeSnapshotResponse struct {
+	Snapshot  NodeSnapshotSummary    `json:"snapshot"`
+	Protocols []NodeSnapshotProtocol `json:"protocols"`
+}
+
+// NodeSnapshotFieldChange is a changed protocol field.
+type NodeSnapshotFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// NodeSnapshotProtocolChange lists the differences of a protocol present on both sides.
+type NodeSnapshotProtocolChange struct {
+	KernelID     string                    `json:"kernel_id"`
+	Fields       []NodeSnapshotFieldChange `json:"fields"`
+	UsersAdded   []string                  `json:"users_added"`
+	UsersRemoved []string                  `json:"users_removed"`
+}
+
+// NodeSnapshotRef identifies one side of a diff.
+type NodeSnapshotRef struct {
+	Kind       string `json:"kind"`
+	SnapshotID uint64 `json:"snapshot_id"`
+	Version    int    `json:"version"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// AdminNodeSnapshotDiffResponse is a structured diff from one side to the other.
+type AdminNodeSnapshotDiffResponse struct {
+	NodeID           uint64                       `json:"node_id"`
+	From             NodeSnapshotRef              `json:"from"`
+	To               NodeSnapshotRef              `json:"to"`
+	Identical        bool                         `json:"identical"`
+	AddedProtocols   []NodeSnapshotProtocol       `json:"added_protocols"`
+	RemovedProtocols []NodeSnapshotProtocol       `json:"removed_protocols"`
+	ChangedProtocols []NodeSnapshotProtocolChange `json:"changed_protocols"`
+}
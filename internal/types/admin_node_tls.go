@@ -0,0 +1,56 @@
+package types
+
+// AdminNodeTLSRequest lists the TLS nodes of a panel node.
+type AdminNodeTLSRequest struct {
+	NodeID uint64 `path:"id"`
+}
+
+// AdminUpdateNodeTLSModeRequest switches the TLS mode of a kernel TLS node.
+type AdminUpdateNodeTLSModeRequest struct {
+	NodeID    uint64 `path:"id"`
+	TLSNodeID string `path:"tls_id"`
+	Mode      string `json:"mode"`
+}
+
+// AdminSetNodeTLSCertificatePathsRequest points a kernel TLS node at certificate files on the node.
+type AdminSetNodeTLSCertificatePathsRequest struct {
+	NodeID          uint64 `path:"id"`
+	TLSNodeID       string `path:"tls_id"`
+	CertificatePath string `json:"certificate_path"`
+	PrivateKeyPath  string `json:"private_key_path"`
+}
+
+// NodeTLSCertificateSummary describes a kernel TLS node and its certificate.
+type NodeTLSCertificateSummary struct {
+	TLSNodeID     string   `json:"tls_node_id"`
+	Role          string   `json:"role"`
+	Mode          string   `json:"mode"`
+	InnerProtocol string   `json:"inner_protocol"`
+	HasReality    bool     `json:"has_reality"`
+	Listen        string   `json:"listen"`
+	Subject       string   `json:"subject"`
+	Issuer        string   `json:"issuer"`
+	DNSNames      []string `json:"dns_names"`
+	Fingerprint   string   `json:"fingerprint"`
+	NotBefore     int64    `json:"not_before"`
+	NotAfter      int64    `json:"not_after"`
+	DaysRemaining int      `json:"days_remaining"`
+	Expiring      bool     `json:"expiring"`
+	Source        string   `json:"source"`
+	CheckError    string   `json:"check_error"`
+	CheckedAt     int64    `json:"checked_at"`
+}
+
+// AdminNodeTLSResponse returns the TLS nodes of a panel node.
+type AdminNodeTLSResponse struct {
+	NodeID              uint64                      `json:"node_id"`
+	ExpiryWindowSeconds int64                       `json:"expiry_window_seconds"`
+	TLSNodes            []NodeTLSCertificateSummary `json:"tls_nodes"`
+	Message             string                      `json:"message,omitempty"`
+}
+
+// AdminNodeTLSNodeResponse returns a single kernel TLS node.
+type AdminNodeTLSNodeResponse struct {
+	NodeID  uint64                    `json:"node_id"`
+	TLSNode NodeTLSCertificateSummary `json:"tls_node"`
+}
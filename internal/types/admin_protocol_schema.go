@@ -0,0 +1,42 @@
+package types
+
+// ProtocolFieldCondition makes a field required while a sibling field holds
+// one of the values.
+type ProtocolFieldCondition struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+// ProtocolSchemaField describes one profile key.
+type ProtocolSchemaField struct {
+	Name         string                  `json:"name"`
+	Type         string                  `json:"type"`
+	Description  string                  `json:"description"`
+	Required     bool                    `json:"required"`
+	RequiredWhen *ProtocolFieldCondition `json:"required_when,omitempty"`
+	Enum         []string                `json:"enum,omitempty"`
+	Items        string                  `json:"items,omitempty"`
+	Min          *int                    `json:"min,omitempty"`
+	Max          *int                    `json:"max,omitempty"`
+	Default      any                     `json:"default,omitempty"`
+	Fields       []ProtocolSchemaField   `json:"fields,omitempty"`
+}
+
+// ProtocolPreset is a ready-made server and client profile.
+type ProtocolPreset struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	ServerProfile map[string]any `json:"server_profile"`
+	ClientProfile map[string]any `json:"client_profile"`
+}
+
+// ProtocolSchema lists the profile fields a protocol accepts. Server fields
+// apply to listener bindings, client fields to connector bindings and entries.
+type ProtocolSchema struct {
+	Protocol     string                `json:"protocol"`
+	Aliases      []string              `json:"aliases"`
+	Description  string                `json:"description"`
+	ServerFields []ProtocolSchemaField `json:"server_fields"`
+	ClientFields []ProtocolSchemaField `json:"client_fields"`
+	Presets      []ProtocolPreset      `json:"presets"`
+}
@@ -101,3 +101,64 @@ type AdminExtendSubscriptionRequest struct {
 	ExtendHours    int    `json:"extend_hours,omitempty,optional"`
 	ExpiresAt      *int64 `json:"expires_at,omitempty,optional"`
 }
+
+// AdminSubscriptionDevicesRequest addresses the devices of a subscription.
+type AdminSubscriptionDevicesRequest struct {
+	SubscriptionID uint64 `path:"id"`
+}
+
+// SubscriptionDeviceSummary describes a client IP seen for a subscription.
+type SubscriptionDeviceSummary struct {
+	ClientIP    string `json:"client_ip"`
+	Source      string `json:"source"`
+	UserAgent   string `json:"user_agent"`
+	Hits        int64  `json:"hits"`
+	Active      bool   `json:"active"`
+	FirstSeenAt int64  `json:"first_seen_at"`
+	LastSeenAt  int64  `json:"last_seen_at"`
+}
+
+// DeviceLimitViolationSummary describes a DevicesLimit violation.
+type DeviceLimitViolationSummary struct {
+	DeviceCount       int    `json:"device_count"`
+	DevicesLimit      int    `json:"devices_limit"`
+	Action            string `json:"action"`
+	ThrottleRateBytes int64  `json:"throttle_rate_bytes"`
+	DetectedAt        int64  `json:"detected_at"`
+	PenaltyUntil      int64  `json:"penalty_until,omitempty"`
+}
+
+// AdminSubscriptionDevicesResponse lists devices and the current violation.
+type AdminSubscriptionDevicesResponse struct {
+	SubscriptionID uint64                       `json:"subscription_id"`
+	DevicesLimit   int                          `json:"devices_limit"`
+	WindowSeconds  int                          `json:"window_seconds"`
+	ActiveDevices  int                          `json:"active_devices"`
+	Devices        []SubscriptionDeviceSummary  `json:"devices"`
+	Violation      *DeviceLimitViolationSummary `json:"violation,omitempty"`
+}
+
+// AdminGetDevicePolicyRequest fetches the device limit policy.
+type AdminGetDevicePolicyRequest struct{}
+
+// AdminUpdateDevicePolicyRequest updates the device limit policy.
+type AdminUpdateDevicePolicyRequest struct {
+	Action            *string `json:"action,omitempty,optional"`
+	WindowSeconds     *int    `json:"window_seconds,omitempty,optional"`
+	PenaltySeconds    *int    `json:"penalty_seconds,omitempty,optional"`
+	ThrottleRateBytes *int64  `json:"throttle_rate_bytes,omitempty,optional"`
+}
+
+// DevicePolicySummary describes how DevicesLimit violations are handled.
+type DevicePolicySummary struct {
+	Action            string `json:"action"`
+	WindowSeconds     int    `json:"window_seconds"`
+	PenaltySeconds    int    `json:"penalty_seconds"`
+	ThrottleRateBytes int64  `json:"throttle_rate_bytes"`
+	UpdatedAt         int64  `json:"updated_at"`
+}
+
+// AdminDevicePolicyResponse returns the device limit policy.
+type AdminDevicePolicyResponse struct {
+	Policy DevicePolicySummary `json:"policy"`
+}
@@ -0,0 +1,32 @@
+package types
+
+// AdminSyncJobRequest addresses a binding sync job.
+type AdminSyncJobRequest struct {
+	JobID uint64 `path:"id"`
+}
+
+// SyncJobSummary reports the progress of a binding sync job. Results lists the
+// bindings that have finished so far.
+type SyncJobSummary struct {
+	ID         uint64                      `json:"id"`
+	Status     string                      `json:"status"`
+	Mode       string                      `json:"mode"`
+	Total      int                         `json:"total"`
+	Pending    int                         `json:"pending"`
+	Running    int                         `json:"running"`
+	Synced     int                         `json:"synced"`
+	Failed     int                         `json:"failed"`
+	Skipped    int                         `json:"skipped"`
+	Cancelled  int                         `json:"cancelled"`
+	Message    string                      `json:"message"`
+	ActorID    *uint64                     `json:"actor_id"`
+	CreatedAt  int64                       `json:"created_at"`
+	StartedAt  int64                       `json:"started_at"`
+	FinishedAt int64                       `json:"finished_at"`
+	Results    []ProtocolBindingSyncResult `json:"results"`
+}
+
+// AdminSyncJobResponse returns a binding sync job.
+type AdminSyncJobResponse struct {
+	Job SyncJobSummary `json:"job"`
+}
@@ -88,3 +88,39 @@ type AdminRotateUserCredentialResponse struct {
 	UserID     uint64            `json:"user_id"`
 	Credential CredentialSummary `json:"credential"`
 }
+
+// AdminGetUserRateLimitRequest fetches a user's speed limit override.
+type AdminGetUserRateLimitRequest struct {
+	UserID uint64 `path:"id"`
+}
+
+// AdminSetUserRateLimitRequest sets a user's speed limit override.
+type AdminSetUserRateLimitRequest struct {
+	UserID            uint64 `path:"id"`
+	UploadRateBytes   int64  `json:"upload_rate_bytes"`
+	DownloadRateBytes int64  `json:"download_rate_bytes"`
+	Reason            string `json:"reason,optional"`
+	ExpiresAt         int64  `json:"expires_at,optional"`
+}
+
+// AdminDeleteUserRateLimitRequest removes a user's speed limit override.
+type AdminDeleteUserRateLimitRequest struct {
+	UserID uint64 `path:"id"`
+}
+
+// UserRateLimitSummary describes a speed limit override in bytes per second.
+type UserRateLimitSummary struct {
+	UserID            uint64 `json:"user_id"`
+	UploadRateBytes   int64  `json:"upload_rate_bytes"`
+	DownloadRateBytes int64  `json:"download_rate_bytes"`
+	Reason            string `json:"reason"`
+	ExpiresAt         *int64 `json:"expires_at,omitempty"`
+	CreatedBy         string `json:"created_by"`
+	CreatedAt         int64  `json:"created_at"`
+	UpdatedAt         int64  `json:"updated_at"`
+}
+
+// AdminUserRateLimitResponse returns the override; nil when plan limits apply.
+type AdminUserRateLimitResponse struct {
+	RateLimit *UserRateLimitSummary `json:"rate_limit"`
+}
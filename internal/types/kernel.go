@@ -21,8 +21,9 @@ type KernelTrafficRecord struct {
 
 // KernelTrafficIngestResponse acknowledges traffic ingestion.
 type KernelTrafficIngestResponse struct {
-	Accepted int `json:"accepted"`
-	Failed   int `json:"failed"`
+	Accepted   int `json:"accepted"`
+	Failed     int `json:"failed"`
+	Duplicated int `json:"duplicated,omitempty"`
 }
 
 // KernelNodeEventRequest represents a node event notification.
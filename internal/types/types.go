@@ -85,6 +85,8 @@ type AuditLogSummary struct {
 	ResourceType string         `json:"resource_type"`
 	ResourceID   string         `json:"resource_id"`
 	SourceIP     string         `json:"source_ip"`
+	Source       string         `json:"source"`
+	NodeID       *uint64        `json:"node_id"`
 	Metadata     map[string]any `json:"metadata"`
 	CreatedAt    int64          `json:"created_at"`
 }
@@ -97,6 +99,8 @@ type AdminAuditLogListRequest struct {
 	Action       string  `form:"action,optional" json:"action,optional"`
 	ResourceType string  `form:"resource_type,optional" json:"resource_type,optional"`
 	ResourceID   string  `form:"resource_id,optional" json:"resource_id,optional"`
+	Source       string  `form:"source,optional" json:"source,optional"`
+	NodeID       *uint64 `form:"node_id,optional" json:"node_id,optional"`
 	Since        int64   `form:"since,optional" json:"since,optional"`
 	Until        int64   `form:"until,optional" json:"until,optional"`
 }
@@ -115,6 +119,8 @@ type AdminAuditLogExportRequest struct {
 	Action       string  `form:"action,optional" json:"action,optional"`
 	ResourceType string  `form:"resource_type,optional" json:"resource_type,optional"`
 	ResourceID   string  `form:"resource_id,optional" json:"resource_id,optional"`
+	Source       string  `form:"source,optional" json:"source,optional"`
+	NodeID       *uint64 `form:"node_id,optional" json:"node_id,optional"`
 	Since        int64   `form:"since,optional" json:"since,optional"`
 	Until        int64   `form:"until,optional" json:"until,optional"`
 	Format       string  `form:"format,optional" json:"format,optional"`
@@ -129,13 +135,15 @@ type AdminAuditLogExportResponse struct {
 
 // SiteSetting 站点品牌配置。
 type SiteSetting struct {
-	ID                 uint64 `json:"id"`
-	Name               string `json:"name"`
-	LogoURL            string `json:"logo_url"`
-	ServiceDomain      string `json:"service_domain"`
-	SubscriptionDomain string `json:"subscription_domain"`
-	CreatedAt          int64  `json:"created_at"`
-	UpdatedAt          int64  `json:"updated_at"`
+	ID                   uint64 `json:"id"`
+	Name                 string `json:"name"`
+	LogoURL              string `json:"logo_url"`
+	ServiceDomain        string `json:"service_domain"`
+	SubscriptionDomain   string `json:"subscription_domain"`
+	UnhealthyEntryPolicy string `json:"unhealthy_entry_policy"`
+	UnhealthyEntrySuffix string `json:"unhealthy_entry_suffix"`
+	CreatedAt            int64  `json:"created_at"`
+	UpdatedAt            int64  `json:"updated_at"`
 }
 
 // AdminSiteSettingResponse 站点配置响应。
@@ -149,6 +157,9 @@ type AdminUpdateSiteSettingRequest struct {
 	LogoURL            *string `json:"logo_url,optional"`
 	ServiceDomain      *string `json:"service_domain,optional"`
 	SubscriptionDomain *string `json:"subscription_domain,optional"`
+	// UnhealthyEntryPolicy 不可用协议发布的展示策略：show/hide/demote/mark。
+	UnhealthyEntryPolicy *string `json:"unhealthy_entry_policy,optional"`
+	UnhealthyEntrySuffix *string `json:"unhealthy_entry_suffix,optional"`
 }
 
 // AdminListNodesRequest 管理端节点列表查询参数。
@@ -164,28 +175,45 @@ type AdminListNodesRequest struct {
 
 // NodeSummary 节点摘要信息。
 type NodeSummary struct {
-	ID                                        uint64   `json:"id"`
-	Name                                      string   `json:"name"`
-	Region                                    string   `json:"region"`
-	Country                                   string   `json:"country"`
-	ISP                                       string   `json:"isp"`
-	Status                                    int      `json:"status"`
-	Tags                                      []string `json:"tags"`
-	CapacityMbps                              int      `json:"capacity_mbps"`
-	Description                               string   `json:"description"`
-	AccessAddress                             string   `json:"access_address"`
-	ControlEndpoint                           string   `json:"control_endpoint"`
-	KernelDefaultProtocol                     string   `json:"kernel_default_protocol"`
-	KernelHTTPTimeoutSeconds                  int      `json:"kernel_http_timeout_seconds"`
-	KernelStatusPollIntervalSeconds           int      `json:"kernel_status_poll_interval_seconds"`
-	KernelStatusPollBackoffEnabled            bool     `json:"kernel_status_poll_backoff_enabled"`
-	KernelStatusPollBackoffMaxIntervalSeconds int      `json:"kernel_status_poll_backoff_max_interval_seconds"`
-	KernelStatusPollBackoffMultiplier         float64  `json:"kernel_status_poll_backoff_multiplier"`
-	KernelStatusPollBackoffJitter             float64  `json:"kernel_status_poll_backoff_jitter"`
-	KernelOfflineProbeMaxIntervalSeconds      int      `json:"kernel_offline_probe_max_interval_seconds"`
-	StatusSyncEnabled                         bool     `json:"status_sync_enabled"`
-	LastSyncedAt                              int64    `json:"last_synced_at"`
-	UpdatedAt                                 int64    `json:"updated_at"`
+	ID                                        uint64               `json:"id"`
+	Name                                      string               `json:"name"`
+	Region                                    string               `json:"region"`
+	Country                                   string               `json:"country"`
+	ISP                                       string               `json:"isp"`
+	Status                                    int                  `json:"status"`
+	Tags                                      []string             `json:"tags"`
+	CapacityMbps                              int                  `json:"capacity_mbps"`
+	PortRangeStart                            int                  `json:"port_range_start"`
+	PortRangeEnd                              int                  `json:"port_range_end"`
+	Description                               string               `json:"description"`
+	AccessAddress                             string               `json:"access_address"`
+	ControlEndpoint                           string               `json:"control_endpoint"`
+	KernelDefaultProtocol                     string               `json:"kernel_default_protocol"`
+	KernelHTTPTimeoutSeconds                  int                  `json:"kernel_http_timeout_seconds"`
+	KernelStatusPollIntervalSeconds           int                  `json:"kernel_status_poll_interval_seconds"`
+	KernelStatusPollBackoffEnabled            bool                 `json:"kernel_status_poll_backoff_enabled"`
+	KernelStatusPollBackoffMaxIntervalSeconds int                  `json:"kernel_status_poll_backoff_max_interval_seconds"`
+	KernelStatusPollBackoffMultiplier         float64              `json:"kernel_status_poll_backoff_multiplier"`
+	KernelStatusPollBackoffJitter             float64              `json:"kernel_status_poll_backoff_jitter"`
+	KernelOfflineProbeMaxIntervalSeconds      int                  `json:"kernel_offline_probe_max_interval_seconds"`
+	StatusSyncEnabled                         bool                 `json:"status_sync_enabled"`
+	KernelEventMode                           string               `json:"kernel_event_mode"`
+	TLSExpiresAt                              int64                `json:"tls_expires_at"`
+	TLSExpiring                               bool                 `json:"tls_expiring"`
+	AuditSink                                 *NodeAuditSinkStatus `json:"audit_sink,omitempty"`
+	LastSyncedAt                              int64                `json:"last_synced_at"`
+	UpdatedAt                                 int64                `json:"updated_at"`
+}
+
+// NodeAuditSinkStatus 节点内核审计管道状态。
+type NodeAuditSinkStatus struct {
+	Healthy      bool   `json:"healthy"`
+	Queued       int64  `json:"queued"`
+	Dropped      int64  `json:"dropped"`
+	SinkFailures int64  `json:"sink_failures"`
+	LastError    string `json:"last_error"`
+	PullError    string `json:"pull_error"`
+	CheckedAt    int64  `json:"checked_at"`
 }
 
 // AdminNodeResponse 节点详情响应。
@@ -202,6 +230,8 @@ type AdminCreateNodeRequest struct {
 	Status                                    int      `json:"status,optional"`
 	Tags                                      []string `json:"tags,optional"`
 	CapacityMbps                              int      `json:"capacity_mbps,optional"`
+	PortRangeStart                            int      `json:"port_range_start,optional"`
+	PortRangeEnd                              int      `json:"port_range_end,optional"`
 	Description                               string   `json:"description,optional"`
 	AccessAddress                             string   `json:"access_address,optional"`
 	ControlEndpoint                           string   `json:"control_endpoint"`
@@ -219,6 +249,7 @@ type AdminCreateNodeRequest struct {
 	KernelStatusPollBackoffJitter             *float64 `json:"kernel_status_poll_backoff_jitter,optional"`
 	KernelOfflineProbeMaxIntervalSeconds      *int     `json:"kernel_offline_probe_max_interval_seconds,optional"`
 	StatusSyncEnabled                         *bool    `json:"status_sync_enabled,optional"`
+	KernelEventMode                           string   `json:"kernel_event_mode,optional"`
 }
 
 // AdminUpdateNodeRequest 管理端更新节点请求。
@@ -231,6 +262,8 @@ type AdminUpdateNodeRequest struct {
 	Status                                    *int     `json:"status,optional"`
 	Tags                                      []string `json:"tags,optional"`
 	CapacityMbps                              *int     `json:"capacity_mbps,optional"`
+	PortRangeStart                            *int     `json:"port_range_start,optional"`
+	PortRangeEnd                              *int     `json:"port_range_end,optional"`
 	Description                               *string  `json:"description,optional"`
 	AccessAddress                             *string  `json:"access_address,optional"`
 	ControlEndpoint                           *string  `json:"control_endpoint,optional"`
@@ -248,6 +281,7 @@ type AdminUpdateNodeRequest struct {
 	KernelStatusPollBackoffJitter             *float64 `json:"kernel_status_poll_backoff_jitter,optional"`
 	KernelOfflineProbeMaxIntervalSeconds      *int     `json:"kernel_offline_probe_max_interval_seconds,optional"`
 	StatusSyncEnabled                         *bool    `json:"status_sync_enabled,optional"`
+	KernelEventMode                           *string  `json:"kernel_event_mode,optional"`
 }
 
 // AdminDisableNodeRequest 管理端禁用节点请求。
@@ -339,7 +373,8 @@ type AdminSyncNodeStatusResponse struct {
 
 // AdminProtocolListResponse 管理端协议列表响应。
 type AdminProtocolListResponse struct {
-	Protocols []string `json:"protocols"`
+	Protocols []string         `json:"protocols"`
+	Schemas   []ProtocolSchema `json:"schemas"`
 }
 
 // AdminListProtocolBindingsRequest 管理端协议绑定列表请求。
@@ -356,28 +391,33 @@ type AdminListProtocolBindingsRequest struct {
 
 // ProtocolBindingSummary 协议绑定摘要。
 type ProtocolBindingSummary struct {
-	ID              uint64         `json:"id"`
-	Name            string         `json:"name"`
-	NodeID          uint64         `json:"node_id"`
-	NodeName        string         `json:"node_name"`
-	Protocol        string         `json:"protocol"`
-	Role            string         `json:"role"`
-	Listen          string         `json:"listen"`
-	Connect         string         `json:"connect"`
-	AccessPort      int            `json:"access_port"`
-	Status          int            `json:"status"`
-	KernelID        string         `json:"kernel_id"`
-	SyncStatus      int            `json:"sync_status"`
-	HealthStatus    int            `json:"health_status"`
-	LastSyncedAt    int64          `json:"last_synced_at"`
-	LastHeartbeatAt int64          `json:"last_heartbeat_at"`
-	LastSyncError   string         `json:"last_sync_error"`
-	Tags            []string       `json:"tags"`
-	Description     string         `json:"description"`
-	Profile         map[string]any `json:"profile"`
-	Metadata        map[string]any `json:"metadata"`
-	CreatedAt       int64          `json:"created_at"`
-	UpdatedAt       int64          `json:"updated_at"`
+	ID                uint64         `json:"id"`
+	Name              string         `json:"name"`
+	NodeID            uint64         `json:"node_id"`
+	NodeName          string         `json:"node_name"`
+	Protocol          string         `json:"protocol"`
+	Role              string         `json:"role"`
+	Listen            string         `json:"listen"`
+	Connect           string         `json:"connect"`
+	AccessPort        int            `json:"access_port"`
+	Status            int            `json:"status"`
+	KernelID          string         `json:"kernel_id"`
+	UpstreamBindingID uint64         `json:"upstream_binding_id"`
+	SyncStatus        int            `json:"sync_status"`
+	HealthStatus      int            `json:"health_status"`
+	ChainHealthStatus int            `json:"chain_health_status"`
+	LastSyncedAt      int64          `json:"last_synced_at"`
+	LastHeartbeatAt   int64          `json:"last_heartbeat_at"`
+	LastSyncError     string         `json:"last_sync_error"`
+	SyncPending       bool           `json:"sync_pending"`
+	SyncRequestedAt   int64          `json:"sync_requested_at"`
+	SyncRequestReason string         `json:"sync_request_reason"`
+	Tags              []string       `json:"tags"`
+	Description       string         `json:"description"`
+	Profile           map[string]any `json:"profile"`
+	Metadata          map[string]any `json:"metadata"`
+	CreatedAt         int64          `json:"created_at"`
+	UpdatedAt         int64          `json:"updated_at"`
 }
 
 // AdminProtocolBindingListResponse 协议绑定列表响应。
@@ -388,42 +428,47 @@ type AdminProtocolBindingListResponse struct {
 
 // AdminCreateProtocolBindingRequest 创建协议绑定请求。
 type AdminCreateProtocolBindingRequest struct {
-	Name        string         `json:"name,optional"`
-	NodeID      uint64         `json:"node_id"`
-	Protocol    string         `json:"protocol"`
-	Role        string         `json:"role"`
-	Listen      string         `json:"listen,optional"`
-	Connect     string         `json:"connect,optional"`
-	AccessPort  int            `json:"access_port,optional"`
-	Status      int            `json:"status,optional"`
-	KernelID    string         `json:"kernel_id"`
-	Tags        []string       `json:"tags,optional"`
-	Description string         `json:"description,optional"`
-	Profile     map[string]any `json:"profile"`
-	Metadata    map[string]any `json:"metadata,optional"`
+	Name              string         `json:"name,optional"`
+	NodeID            uint64         `json:"node_id"`
+	Protocol          string         `json:"protocol"`
+	Role              string         `json:"role"`
+	Listen            string         `json:"listen,optional"`
+	Connect           string         `json:"connect,optional"`
+	AccessPort        int            `json:"access_port,optional"`
+	AutoPort          bool           `json:"auto_port,optional"`
+	Status            int            `json:"status,optional"`
+	KernelID          string         `json:"kernel_id"`
+	UpstreamBindingID uint64         `json:"upstream_binding_id,optional"`
+	Tags              []string       `json:"tags,optional"`
+	Description       string         `json:"description,optional"`
+	Profile           map[string]any `json:"profile"`
+	Preset            string         `json:"preset,optional"`
+	Metadata          map[string]any `json:"metadata,optional"`
 }
 
 // AdminUpdateProtocolBindingRequest 更新协议绑定请求。
 type AdminUpdateProtocolBindingRequest struct {
-	BindingID       uint64         `path:"id"`
-	Name            *string        `json:"name,optional"`
-	NodeID          *uint64        `json:"node_id,optional"`
-	Protocol        *string        `json:"protocol,optional"`
-	Role            *string        `json:"role,optional"`
-	Listen          *string        `json:"listen,optional"`
-	Connect         *string        `json:"connect,optional"`
-	AccessPort      *int           `json:"access_port,optional"`
-	Status          *int           `json:"status,optional"`
-	KernelID        *string        `json:"kernel_id,optional"`
-	SyncStatus      *int           `json:"sync_status,optional"`
-	HealthStatus    *int           `json:"health_status,optional"`
-	LastSyncedAt    *int64         `json:"last_synced_at,omitempty,optional"`
-	LastHeartbeatAt *int64         `json:"last_heartbeat_at,omitempty,optional"`
-	LastSyncError   *string        `json:"last_sync_error,optional"`
-	Tags            []string       `json:"tags,optional"`
-	Description     *string        `json:"description,optional"`
-	Profile         map[string]any `json:"profile,optional"`
-	Metadata        map[string]any `json:"metadata,optional"`
+	BindingID         uint64         `path:"id"`
+	Name              *string        `json:"name,optional"`
+	NodeID            *uint64        `json:"node_id,optional"`
+	Protocol          *string        `json:"protocol,optional"`
+	Role              *string        `json:"role,optional"`
+	Listen            *string        `json:"listen,optional"`
+	Connect           *string        `json:"connect,optional"`
+	AccessPort        *int           `json:"access_port,optional"`
+	AutoPort          bool           `json:"auto_port,optional"`
+	Status            *int           `json:"status,optional"`
+	KernelID          *string        `json:"kernel_id,optional"`
+	UpstreamBindingID *uint64        `json:"upstream_binding_id,optional"`
+	SyncStatus        *int           `json:"sync_status,optional"`
+	HealthStatus      *int           `json:"health_status,optional"`
+	LastSyncedAt      *int64         `json:"last_synced_at,omitempty,optional"`
+	LastHeartbeatAt   *int64         `json:"last_heartbeat_at,omitempty,optional"`
+	LastSyncError     *string        `json:"last_sync_error,optional"`
+	Tags              []string       `json:"tags,optional"`
+	Description       *string        `json:"description,optional"`
+	Profile           map[string]any `json:"profile,optional"`
+	Metadata          map[string]any `json:"metadata,optional"`
 }
 
 // AdminDeleteProtocolBindingRequest 删除协议绑定请求。
@@ -457,6 +502,7 @@ type ProtocolEntrySummary struct {
 	HealthStatus  int            `json:"health_status"`
 	EntryAddress  string         `json:"entry_address"`
 	EntryPort     int            `json:"entry_port"`
+	Priority      int            `json:"priority"`
 	Tags          []string       `json:"tags"`
 	Description   string         `json:"description"`
 	Profile       map[string]any `json:"profile"`
@@ -478,9 +524,11 @@ type AdminCreateProtocolEntryRequest struct {
 	Status       int            `json:"status,optional"`
 	EntryAddress string         `json:"entry_address"`
 	EntryPort    int            `json:"entry_port"`
+	Priority     int            `json:"priority,optional"`
 	Tags         []string       `json:"tags,optional"`
 	Description  string         `json:"description,optional"`
 	Profile      map[string]any `json:"profile,optional"`
+	Preset       string         `json:"preset,optional"`
 }
 
 // AdminUpdateProtocolEntryRequest 管理端更新协议发布请求。
@@ -492,6 +540,7 @@ type AdminUpdateProtocolEntryRequest struct {
 	Status       *int           `json:"status,optional"`
 	EntryAddress *string        `json:"entry_address,optional"`
 	EntryPort    *int           `json:"entry_port,optional"`
+	Priority     *int           `json:"priority,optional"`
 	Tags         []string       `json:"tags,optional"`
 	Description  *string        `json:"description,optional"`
 	Profile      map[string]any `json:"profile,optional"`
@@ -504,21 +553,27 @@ type AdminDeleteProtocolEntryRequest struct {
 
 // ProtocolBindingSyncResult 单条协议下发结果。
 type ProtocolBindingSyncResult struct {
-	BindingID uint64 `json:"binding_id"`
-	Status    int    `json:"status"`
-	Message   string `json:"message"`
-	SyncedAt  int64  `json:"synced_at"`
+	BindingID    uint64 `json:"binding_id"`
+	Status       int    `json:"status"`
+	Message      string `json:"message"`
+	Mode         string `json:"mode"`
+	UsersAdded   int    `json:"users_added"`
+	UsersUpdated int    `json:"users_updated"`
+	UsersRemoved int    `json:"users_removed"`
+	SyncedAt     int64  `json:"synced_at"`
 }
 
 // AdminSyncProtocolBindingRequest 触发单条协议下发。
 type AdminSyncProtocolBindingRequest struct {
 	BindingID uint64 `path:"id"`
+	Mode      string `json:"mode,optional"`
 }
 
 // AdminSyncProtocolBindingsRequest 批量协议下发请求。
 type AdminSyncProtocolBindingsRequest struct {
 	BindingIDs []uint64 `json:"binding_ids,optional"`
 	NodeIDs    []uint64 `json:"node_ids,optional"`
+	Mode       string   `json:"mode,optional"`
 }
 
 // AdminSyncProtocolBindingsResponse 批量协议下发响应。
@@ -545,6 +600,66 @@ type AdminSyncProtocolBindingStatusResponse struct {
 	Results []ProtocolBindingStatusSyncResult `json:"results"`
 }
 
+// AdminProtocolDriftRequest 内核漂移检测请求，未指定节点时检测全部已配置控制面的节点。
+type AdminProtocolDriftRequest struct {
+	NodeID uint64 `form:"node_id,optional" json:"node_id,optional"`
+}
+
+// ProtocolDriftItem 协议漂移条目。
+type ProtocolDriftItem struct {
+	BindingID uint64 `json:"binding_id"`
+	KernelID  string `json:"kernel_id"`
+	Protocol  string `json:"protocol"`
+	Reason    string `json:"reason"`
+}
+
+// ProtocolUserDrift 协议用户漂移（用户 ID 列表）。
+type ProtocolUserDrift struct {
+	BindingID       uint64   `json:"binding_id"`
+	KernelID        string   `json:"kernel_id"`
+	MissingUsers    []string `json:"missing_users"`
+	ExtraUsers      []string `json:"extra_users"`
+	MismatchedUsers []string `json:"mismatched_users"`
+}
+
+// NodeDriftReport 单节点漂移报告。
+type NodeDriftReport struct {
+	NodeID              uint64              `json:"node_id"`
+	NodeName            string              `json:"node_name"`
+	InSync              bool                `json:"in_sync"`
+	Message             string              `json:"message"`
+	MissingProtocols    []ProtocolDriftItem `json:"missing_protocols"`
+	ExtraProtocols      []ProtocolDriftItem `json:"extra_protocols"`
+	MismatchedProtocols []ProtocolDriftItem `json:"mismatched_protocols"`
+	Users               []ProtocolUserDrift `json:"users"`
+	CheckedAt           int64               `json:"checked_at"`
+}
+
+// AdminProtocolDriftResponse 内核漂移检测响应。
+type AdminProtocolDriftResponse struct {
+	Nodes []NodeDriftReport `json:"nodes"`
+}
+
+// AdminFixProtocolDriftRequest 内核漂移修复请求，未指定节点时修复全部存在漂移的节点。
+type AdminFixProtocolDriftRequest struct {
+	NodeIDs []uint64 `json:"node_ids,optional"`
+}
+
+// NodeDriftFixResult 单节点漂移修复结果。
+type NodeDriftFixResult struct {
+	NodeID           uint64 `json:"node_id"`
+	Status           int    `json:"status"`
+	Message          string `json:"message"`
+	ProtocolsSynced  int    `json:"protocols_synced"`
+	ProtocolsRemoved int    `json:"protocols_removed"`
+	FixedAt          int64  `json:"fixed_at"`
+}
+
+// AdminFixProtocolDriftResponse 内核漂移修复响应。
+type AdminFixProtocolDriftResponse struct {
+	Results []NodeDriftFixResult `json:"results"`
+}
+
 // AdminListSubscriptionTemplatesRequest 管理端模板列表查询。
 type AdminListSubscriptionTemplatesRequest struct {
 	Page          int    `form:"page,optional" json:"page,optional"`
@@ -627,6 +742,7 @@ type AdminPublishSubscriptionTemplateRequest struct {
 	TemplateID uint64 `path:"id"`
 	Changelog  string `json:"changelog"`
 	Operator   string `json:"operator"`
+	Force      bool   `json:"force,optional"`
 }
 
 // AdminPublishSubscriptionTemplateResponse 发布结果。
@@ -642,6 +758,8 @@ type SubscriptionTemplateHistoryEntry struct {
 	PublishedAt int64                       `json:"published_at"`
 	PublishedBy string                      `json:"published_by"`
 	Variables   map[string]TemplateVariable `json:"variables"`
+	Downloads   int64                       `json:"downloads"`
+	LastUsedAt  int64                       `json:"last_used_at"`
 }
 
 // AdminSubscriptionTemplateHistoryRequest 查询历史。
@@ -655,6 +773,83 @@ type AdminSubscriptionTemplateHistoryResponse struct {
 	History    []SubscriptionTemplateHistoryEntry `json:"history"`
 }
 
+// AdminRollbackSubscriptionTemplateRequest 回滚模板到历史版本。
+type AdminRollbackSubscriptionTemplateRequest struct {
+	TemplateID   uint64 `path:"id"`
+	Version      uint32 `json:"version"`
+	Changelog    string `json:"changelog,optional"`
+	Operator     string `json:"operator,optional"`
+	Force        bool   `json:"force,optional"`
+	DiscardDraft bool   `json:"discard_draft,optional"`
+}
+
+// AdminSubscriptionTemplateDiffRequest 比较两个模板版本，版本为 0 表示当前草稿。
+type AdminSubscriptionTemplateDiffRequest struct {
+	TemplateID uint64 `path:"id"`
+	From       uint32 `form:"from,optional"`
+	To         uint32 `form:"to,optional"`
+}
+
+// TemplateDiffLine 逐行差异。
+type TemplateDiffLine struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// AdminSubscriptionTemplateDiffResponse 模板版本差异。
+type AdminSubscriptionTemplateDiffResponse struct {
+	TemplateID uint64             `json:"template_id"`
+	From       uint32             `json:"from"`
+	To         uint32             `json:"to"`
+	FromFormat string             `json:"from_format"`
+	ToFormat   string             `json:"to_format"`
+	Content    []TemplateDiffLine `json:"content"`
+	Variables  []TemplateDiffLine `json:"variables"`
+	Added      int                `json:"added"`
+	Removed    int                `json:"removed"`
+}
+
+// AdminValidateSubscriptionTemplateRequest 校验模板，未提供的字段使用当前草稿。
+type AdminValidateSubscriptionTemplateRequest struct {
+	TemplateID      uint64                      `path:"id"`
+	Format          *string                     `json:"format,optional"`
+	Content         *string                     `json:"content,optional"`
+	Variables       map[string]TemplateVariable `json:"variables,optional"`
+	SubscriptionIDs []uint64                    `json:"subscription_ids,optional"`
+	SampleSize      int                         `json:"sample_size,optional"`
+}
+
+// TemplateValidationIssue 模板校验问题。
+type TemplateValidationIssue struct {
+	Stage          string `json:"stage"`
+	Message        string `json:"message"`
+	Line           int    `json:"line,omitempty"`
+	Column         int    `json:"column,omitempty"`
+	SubscriptionID uint64 `json:"subscription_id,omitempty"`
+}
+
+// TemplateDryRunResult 模板试渲染结果。
+type TemplateDryRunResult struct {
+	Source         string `json:"source"`
+	SubscriptionID uint64 `json:"subscription_id,omitempty"`
+	OK             bool   `json:"ok"`
+	Error          string `json:"error,omitempty"`
+	OutputBytes    int    `json:"output_bytes"`
+	Preview        string `json:"preview,omitempty"`
+	Truncated      bool   `json:"truncated"`
+}
+
+// AdminValidateSubscriptionTemplateResponse 模板校验结果。
+type AdminValidateSubscriptionTemplateResponse struct {
+	TemplateID uint64                    `json:"template_id"`
+	Valid      bool                      `json:"valid"`
+	Errors     []TemplateValidationIssue `json:"errors"`
+	Warnings   []TemplateValidationIssue `json:"warnings"`
+	DryRuns    []TemplateDryRunResult    `json:"dry_runs"`
+}
+
 // UserListSubscriptionsRequest 用户订阅列表查询。
 type UserListSubscriptionsRequest struct {
 	Page      int    `form:"page,optional" json:"page,optional"`
@@ -766,6 +961,8 @@ type AdminCreatePlanRequest struct {
 	TrafficLimitBytes  int64              `json:"traffic_limit_bytes"`
 	TrafficMultipliers map[string]float64 `json:"traffic_multipliers"`
 	DevicesLimit       int                `json:"devices_limit"`
+	UploadRateBytes    int64              `json:"upload_rate_bytes"`
+	DownloadRateBytes  int64              `json:"download_rate_bytes"`
 	SortOrder          int                `json:"sort_order"`
 	Status             int                `json:"status"`
 	Visible            bool               `json:"visible"`
@@ -786,6 +983,8 @@ type AdminUpdatePlanRequest struct {
 	TrafficLimitBytes  *int64             `json:"traffic_limit_bytes,optional"`
 	TrafficMultipliers map[string]float64 `json:"traffic_multipliers,optional"`
 	DevicesLimit       *int               `json:"devices_limit,optional"`
+	UploadRateBytes    *int64             `json:"upload_rate_bytes,optional"`
+	DownloadRateBytes  *int64             `json:"download_rate_bytes,optional"`
 	SortOrder          *int               `json:"sort_order,optional"`
 	Status             *int               `json:"status,optional"`
 	Visible            *bool              `json:"visible,optional"`
@@ -807,6 +1006,8 @@ type PlanSummary struct {
 	TrafficLimitBytes  int64                      `json:"traffic_limit_bytes"`
 	TrafficMultipliers map[string]float64         `json:"traffic_multipliers"`
 	DevicesLimit       int                        `json:"devices_limit"`
+	UploadRateBytes    int64                      `json:"upload_rate_bytes"`
+	DownloadRateBytes  int64                      `json:"download_rate_bytes"`
 	SortOrder          int                        `json:"sort_order"`
 	Status             int                        `json:"status"`
 	Visible            bool                       `json:"visible"`
@@ -919,6 +1120,8 @@ type UserPlanSummary struct {
 	DurationDays      int                        `json:"duration_days"`
 	TrafficLimitBytes int64                      `json:"traffic_limit_bytes"`
 	DevicesLimit      int                        `json:"devices_limit"`
+	UploadRateBytes   int64                      `json:"upload_rate_bytes"`
+	DownloadRateBytes int64                      `json:"download_rate_bytes"`
 	Tags              []string                   `json:"tags"`
 }
 
@@ -7,15 +7,21 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/pkg/metrics"
 )
 
 // ControlClient provides access to kernel control plane APIs.
 type ControlClient struct {
-	baseURL string
-	token   string
-	client  *http.Client
+	baseURL  string
+	token    string
+	client   *http.Client
+	retry    RetryPolicy
+	breakers *CircuitBreakers
 }
 
 // NewControlClient constructs a control client.
@@ -30,42 +36,37 @@ func NewControlClient(opts HTTPOptions) (*ControlClient, error) {
 	}
 
 	return &ControlClient{
-		baseURL: strings.TrimSuffix(strings.TrimSpace(opts.BaseURL), "/"),
-		token:   strings.TrimSpace(opts.Token),
-		client:  &http.Client{Timeout: timeout},
+		baseURL:  normalizeEndpoint(opts.BaseURL),
+		token:    strings.TrimSpace(opts.Token),
+		client:   &http.Client{Timeout: timeout},
+		retry:    opts.Retry,
+		breakers: opts.Breakers,
 	}, nil
 }
 
-// UpsertProtocol pushes protocol configuration to the kernel.
-func (c *ControlClient) UpsertProtocol(ctx context.Context, req ProtocolUpsertRequest) (ProtocolSummary, error) {
-	endpoint := c.buildURL("/protocols")
-
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return ProtocolSummary{}, err
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
-	if err != nil {
-		return ProtocolSummary{}, err
-	}
-	c.applyAuth(httpReq)
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
+// ControlError describes a non-2xx response from the kernel control plane.
+type ControlError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
 
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return ProtocolSummary{}, err
-	}
-	defer closeBody(resp.Body)
+func (e *ControlError) Error() string {
+	return fmt.Sprintf("kernel control: %s: %s", e.Status, e.Body)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return ProtocolSummary{}, fmt.Errorf("kernel control: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+// Unwrap maps well-known status codes to package sentinel errors.
+func (e *ControlError) Unwrap() error {
+	if e.StatusCode == http.StatusNotFound {
+		return ErrNotFound
 	}
+	return nil
+}
 
+// UpsertProtocol pushes protocol configuration to the kernel.
+func (c *ControlClient) UpsertProtocol(ctx context.Context, req ProtocolUpsertRequest) (ProtocolSummary, error) {
 	var summary ProtocolSummary
-	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+	if err := c.doJSON(ctx, http.MethodPost, "/protocols", req, &summary); err != nil {
 		return ProtocolSummary{}, err
 	}
 	return summary, nil
@@ -73,130 +74,324 @@ func (c *ControlClient) UpsertProtocol(ctx context.Context, req ProtocolUpsertRe
 
 // RegisterEvent registers node event callbacks.
 func (c *ControlClient) RegisterEvent(ctx context.Context, req EventRegistrationRequest) (EventRegistrationRecord, error) {
-	endpoint := c.buildURL("/events/registrations")
-
-	payload, err := json.Marshal(req)
-	if err != nil {
+	var record EventRegistrationRecord
+	if err := c.doJSON(ctx, http.MethodPost, "/events/registrations", req, &record); err != nil {
 		return EventRegistrationRecord{}, err
 	}
+	return record, nil
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
-	if err != nil {
-		return EventRegistrationRecord{}, err
+// RegisterServiceEvent registers service event callbacks.
+func (c *ControlClient) RegisterServiceEvent(ctx context.Context, req ServiceEventRegistrationRequest) (EventSubscriptionRecord, error) {
+	var record EventSubscriptionRecord
+	if err := c.doJSON(ctx, http.MethodPost, "/service-events/registrations", req, &record); err != nil {
+		return EventSubscriptionRecord{}, err
 	}
-	c.applyAuth(httpReq)
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
+	return record, nil
+}
 
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return EventRegistrationRecord{}, err
+// ListEventRegistrations lists node event callbacks.
+func (c *ControlClient) ListEventRegistrations(ctx context.Context) ([]EventRegistrationRecord, error) {
+	var records []EventRegistrationRecord
+	if err := c.doJSON(ctx, http.MethodGet, "/events/registrations", nil, &records); err != nil {
+		return nil, err
 	}
-	defer closeBody(resp.Body)
+	return records, nil
+}
+
+// DeleteEventRegistration removes a node event callback.
+func (c *ControlClient) DeleteEventRegistration(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/events/registrations/"+url.PathEscape(id), nil, nil)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return EventRegistrationRecord{}, fmt.Errorf("kernel control: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+// ListServiceEventRegistrations lists service event callbacks.
+func (c *ControlClient) ListServiceEventRegistrations(ctx context.Context) ([]EventSubscriptionRecord, error) {
+	var records []EventSubscriptionRecord
+	if err := c.doJSON(ctx, http.MethodGet, "/service-events/registrations", nil, &records); err != nil {
+		return nil, err
 	}
+	return records, nil
+}
 
-	var record EventRegistrationRecord
-	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
-		return EventRegistrationRecord{}, err
+// DeleteServiceEventRegistration removes a service event callback.
+func (c *ControlClient) DeleteServiceEventRegistration(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/service-events/registrations/"+url.PathEscape(id), nil, nil)
+}
+
+// ListProtocols fetches protocol summaries from kernel control plane.
+func (c *ControlClient) ListProtocols(ctx context.Context) ([]ProtocolSummary, error) {
+	var protocols []ProtocolSummary
+	if err := c.doJSON(ctx, http.MethodGet, "/protocols", nil, &protocols); err != nil {
+		return nil, err
 	}
-	return record, nil
+	return protocols, nil
 }
 
-// RegisterServiceEvent registers service event callbacks.
-func (c *ControlClient) RegisterServiceEvent(ctx context.Context, req ServiceEventRegistrationRequest) (EventSubscriptionRecord, error) {
-	endpoint := c.buildURL("/service-events/registrations")
+// DeleteProtocol removes a protocol from the kernel.
+func (c *ControlClient) DeleteProtocol(ctx context.Context, protocolID string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/protocols/"+url.PathEscape(protocolID), nil, nil)
+}
 
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return EventSubscriptionRecord{}, err
+// ListProtocolUsers lists users attached to a protocol (passwords are never returned).
+func (c *ControlClient) ListProtocolUsers(ctx context.Context, protocolID string) ([]UserView, error) {
+	path := "/protocols/" + url.PathEscape(protocolID) + "/users?redact=false"
+	var users []UserView
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &users); err != nil {
+		return nil, err
 	}
+	return users, nil
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
-	if err != nil {
-		return EventSubscriptionRecord{}, err
+// ListUsers lists all users known to the kernel user registry.
+func (c *ControlClient) ListUsers(ctx context.Context) ([]UserView, error) {
+	var users []UserView
+	if err := c.doJSON(ctx, http.MethodGet, "/users?redact=false", nil, &users); err != nil {
+		return nil, err
 	}
-	c.applyAuth(httpReq)
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
+	return users, nil
+}
 
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return EventSubscriptionRecord{}, err
+// CreateUser creates a single user in the kernel user registry.
+func (c *ControlClient) CreateUser(ctx context.Context, req UserCreateRequest) (UserCreateResponse, error) {
+	var resp UserCreateResponse
+	if err := c.doJSON(ctx, http.MethodPost, "/users", req, &resp); err != nil {
+		return UserCreateResponse{}, err
 	}
-	defer closeBody(resp.Body)
+	return resp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return EventSubscriptionRecord{}, fmt.Errorf("kernel control: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+// ImportUsers loads a user snapshot in one request.
+func (c *ControlClient) ImportUsers(ctx context.Context, users []User) (UsersImportResponse, error) {
+	if users == nil {
+		users = []User{}
 	}
+	var resp UsersImportResponse
+	if err := c.doJSON(ctx, http.MethodPost, "/users/import", users, &resp); err != nil {
+		return UsersImportResponse{}, err
+	}
+	return resp, nil
+}
 
-	var record EventSubscriptionRecord
-	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
-		return EventSubscriptionRecord{}, err
+// PatchUser applies a partial update to a kernel user.
+func (c *ControlClient) PatchUser(ctx context.Context, id string, req UserPatchRequest) (UserPatchResponse, error) {
+	var resp UserPatchResponse
+	if err := c.doJSON(ctx, http.MethodPatch, "/users/"+url.PathEscape(id), req, &resp); err != nil {
+		return UserPatchResponse{}, err
 	}
-	return record, nil
+	return resp, nil
 }
 
-// ListProtocols fetches protocol summaries from kernel control plane.
-func (c *ControlClient) ListProtocols(ctx context.Context) ([]ProtocolSummary, error) {
-	endpoint := c.buildURL("/protocols")
+// DeleteUser removes a user from the kernel user registry.
+func (c *ControlClient) DeleteUser(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/users/"+url.PathEscape(id), nil, nil)
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
+// GetStatus fetches runtime status snapshot (nodes only when supported).
+func (c *ControlClient) GetStatus(ctx context.Context) (StatusResponse, error) {
+	var status StatusResponse
+	if err := c.doJSON(ctx, http.MethodGet, "/status?include=nodes", nil, &status); err != nil {
+		return StatusResponse{}, err
+	}
+	return status, nil
+}
+
+// GetTraffic fetches cumulative traffic counters grouped by protocol node.
+func (c *ControlClient) GetTraffic(ctx context.Context) (TrafficSummaryResponse, error) {
+	var summary TrafficSummaryResponse
+	if err := c.doJSON(ctx, http.MethodGet, "/traffic", nil, &summary); err != nil {
+		return TrafficSummaryResponse{}, err
+	}
+	return summary, nil
+}
+
+// ListTLSNodes lists protocol nodes that terminate or originate TLS.
+func (c *ControlClient) ListTLSNodes(ctx context.Context) ([]TLSNodeSummary, error) {
+	var nodes []TLSNodeSummary
+	if err := c.doJSON(ctx, http.MethodGet, "/tls/nodes", nil, &nodes); err != nil {
 		return nil, err
 	}
-	c.applyAuth(httpReq)
-	httpReq.Header.Set("Accept", "application/json")
+	return nodes, nil
+}
 
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
+// GetTLSNode fetches the TLS profile of a single node.
+func (c *ControlClient) GetTLSNode(ctx context.Context, id string) (TLSNodeDetail, error) {
+	var detail TLSNodeDetail
+	if err := c.doJSON(ctx, http.MethodGet, "/tls/nodes/"+url.PathEscape(id), nil, &detail); err != nil {
+		return TLSNodeDetail{}, err
+	}
+	return detail, nil
+}
+
+// PatchTLSNode updates the TLS or inner protocol settings of a node.
+func (c *ControlClient) PatchTLSNode(ctx context.Context, id string, req TLSNodePatchRequest) (TLSNodeDetail, error) {
+	var detail TLSNodeDetail
+	if err := c.doJSON(ctx, http.MethodPatch, "/tls/nodes/"+url.PathEscape(id), req, &detail); err != nil {
+		return TLSNodeDetail{}, err
+	}
+	return detail, nil
+}
+
+// ListAudit fetches kernel audit records.
+func (c *ControlClient) ListAudit(ctx context.Context, query AuditQuery) ([]AuditRecord, error) {
+	values := url.Values{}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.SinceMS > 0 {
+		values.Set("since_ms", strconv.FormatInt(query.SinceMS, 10))
+	}
+	path := "/audit"
+	if encoded := values.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	var records []AuditRecord
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &records); err != nil {
 		return nil, err
 	}
-	defer closeBody(resp.Body)
+	return records, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, fmt.Errorf("kernel control: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+// GetAuditHealth fetches the kernel audit pipeline health.
+func (c *ControlClient) GetAuditHealth(ctx context.Context) (AuditHealthSnapshot, error) {
+	var health AuditHealthSnapshot
+	if err := c.doJSON(ctx, http.MethodGet, "/audit/health", nil, &health); err != nil {
+		return AuditHealthSnapshot{}, err
 	}
+	return health, nil
+}
 
-	var protocols []ProtocolSummary
-	if err := json.NewDecoder(resp.Body).Decode(&protocols); err != nil {
+// Export asks the kernel to write its running configuration to a file on the kernel host.
+func (c *ControlClient) Export(ctx context.Context, req ExportRequest) (ExportResponse, error) {
+	var resp ExportResponse
+	if err := c.doJSON(ctx, http.MethodPost, "/export", req, &resp); err != nil {
+		return ExportResponse{}, err
+	}
+	return resp, nil
+}
+
+// ListInterfaces lists the network interfaces of the kernel host.
+func (c *ControlClient) ListInterfaces(ctx context.Context) (InterfaceListResponse, error) {
+	var resp InterfaceListResponse
+	if err := c.doJSON(ctx, http.MethodGet, "/interfaces", nil, &resp); err != nil {
+		return InterfaceListResponse{}, err
+	}
+	return resp, nil
+}
+
+// ListRuleProviders lists rule provider states.
+func (c *ControlClient) ListRuleProviders(ctx context.Context) ([]ProviderStatus, error) {
+	var providers []ProviderStatus
+	if err := c.doJSON(ctx, http.MethodGet, "/rules/providers", nil, &providers); err != nil {
 		return nil, err
 	}
-	return protocols, nil
+	return providers, nil
 }
 
-// GetStatus fetches runtime status snapshot (nodes only when supported).
-func (c *ControlClient) GetStatus(ctx context.Context) (StatusResponse, error) {
-	endpoint := c.buildURL("/status?include=nodes")
+// RefreshRuleProvider forces a rule provider to reload.
+func (c *ControlClient) RefreshRuleProvider(ctx context.Context, name string) (ProviderStatus, error) {
+	var provider ProviderStatus
+	path := "/rules/providers/" + url.PathEscape(name) + "/refresh"
+	if err := c.doJSON(ctx, http.MethodPost, path, nil, &provider); err != nil {
+		return ProviderStatus{}, err
+	}
+	return provider, nil
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+// doJSON issues a request with an optional JSON body and decodes the JSON response into out.
+// Only calls that are safe to repeat are retried, see retryableCall.
+func (c *ControlClient) doJSON(ctx context.Context, method, path string, body any, out any) error {
+	return c.do(ctx, method, path, body, out, retryableCall(method, path))
+}
+
+// do runs a control request through the endpoint circuit breaker, retrying
+// failed retryable calls with jittered backoff. The breaker records the final
+// outcome only, so one call counts as one failure however often it retried.
+func (c *ControlClient) do(ctx context.Context, method, path string, body any, out any, retryable bool) error {
+	var payload []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = encoded
+	}
+
+	if c.breakers != nil {
+		if err := c.breakers.allow(c.baseURL); err != nil {
+			return err
+		}
+	}
+
+	attempts := 1
+	if retryable && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+
+	var (
+		raw    []byte
+		status int
+		err    error
+	)
+	for attempt := 1; ; attempt++ {
+		raw, status, err = c.roundTrip(ctx, method, path, payload)
+		if err == nil || attempt >= attempts || !isRetryable(ctx, err) {
+			break
+		}
+		metrics.ObserveKernelRetry(c.baseURL)
+		if sleepContext(ctx, c.retry.backoff(attempt)) != nil {
+			break
+		}
+	}
+	if c.breakers != nil {
+		c.breakers.record(ctx, c.baseURL, err)
+	}
 	if err != nil {
-		return StatusResponse{}, err
+		return err
+	}
+
+	if out == nil || status == http.StatusNoContent {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// roundTrip performs a single HTTP exchange and returns the response body.
+func (c *ControlClient) roundTrip(ctx context.Context, method, path string, payload []byte) ([]byte, int, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.buildURL(path), reader)
+	if err != nil {
+		return nil, 0, err
 	}
 	c.applyAuth(httpReq)
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
 	httpReq.Header.Set("Accept", "application/json")
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
-		return StatusResponse{}, err
+		return nil, 0, err
 	}
 	defer closeBody(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return StatusResponse{}, fmt.Errorf("kernel control: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, resp.StatusCode, &ControlError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(raw)),
+		}
 	}
 
-	var status StatusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-		return StatusResponse{}, err
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
 	}
-	return status, nil
+	return raw, resp.StatusCode, nil
 }
 
 func (c *ControlClient) buildURL(path string) string {
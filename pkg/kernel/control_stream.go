@@ -0,0 +1,217 @@
+package kernel
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrStreamClosed is returned when the kernel ends an SSE stream.
+	ErrStreamClosed = errors.New("kernel: stream closed")
+	// ErrStreamIdle is returned when an SSE stream stays silent past its idle timeout.
+	ErrStreamIdle = errors.New("kernel: stream idle")
+)
+
+// defaultStreamIdleTimeout bounds the silence tolerated on an SSE stream before
+// the connection is considered dead.
+const defaultStreamIdleTimeout = 90 * time.Second
+
+// StreamEvent is a single server-sent event.
+type StreamEvent struct {
+	Event string
+	ID    string
+	Data  []byte
+}
+
+// StatusStreamOptions controls /v1/status/stream.
+type StatusStreamOptions struct {
+	// IntervalSeconds is the push period (1-60); zero uses the kernel default.
+	IntervalSeconds int
+	// Mode is full or diff; empty defaults to full.
+	Mode string
+	// IdleTimeout overrides the silence tolerated before reconnecting.
+	IdleTimeout time.Duration
+}
+
+// StatusStreamEvent carries a status snapshot pushed over the stream.
+type StatusStreamEvent struct {
+	Type     string                `json:"type"`
+	Snapshot RuntimeStatusSnapshot `json:"snapshot"`
+}
+
+// StreamStatus subscribes to /v1/status/stream and invokes handle for every
+// snapshot. It blocks until the context ends, the stream fails or handle
+// returns an error.
+func (c *ControlClient) StreamStatus(ctx context.Context, opts StatusStreamOptions, handle func(StatusStreamEvent) error) error {
+	query := url.Values{}
+	mode := strings.ToLower(strings.TrimSpace(opts.Mode))
+	if mode == "" {
+		mode = "full"
+	}
+	query.Set("mode", mode)
+	if opts.IntervalSeconds > 0 {
+		query.Set("interval", strconv.Itoa(opts.IntervalSeconds))
+	}
+	query.Set("events", "runtime")
+	query.Set("include", "nodes")
+	query.Set("redact_users", "true")
+
+	return c.stream(ctx, "/status/stream?"+query.Encode(), "", opts.IdleTimeout, func(event StreamEvent) error {
+		if len(event.Data) == 0 {
+			return nil
+		}
+		var payload StatusStreamEvent
+		if err := json.Unmarshal(event.Data, &payload); err != nil {
+			return fmt.Errorf("kernel status stream: decode %q: %w", event.Event, err)
+		}
+		if payload.Type == "" {
+			payload.Type = strings.TrimPrefix(event.Event, "status.")
+		}
+		return handle(payload)
+	})
+}
+
+// EventStreamOptions controls /v1/events/stream.
+type EventStreamOptions struct {
+	// Events filters categories (node, service, runtime); empty streams all.
+	Events []string
+	// LastEventID resumes after the given event when the kernel supports it.
+	LastEventID string
+	// IdleTimeout overrides the silence tolerated before reconnecting.
+	IdleTimeout time.Duration
+}
+
+// EventStreamMessage is a kernel event envelope delivered over the stream.
+type EventStreamMessage struct {
+	Event        string          `json:"event"`
+	EventID      string          `json:"event_id"`
+	OccurredAtMS int64           `json:"occurred_at_ms"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// StreamEvents subscribes to /v1/events/stream and invokes handle for every
+// event envelope. It blocks until the context ends, the stream fails or handle
+// returns an error.
+func (c *ControlClient) StreamEvents(ctx context.Context, opts EventStreamOptions, handle func(EventStreamMessage) error) error {
+	path := "/events/stream"
+	if len(opts.Events) > 0 {
+		path += "?" + url.Values{"events": {strings.Join(opts.Events, ",")}}.Encode()
+	}
+	return c.stream(ctx, path, opts.LastEventID, opts.IdleTimeout, func(event StreamEvent) error {
+		if len(event.Data) == 0 {
+			return nil
+		}
+		var message EventStreamMessage
+		if err := json.Unmarshal(event.Data, &message); err != nil {
+			return fmt.Errorf("kernel event stream: decode %q: %w", event.Event, err)
+		}
+		if message.Event == "" {
+			message.Event = event.Event
+		}
+		if message.EventID == "" {
+			message.EventID = event.ID
+		}
+		return handle(message)
+	})
+}
+
+// stream opens an SSE endpoint and dispatches events until it ends.
+func (c *ControlClient) stream(ctx context.Context, path, lastEventID string, idleTimeout time.Duration, handle func(StreamEvent) error) error {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultStreamIdleTimeout
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(streamCtx, http.MethodGet, c.buildURL(path), nil)
+	if err != nil {
+		return err
+	}
+	c.applyAuth(httpReq)
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-cache")
+	if lastEventID != "" {
+		httpReq.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	// The regular client timeout would cut the long-lived body; only the
+	// connect phase is bounded by it.
+	connectTimer := time.AfterFunc(c.client.Timeout, cancel)
+	resp, err := (&http.Client{Transport: c.client.Transport}).Do(httpReq)
+	connectTimer.Stop()
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &ControlError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(raw)),
+		}
+	}
+
+	idle := time.AfterFunc(idleTimeout, cancel)
+	defer idle.Stop()
+
+	reader := bufio.NewReader(resp.Body)
+	var event StreamEvent
+	var data bytes.Buffer
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if streamCtx.Err() != nil {
+				return ErrStreamIdle
+			}
+			if errors.Is(err, io.EOF) {
+				return ErrStreamClosed
+			}
+			return err
+		}
+		idle.Reset(idleTimeout)
+
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			if data.Len() > 0 || event.Event != "" {
+				event.Data = bytes.TrimSuffix(data.Bytes(), []byte("\n"))
+				if err := handle(event); err != nil {
+					return err
+				}
+			}
+			event = StreamEvent{}
+			data.Reset()
+			continue
+		}
+		if line[0] == ':' {
+			// Comment / keep-alive.
+			continue
+		}
+
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			event.Event = string(value)
+		case "id":
+			event.ID = string(value)
+		case "data":
+			data.Write(value)
+			data.WriteByte('\n')
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package kernel
 
+import "encoding/json"
+
 // ProtocolUpsertRequest aligns with core.yaml ProtocolUpsertRequest.
 type ProtocolUpsertRequest struct {
 	Listen  string      `json:"listen,omitempty"`
@@ -22,12 +24,24 @@ type ProtocolSummary struct {
 
 // NodeProfile describes protocol profile payload for kernel.
 type NodeProfile struct {
-	ID          string         `json:"id"`
-	Role        string         `json:"role"`
-	Protocol    string         `json:"protocol"`
-	Tags        []string       `json:"tags,omitempty"`
-	Description string         `json:"description,omitempty"`
-	Profile     map[string]any `json:"profile"`
+	ID          string            `json:"id"`
+	Role        string            `json:"role"`
+	Protocol    string            `json:"protocol"`
+	Tags        []string          `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Profile     map[string]any    `json:"profile"`
+	Upstream    []UpstreamProfile `json:"upstream,omitempty"`
+}
+
+// UpstreamProfile aligns with core.yaml UpstreamProfile: the outbound a node
+// forwards its traffic through.
+type UpstreamProfile struct {
+	ID          string            `json:"id,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Protocol    string            `json:"protocol"`
+	Profile     map[string]any    `json:"profile"`
+	Upstream    []UpstreamProfile `json:"upstream,omitempty"`
 }
 
 // User describes kernel user payload (subset).
@@ -35,10 +49,83 @@ type User struct {
 	ID       string         `json:"id"`
 	Username string         `json:"username"`
 	Password string         `json:"password"`
+	Rate     *UserRate      `json:"rate,omitempty"`
 	Metadata map[string]any `json:"metadata,omitempty"`
 	Tags     []string       `json:"tags,omitempty"`
 }
 
+// UserRate aligns with core.yaml Rate/RatePatch: per-user speed limits in
+// bytes per second, nil meaning unlimited.
+type UserRate struct {
+	Up   *int64 `json:"up"`
+	Down *int64 `json:"down"`
+}
+
+// UserView is the kernel's read-only view of a user (passwords are never returned).
+type UserView struct {
+	ID       string         `json:"id"`
+	Username string         `json:"username"`
+	Tags     []string       `json:"tags,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Traffic  *UserTraffic   `json:"traffic,omitempty"`
+	Rate     *UserRate      `json:"rate,omitempty"`
+}
+
+// UserTraffic carries per-user traffic counters in bytes.
+type UserTraffic struct {
+	Allocated int64 `json:"allocated"`
+	Used      int64 `json:"used"`
+}
+
+// TrafficSummaryResponse aligns with core.yaml TrafficSummaryResponse.
+type TrafficSummaryResponse struct {
+	GeneratedAtMS  int64                 `json:"generated_at_ms"`
+	BytesUp        int64                 `json:"bytes_up"`
+	BytesDown      int64                 `json:"bytes_down"`
+	ByNodeProtocol []NodeProtocolTraffic `json:"by_node_protocol"`
+}
+
+// NodeProtocolTraffic is the cumulative traffic of a single protocol node.
+type NodeProtocolTraffic struct {
+	NodeID    string `json:"node_id"`
+	Protocol  string `json:"protocol"`
+	BytesUp   int64  `json:"bytes_up"`
+	BytesDown int64  `json:"bytes_down"`
+}
+
+// UsersImportResponse aligns with core.yaml UsersImportResponse.
+type UsersImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+// UserCreateRequest aligns with core.yaml UserCreateRequest (subset).
+type UserCreateRequest struct {
+	ID       string         `json:"id"`
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	Rate     *UserRate      `json:"rate,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+}
+
+// UserCreateResponse returns the created user id.
+type UserCreateResponse struct {
+	ID string `json:"id"`
+}
+
+// UserPatchRequest aligns with core.yaml UserPatchRequest (subset); nil fields are left untouched.
+type UserPatchRequest struct {
+	Password *string        `json:"password,omitempty"`
+	Rate     *UserRate      `json:"rate,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Tags     *[]string      `json:"tags,omitempty"`
+}
+
+// UserPatchResponse returns the patched user id.
+type UserPatchResponse struct {
+	ID string `json:"id"`
+}
+
 // StatusResponse carries kernel status snapshot (subset).
 type StatusResponse struct {
 	Snapshot RuntimeStatusSnapshot `json:"snapshot"`
@@ -62,6 +149,33 @@ type NodeHealthState struct {
 	Status string `json:"status"`
 }
 
+// UnmarshalJSON accepts both the plain string form ("Healthy") and the tagged
+// object form ({"Degraded":{"consecutive_failures":3}}) of HealthStatus.
+func (h *NodeHealthState) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Status json.RawMessage `json:"status"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	h.Status = ""
+	if len(raw.Status) == 0 || string(raw.Status) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Status, &h.Status); err == nil {
+		return nil
+	}
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(raw.Status, &tagged); err != nil {
+		return err
+	}
+	for key := range tagged {
+		h.Status = key
+		break
+	}
+	return nil
+}
+
 // EventRegistrationRequest registers a node event callback.
 type EventRegistrationRequest struct {
 	Event    string `json:"event"`
@@ -91,3 +205,158 @@ type EventSubscriptionRecord struct {
 	Callback    string `json:"callback"`
 	CreatedAtMS int64  `json:"created_at_ms"`
 }
+
+// TLS modes aligned with core.yaml TlsMode.
+const (
+	TLSModeDisabled = "disabled"
+	TLSModeServer   = "server"
+	TLSModeClient   = "client"
+	TLSModeDual     = "dual"
+)
+
+// TLSNodeSummary aligns with core.yaml TlsNodeSummary.
+type TLSNodeSummary struct {
+	ID            string   `json:"id"`
+	Role          string   `json:"role,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	InnerProtocol string   `json:"inner_protocol,omitempty"`
+	TLSMode       string   `json:"tls_mode,omitempty"`
+	HasReality    bool     `json:"has_reality"`
+}
+
+// TLSNodeDetail aligns with core.yaml TlsNodeDetail; Profile is the AnyTLS profile.
+type TLSNodeDetail struct {
+	ID          string         `json:"id"`
+	Role        string         `json:"role,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Listen      string         `json:"listen,omitempty"`
+	Connect     string         `json:"connect,omitempty"`
+	Profile     map[string]any `json:"profile,omitempty"`
+}
+
+// TLSNodePatchRequest aligns with core.yaml TlsNodePatchRequest.
+type TLSNodePatchRequest struct {
+	TLS   map[string]any `json:"tls,omitempty"`
+	Inner map[string]any `json:"inner,omitempty"`
+}
+
+// AuditRecord aligns with core.yaml AuditRecord.
+type AuditRecord struct {
+	ID          string            `json:"id"`
+	TimestampMS int64             `json:"timestamp_ms"`
+	Channel     string            `json:"channel,omitempty"`
+	Endpoint    string            `json:"endpoint,omitempty"`
+	Action      string            `json:"action"`
+	Actor       string            `json:"actor,omitempty"`
+	Target      string            `json:"target,omitempty"`
+	Success     bool              `json:"success"`
+	Detail      string            `json:"detail,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// AuditQuery filters GET /v1/audit; zero values are omitted.
+type AuditQuery struct {
+	Limit   int
+	SinceMS int64
+}
+
+// AuditHealthSnapshot aligns with core.yaml AuditHealthSnapshot.
+type AuditHealthSnapshot struct {
+	Queued       int64             `json:"queued"`
+	Dropped      int64             `json:"dropped"`
+	SinkFailures int64             `json:"sink_failures"`
+	LastError    string            `json:"last_error,omitempty"`
+	Sinks        []AuditSinkHealth `json:"sinks,omitempty"`
+}
+
+// AuditSinkHealth aligns with core.yaml AuditSinkHealth.
+type AuditSinkHealth struct {
+	Name     string `json:"name"`
+	Failures int64  `json:"failures"`
+}
+
+// ExportRequest aligns with core.yaml ExportRequest.
+type ExportRequest struct {
+	Path             string   `json:"path"`
+	Format           string   `json:"format,omitempty"`
+	RedactUsers      bool     `json:"redact_users,omitempty"`
+	IncludeUsers     bool     `json:"include_users,omitempty"`
+	IncludeAPI       bool     `json:"include_api,omitempty"`
+	IncludePipeline  bool     `json:"include_pipeline,omitempty"`
+	ResolveProviders bool     `json:"resolve_providers,omitempty"`
+	ProtocolIDs      []string `json:"protocol_ids,omitempty"`
+	AllowOverwrite   bool     `json:"allow_overwrite,omitempty"`
+	VersionLabel     string   `json:"version_label,omitempty"`
+}
+
+// ExportResponse aligns with core.yaml ExportResponse.
+type ExportResponse struct {
+	Path      string              `json:"path"`
+	Protocols int                 `json:"protocols"`
+	Version   string              `json:"version"`
+	Users     *int                `json:"users,omitempty"`
+	API       *bool               `json:"api,omitempty"`
+	Pipeline  *PipelineExportMeta `json:"pipeline,omitempty"`
+	Filters   *ExportFilters      `json:"filters,omitempty"`
+}
+
+// ExportFilters aligns with core.yaml ExportFilters.
+type ExportFilters struct {
+	Protocols []string `json:"protocols,omitempty"`
+}
+
+// PipelineExportMeta aligns with core.yaml PipelineExportMeta.
+type PipelineExportMeta struct {
+	Strategies    int  `json:"strategies"`
+	Rules         int  `json:"rules"`
+	ResolvedRules *int `json:"resolved_rules,omitempty"`
+}
+
+// Interface kinds (core.yaml InterfaceKindSummary).
+const (
+	InterfaceKindPhysical = "physical"
+	InterfaceKindVirtual  = "virtual"
+)
+
+// InterfaceListResponse aligns with core.yaml InterfaceListResponse.
+type InterfaceListResponse struct {
+	Interfaces []InterfaceSummary `json:"interfaces"`
+}
+
+// InterfaceSummary aligns with core.yaml InterfaceSummary.
+type InterfaceSummary struct {
+	Name       string                    `json:"name"`
+	MAC        string                    `json:"mac,omitempty"`
+	MTU        *int                      `json:"mtu,omitempty"`
+	IsUp       bool                      `json:"is_up"`
+	IsLoopback bool                      `json:"is_loopback"`
+	Kind       string                    `json:"kind"`
+	Addresses  []InterfaceAddressSummary `json:"addresses"`
+}
+
+// InterfaceAddressSummary aligns with core.yaml InterfaceAddressSummary.
+type InterfaceAddressSummary struct {
+	Family  string `json:"family"`
+	Address string `json:"address"`
+	Prefix  int    `json:"prefix"`
+}
+
+// Rule provider states (core.yaml ProviderState).
+const (
+	ProviderStatePending = "pending"
+	ProviderStateHealthy = "healthy"
+	ProviderStateError   = "error"
+)
+
+// ProviderStatus aligns with core.yaml ProviderStatus.
+type ProviderStatus struct {
+	Name              string `json:"name"`
+	Checksum          string `json:"checksum,omitempty"`
+	LastSuccessUnixMS *int64 `json:"last_success_unix_ms,omitempty"`
+	LastAttemptUnixMS *int64 `json:"last_attempt_unix_ms,omitempty"`
+	ETag              string `json:"etag,omitempty"`
+	State             string `json:"state"`
+	LastError         string `json:"last_error,omitempty"`
+}
@@ -6,4 +6,5 @@ var (
 	ErrNotFound         = errors.New("kernel: resource not found")
 	ErrProviderNotFound = errors.New("kernel: provider not found")
 	ErrNotImplemented   = errors.New("kernel: operation not implemented")
+	ErrCircuitOpen      = errors.New("kernel: control endpoint circuit open")
 )
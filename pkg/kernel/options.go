@@ -14,6 +14,10 @@ type HTTPOptions struct {
 	BaseURL string
 	Token   string
 	Timeout time.Duration
+	// Retry 控制幂等控制面调用的重试；零值表示不重试。
+	Retry RetryPolicy
+	// Breakers 为共享的按端点熔断器；为 nil 时不熔断。
+	Breakers *CircuitBreakers
 }
 
 // GRPCOptions 是 gRPC Provider 所需配置。
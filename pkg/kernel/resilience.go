@@ -0,0 +1,243 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/pkg/metrics"
+)
+
+// RetryPolicy controls retries of idempotent control calls, including the
+// POST calls that replace protocol or user state. MaxAttempts <= 1 disables
+// retries.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter spreads each delay by up to ±Jitter (0..1) of its value.
+	Jitter float64
+}
+
+// backoff returns the delay before the retry following the given failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if jitter := p.Jitter; jitter > 0 {
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay = time.Duration(float64(delay) * (1 + (rand.Float64()*2-1)*jitter))
+	}
+	return delay
+}
+
+// Circuit breaker states.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// BreakerOptions configures circuit breakers. A breaker opens after
+// FailureThreshold consecutive failures and admits a single trial call once
+// OpenDuration has passed.
+type BreakerOptions struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+	// OnClose, when set, runs in its own goroutine after a breaker that had
+	// opened closes again, with the normalized endpoint.
+	OnClose func(endpoint string)
+}
+
+// CircuitBreakers keeps one breaker per control endpoint. Clients built with the
+// same registry share the state of an endpoint, so a node that keeps failing is
+// skipped by every caller until its trial call succeeds.
+type CircuitBreakers struct {
+	opts     BreakerOptions
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+	now      func() time.Time
+}
+
+type circuitBreaker struct {
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreakers constructs a breaker registry.
+func NewCircuitBreakers(opts BreakerOptions) *CircuitBreakers {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = 30 * time.Second
+	}
+	return &CircuitBreakers{
+		opts:     opts,
+		breakers: make(map[string]*circuitBreaker),
+		now:      time.Now,
+	}
+}
+
+// State reports the breaker state of an endpoint.
+func (b *CircuitBreakers) State(endpoint string) string {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker, ok := b.breakers[normalizeEndpoint(endpoint)]
+	if !ok {
+		return CircuitClosed
+	}
+	if breaker.state == CircuitOpen && b.now().Sub(breaker.openedAt) >= b.opts.OpenDuration {
+		return CircuitHalfOpen
+	}
+	return breaker.state
+}
+
+// allow admits a call, moving an open breaker to half-open once its open
+// duration has passed. Only one trial call runs while half-open.
+func (b *CircuitBreakers) allow(endpoint string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker := b.breaker(endpoint)
+	switch breaker.state {
+	case CircuitOpen:
+		if b.now().Sub(breaker.openedAt) < b.opts.OpenDuration {
+			metrics.ObserveKernelCircuitRejected(endpoint)
+			return ErrCircuitOpen
+		}
+		b.transition(endpoint, breaker, CircuitHalfOpen)
+		breaker.trial = true
+		return nil
+	case CircuitHalfOpen:
+		if breaker.trial {
+			metrics.ObserveKernelCircuitRejected(endpoint)
+			return ErrCircuitOpen
+		}
+		breaker.trial = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record applies the outcome of an admitted call. Calls abandoned by the caller
+// only release the trial slot.
+func (b *CircuitBreakers) record(ctx context.Context, endpoint string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	breaker := b.breaker(endpoint)
+	breaker.trial = false
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return
+	case isBreakerFailure(err):
+		breaker.failures++
+		if breaker.state == CircuitHalfOpen || breaker.failures >= b.opts.FailureThreshold {
+			breaker.openedAt = b.now()
+			b.transition(endpoint, breaker, CircuitOpen)
+		}
+	default:
+		breaker.failures = 0
+		b.transition(endpoint, breaker, CircuitClosed)
+	}
+}
+
+func (b *CircuitBreakers) breaker(endpoint string) *circuitBreaker {
+	key := normalizeEndpoint(endpoint)
+	breaker, ok := b.breakers[key]
+	if !ok {
+		breaker = &circuitBreaker{state: CircuitClosed}
+		b.breakers[key] = breaker
+	}
+	return breaker
+}
+
+func (b *CircuitBreakers) transition(endpoint string, breaker *circuitBreaker, state string) {
+	if breaker.state == state {
+		return
+	}
+	reopened := state == CircuitClosed && breaker.state != CircuitClosed
+	breaker.state = state
+	metrics.SetKernelCircuitState(endpoint, state)
+	if reopened && b.opts.OnClose != nil {
+		go b.opts.OnClose(normalizeEndpoint(endpoint))
+	}
+}
+
+// isBreakerFailure reports whether an error means the node is unavailable.
+// Client errors (4xx other than 429) come from a reachable kernel.
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var controlErr *ControlError
+	if errors.As(err, &controlErr) {
+		return controlErr.StatusCode == http.StatusTooManyRequests || controlErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// isRetryable reports whether a failed call may succeed when repeated.
+func isRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	return isBreakerFailure(err)
+}
+
+func normalizeEndpoint(endpoint string) string {
+	return strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+}
+
+// replacingPosts lists POST paths that replace kernel state wholesale, so
+// repeating them converges on the same result.
+var replacingPosts = map[string]bool{
+	"/protocols":    true,
+	"/users/import": true,
+}
+
+// retryableCall reports whether a control call may be repeated safely: calls
+// with idempotent methods and the full-state POST replacements.
+func retryableCall(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return replacingPosts[path]
+	default:
+		return false
+	}
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestControlClientRetriesIdempotentCalls(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := NewControlClient(HTTPOptions{
+		BaseURL: server.URL,
+		Retry:   RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Jitter: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.ListProtocols(context.Background()); err != nil {
+		t.Fatalf("list protocols: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+
+	// Full-state replacements are retried although they are POSTs.
+	calls.Store(0)
+	if _, err := client.UpsertProtocol(context.Background(), ProtocolUpsertRequest{}); err != nil {
+		t.Fatalf("upsert protocol: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 upsert attempts, got %d", got)
+	}
+	calls.Store(0)
+	if _, err := client.ImportUsers(context.Background(), nil); err != nil {
+		t.Fatalf("import users: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 import attempts, got %d", got)
+	}
+
+	// Non-idempotent calls are attempted once.
+	calls.Store(0)
+	if _, err := client.CreateUser(context.Background(), UserCreateRequest{}); err == nil {
+		t.Fatalf("expected create user to fail")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	closed := make(chan string, 1)
+	breakers := NewCircuitBreakers(BreakerOptions{
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+		OnClose:          func(endpoint string) { closed <- endpoint },
+	})
+	breakers.now = func() time.Time { return now }
+
+	client, err := NewControlClient(HTTPOptions{BaseURL: server.URL + "/", Breakers: breakers})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListProtocols(ctx); err == nil {
+			t.Fatalf("expected failure")
+		}
+	}
+	if state := breakers.State(server.URL); state != CircuitOpen {
+		t.Fatalf("expected open breaker, got %s", state)
+	}
+
+	// Another client for the same endpoint shares the breaker and skips the call.
+	other, _ := NewControlClient(HTTPOptions{BaseURL: server.URL, Breakers: breakers})
+	if _, err := other.GetStatus(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected rejected call to skip the kernel, got %d calls", got)
+	}
+
+	// Client errors come from a reachable kernel and do not count as failures.
+	now = now.Add(time.Minute)
+	healthy.Store(true)
+	if _, err := client.ListProtocols(ctx); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if state := breakers.State(server.URL); state != CircuitClosed {
+		t.Fatalf("expected closed breaker, got %s", state)
+	}
+	select {
+	case endpoint := <-closed:
+		if endpoint != server.URL {
+			t.Fatalf("expected close hook for %s, got %s", server.URL, endpoint)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected close hook to run")
+	}
+	if isBreakerFailure(&ControlError{StatusCode: http.StatusNotFound}) {
+		t.Fatalf("expected 404 not to trip the breaker")
+	}
+	if !isBreakerFailure(&ControlError{StatusCode: http.StatusTooManyRequests}) {
+		t.Fatalf("expected 429 to trip the breaker")
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	now := time.Now()
+	breakers := NewCircuitBreakers(BreakerOptions{FailureThreshold: 1, OpenDuration: time.Second})
+	breakers.now = func() time.Time { return now }
+	ctx := context.Background()
+	const endpoint = "http://kernel.invalid"
+
+	if err := breakers.allow(endpoint); err != nil {
+		t.Fatalf("allow: %v", err)
+	}
+	breakers.record(ctx, endpoint, errors.New("dial failed"))
+	if err := breakers.allow(endpoint); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open breaker, got %v", err)
+	}
+
+	now = now.Add(time.Second)
+	if err := breakers.allow(endpoint); err != nil {
+		t.Fatalf("trial should be admitted: %v", err)
+	}
+	if err := breakers.allow(endpoint); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a single trial call, got %v", err)
+	}
+	breakers.record(ctx, endpoint, errors.New("dial failed"))
+	if state := breakers.State(endpoint); state != CircuitOpen {
+		t.Fatalf("expected breaker to reopen, got %s", state)
+	}
+}
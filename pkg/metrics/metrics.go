@@ -121,6 +121,30 @@ var (
 		Help:      "Distribution of refunded amount per operation (in currency units).",
 		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 50, 100, 200},
 	}, []string{"actor"})
+
+	// KernelRequestRetriesTotal counts retried kernel control calls grouped by endpoint.
+	KernelRequestRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kernel_control",
+		Name:      "retries_total",
+		Help:      "Total number of retried kernel control requests.",
+	}, []string{"endpoint"})
+
+	// KernelCircuitState reports the circuit breaker state per endpoint (0 closed, 1 half-open, 2 open).
+	KernelCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "kernel_control",
+		Name:      "circuit_state",
+		Help:      "Kernel control circuit breaker state (0 closed, 1 half-open, 2 open).",
+	}, []string{"endpoint"})
+
+	// KernelCircuitRejectedTotal counts kernel control calls rejected by an open circuit.
+	KernelCircuitRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kernel_control",
+		Name:      "circuit_rejected_total",
+		Help:      "Total number of kernel control requests rejected by an open circuit breaker.",
+	}, []string{"endpoint"})
 )
 
 // ObserveNodeSync records a node synchronization attempt with duration and outcome labels.
@@ -202,6 +226,36 @@ func ObserveHTTPRequest(path, method, status string, duration time.Duration) {
 	HTTPRequestDurationSeconds.WithLabelValues(sanitizedPath, sanitizedMethod, status).Observe(duration.Seconds())
 }
 
+// ObserveKernelRetry records a retried kernel control request.
+func ObserveKernelRetry(endpoint string) {
+	KernelRequestRetriesTotal.WithLabelValues(normalizeEndpoint(endpoint)).Inc()
+}
+
+// ObserveKernelCircuitRejected records a call skipped because the endpoint circuit is open.
+func ObserveKernelCircuitRejected(endpoint string) {
+	KernelCircuitRejectedTotal.WithLabelValues(normalizeEndpoint(endpoint)).Inc()
+}
+
+// SetKernelCircuitState publishes the circuit breaker state of an endpoint.
+func SetKernelCircuitState(endpoint, state string) {
+	value := 0.0
+	switch strings.ToLower(strings.TrimSpace(state)) {
+	case "half_open":
+		value = 1
+	case "open":
+		value = 2
+	}
+	KernelCircuitState.WithLabelValues(normalizeEndpoint(endpoint)).Set(value)
+}
+
+func normalizeEndpoint(endpoint string) string {
+	normalized := strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+	if normalized == "" {
+		return "unknown"
+	}
+	return normalized
+}
+
 func normalizeResult(result string) string {
 	normalized := strings.ToLower(strings.TrimSpace(result))
 	if normalized == "" {
@@ -75,3 +75,26 @@ func TestObserveOrderRefund(t *testing.T) {
 		t.Fatalf("expected refund amount histogram to collect samples")
 	}
 }
+
+func TestKernelCircuitMetrics(t *testing.T) {
+	const endpoint = "http://unit-kernel:8080"
+
+	before := testutil.ToFloat64(KernelRequestRetriesTotal.WithLabelValues(endpoint))
+	ObserveKernelRetry(endpoint + "/")
+	if diff := testutil.ToFloat64(KernelRequestRetriesTotal.WithLabelValues(endpoint)) - before; diff != 1 {
+		t.Fatalf("expected retry counter increase by 1, got %.0f", diff)
+	}
+
+	SetKernelCircuitState(endpoint, "open")
+	if got := testutil.ToFloat64(KernelCircuitState.WithLabelValues(endpoint)); got != 2 {
+		t.Fatalf("expected open circuit gauge 2, got %.0f", got)
+	}
+	SetKernelCircuitState(endpoint, "half_open")
+	if got := testutil.ToFloat64(KernelCircuitState.WithLabelValues(endpoint)); got != 1 {
+		t.Fatalf("expected half-open circuit gauge 1, got %.0f", got)
+	}
+	SetKernelCircuitState(endpoint, "closed")
+	if got := testutil.ToFloat64(KernelCircuitState.WithLabelValues(endpoint)); got != 0 {
+		t.Fatalf("expected closed circuit gauge 0, got %.0f", got)
+	}
+}
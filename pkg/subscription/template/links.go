@@ -0,0 +1,358 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// FormatURIList 内置格式：忽略模板内容，输出 base64 编码的分享链接列表。
+const FormatURIList = "uri-list"
+
+// linkBuilder 根据节点上下文与用户身份生成单条分享链接。
+type linkBuilder func(node, identity map[string]any) (string, error)
+
+var linkBuilders = map[string]linkBuilder{
+	"vless":       vlessLink,
+	"vmess":       vmessLink,
+	"trojan":      trojanLink,
+	"shadowsocks": shadowsocksLink,
+	"ss":          shadowsocksLink,
+	"hysteria2":   hysteria2Link,
+	"hy2":         hysteria2Link,
+}
+
+// ShareLink 按节点协议生成分享链接；不支持的协议返回空字符串。
+func ShareLink(node, identity map[string]any) (string, error) {
+	builder, ok := linkBuilders[strings.ToLower(stringValue(node, "protocol"))]
+	if !ok {
+		return "", nil
+	}
+	return builder(node, identity)
+}
+
+// templateShareLink 是模板中的 shareLink：无法生成的条目记录日志并返回空字符串，
+// 模板可用 with 跳过，单个配置错误的条目不会使整份订阅渲染失败。
+func templateShareLink(node, identity map[string]any) string {
+	link, err := ShareLink(node, identity)
+	if err != nil {
+		logx.Errorf("subscription share link skipped: %v", err)
+		return ""
+	}
+	return link
+}
+
+// ShareLinks 为全部节点生成分享链接，跳过不支持的协议；
+// 缺少地址或凭据的条目记录日志后跳过，不影响其余条目。
+func ShareLinks(nodes []map[string]any, identity map[string]any) []string {
+	links := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if link := templateShareLink(node, identity); link != "" {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func renderURIList(data map[string]any) (string, error) {
+	nodes, _ := data["nodes"].([]map[string]any)
+	identity, _ := data["user_identity"].(map[string]any)
+	links := ShareLinks(nodes, identity)
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(links, "\n"))), nil
+}
+
+func vlessLink(node, identity map[string]any) (string, error) {
+	server, err := linkServer("vless", node)
+	if err != nil {
+		return "", err
+	}
+	uuid, err := linkCredential("vless", identity, "uuid")
+	if err != nil {
+		return "", err
+	}
+	profile := profileValue(node)
+	query := url.Values{}
+	query.Set("encryption", "none")
+	setQuery(query, "flow", stringValue(profile, "flow"))
+	applySecurityQuery(query, profile, "none")
+	applyTransportQuery(query, profile)
+	return buildLink("vless", url.User(uuid), server, query, linkName(node)), nil
+}
+
+func trojanLink(node, identity map[string]any) (string, error) {
+	server, err := linkServer("trojan", node)
+	if err != nil {
+		return "", err
+	}
+	password, err := linkCredential("trojan", identity, "password")
+	if err != nil {
+		return "", err
+	}
+	profile := profileValue(node)
+	query := url.Values{}
+	applySecurityQuery(query, profile, "tls")
+	applyTransportQuery(query, profile)
+	return buildLink("trojan", url.User(password), server, query, linkName(node)), nil
+}
+
+func hysteria2Link(node, identity map[string]any) (string, error) {
+	server, err := linkServer("hysteria2", node)
+	if err != nil {
+		return "", err
+	}
+	password, err := linkCredential("hysteria2", identity, "password")
+	if err != nil {
+		return "", err
+	}
+	profile := profileValue(node)
+	query := url.Values{}
+	setQuery(query, "sni", stringValue(profile, "sni"))
+	setQuery(query, "alpn", strings.Join(stringsValue(profile, "alpn"), ","))
+	if boolValue(profile, "allow_insecure") {
+		query.Set("insecure", "1")
+	}
+	if obfs := stringValue(profile, "obfs"); obfs != "" {
+		query.Set("obfs", obfs)
+		setQuery(query, "obfs-password", stringValue(profile, "obfs_password"))
+	}
+	return buildLink("hysteria2", url.User(password), server, query, linkName(node)), nil
+}
+
+// shadowsocksLink 采用 SIP002 格式，userinfo 为 base64url 编码的 method:password。
+func shadowsocksLink(node, identity map[string]any) (string, error) {
+	server, err := linkServer("shadowsocks", node)
+	if err != nil {
+		return "", err
+	}
+	password, err := linkCredential("shadowsocks", identity, "password")
+	if err != nil {
+		return "", err
+	}
+	profile := profileValue(node)
+	cipher := stringValue(profile, "cipher")
+	if cipher == "" {
+		cipher = "aes-128-gcm"
+	}
+	userInfo := base64.RawURLEncoding.EncodeToString([]byte(cipher + ":" + password))
+	query := url.Values{}
+	if plugin := stringValue(profile, "plugin"); plugin != "" {
+		if opts := stringValue(profile, "plugin_opts"); opts != "" {
+			plugin += ";" + opts
+		}
+		query.Set("plugin", plugin)
+	}
+	return buildLink("ss", url.User(userInfo), server, query, linkName(node)), nil
+}
+
+// vmessLink 采用 v2rayN 约定：base64 编码的 JSON 对象。
+func vmessLink(node, identity map[string]any) (string, error) {
+	host, port, err := linkEndpoint("vmess", node)
+	if err != nil {
+		return "", err
+	}
+	uuid, err := linkCredential("vmess", identity, "uuid")
+	if err != nil {
+		return "", err
+	}
+	profile := profileValue(node)
+	network := stringValue(profile, "network")
+	if network == "" {
+		network = "tcp"
+	}
+	path := stringValue(profile, "path")
+	if network == "grpc" {
+		path = stringValue(profile, "service_name")
+	}
+	security := ""
+	if strings.EqualFold(stringValue(profile, "security"), "tls") {
+		security = "tls"
+	}
+	cipher := stringValue(profile, "cipher")
+	if cipher == "" {
+		cipher = "auto"
+	}
+	payload := map[string]string{
+		"v":    "2",
+		"ps":   linkName(node),
+		"add":  host,
+		"port": strconv.Itoa(port),
+		"id":   uuid,
+		"aid":  strconv.Itoa(intValue(profile, "alter_id")),
+		"scy":  cipher,
+		"net":  network,
+		"type": "none",
+		"host": stringValue(profile, "host"),
+		"path": path,
+		"tls":  security,
+		"sni":  stringValue(profile, "sni"),
+		"alpn": strings.Join(stringsValue(profile, "alpn"), ","),
+		"fp":   stringValue(profile, "fingerprint"),
+	}
+	buf, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return "vmess://" + base64.StdEncoding.EncodeToString(buf), nil
+}
+
+func applySecurityQuery(query url.Values, profile map[string]any, defaultSecurity string) {
+	security := strings.ToLower(stringValue(profile, "security"))
+	if security == "" {
+		security = defaultSecurity
+	}
+	query.Set("security", security)
+	if security == "none" {
+		return
+	}
+	setQuery(query, "sni", stringValue(profile, "sni"))
+	setQuery(query, "alpn", strings.Join(stringsValue(profile, "alpn"), ","))
+	setQuery(query, "fp", stringValue(profile, "fingerprint"))
+	if boolValue(profile, "allow_insecure") {
+		query.Set("allowInsecure", "1")
+	}
+	if security == "reality" {
+		reality, _ := profile["reality"].(map[string]any)
+		setQuery(query, "pbk", stringValue(reality, "public_key"))
+		setQuery(query, "sid", stringValue(reality, "short_id"))
+		setQuery(query, "spx", stringValue(reality, "spider_x"))
+	}
+}
+
+func applyTransportQuery(query url.Values, profile map[string]any) {
+	network := strings.ToLower(stringValue(profile, "network"))
+	if network == "" {
+		network = "tcp"
+	}
+	query.Set("type", network)
+	setQuery(query, "host", stringValue(profile, "host"))
+	setQuery(query, "path", stringValue(profile, "path"))
+	if network == "grpc" {
+		setQuery(query, "serviceName", stringValue(profile, "service_name"))
+	}
+}
+
+func buildLink(scheme string, user *url.Userinfo, server string, query url.Values, name string) string {
+	link := url.URL{
+		Scheme:   scheme,
+		User:     user,
+		Host:     server,
+		RawQuery: query.Encode(),
+		Fragment: name,
+	}
+	return link.String()
+}
+
+func linkServer(protocol string, node map[string]any) (string, error) {
+	host, port, err := linkEndpoint(protocol, node)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+func linkEndpoint(protocol string, node map[string]any) (string, int, error) {
+	host := stringValue(node, "hostname")
+	port := intValue(node, "port")
+	if host == "" || port <= 0 {
+		return "", 0, fmt.Errorf("%s link: entry %v has no address", protocol, node["entry_id"])
+	}
+	return host, port, nil
+}
+
+func linkCredential(protocol string, identity map[string]any, key string) (string, error) {
+	value := stringValue(identity, key)
+	if value == "" {
+		return "", fmt.Errorf("%s link: user identity has no %s", protocol, key)
+	}
+	return value, nil
+}
+
+func linkName(node map[string]any) string {
+	if name := stringValue(node, "name"); name != "" {
+		return name
+	}
+	return stringValue(node, "node_name")
+}
+
+func profileValue(node map[string]any) map[string]any {
+	profile, _ := node["profile"].(map[string]any)
+	return profile
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func stringValue(values map[string]any, key string) string {
+	switch value := values[key].(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case fmt.Stringer:
+		return strings.TrimSpace(value.String())
+	case nil:
+		return ""
+	default:
+		return strings.TrimSpace(fmt.Sprint(value))
+	}
+}
+
+func intValue(values map[string]any, key string) int {
+	switch value := values[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	case json.Number:
+		parsed, _ := value.Int64()
+		return int(parsed)
+	case string:
+		parsed, _ := strconv.Atoi(strings.TrimSpace(value))
+		return parsed
+	default:
+		return 0
+	}
+}
+
+func boolValue(values map[string]any, key string) bool {
+	switch value := values[key].(type) {
+	case bool:
+		return value
+	case string:
+		parsed, _ := strconv.ParseBool(strings.TrimSpace(value))
+		return parsed
+	default:
+		return false
+	}
+}
+
+func stringsValue(values map[string]any, key string) []string {
+	switch value := values[key].(type) {
+	case []string:
+		return value
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok && strings.TrimSpace(text) != "" {
+				result = append(result, strings.TrimSpace(text))
+			}
+		}
+		return result
+	case string:
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		return strings.Split(value, ",")
+	default:
+		return nil
+	}
+}
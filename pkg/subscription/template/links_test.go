@@ -0,0 +1,141 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+var testIdentity = map[string]any{
+	"uuid":     "0b6f4d2e-3c1a-4e8b-9f00-1a2b3c4d5e6f",
+	"password": "p@ss word/1",
+}
+
+func TestShareLink(t *testing.T) {
+	node := map[string]any{
+		"entry_id": uint64(7),
+		"name":     "HK #1",
+		"protocol": "vless",
+		"hostname": "hk.example.com",
+		"port":     443,
+		"profile": map[string]any{
+			"flow":        "xtls-rprx-vision",
+			"security":    "reality",
+			"network":     "tcp",
+			"sni":         "www.example.com",
+			"fingerprint": "chrome",
+			"reality":     map[string]any{"public_key": "pub+key", "short_id": "ab12"},
+		},
+	}
+	link, err := ShareLink(node, testIdentity)
+	if err != nil {
+		t.Fatalf("vless link: %v", err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse %q: %v", link, err)
+	}
+	if parsed.Scheme != "vless" || parsed.User.Username() != testIdentity["uuid"] || parsed.Host != "hk.example.com:443" {
+		t.Fatalf("unexpected vless link %q", link)
+	}
+	query := parsed.Query()
+	if query.Get("pbk") != "pub+key" || query.Get("security") != "reality" || query.Get("flow") != "xtls-rprx-vision" {
+		t.Fatalf("unexpected vless query %q", parsed.RawQuery)
+	}
+	if parsed.Fragment != "HK #1" {
+		t.Fatalf("expected escaped name, got %q", link)
+	}
+
+	node["protocol"] = "trojan"
+	node["hostname"] = "2001:db8::1"
+	node["profile"] = map[string]any{"sni": "t.example.com"}
+	link, err = ShareLink(node, testIdentity)
+	if err != nil {
+		t.Fatalf("trojan link: %v", err)
+	}
+	parsed, err = url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse %q: %v", link, err)
+	}
+	if parsed.Host != "[2001:db8::1]:443" || parsed.User.Username() != testIdentity["password"] || parsed.Query().Get("security") != "tls" {
+		t.Fatalf("unexpected trojan link %q", link)
+	}
+
+	node["protocol"] = "ss"
+	node["profile"] = map[string]any{"cipher": "aes-256-gcm"}
+	link, err = ShareLink(node, testIdentity)
+	if err != nil {
+		t.Fatalf("ss link: %v", err)
+	}
+	parsed, _ = url.Parse(link)
+	userInfo, err := base64.RawURLEncoding.DecodeString(parsed.User.Username())
+	if err != nil || string(userInfo) != "aes-256-gcm:p@ss word/1" {
+		t.Fatalf("unexpected ss userinfo in %q", link)
+	}
+
+	node["protocol"] = "vmess"
+	node["profile"] = map[string]any{"network": "ws", "path": "/vmess", "security": "tls"}
+	link, err = ShareLink(node, testIdentity)
+	if err != nil {
+		t.Fatalf("vmess link: %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(link, "vmess://"))
+	if err != nil {
+		t.Fatalf("decode vmess link: %v", err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("vmess payload: %v", err)
+	}
+	if payload["id"] != testIdentity["uuid"] || payload["net"] != "ws" || payload["tls"] != "tls" || payload["port"] != "443" {
+		t.Fatalf("unexpected vmess payload %v", payload)
+	}
+
+	node["protocol"] = "tuic"
+	if link, err = ShareLink(node, testIdentity); err != nil || link != "" {
+		t.Fatalf("expected unsupported protocol to be skipped, got %q, %v", link, err)
+	}
+
+	node["protocol"] = "hysteria2"
+	node["hostname"] = ""
+	if _, err = ShareLink(node, testIdentity); err == nil {
+		t.Fatalf("expected error for entry without address")
+	}
+}
+
+func TestRenderURIList(t *testing.T) {
+	data := map[string]any{
+		"nodes": []map[string]any{
+			{"name": "a", "protocol": "hysteria2", "hostname": "a.example.com", "port": 8443, "profile": map[string]any{"obfs": "salamander", "obfs_password": "x"}},
+			{"name": "b", "protocol": "tuic", "hostname": "b.example.com", "port": 8443},
+			{"name": "c", "protocol": "trojan", "hostname": "c.example.com", "port": 443},
+			{"name": "d", "protocol": "vless", "port": 443},
+		},
+		"user_identity": testIdentity,
+	}
+	out, err := Render(FormatURIList, "", data)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(out)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	lines := strings.Split(string(decoded), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "hysteria2://") || !strings.HasPrefix(lines[1], "trojan://") {
+		t.Fatalf("unexpected uri list %q", decoded)
+	}
+	if !strings.Contains(lines[0], "obfs-password=x") {
+		t.Fatalf("expected obfs password in %q", lines[0])
+	}
+
+	out, err = Render("text", `{{ range .nodes }}{{ with shareLink . $.user_identity }}{{ . }};{{ end }}{{ end }}`, data)
+	if err != nil {
+		t.Fatalf("render template: %v", err)
+	}
+	if strings.Count(out, ";") != 2 {
+		t.Fatalf("unexpected template output %q", out)
+	}
+}
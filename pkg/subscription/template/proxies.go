@@ -0,0 +1,346 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// field 为有序对象的一个键值。
+type field struct {
+	key   string
+	value any
+}
+
+// object 是保持键顺序的对象，序列化为 yaml 映射节点。
+type object struct {
+	fields []field
+}
+
+// add 追加键值，忽略空字符串、nil、空列表与空对象。
+func (o *object) add(key string, value any) *object {
+	switch v := value.(type) {
+	case nil:
+		return o
+	case string:
+		if v == "" {
+			return o
+		}
+	case []string:
+		if len(v) == 0 {
+			return o
+		}
+	case *object:
+		if v == nil || len(v.fields) == 0 {
+			return o
+		}
+	}
+	o.fields = append(o.fields, field{key: key, value: value})
+	return o
+}
+
+func (o *object) get(key string) string {
+	for _, f := range o.fields {
+		if f.key == key {
+			if value, ok := f.value.(string); ok {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+func (o *object) put(key, value string) {
+	for i := range o.fields {
+		if o.fields[i].key == key {
+			o.fields[i].value = value
+			return
+		}
+	}
+	o.fields = append(o.fields, field{key: key, value: value})
+}
+
+func (o *object) node() *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, f := range o.fields {
+		node.Content = append(node.Content, scalarNode(f.key), valueNode(f.value))
+	}
+	return node
+}
+
+func valueNode(value any) *yaml.Node {
+	switch v := value.(type) {
+	case *object:
+		return v.node()
+	case string:
+		return scalarNode(v)
+	case []string:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, scalarNode(item))
+		}
+		return node
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	case int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(v)}
+	default:
+		return scalarNode(fmt.Sprint(v))
+	}
+}
+
+// entryInfo 汇总生成代理对象所需的条目字段。
+type entryInfo struct {
+	protocol string
+	name     string
+	host     string
+	port     int
+	profile  map[string]any
+	uuid     string
+	password string
+}
+
+func collectEntries(nodes []map[string]any, identity map[string]any) []entryInfo {
+	entries := make([]entryInfo, 0, len(nodes))
+	for _, node := range nodes {
+		protocol := strings.ToLower(stringValue(node, "protocol"))
+		switch protocol {
+		case "ss":
+			protocol = "shadowsocks"
+		case "hy2":
+			protocol = "hysteria2"
+		}
+		host := stringValue(node, "hostname")
+		port := intValue(node, "port")
+		if host == "" || port <= 0 {
+			continue
+		}
+		profile := profileValue(node)
+		if profile == nil {
+			profile = map[string]any{}
+		}
+		entries = append(entries, entryInfo{
+			protocol: protocol,
+			name:     linkName(node),
+			host:     host,
+			port:     port,
+			profile:  profile,
+			uuid:     stringValue(identity, "uuid"),
+			password: stringValue(identity, "password"),
+		})
+	}
+	return entries
+}
+
+// clashProxies 生成 Clash（mihomo）proxies 条目，不支持的协议跳过。
+func clashProxies(nodes []map[string]any, identity map[string]any) []object {
+	var proxies []object
+	for _, entry := range collectEntries(nodes, identity) {
+		profile := entry.profile
+		proxy := &object{}
+		proxy.add("name", entry.name)
+		switch entry.protocol {
+		case "vless", "vmess", "trojan":
+			proxy.add("type", entry.protocol).add("server", entry.host).add("port", entry.port)
+			security := strings.ToLower(stringValue(profile, "security"))
+			switch entry.protocol {
+			case "vless":
+				proxy.add("uuid", entry.uuid).add("flow", stringValue(profile, "flow"))
+			case "vmess":
+				cipher := stringValue(profile, "cipher")
+				if cipher == "" {
+					cipher = "auto"
+				}
+				proxy.add("uuid", entry.uuid).add("alterId", intValue(profile, "alter_id")).add("cipher", cipher)
+			case "trojan":
+				proxy.add("password", entry.password)
+				if security == "" {
+					security = "tls"
+				}
+			}
+			proxy.add("udp", true)
+			if security == "tls" || security == "reality" {
+				if entry.protocol != "trojan" {
+					proxy.add("tls", true)
+				}
+				if entry.protocol == "trojan" {
+					proxy.add("sni", stringValue(profile, "sni"))
+				} else {
+					proxy.add("servername", stringValue(profile, "sni"))
+				}
+				proxy.add("alpn", stringsValue(profile, "alpn"))
+				proxy.add("client-fingerprint", stringValue(profile, "fingerprint"))
+				if boolValue(profile, "allow_insecure") {
+					proxy.add("skip-cert-verify", true)
+				}
+			}
+			if security == "reality" {
+				reality, _ := profile["reality"].(map[string]any)
+				proxy.add("reality-opts", (&object{}).
+					add("public-key", stringValue(reality, "public_key")).
+					add("short-id", stringValue(reality, "short_id")))
+			}
+			network := strings.ToLower(stringValue(profile, "network"))
+			switch network {
+			case "ws", "httpupgrade":
+				opts := (&object{}).add("path", stringValue(profile, "path"))
+				if host := stringValue(profile, "host"); host != "" {
+					opts.add("headers", (&object{}).add("Host", host))
+				}
+				if network == "httpupgrade" {
+					opts.add("v2ray-http-upgrade", true)
+				}
+				proxy.add("network", "ws").add("ws-opts", opts)
+			case "grpc":
+				proxy.add("network", "grpc").add("grpc-opts", (&object{}).add("grpc-service-name", stringValue(profile, "service_name")))
+			case "h2":
+				opts := (&object{}).add("path", stringValue(profile, "path"))
+				if host := stringValue(profile, "host"); host != "" {
+					opts.add("host", []string{host})
+				}
+				proxy.add("network", "h2").add("h2-opts", opts)
+			}
+		case "shadowsocks":
+			cipher := stringValue(profile, "cipher")
+			if cipher == "" {
+				cipher = "aes-128-gcm"
+			}
+			proxy.add("type", "ss").add("server", entry.host).add("port", entry.port).
+				add("cipher", cipher).add("password", entry.password).
+				add("udp", profile["udp"] == nil || boolValue(profile, "udp"))
+		case "hysteria2":
+			proxy.add("type", "hysteria2").add("server", entry.host).add("port", entry.port).
+				add("password", entry.password).
+				add("sni", stringValue(profile, "sni")).
+				add("alpn", stringsValue(profile, "alpn"))
+			if boolValue(profile, "allow_insecure") {
+				proxy.add("skip-cert-verify", true)
+			}
+			if obfs := stringValue(profile, "obfs"); obfs != "" {
+				proxy.add("obfs", obfs).add("obfs-password", stringValue(profile, "obfs_password"))
+			}
+			if up := intValue(profile, "up_mbps"); up > 0 {
+				proxy.add("up", fmt.Sprintf("%d Mbps", up))
+			}
+			if down := intValue(profile, "down_mbps"); down > 0 {
+				proxy.add("down", fmt.Sprintf("%d Mbps", down))
+			}
+		case "tuic":
+			proxy.add("type", "tuic").add("server", entry.host).add("port", entry.port).
+				add("uuid", entry.uuid).add("password", entry.password).
+				add("congestion-controller", stringValue(profile, "congestion_control")).
+				add("udp-relay-mode", stringValue(profile, "udp_relay_mode")).
+				add("sni", stringValue(profile, "sni")).
+				add("alpn", stringsValue(profile, "alpn"))
+			if boolValue(profile, "zero_rtt_handshake") {
+				proxy.add("reduce-rtt", true)
+			}
+			if boolValue(profile, "allow_insecure") {
+				proxy.add("skip-cert-verify", true)
+			}
+		default:
+			continue
+		}
+		proxies = append(proxies, *proxy)
+	}
+	return proxies
+}
+
+// singBoxOutbounds 生成 sing-box outbounds 条目，不支持的协议跳过。
+func singBoxOutbounds(nodes []map[string]any, identity map[string]any) []object {
+	var outbounds []object
+	for _, entry := range collectEntries(nodes, identity) {
+		profile := entry.profile
+		outbound := &object{}
+		outbound.add("type", entry.protocol).add("tag", entry.name).
+			add("server", entry.host).add("server_port", entry.port)
+		security := strings.ToLower(stringValue(profile, "security"))
+		switch entry.protocol {
+		case "vless":
+			outbound.add("uuid", entry.uuid).add("flow", stringValue(profile, "flow"))
+		case "vmess":
+			cipher := stringValue(profile, "cipher")
+			if cipher == "" {
+				cipher = "auto"
+			}
+			outbound.add("uuid", entry.uuid).add("security", cipher).add("alter_id", intValue(profile, "alter_id"))
+		case "trojan":
+			outbound.add("password", entry.password)
+			if security == "" {
+				security = "tls"
+			}
+		case "shadowsocks":
+			cipher := stringValue(profile, "cipher")
+			if cipher == "" {
+				cipher = "aes-128-gcm"
+			}
+			outbound.add("method", cipher).add("password", entry.password).
+				add("plugin", stringValue(profile, "plugin")).
+				add("plugin_opts", stringValue(profile, "plugin_opts"))
+		case "hysteria2":
+			outbound.add("password", entry.password)
+			if up := intValue(profile, "up_mbps"); up > 0 {
+				outbound.add("up_mbps", up)
+			}
+			if down := intValue(profile, "down_mbps"); down > 0 {
+				outbound.add("down_mbps", down)
+			}
+			if obfs := stringValue(profile, "obfs"); obfs != "" {
+				outbound.add("obfs", (&object{}).add("type", obfs).add("password", stringValue(profile, "obfs_password")))
+			}
+			security = "tls"
+		case "tuic":
+			outbound.add("uuid", entry.uuid).add("password", entry.password).
+				add("congestion_control", stringValue(profile, "congestion_control")).
+				add("udp_relay_mode", stringValue(profile, "udp_relay_mode"))
+			if boolValue(profile, "zero_rtt_handshake") {
+				outbound.add("zero_rtt_handshake", true)
+			}
+			security = "tls"
+		default:
+			continue
+		}
+
+		if security == "tls" || security == "reality" {
+			tls := (&object{}).add("enabled", true).
+				add("server_name", stringValue(profile, "sni")).
+				add("alpn", stringsValue(profile, "alpn"))
+			if boolValue(profile, "allow_insecure") {
+				tls.add("insecure", true)
+			}
+			if fingerprint := stringValue(profile, "fingerprint"); fingerprint != "" {
+				tls.add("utls", (&object{}).add("enabled", true).add("fingerprint", fingerprint))
+			}
+			if security == "reality" {
+				reality, _ := profile["reality"].(map[string]any)
+				tls.add("reality", (&object{}).add("enabled", true).
+					add("public_key", stringValue(reality, "public_key")).
+					add("short_id", stringValue(reality, "short_id")))
+			}
+			outbound.add("tls", tls)
+		}
+		switch network := strings.ToLower(stringValue(profile, "network")); network {
+		case "ws", "httpupgrade":
+			transport := (&object{}).add("type", network).add("path", stringValue(profile, "path"))
+			if host := stringValue(profile, "host"); host != "" {
+				if network == "ws" {
+					transport.add("headers", (&object{}).add("Host", host))
+				} else {
+					transport.add("host", host)
+				}
+			}
+			outbound.add("transport", transport)
+		case "grpc":
+			outbound.add("transport", (&object{}).add("type", "grpc").add("service_name", stringValue(profile, "service_name")))
+		case "h2":
+			transport := (&object{}).add("type", "http").add("path", stringValue(profile, "path"))
+			if host := stringValue(profile, "host"); host != "" {
+				transport.add("host", []string{host})
+			}
+			outbound.add("transport", transport)
+		}
+		outbounds = append(outbounds, *outbound)
+	}
+	return outbounds
+}
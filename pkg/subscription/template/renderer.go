@@ -61,13 +61,35 @@ var (
 		"urlquery": func(v string) string {
 			return url.QueryEscape(v)
 		},
+		"shareLink":     templateShareLink,
+		"shareLinks":    ShareLinks,
+		"vlessLink":     vlessLink,
+		"vmessLink":     vmessLink,
+		"trojanLink":    trojanLink,
+		"ssLink":        shadowsocksLink,
+		"hysteria2Link": hysteria2Link,
 	}
 )
 
-// Render 根据模板格式渲染订阅内容。
+// Render 根据模板格式渲染订阅内容；uri-list 格式不使用模板内容，
+// yaml/json 格式渲染后校验文档并按注入指令合并代理对象。
 func Render(format, content string, data map[string]any) (string, error) {
-	_ = format
-	return renderGoTemplate(content, data)
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == FormatURIList {
+		return renderURIList(data)
+	}
+	rendered, err := renderGoTemplate(content, data)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case "json":
+		return renderStructured("json", rendered, data)
+	case "yaml", "yml":
+		return renderStructured("yaml", rendered, data)
+	default:
+		return rendered, nil
+	}
 }
 
 func renderGoTemplate(content string, data map[string]any) (string, error) {
@@ -0,0 +1,393 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirectiveKey 为基础配置中的注入指令键，渲染后会被移除。
+const DirectiveKey = "x-znp"
+
+// 注入风格：决定生成的对象结构以及注入位置。
+const (
+	StyleClash   = "clash"
+	StyleSingBox = "sing-box"
+)
+
+// directive 描述基础配置中的注入指令。
+type directive struct {
+	Style  string
+	Groups []string
+}
+
+// renderStructured 校验 yaml/json 渲染结果；若包含注入指令，则将协议发布生成的
+// 代理对象结构化地合并到基础配置中后重新序列化，保证输出为合法文档。
+func renderStructured(format, rendered string, data map[string]any) (string, error) {
+	if !strings.Contains(rendered, DirectiveKey) {
+		return rendered, validateDocument(format, rendered)
+	}
+	doc, err := parseDocument(format, rendered)
+	if err != nil {
+		return "", err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return rendered, nil
+	}
+	root := doc.Content[0]
+	spec, ok, err := takeDirective(root, format)
+	if err != nil {
+		return "", &OutputError{Format: format, Err: err}
+	}
+	if !ok {
+		return rendered, nil
+	}
+
+	nodes, _ := data["nodes"].([]map[string]any)
+	identity, _ := data["user_identity"].(map[string]any)
+	switch spec.Style {
+	case StyleClash:
+		err = injectProxies(root, "proxies", "name", "proxy-groups", "proxies", spec.Groups, clashProxies(nodes, identity))
+	case StyleSingBox:
+		err = injectProxies(root, "outbounds", "tag", "outbounds", "outbounds", spec.Groups, singBoxOutbounds(nodes, identity))
+	default:
+		err = fmt.Errorf("%s.style %q is not supported", DirectiveKey, spec.Style)
+	}
+	if err != nil {
+		return "", &OutputError{Format: format, Err: err}
+	}
+
+	if format == "json" {
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, root, ""); err != nil {
+			return "", err
+		}
+		buf.WriteByte('\n')
+		return buf.String(), nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// takeDirective 读取并移除注入指令；未指定 style 时 yaml 默认 clash，json 默认 sing-box。
+func takeDirective(root *yaml.Node, format string) (directive, bool, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != DirectiveKey {
+			continue
+		}
+		key, value := root.Content[i], root.Content[i+1]
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		// 指令键上方的注释（如文件头注释）转移到下一个键，避免丢失。
+		if key.HeadComment != "" && i < len(root.Content) {
+			next := root.Content[i]
+			next.HeadComment = strings.TrimSpace(key.HeadComment + "\n" + next.HeadComment)
+		}
+
+		spec := directive{Style: StyleClash}
+		if format == "json" {
+			spec.Style = StyleSingBox
+		}
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			return spec, true, nil
+		}
+		var raw struct {
+			Style  string   `yaml:"style"`
+			Groups []string `yaml:"groups"`
+		}
+		if err := value.Decode(&raw); err != nil {
+			return directive{}, false, fmt.Errorf("%s is invalid: %w", DirectiveKey, err)
+		}
+		if style := strings.ToLower(strings.TrimSpace(raw.Style)); style != "" {
+			spec.Style = style
+		}
+		spec.Groups = raw.Groups
+		return spec, true, nil
+	}
+	return directive{}, false, nil
+}
+
+// injectProxies 将生成的对象追加到 listKey 列表，并把其名称追加到 groupsKey 中
+// 名称位于 groups 的分组的 memberKey 列表；名称与已有对象重复时追加序号。
+func injectProxies(root *yaml.Node, listKey, nameKey, groupsKey, memberKey string, groups []string, proxies []object) error {
+	list, err := sequenceValue(root, listKey)
+	if err != nil {
+		return err
+	}
+	used := make(map[string]struct{})
+	for _, item := range list.Content {
+		if name := mappingValue(item, nameKey); name != nil {
+			used[name.Value] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		name := uniqueName(proxy.get(nameKey), used)
+		proxy.put(nameKey, name)
+		list.Content = append(list.Content, proxy.node())
+		names = append(names, name)
+	}
+
+	for _, group := range groups {
+		target := findNamed(root, groupsKey, nameKey, group)
+		if target == nil {
+			return fmt.Errorf("%s: group %q not found in %s", DirectiveKey, group, groupsKey)
+		}
+		members, err := sequenceValue(target, memberKey)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			members.Content = append(members.Content, scalarNode(name))
+		}
+	}
+	return nil
+}
+
+func findNamed(root *yaml.Node, listKey, nameKey, name string) *yaml.Node {
+	list := mappingValue(root, listKey)
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, item := range list.Content {
+		if value := mappingValue(item, nameKey); value != nil && value.Value == name {
+			return item
+		}
+	}
+	return nil
+}
+
+func uniqueName(name string, used map[string]struct{}) string {
+	if name == "" {
+		name = "proxy"
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := used[candidate]; !ok {
+			used[candidate] = struct{}{}
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s %d", name, i)
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceValue 返回映射中 key 对应的列表，不存在或为 null 时创建。
+func sequenceValue(node *yaml.Node, key string) (*yaml.Node, error) {
+	value := mappingValue(node, key)
+	if value == nil {
+		value = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		node.Content = append(node.Content, scalarNode(key), value)
+		return value, nil
+	}
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+		*value = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	if value.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s: %q must be a list", DirectiveKey, key)
+	}
+	// 流式列表（如 JSON 的 []）改为块式，保证追加后缩进正确。
+	value.Style = 0
+	return value, nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// writeJSON 按节点顺序输出 JSON，保留基础配置的键顺序。
+func writeJSON(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, node.Content[0], indent)
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias, indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		inner := indent + "  "
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.WriteString(inner)
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := writeJSON(buf, node.Content[i+1], inner); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+		return nil
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		inner := indent + "  "
+		buf.WriteString("[\n")
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(inner)
+			if err := writeJSON(buf, item, inner); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+		return nil
+	case yaml.ScalarNode:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		return nil
+	default:
+		return fmt.Errorf("unsupported yaml node kind %d", node.Kind)
+	}
+}
+
+func validateDocument(format, content string) error {
+	if format == "json" {
+		var value any
+		if err := json.Unmarshal([]byte(content), &value); err != nil {
+			return jsonOutputError(content, err)
+		}
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return &OutputError{Format: format, Line: yamlErrorLine(err), Err: err}
+	}
+	return nil
+}
+
+// parseDocument 解析渲染结果；JSON 按 token 转换为 yaml 节点以保留键顺序。
+func parseDocument(format, content string) (*yaml.Node, error) {
+	if format != "json" {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			return nil, &OutputError{Format: format, Line: yamlErrorLine(err), Err: err}
+		}
+		return &doc, nil
+	}
+	if err := validateDocument(format, content); err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	root, err := decodeJSONNode(decoder)
+	if err == nil {
+		if _, extra := decoder.Token(); extra != io.EOF {
+			err = fmt.Errorf("unexpected data after top-level value")
+		}
+	}
+	if err != nil {
+		return nil, &OutputError{Format: format, Err: err}
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, nil
+}
+
+func jsonOutputError(content string, err error) error {
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		return &OutputError{Format: "json", Line: jsonErrorLine(content, syntax.Offset), Err: err}
+	}
+	return &OutputError{Format: "json", Err: err}
+}
+
+func decodeJSONNode(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				name, ok := key.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid object key %v", key)
+				}
+				child, err := decodeJSONNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, scalarNode(name), child)
+			}
+			_, err := decoder.Token()
+			return node, err
+		case '[':
+			node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for decoder.More() {
+				child, err := decodeJSONNode(decoder)
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, child)
+			}
+			_, err := decoder.Token()
+			return node, err
+		default:
+			return nil, fmt.Errorf("unexpected delimiter %v", value)
+		}
+	case string:
+		return scalarNode(value), nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(value)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %v", token)
+	}
+}
@@ -0,0 +1,122 @@
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func structuredData() map[string]any {
+	return map[string]any{
+		"nodes": []map[string]any{
+			{"name": "HK", "protocol": "vless", "hostname": "hk.example.com", "port": 443, "profile": map[string]any{
+				"security": "reality", "flow": "xtls-rprx-vision", "sni": "www.example.com",
+				"reality": map[string]any{"public_key": "pk", "short_id": "01"},
+			}},
+			{"name": "HK", "protocol": "hysteria2", "hostname": "hk.example.com", "port": 8443, "profile": map[string]any{"obfs": "salamander", "obfs_password": "o"}},
+			{"name": "skip", "protocol": "mixed", "hostname": "x.example.com", "port": 1080},
+		},
+		"user_identity": testIdentity,
+	}
+}
+
+func TestRenderClashBaseConfig(t *testing.T) {
+	base := `# base
+mixed-port: 7890
+x-znp:
+  groups: [Proxy]
+proxies: []
+proxy-groups:
+  - name: Proxy
+    type: select
+    proxies: [DIRECT]
+rules:
+  - MATCH,Proxy
+`
+	out, err := Render("yaml", base, structuredData())
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var config struct {
+		Proxies []map[string]any `yaml:"proxies"`
+		Groups  []struct {
+			Name    string   `yaml:"name"`
+			Proxies []string `yaml:"proxies"`
+		} `yaml:"proxy-groups"`
+		Directive any `yaml:"x-znp"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &config); err != nil {
+		t.Fatalf("output is not yaml: %v\n%s", err, out)
+	}
+	if config.Directive != nil || !strings.HasPrefix(out, "# base") {
+		t.Fatalf("directive should be removed and comments kept:\n%s", out)
+	}
+	if len(config.Proxies) != 2 || config.Proxies[0]["type"] != "vless" || config.Proxies[1]["name"] != "HK 2" {
+		t.Fatalf("unexpected proxies %v", config.Proxies)
+	}
+	reality, _ := config.Proxies[0]["reality-opts"].(map[string]any)
+	if reality["public-key"] != "pk" || config.Proxies[0]["uuid"] != testIdentity["uuid"] {
+		t.Fatalf("unexpected vless proxy %v", config.Proxies[0])
+	}
+	if got := strings.Join(config.Groups[0].Proxies, ","); got != "DIRECT,HK,HK 2" {
+		t.Fatalf("unexpected group members %q", got)
+	}
+}
+
+func TestRenderSingBoxBaseConfig(t *testing.T) {
+	base := `{
+  "log": {"level": "warn"},
+  "x-znp": {"groups": ["select"]},
+  "outbounds": [
+    {"type": "selector", "tag": "select", "outbounds": []},
+    {"type": "direct", "tag": "direct"}
+  ],
+  "route": {"final": "select"}
+}`
+	out, err := Render("json", base, structuredData())
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var config struct {
+		Outbounds []map[string]any `json:"outbounds"`
+	}
+	if err := json.Unmarshal([]byte(out), &config); err != nil {
+		t.Fatalf("output is not json: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "x-znp") || strings.Index(out, `"log"`) > strings.Index(out, `"outbounds"`) {
+		t.Fatalf("directive should be removed and key order kept:\n%s", out)
+	}
+	if len(config.Outbounds) != 4 {
+		t.Fatalf("unexpected outbounds %v", config.Outbounds)
+	}
+	members, _ := config.Outbounds[0]["outbounds"].([]any)
+	if len(members) != 2 || members[0] != "HK" || members[1] != "HK 2" {
+		t.Fatalf("unexpected selector members %v", members)
+	}
+	hy2 := config.Outbounds[3]
+	if hy2["type"] != "hysteria2" || hy2["server_port"] != float64(8443) {
+		t.Fatalf("unexpected hysteria2 outbound %v", hy2)
+	}
+	tls, _ := hy2["tls"].(map[string]any)
+	if tls["enabled"] != true {
+		t.Fatalf("hysteria2 outbound needs tls: %v", hy2)
+	}
+}
+
+func TestRenderStructuredRejectsInvalidOutput(t *testing.T) {
+	if _, err := Render("json", `{"a": 1,}`, structuredData()); err == nil {
+		t.Fatalf("expected invalid json to fail")
+	}
+	if _, err := Render("yaml", "a: [1", structuredData()); err == nil {
+		t.Fatalf("expected invalid yaml to fail")
+	}
+	if _, err := Render("yaml", "x-znp:\n  groups: [Missing]\nproxy-groups: []\n", structuredData()); err == nil {
+		t.Fatalf("expected unknown group to fail")
+	}
+	out, err := Render("text", `{"a": 1,}`, structuredData())
+	if err != nil || out != `{"a": 1,}` {
+		t.Fatalf("text templates are not validated: %q, %v", out, err)
+	}
+}
@@ -0,0 +1,166 @@
+package template
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// OutputError 表示渲染结果不是合法的 yaml/json 文档，Line 为输出中的行号（未知时为 0）。
+type OutputError struct {
+	Format string
+	Line   int
+	Err    error
+}
+
+func (e *OutputError) Error() string {
+	if e.Line > 0 {
+		return "rendered " + e.Format + " is invalid: line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+	}
+	return "rendered " + e.Format + " is invalid: " + e.Err.Error()
+}
+
+func (e *OutputError) Unwrap() error { return e.Err }
+
+// Reference 为模板中以根上下文为起点的字段引用，如 `.variables.region`。
+type Reference struct {
+	Path   []string
+	Line   int
+	Column int
+}
+
+var (
+	templateLocation = regexp.MustCompile(`template: subscription:(\d+)(?::(\d+))?`)
+	yamlLine         = regexp.MustCompile(`line (\d+)`)
+)
+
+// Parse 解析模板内容，不执行渲染。
+func Parse(content string) error {
+	_, err := template.New("subscription").Funcs(funcMap).Parse(content)
+	return err
+}
+
+// ErrorLocation 返回模板解析、执行或输出校验错误对应的行号与列号（未知时为 0）。
+func ErrorLocation(err error) (int, int) {
+	if err == nil {
+		return 0, 0
+	}
+	var output *OutputError
+	if errors.As(err, &output) {
+		return output.Line, 0
+	}
+	match := templateLocation.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	return line, column
+}
+
+// References 列出模板在根上下文上引用的字段路径：包括 range/with 之外的 `.a.b`
+// 以及任意位置的 `$.a.b`；range/with 内部的点号引用随上下文变化，不在此列。
+func References(content string) ([]Reference, error) {
+	tmpl, err := template.New("subscription").Funcs(funcMap).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Reference
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		walkReferences(t.Tree, t.Tree.Root, t.Name() == "subscription", &refs)
+	}
+	return refs, nil
+}
+
+func walkReferences(tree *parse.Tree, node parse.Node, rootDot bool, refs *[]Reference) {
+	add := func(n parse.Node, path []string) {
+		if len(path) == 0 {
+			return
+		}
+		line, column := nodeLocation(tree, n)
+		*refs = append(*refs, Reference{Path: append([]string(nil), path...), Line: line, Column: column})
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkReferences(tree, child, rootDot, refs)
+		}
+	case *parse.ActionNode:
+		walkReferences(tree, n.Pipe, rootDot, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkReferences(tree, cmd, rootDot, refs)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkReferences(tree, arg, rootDot, refs)
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			add(n, n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			add(n, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		walkReferences(tree, n.Node, rootDot, refs)
+	case *parse.IfNode:
+		walkBranch(tree, &n.BranchNode, rootDot, rootDot, refs)
+	case *parse.RangeNode:
+		walkBranch(tree, &n.BranchNode, rootDot, false, refs)
+	case *parse.WithNode:
+		walkBranch(tree, &n.BranchNode, rootDot, false, refs)
+	case *parse.TemplateNode:
+		walkReferences(tree, n.Pipe, rootDot, refs)
+	}
+}
+
+// walkBranch 遍历 if/range/with：条件按外层上下文解析，主体按 bodyDot 解析，else 分支保持外层上下文。
+func walkBranch(tree *parse.Tree, n *parse.BranchNode, rootDot, bodyDot bool, refs *[]Reference) {
+	walkReferences(tree, n.Pipe, rootDot, refs)
+	walkReferences(tree, n.List, bodyDot, refs)
+	if n.ElseList != nil {
+		walkReferences(tree, n.ElseList, rootDot, refs)
+	}
+}
+
+func nodeLocation(tree *parse.Tree, node parse.Node) (int, int) {
+	location, _ := tree.ErrorContext(node)
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0, 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	column, _ := strconv.Atoi(parts[len(parts)-1])
+	return line, column
+}
+
+func yamlErrorLine(err error) int {
+	match := yamlLine.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+func jsonErrorLine(content string, offset int64) int {
+	if offset <= 0 || int(offset) > len(content) {
+		return 0
+	}
+	return strings.Count(content[:offset], "\n") + 1
+}
@@ -0,0 +1,47 @@
+package template
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	content := `{{ .variables.region }}
+{{ range .nodes }}{{ .name }} {{ $.variables.suffix }}{{ end }}
+{{ with .subscription }}{{ .name }}{{ else }}{{ .unknown }}{{ end }}`
+	refs, err := References(content)
+	if err != nil {
+		t.Fatalf("references: %v", err)
+	}
+	var got []string
+	for _, ref := range refs {
+		got = append(got, strings.Join(ref.Path, ".")+"@"+strconv.Itoa(ref.Line))
+	}
+	want := "variables.region@1,nodes@2,variables.suffix@2,subscription@3,unknown@3"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected references %v", got)
+	}
+}
+
+func TestErrorLocation(t *testing.T) {
+	err := Parse("ok\n{{ .nodes")
+	if line, _ := ErrorLocation(err); line != 2 {
+		t.Fatalf("expected parse error on line 2, got %d (%v)", line, err)
+	}
+
+	_, err = Render("json", "{\n  \"a\": 1,\n}", map[string]any{})
+	var output *OutputError
+	if !errors.As(err, &output) {
+		t.Fatalf("expected output error, got %v", err)
+	}
+	if line, _ := ErrorLocation(err); line != 3 {
+		t.Fatalf("expected json error on line 3, got %d (%v)", line, err)
+	}
+
+	_, err = Render("yaml", "a: 1\nb: [\n", map[string]any{})
+	if !errors.As(err, &output) || output.Line == 0 {
+		t.Fatalf("expected yaml output error with line, got %v", err)
+	}
+}